@@ -0,0 +1,32 @@
+/*
+Copyright 2021 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// The v1 types are the hub of the conversions, and the storage version.
+// The v1alpha1 types are converted to and from them.
+
+// Hub marks LocalVolumeSet as a conversion hub
+func (*LocalVolumeSet) Hub() {}
+
+// Hub marks LocalVolumeDiscovery as a conversion hub
+func (*LocalVolumeDiscovery) Hub() {}
+
+// Hub marks LocalVolumeDiscoveryResult as a conversion hub
+func (*LocalVolumeDiscoveryResult) Hub() {}
+
+// Hub marks LocalStorageOperatorConfig as a conversion hub
+func (*LocalStorageOperatorConfig) Hub() {}
@@ -25,7 +25,9 @@ import (
 )
 
 const (
-	LocalVolumeKind = "LocalVolume"
+	LocalVolumeKind                = "LocalVolume"
+	LocalVolumeSetKind             = "LocalVolumeSet"
+	LocalStorageOperatorConfigKind = "LocalStorageOperatorConfig"
 )
 
 var (
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LocalStorageOperatorConfigName is the name of the only LocalStorageOperatorConfig read by the operator
+const LocalStorageOperatorConfigName = "cluster"
+
+// NodeDaemonConfig configures the daemonset of a node daemon
+type NodeDaemonConfig struct {
+	// Image of the daemon. It will default to the diskmaker image of the operator.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Resources are the compute resources of the daemon container. No requests or limits are set by default.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// UpdateStrategy of the daemonset. It will default to a rolling update with a maxUnavailable of 10%.
+	// +optional
+	UpdateStrategy *appsv1.DaemonSetUpdateStrategy `json:"updateStrategy,omitempty"`
+}
+
+// LocalStorageOperatorConfigSpec defines the settings of the node daemons shared by all the LocalVolumes,
+// LocalVolumeSets and LocalVolumeDiscoveries
+type LocalStorageOperatorConfigSpec struct {
+	// DiskMaker configures the diskmaker-manager daemonset, that provisions the PVs of the LocalVolumes and LocalVolumeSets
+	// +optional
+	DiskMaker NodeDaemonConfig `json:"diskMaker,omitempty"`
+	// Discovery configures the diskmaker-discovery daemonset of the LocalVolumeDiscovery
+	// +optional
+	Discovery NodeDaemonConfig `json:"discovery,omitempty"`
+	// ImagePullPolicy of the daemons. It will default to IfNotPresent.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ImagePullSecrets are the secrets of the namespace of the operator used to pull the images of the daemons
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// PriorityClassName of the daemons. It will default to openshift-user-critical.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// LogVerbosity is the klog verbosity of the daemons, passed with their --v flag
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LogVerbosity int32 `json:"logVerbosity,omitempty"`
+	// Tolerations are added to the tolerations of the LocalVolumes, LocalVolumeSets and LocalVolumeDiscoveries
+	// so that the daemons run on nodes tainted for all of them
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// SymlinkDir is the host directory the symlinks to the devices are created in, under a directory per storageclass.
+	// It will default to the LOCAL_DISK_LOCATION env of the operator, or /mnt/local-storage.
+	// It can't be changed while local PVs have their symlinks in it.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	SymlinkDir string `json:"symlinkDir,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:storageversion
+// +kubebuilder:resource:path=localstorageoperatorconfigs,scope=Cluster
+
+// LocalStorageOperatorConfig configures the daemonsets that the operator creates on the nodes.
+// Only the LocalStorageOperatorConfig named "cluster" is used.
+type LocalStorageOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LocalStorageOperatorConfigSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LocalStorageOperatorConfigList contains a list of LocalStorageOperatorConfig
+type LocalStorageOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalStorageOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalStorageOperatorConfig{}, &LocalStorageOperatorConfigList{})
+}
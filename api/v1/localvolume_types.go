@@ -61,9 +61,98 @@ type StorageClassDevice struct {
 	// File system type
 	// +optional
 	FSType string `json:"fsType,omitempty"`
+	// Filesystem configures how the devices are formatted with fsType and mounted.
+	// It only applies when volumeMode is Filesystem and fsType is set.
+	// +optional
+	Filesystem *FilesystemSpec `json:"filesystem,omitempty"`
 	// A list of device paths which would be chosen for local storage.
 	// For example - ["/dev/sda", "/dev/sdb", "/dev/disk/by-id/ata-crucial"]
 	DevicePaths []string `json:"devicePaths,omitempty"`
+	// Encryption of the devices with LUKS. The devices are not encrypted when it is not set.
+	// +optional
+	Encryption *EncryptionSpec `json:"encryption,omitempty"`
+	// IdentityPolicy is the ordered list of persistent names tried for the devices, the device is symlinked
+	// by the first one that exists. Defaults to by-id, wwn, by-path, partuuid and by-lso.
+	// The device is symlinked by its kernel name, which can change after a reboot, when it has none of them.
+	// +optional
+	IdentityPolicy []DeviceIdentity `json:"identityPolicy,omitempty"`
+}
+
+// DeviceIdentity is a source of persistent names of the devices
+// +kubebuilder:validation:Enum=by-id;wwn;by-path;partuuid;by-lso
+type DeviceIdentity string
+
+const (
+	// DeviceIdentityByID is any link in /dev/disk/by-id
+	DeviceIdentityByID DeviceIdentity = "by-id"
+	// DeviceIdentityWWN is the /dev/disk/by-id/wwn-* link
+	DeviceIdentityWWN DeviceIdentity = "wwn"
+	// DeviceIdentityByPath is the /dev/disk/by-path link. It is bound to the port or slot of the device,
+	// a disk replaced in the same slot takes the identity of the previous one
+	DeviceIdentityByPath DeviceIdentity = "by-path"
+	// DeviceIdentityPartUUID is the /dev/disk/by-partuuid link of partitions
+	DeviceIdentityPartUUID DeviceIdentity = "partuuid"
+	// DeviceIdentityByLSO is a /dev/disk/by-lso/<serial> link, created by the diskmaker with a udev rule
+	DeviceIdentityByLSO DeviceIdentity = "by-lso"
+)
+
+// EncryptionKeyPolicy determines where the LUKS keys of the encrypted devices come from
+type EncryptionKeyPolicy string
+
+const (
+	// EncryptionKeySecret encrypts all the devices with the key of the Secret referenced by keySecretRef.
+	// Once the key of a device is rotated, it is stored in the Secret of the device like generated keys.
+	EncryptionKeySecret EncryptionKeyPolicy = "Secret"
+	// EncryptionKeyGenerated encrypts each device with a random key, that the diskmaker stores
+	// in a Secret of the device in the namespace of the object
+	EncryptionKeyGenerated EncryptionKeyPolicy = "Generated"
+)
+
+// FilesystemSpec configures the filesystem of Filesystem mode volumes.
+// The devices are formatted with it before their PVs are created, and again after they are cleaned up.
+type FilesystemSpec struct {
+	// MkfsOptions are passed to mkfs.<fsType> when the device is formatted.
+	// For example - ["-m", "0"] for ext4 or ["-m", "reflink=1"] for xfs
+	// +optional
+	MkfsOptions []string `json:"mkfsOptions,omitempty"`
+	// Label of the filesystem.
+	// +optional
+	Label string `json:"label,omitempty"`
+	// MountOptions are set on the storage class and the PVs.
+	// For example - ["noatime"]
+	// +optional
+	MountOptions []string `json:"mountOptions,omitempty"`
+}
+
+// EncryptionSpec configures the LUKS encryption of the devices.
+// The devices are formatted with LUKS when they are claimed, and the PVs are created on the opened devices.
+type EncryptionSpec struct {
+	// KeyPolicy determines where the keys of the devices come from
+	// +kubebuilder:validation:Enum=Secret;Generated
+	KeyPolicy EncryptionKeyPolicy `json:"keyPolicy"`
+	// KeySecretRef references the Secret holding the key in its "key" entry, in the namespace of the object.
+	// It is required with the Secret keyPolicy.
+	// +optional
+	KeySecretRef *corev1.LocalObjectReference `json:"keySecretRef,omitempty"`
+	// Cipher used to format the devices. For example, aes-xts-plain64. Defaults to the cryptsetup default.
+	// +optional
+	Cipher string `json:"cipher,omitempty"`
+	// Escrow copies the keys of the devices to a Secret or a directory of the nodes,
+	// each time a device is formatted or its key is rotated.
+	// +optional
+	Escrow *KeyEscrowSpec `json:"escrow,omitempty"`
+}
+
+// KeyEscrowSpec configures where the keys of the encrypted devices are escrowed
+type KeyEscrowSpec struct {
+	// SecretName is the Secret in the namespace of the object that the keys are copied to.
+	// It has an entry per node and device, named like the Secret of the device key.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// HostDir is a directory of the nodes that the keys are written to, one file per device named after its device-mapper node.
+	// It must be under /var/lib/local-storage/escrow.
+	// +optional
+	HostDir string `json:"hostDir,omitempty"`
 }
 
 // LocalVolumeStatus defines the observed state of LocalVolume
@@ -84,6 +173,55 @@ type LocalVolumeStatus struct {
 	// generations are used to determine when an item needs to be reconciled or has changed in a way that needs a reaction.
 	// +optional
 	Generations []operatorv1.GenerationStatus `json:"generations,omitempty"`
+
+	// Nodes is the provisioning state of the devicePaths on each node, reported by the diskmakers and sorted by node name
+	// +optional
+	// +listType=map
+	// +listMapKey=nodeName
+	Nodes []LocalVolumeNodeStatus `json:"nodes,omitempty"`
+}
+
+// LocalVolumeNodeStatus is the provisioning state of the devicePaths of a LocalVolume on a node
+type LocalVolumeNodeStatus struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+	// Devices are the devicePaths of all the storageClassDevices, sorted by storage class and path
+	// +optional
+	Devices []DevicePathStatus `json:"devices,omitempty"`
+}
+
+// DevicePathState is the provisioning state of a devicePath on a node
+// +kubebuilder:validation:Enum=Linked;Matched;Missing;Refused
+type DevicePathState string
+
+const (
+	// DevicePathLinked is the state of the devices symlinked for the provisioner, whose PV is created
+	DevicePathLinked DevicePathState = "Linked"
+	// DevicePathMatched is the state of the devices found on the node but not provisioned yet, or that could not be
+	DevicePathMatched DevicePathState = "Matched"
+	// DevicePathMissing is the state of the devicePaths that don't exist on the node
+	DevicePathMissing DevicePathState = "Missing"
+	// DevicePathRefused is the state of the devices that can't be provisioned, because they are in use or not block devices
+	DevicePathRefused DevicePathState = "Refused"
+)
+
+// DevicePathStatus is the provisioning state of a devicePath on a node
+type DevicePathStatus struct {
+	// StorageClassName is the storage class of the storageClassDevices listing the devicePath
+	StorageClassName string `json:"storageClassName"`
+	// DevicePath is the path as listed in the devicePaths
+	DevicePath string `json:"devicePath"`
+	// State is the provisioning state of the devicePath
+	State DevicePathState `json:"state"`
+	// DeviceName is the kernel name of the device the path resolves to
+	// +optional
+	DeviceName string `json:"deviceName,omitempty"`
+	// PersistentVolumeName is the name of the PV of the device
+	// +optional
+	PersistentVolumeName string `json:"persistentVolumeName,omitempty"`
+	// Message is why the device is not linked
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:root=true
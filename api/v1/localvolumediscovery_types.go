@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DiscoveryPhase defines the observed phase of the discovery process
+type DiscoveryPhase string
+
+// Different phases of the discovery process
+const (
+	// Discovering represents that the continuous discovery of devices is in progress
+	Discovering DiscoveryPhase = "Discovering"
+	// DiscoveryFailed represents that the discovery process has failed
+	DiscoveryFailed DiscoveryPhase = "DiscoveryFailed"
+)
+
+// DiscoveredDeviceType is the types that will be discovered by the LSO.
+type DiscoveredDeviceType string
+
+const (
+	// DiskType represents a device-type of block disk
+	DiskType DiscoveredDeviceType = "disk"
+	// PartType represents a device-type of partition
+	PartType DiscoveredDeviceType = "part"
+	// LVMType is an LVM type
+	LVMType DiscoveredDeviceType = "lvm"
+	// RAIDType is the type of the md arrays of all the RAID levels
+	RAIDType DiscoveredDeviceType = "raid"
+	// MultipathType is the type of the dm-multipath devices
+	MultipathType DiscoveredDeviceType = "mpath"
+)
+
+// LocalVolumeDiscoverySpec defines the desired state of LocalVolumeDiscovery
+type LocalVolumeDiscoverySpec struct {
+	// Nodes on which the automatic detection policies must run.
+	// +optional
+	NodeSelector *corev1.NodeSelector `json:"nodeSelector,omitempty"`
+	// If specified tolerations is the list of toleration that is passed to the
+	// LocalVolumeDiscovery Daemon
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// ProbeInterval is the interval between two periodic scans of the devices on a node.
+	// Defaults to 5m
+	// +optional
+	ProbeInterval *metav1.Duration `json:"probeInterval,omitempty"`
+	// UdevEventPeriod is the period over which udev events are collapsed into a single scan.
+	// Defaults to 5s
+	// +optional
+	UdevEventPeriod *metav1.Duration `json:"udevEventPeriod,omitempty"`
+	// UdevExclusionFilter is a list of case-insensitive regular expressions. udev events on devices
+	// matching any of them don't trigger a scan. Defaults to dm-[0-9]+, rbd[0-9] and nbd[0-9]+
+	// The events on multipath devices are never excluded.
+	// +optional
+	UdevExclusionFilter []string `json:"udevExclusionFilter,omitempty"`
+	// SupportedDeviceTypes is the list of device types that are discovered.
+	// Defaults to disk, part, lvm, raid and mpath
+	// +optional
+	SupportedDeviceTypes []DiscoveredDeviceType `json:"supportedDeviceTypes,omitempty"`
+	// ManagementState of the LocalVolumeDiscovery. Unmanaged leaves the discovery daemonset as is and freezes
+	// the LocalVolumeDiscoveryResults, Removed deletes the daemonset and the results. It will default to Managed.
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	// +optional
+	ManagementState operatorv1.ManagementState `json:"managementState,omitempty"`
+}
+
+// LocalVolumeDiscoveryStatus defines the observed state of LocalVolumeDiscovery
+type LocalVolumeDiscoveryStatus struct {
+	// Phase represents the current phase of discovery process
+	// This is used by the OLM UI to provide status information
+	// to the user
+	Phase DiscoveryPhase `json:"phase,omitempty"`
+	// Conditions are the latest observations of the state of the discovery.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// observedGeneration is the last generation change the operator has dealt with
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Summary aggregates the Available devices reported by the LocalVolumeDiscoveryResults of all the nodes
+	// +optional
+	Summary *DiscoverySummary `json:"summary,omitempty"`
+	// state indicates what the operator has observed to be its current operational status.
+	// +optional
+	State operatorv1.ManagementState `json:"managementState,omitempty"`
+}
+
+// DiscoverySummary is the cluster level summary of the discovered devices
+type DiscoverySummary struct {
+	// TotalNodes is the number of nodes that reported a LocalVolumeDiscoveryResult
+	TotalNodes int32 `json:"totalNodes"`
+	// StaleNodes is the number of nodes whose LocalVolumeDiscoveryResult has not been refreshed recently
+	StaleNodes int32 `json:"staleNodes"`
+	// OldestStaleResultTimeStamp is the discovery time of the oldest stale LocalVolumeDiscoveryResult
+	// +optional
+	OldestStaleResultTimeStamp *metav1.Time `json:"oldestStaleResultTimeStamp,omitempty"`
+	// AvailableDeviceCount is the total number of Available devices in the cluster
+	AvailableDeviceCount int32 `json:"availableDeviceCount"`
+	// AvailableCapacity is the total capacity of Available devices in the cluster
+	AvailableCapacity resource.Quantity `json:"availableCapacity"`
+	// DeviceGroups is the cluster wide list of Available devices, grouped by their properties
+	// +optional
+	DeviceGroups []DeviceGroupSummary `json:"deviceGroups,omitempty"`
+	// Nodes contains the summary of Available devices on each node
+	// +optional
+	Nodes []NodeDiscoverySummary `json:"nodes,omitempty"`
+}
+
+// NodeDiscoverySummary is the summary of the discovered devices on a single node
+type NodeDiscoverySummary struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+	// Stale is set when the LocalVolumeDiscoveryResult of the node has not been refreshed recently
+	// +optional
+	Stale bool `json:"stale,omitempty"`
+	// AvailableDeviceCount is the number of Available devices on the node
+	AvailableDeviceCount int32 `json:"availableDeviceCount"`
+	// AvailableCapacity is the total capacity of Available devices on the node
+	AvailableCapacity resource.Quantity `json:"availableCapacity"`
+	// DeviceGroups is the list of Available devices on the node, grouped by their properties
+	// +optional
+	DeviceGroups []DeviceGroupSummary `json:"deviceGroups,omitempty"`
+}
+
+// DeviceGroupSummary counts the Available devices that share the same type, mechanical property, model and size bucket
+type DeviceGroupSummary struct {
+	// Type of the devices in the group
+	Type DiscoveredDeviceType `json:"type"`
+	// Property represents whether the devices in the group are rotational or not
+	// +optional
+	Property DeviceMechanicalProperty `json:"property,omitempty"`
+	// Model of the devices in the group
+	// +optional
+	Model string `json:"model,omitempty"`
+	// SizeBucket is the size range of the devices in the group. For eg, 512Gi-1Ti
+	SizeBucket string `json:"sizeBucket"`
+	// Count is the number of devices in the group
+	Count int32 `json:"count"`
+	// Capacity is the total capacity of the devices in the group
+	Capacity resource.Quantity `json:"capacity"`
+}
+
+//+kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+//+kubebuilder:storageversion
+// +kubebuilder:resource:path=localvolumediscoveries,scope=Namespaced
+// LocalVolumeDiscovery is the Schema for the localvolumediscoveries API
+type LocalVolumeDiscovery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeDiscoverySpec   `json:"spec,omitempty"`
+	Status LocalVolumeDiscoveryStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LocalVolumeDiscoveryList contains a list of LocalVolumeDiscovery
+type LocalVolumeDiscoveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeDiscovery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeDiscovery{}, &LocalVolumeDiscoveryList{})
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2021 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeviceMechanicalProperty holds the device's mechanical spec. It can be rotational or nonRotational
+type DeviceMechanicalProperty string
+
+// The mechanical properties of the devices
+const (
+	// Rotational refers to magnetic disks
+	Rotational DeviceMechanicalProperty = "Rotational"
+	// NonRotational refers to ssds
+	NonRotational DeviceMechanicalProperty = "NonRotational"
+)
+
+// DeviceType is the types that will be supported by the LSO.
+type DeviceType string
+
+const (
+	// RawDisk represents a device-type of block disk
+	RawDisk DeviceType = "disk"
+	// Partition represents a device-type of partition
+	Partition DeviceType = "part"
+	// Loop type device
+	Loop DeviceType = "loop"
+	// RAID represents the md arrays of all the RAID levels
+	RAID DeviceType = "raid"
+	// Multipath represents the dm-multipath devices. The paths of a multipath device are never selected
+	Multipath DeviceType = "mpath"
+)
+
+// ClaimPolicy determines when the matching devices are provisioned
+type ClaimPolicy string
+
+const (
+	// ClaimPolicyAutomatic provisions the matching devices once they are older than the minimum device age
+	ClaimPolicyAutomatic ClaimPolicy = "Automatic"
+	// ClaimPolicyManual records the matching devices as pending in the LocalVolumeSetApproval of their node,
+	// and provisions them only once they are approved
+	ClaimPolicyManual ClaimPolicy = "Manual"
+)
+
+// DeviceInclusionSpec holds the inclusion filter spec
+type DeviceInclusionSpec struct {
+	// Devices is the list of devices that should be used for automatic detection.
+	// This would be one of the types supported by the local-storage operator. Currently,
+	// the supported types are: disk, part, raid, mpath. If the list is empty only `disk` types will be selected
+	// +optional
+	DeviceTypes []DeviceType `json:"deviceTypes,omitempty"`
+	// DeviceMechanicalProperty denotes whether Rotational or NonRotational disks should be used.
+	// by default, it selects both
+	// +optional
+	DeviceMechanicalProperties []DeviceMechanicalProperty `json:"deviceMechanicalProperties,omitempty"`
+	// MinSize is the minimum size of the device which needs to be included. Defaults to `1Gi` if empty
+	// +optional
+	MinSize *resource.Quantity `json:"minSize,omitempty"`
+	// MaxSize is the maximum size of the device which needs to be included
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// Models is a list of device models. If not empty, the device's model as outputted by lsblk needs
+	// to contain at least one of these strings.
+	// +optional
+	Models []string `json:"models,omitempty"`
+	// Vendors is a list of device vendors. If not empty, the device's model as outputted by lsblk needs
+	// to contain at least one of these strings.
+	// +optional
+	Vendors []string `json:"vendors,omitempty"`
+	// DevicePaths is a list of device paths, such as /dev/disk/by-id links. If not empty, only the devices
+	// these paths point to are included.
+	// +optional
+	DevicePaths []string `json:"devicePaths,omitempty"`
+	// ExcludedDevicePaths is a list of device paths, such as /dev/disk/by-id links, whose devices are never included.
+	// +optional
+	ExcludedDevicePaths []string `json:"excludedDevicePaths,omitempty"`
+}
+
+// SharedFilesystemSpec configures the directory PVs carved out of a shared XFS filesystem.
+// The first matching device of each node is formatted with XFS and mounted with project quotas,
+// and each directory is limited by its own XFS project quota.
+type SharedFilesystemSpec struct {
+	// VolumeCount is the number of directory PVs created on each node.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	VolumeCount int32 `json:"volumeCount"`
+	// VolumeSize is the project quota of each directory.
+	// It will default to the size of the filesystem divided by volumeCount.
+	// +optional
+	VolumeSize *resource.Quantity `json:"volumeSize,omitempty"`
+}
+
+// RAIDLevel is the RAID level of an md array
+type RAIDLevel string
+
+const (
+	RAID0  RAIDLevel = "raid0"
+	RAID1  RAIDLevel = "raid1"
+	RAID5  RAIDLevel = "raid5"
+	RAID6  RAIDLevel = "raid6"
+	RAID10 RAIDLevel = "raid10"
+)
+
+// RAIDSpec configures the md arrays the matching devices are grouped into.
+// A degraded array is rebuilt with the next matching device, except the raid0 arrays that have no redundancy.
+type RAIDSpec struct {
+	// Level of the arrays
+	// +kubebuilder:validation:Enum=raid0;raid1;raid5;raid6;raid10
+	Level RAIDLevel `json:"level"`
+	// Width is the number of devices of each array
+	// +kubebuilder:validation:Minimum=2
+	Width int32 `json:"width"`
+}
+
+// LocalVolumeSetSpec defines the desired state of LocalVolumeSet
+type LocalVolumeSetSpec struct {
+	// Nodes on which the automatic detection policies must run.
+	// +optional
+	NodeSelector *corev1.NodeSelector `json:"nodeSelector,omitempty"`
+	// StorageClassName to use for set of matched devices
+	StorageClassName string `json:"storageClassName"`
+	// MaxDeviceCount is the maximum number of Devices that needs to be detected per node.
+	// If it is not specified, there will be no limit to the number of provisioned devices.
+	// +optional
+	MaxDeviceCount *int32 `json:"maxDeviceCount,omitempty"`
+	// VolumeMode determines whether the PV created is Block or Filesystem.
+	// It will default to Filesystem.
+	// +optional
+	VolumeMode PersistentVolumeMode `json:"volumeMode,omitempty"`
+	// FSType type to create when volumeMode is Filesystem
+	// +optional
+	FSType string `json:"fsType,omitempty"`
+	// Filesystem configures how the devices are formatted with fsType and mounted.
+	// It only applies when volumeMode is Filesystem and fsType is set.
+	// +optional
+	Filesystem *FilesystemSpec `json:"filesystem,omitempty"`
+	// If specified, a list of tolerations to pass to the discovery daemons.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// DeviceInclusionSpec is the filtration rule for including a device in the device discovery
+	// +optional
+	DeviceInclusionSpec *DeviceInclusionSpec `json:"deviceInclusionSpec,omitempty"`
+	// ClaimPolicy determines whether the matching devices are provisioned automatically,
+	// or only once approved in the LocalVolumeSetApproval of their node. It will default to Automatic.
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	// +optional
+	ClaimPolicy ClaimPolicy `json:"claimPolicy,omitempty"`
+	// Encryption of the devices with LUKS. The devices are not encrypted when it is not set.
+	// +optional
+	Encryption *EncryptionSpec `json:"encryption,omitempty"`
+	// SharedFilesystem publishes directories of a single XFS formatted device per node as PVs, instead of whole devices.
+	// volumeMode must be Filesystem, and encryption is not supported.
+	// +optional
+	SharedFilesystem *SharedFilesystemSpec `json:"sharedFilesystem,omitempty"`
+	// RAID groups the matching devices of each node into md arrays, and provisions a PV for each array instead of each device.
+	// maxDeviceCount limits the number of arrays per node.
+	// +optional
+	RAID *RAIDSpec `json:"raid,omitempty"`
+	// IdentityPolicy is the ordered list of persistent names tried for the devices, the device is symlinked
+	// by the first one that exists. Defaults to by-id, wwn, by-path, partuuid and by-lso.
+	// The device is symlinked by its kernel name, which can change after a reboot, when it has none of them.
+	// +optional
+	IdentityPolicy []DeviceIdentity `json:"identityPolicy,omitempty"`
+	// Priority arbitrates between the LocalVolumeSets that match the same devices of a node.
+	// A device is claimed by the LocalVolumeSet with the highest priority that can still claim it,
+	// then by the oldest one. It will default to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// ManagementState of the LocalVolumeSet. Unmanaged freezes the provisioning and the cleanup of its PVs on the nodes,
+	// Removed deletes its PVs that are not bound to a claim with their symlinks, the bound ones once they are released.
+	// It will default to Managed.
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	// +optional
+	ManagementState operatorv1.ManagementState `json:"managementState,omitempty"`
+}
+
+// LocalVolumeSetStatus defines the observed state of LocalVolumeSet
+type LocalVolumeSetStatus struct {
+	// Conditions are the latest observations of the state of the LocalVolumeSet.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// TotalProvisionedDeviceCount is the count of the total devices over which the PVs has been provisioned
+	// +optional
+	TotalProvisionedDeviceCount *int32 `json:"totalProvisionedDeviceCount,omitempty"`
+	// observedGeneration is the last generation change the operator has dealt with
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Nodes is the state of the LocalVolumeSet on each node, reported by the diskmakers and sorted by node name
+	// +optional
+	// +listType=map
+	// +listMapKey=nodeName
+	Nodes []LocalVolumeSetNodeStatus `json:"nodes,omitempty"`
+	// state indicates what the operator has observed to be its current operational status.
+	// +optional
+	State operatorv1.ManagementState `json:"managementState,omitempty"`
+}
+
+// LocalVolumeSetNodeStatus is the state of a LocalVolumeSet on a node
+type LocalVolumeSetNodeStatus struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+	// ProvisionedDeviceCount is the number of PVs provisioned by the LocalVolumeSet on the node
+	// +optional
+	ProvisionedDeviceCount int32 `json:"provisionedDeviceCount,omitempty"`
+	// TotalCapacity is the sum of the capacity of the PVs provisioned on the node
+	// +optional
+	TotalCapacity *resource.Quantity `json:"totalCapacity,omitempty"`
+	// BoundDeviceCount is the number of PVs of the node that are bound to a claim
+	// +optional
+	BoundDeviceCount int32 `json:"boundDeviceCount,omitempty"`
+	// AvailableDeviceCount is the number of PVs of the node that are available for a claim
+	// +optional
+	AvailableDeviceCount int32 `json:"availableDeviceCount,omitempty"`
+	// DelayedDevices are the kernel names of the matching devices that are not older than the minimum age yet, sorted
+	// +optional
+	DelayedDevices []string `json:"delayedDevices,omitempty"`
+	// LastReconcileTime is the time the diskmaker of the node last reconciled the LocalVolumeSet.
+	// When nothing else changes, it is only refreshed every 5 minutes
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+	// LastError is the error of the last reconcile on the node, empty when it succeeded
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// Paused is true while the node has the local.storage.openshift.io/maintenance label,
+	// no device is provisioned on the node then
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// RAIDArrays is the health of the md arrays provisioned on the node, sorted by name
+	// +optional
+	RAIDArrays []RAIDArrayStatus `json:"raidArrays,omitempty"`
+	// TornDown is true once the PVs and symlinks of the Removed LocalVolumeSet on the node are deleted, except the RetainedPVs
+	// +optional
+	TornDown bool `json:"tornDown,omitempty"`
+	// RetainedPVs are the Released PVs of the Removed LocalVolumeSet on the node whose reclaim policy is Retain, sorted.
+	// Nothing deletes them, they must be deleted by hand
+	// +optional
+	RetainedPVs []string `json:"retainedPVs,omitempty"`
+}
+
+// RAIDArrayStatus is the health of an md array, as listed in /proc/mdstat
+type RAIDArrayStatus struct {
+	// Name of the array, it is assembled as /dev/md/<name>
+	Name string `json:"name"`
+	// State is the state of the array, with the progress of its recovery or resync
+	State string `json:"state"`
+	// Degraded is true when members of the array are missing
+	Degraded bool `json:"degraded"`
+	// RAIDDevices is the number of members of the healthy array
+	RAIDDevices int32 `json:"raidDevices"`
+	// ActiveDevices is the number of working members of the array
+	ActiveDevices int32 `json:"activeDevices"`
+	// Members are the kernel names of the members of the array
+	// +optional
+	Members []string `json:"members,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+// +kubebuilder:resource:path=localvolumesets,scope=Namespaced
+// LocalVolumeSet is the Schema for the localvolumesets API
+type LocalVolumeSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeSetSpec   `json:"spec,omitempty"`
+	Status LocalVolumeSetStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LocalVolumeSetList contains a list of LocalVolumeSet
+type LocalVolumeSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeSet{}, &LocalVolumeSetList{})
+}
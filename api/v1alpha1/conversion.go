@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// unspecifiedReason is the reason of the v1 conditions converted from v1alpha1 conditions without a reason,
+// as it is required by metav1.Condition. It is dropped when they are converted back.
+const unspecifiedReason = "Unspecified"
+
+var _ conversion.Convertible = &LocalVolumeSet{}
+var _ conversion.Convertible = &LocalVolumeDiscovery{}
+var _ conversion.Convertible = &LocalVolumeDiscoveryResult{}
+var _ conversion.Convertible = &LocalStorageOperatorConfig{}
+
+// ConvertTo converts the LocalVolumeSet to the v1 hub version
+func (src *LocalVolumeSet) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*localv1.LocalVolumeSet)
+	dst.ObjectMeta = src.ObjectMeta
+	// the spec is unchanged in v1
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalVolumeSet %q: %w", src.Name, err)
+	}
+	dst.Status = localv1.LocalVolumeSetStatus{
+		Conditions:                  convertConditionsToV1(src.Status.Conditions),
+		TotalProvisionedDeviceCount: src.Status.TotalProvisionedDeviceCount,
+		ObservedGeneration:          src.Status.ObservedGeneration,
+	}
+	// the md arrays are sorted by node, the consecutive arrays of a node make its entry
+	for _, array := range src.Status.RAIDArrays {
+		nodes := dst.Status.Nodes
+		if len(nodes) == 0 || nodes[len(nodes)-1].NodeName != array.NodeName {
+			dst.Status.Nodes = append(dst.Status.Nodes, localv1.LocalVolumeSetNodeStatus{NodeName: array.NodeName})
+		}
+		node := &dst.Status.Nodes[len(dst.Status.Nodes)-1]
+		node.RAIDArrays = append(node.RAIDArrays, localv1.RAIDArrayStatus{
+			Name:          array.Name,
+			State:         array.State,
+			Degraded:      array.Degraded,
+			RAIDDevices:   array.RAIDDevices,
+			ActiveDevices: array.ActiveDevices,
+			Members:       array.Members,
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts the LocalVolumeSet from the v1 hub version.
+// The nodes without md arrays have no equivalent in v1alpha1.
+func (dst *LocalVolumeSet) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*localv1.LocalVolumeSet)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalVolumeSet %q: %w", src.Name, err)
+	}
+	dst.Status = LocalVolumeSetStatus{
+		Conditions:                  convertConditionsFromV1(src.Status.Conditions),
+		TotalProvisionedDeviceCount: src.Status.TotalProvisionedDeviceCount,
+		ObservedGeneration:          src.Status.ObservedGeneration,
+	}
+	for _, node := range src.Status.Nodes {
+		for _, array := range node.RAIDArrays {
+			dst.Status.RAIDArrays = append(dst.Status.RAIDArrays, RAIDArrayStatus{
+				NodeName:      node.NodeName,
+				Name:          array.Name,
+				State:         array.State,
+				Degraded:      array.Degraded,
+				RAIDDevices:   array.RAIDDevices,
+				ActiveDevices: array.ActiveDevices,
+				Members:       array.Members,
+			})
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts the LocalVolumeDiscovery to the v1 hub version
+func (src *LocalVolumeDiscovery) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*localv1.LocalVolumeDiscovery)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalVolumeDiscovery %q: %w", src.Name, err)
+	}
+	dst.Status = localv1.LocalVolumeDiscoveryStatus{
+		Phase:              localv1.DiscoveryPhase(src.Status.Phase),
+		Conditions:         convertConditionsToV1(src.Status.Conditions),
+		ObservedGeneration: src.Status.ObservedGeneration,
+	}
+	if src.Status.Summary != nil {
+		dst.Status.Summary = &localv1.DiscoverySummary{}
+		if err := convertUnchanged(src.Status.Summary, dst.Status.Summary); err != nil {
+			return fmt.Errorf("could not convert the summary of LocalVolumeDiscovery %q: %w", src.Name, err)
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the LocalVolumeDiscovery from the v1 hub version
+func (dst *LocalVolumeDiscovery) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*localv1.LocalVolumeDiscovery)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalVolumeDiscovery %q: %w", src.Name, err)
+	}
+	dst.Status = LocalVolumeDiscoveryStatus{
+		Phase:              DiscoveryPhase(src.Status.Phase),
+		Conditions:         convertConditionsFromV1(src.Status.Conditions),
+		ObservedGeneration: src.Status.ObservedGeneration,
+	}
+	if src.Status.Summary != nil {
+		dst.Status.Summary = &DiscoverySummary{}
+		if err := convertUnchanged(src.Status.Summary, dst.Status.Summary); err != nil {
+			return fmt.Errorf("could not convert the summary of LocalVolumeDiscovery %q: %w", src.Name, err)
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts the LocalVolumeDiscoveryResult to the v1 hub version
+func (src *LocalVolumeDiscoveryResult) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*localv1.LocalVolumeDiscoveryResult)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalVolumeDiscoveryResult %q: %w", src.Name, err)
+	}
+	if err := convertUnchanged(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("could not convert the status of LocalVolumeDiscoveryResult %q: %w", src.Name, err)
+	}
+	return nil
+}
+
+// ConvertFrom converts the LocalVolumeDiscoveryResult from the v1 hub version
+func (dst *LocalVolumeDiscoveryResult) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*localv1.LocalVolumeDiscoveryResult)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalVolumeDiscoveryResult %q: %w", src.Name, err)
+	}
+	if err := convertUnchanged(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("could not convert the status of LocalVolumeDiscoveryResult %q: %w", src.Name, err)
+	}
+	return nil
+}
+
+// ConvertTo converts the LocalStorageOperatorConfig to the v1 hub version
+func (src *LocalStorageOperatorConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*localv1.LocalStorageOperatorConfig)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalStorageOperatorConfig %q: %w", src.Name, err)
+	}
+	return nil
+}
+
+// ConvertFrom converts the LocalStorageOperatorConfig from the v1 hub version
+func (dst *LocalStorageOperatorConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*localv1.LocalStorageOperatorConfig)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertUnchanged(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("could not convert the spec of LocalStorageOperatorConfig %q: %w", src.Name, err)
+	}
+	return nil
+}
+
+// convertUnchanged converts a type whose schema is the same in both versions, through its JSON representation
+func convertUnchanged(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func convertConditionsToV1(conditions []operatorv1.OperatorCondition) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+	converted := make([]metav1.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		reason := condition.Reason
+		if reason == "" {
+			reason = unspecifiedReason
+		}
+		converted = append(converted, metav1.Condition{
+			Type:               condition.Type,
+			Status:             metav1.ConditionStatus(condition.Status),
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             reason,
+			Message:            condition.Message,
+		})
+	}
+	return converted
+}
+
+// convertConditionsFromV1 converts the conditions back, their observedGeneration has no equivalent in v1alpha1
+func convertConditionsFromV1(conditions []metav1.Condition) []operatorv1.OperatorCondition {
+	if conditions == nil {
+		return nil
+	}
+	converted := make([]operatorv1.OperatorCondition, 0, len(conditions))
+	for _, condition := range conditions {
+		reason := condition.Reason
+		if reason == unspecifiedReason {
+			reason = ""
+		}
+		converted = append(converted, operatorv1.OperatorCondition{
+			Type:               condition.Type,
+			Status:             operatorv1.ConditionStatus(condition.Status),
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             reason,
+			Message:            condition.Message,
+		})
+	}
+	return converted
+}
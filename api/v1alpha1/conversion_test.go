@@ -0,0 +1,216 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the times of the API have a precision of a second
+var now = metav1.NewTime(time.Now().Truncate(time.Second))
+
+func quantity(value string) *resource.Quantity {
+	q := resource.MustParse(value)
+	return &q
+}
+
+func newLocalVolumeSet() *LocalVolumeSet {
+	two := int32(2)
+	ten := int32(10)
+	return &LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "lvset",
+			Namespace:         "openshift-local-storage",
+			Labels:            map[string]string{"app": "lso"},
+			Finalizers:        []string{"storage.openshift.com/local-volume-protection"},
+			CreationTimestamp: now,
+			Generation:        3,
+		},
+		Spec: LocalVolumeSetSpec{
+			NodeSelector: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node1"}}},
+			}}},
+			StorageClassName: "fast",
+			MaxDeviceCount:   &ten,
+			VolumeMode:       localv1.PersistentVolumeFilesystem,
+			FSType:           "xfs",
+			Filesystem:       &localv1.FilesystemSpec{MkfsOptions: []string{"-K"}, MountOptions: []string{"noatime"}},
+			Tolerations:      []corev1.Toleration{{Key: "storage", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+			DeviceInclusionSpec: &DeviceInclusionSpec{
+				DeviceTypes:                []DeviceType{RawDisk, Partition},
+				DeviceMechanicalProperties: []DeviceMechanicalProperty{NonRotational},
+				MinSize:                    quantity("10Gi"),
+				MaxSize:                    quantity("1Ti"),
+				Models:                     []string{"SAMSUNG"},
+				Vendors:                    []string{"ATA"},
+				DevicePaths:                []string{"/dev/disk/by-id/wwn-0x1"},
+				ExcludedDevicePaths:        []string{"/dev/sda"},
+			},
+			ClaimPolicy: ClaimPolicyManual,
+			Encryption: &localv1.EncryptionSpec{
+				KeyPolicy:    localv1.EncryptionKeySecret,
+				KeySecretRef: &corev1.LocalObjectReference{Name: "luks-key"},
+			},
+			RAID:           &RAIDSpec{Level: RAID1, Width: 2},
+			IdentityPolicy: []localv1.DeviceIdentity{localv1.DeviceIdentityWWN, localv1.DeviceIdentityByPath},
+			Priority:       5,
+		},
+		Status: LocalVolumeSetStatus{
+			Conditions: []operatorv1.OperatorCondition{
+				{Type: "DaemonSetsAvailable", Status: operatorv1.ConditionTrue, LastTransitionTime: now, Message: "DaemonSets Available"},
+				{Type: "Available", Status: operatorv1.ConditionFalse, LastTransitionTime: now, Reason: "NoDevices", Message: "no device"},
+			},
+			TotalProvisionedDeviceCount: &two,
+			ObservedGeneration:          3,
+			RAIDArrays: []RAIDArrayStatus{
+				{NodeName: "node1", Name: "lso-a", State: "clean", RAIDDevices: 2, ActiveDevices: 2, Members: []string{"sdb", "sdc"}},
+				{NodeName: "node1", Name: "lso-b", State: "clean, degraded, recovering (12.5%)", Degraded: true, RAIDDevices: 2, ActiveDevices: 1, Members: []string{"sdd"}},
+				{NodeName: "node2", Name: "lso-a", State: "active", RAIDDevices: 2, ActiveDevices: 2},
+			},
+		},
+	}
+}
+
+func TestLocalVolumeSetConversion(t *testing.T) {
+	lvset := newLocalVolumeSet()
+	hub := &localv1.LocalVolumeSet{}
+	assert.NoError(t, lvset.ConvertTo(hub))
+
+	assert.Equal(t, "fast", hub.Spec.StorageClassName)
+	assert.Equal(t, []localv1.DeviceType{localv1.RawDisk, localv1.Partition}, hub.Spec.DeviceInclusionSpec.DeviceTypes)
+	assert.Equal(t, unspecifiedReason, hub.Status.Conditions[0].Reason)
+	assert.Equal(t, "NoDevices", hub.Status.Conditions[1].Reason)
+	if assert.Len(t, hub.Status.Nodes, 2) {
+		assert.Equal(t, "node1", hub.Status.Nodes[0].NodeName)
+		assert.Len(t, hub.Status.Nodes[0].RAIDArrays, 2)
+		assert.Equal(t, "node2", hub.Status.Nodes[1].NodeName)
+	}
+
+	converted := &LocalVolumeSet{}
+	assert.NoError(t, converted.ConvertFrom(hub))
+	assert.True(t, equality.Semantic.DeepEqual(lvset, converted), "round trip lost data:\n%+v\n%+v", lvset, converted)
+
+	// the empty objects round trip too
+	hub = &localv1.LocalVolumeSet{}
+	assert.NoError(t, (&LocalVolumeSet{}).ConvertTo(hub))
+	converted = &LocalVolumeSet{}
+	assert.NoError(t, converted.ConvertFrom(hub))
+	assert.True(t, equality.Semantic.DeepEqual(&LocalVolumeSet{}, converted))
+}
+
+func TestLocalVolumeDiscoveryConversion(t *testing.T) {
+	interval := metav1.Duration{Duration: 10 * time.Minute}
+	discovery := &LocalVolumeDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Name: "auto-discover-devices", Namespace: "openshift-local-storage", CreationTimestamp: now},
+		Spec: LocalVolumeDiscoverySpec{
+			Tolerations:          []corev1.Toleration{{Key: "storage", Operator: corev1.TolerationOpExists}},
+			ProbeInterval:        &interval,
+			UdevExclusionFilter:  []string{"loop[0-9]+"},
+			SupportedDeviceTypes: []DiscoveredDeviceType{DiskType, MultipathType},
+		},
+		Status: LocalVolumeDiscoveryStatus{
+			Phase: Discovering,
+			Conditions: []operatorv1.OperatorCondition{
+				{Type: operatorv1.OperatorStatusTypeAvailable, Status: operatorv1.ConditionTrue, LastTransitionTime: now, Message: "DiscoveryDaemons Available"},
+			},
+			ObservedGeneration: 1,
+			Summary: &DiscoverySummary{
+				TotalNodes:                 2,
+				StaleNodes:                 1,
+				OldestStaleResultTimeStamp: &now,
+				AvailableDeviceCount:       1,
+				AvailableCapacity:          resource.MustParse("100Gi"),
+				DeviceGroups: []DeviceGroupSummary{
+					{Type: DiskType, Property: NonRotational, Model: "SAMSUNG", SizeBucket: "64Gi-128Gi", Count: 1, Capacity: resource.MustParse("100Gi")},
+				},
+				Nodes: []NodeDiscoverySummary{
+					{NodeName: "node1", AvailableDeviceCount: 1, AvailableCapacity: resource.MustParse("100Gi")},
+					{NodeName: "node2", Stale: true, AvailableCapacity: resource.MustParse("0")},
+				},
+			},
+		},
+	}
+	hub := &localv1.LocalVolumeDiscovery{}
+	assert.NoError(t, discovery.ConvertTo(hub))
+	assert.Equal(t, localv1.Discovering, hub.Status.Phase)
+	assert.Equal(t, metav1.ConditionTrue, hub.Status.Conditions[0].Status)
+	assert.Len(t, hub.Status.Summary.Nodes, 2)
+
+	converted := &LocalVolumeDiscovery{}
+	assert.NoError(t, converted.ConvertFrom(hub))
+	assert.True(t, equality.Semantic.DeepEqual(discovery, converted), "round trip lost data:\n%+v\n%+v", discovery, converted)
+}
+
+func TestLocalVolumeDiscoveryResultConversion(t *testing.T) {
+	result := &LocalVolumeDiscoveryResult{
+		ObjectMeta: metav1.ObjectMeta{Name: "discovery-result-node1", Namespace: "openshift-local-storage", Labels: map[string]string{"discovery-result-node": "node1"}},
+		Spec:       LocalVolumeDiscoveryResultSpec{NodeName: "node1"},
+		Status: LocalVolumeDiscoveryResultStatus{
+			DiscoveredTimeStamp: now.String(),
+			DiscoveredDevices: []DiscoveredDevice{
+				{
+					DeviceID: "/dev/disk/by-id/wwn-0x1",
+					Path:     "/dev/sdb",
+					Model:    "SAMSUNG",
+					Type:     DiskType,
+					Vendor:   "ATA",
+					Serial:   "S1",
+					Size:     100 << 30,
+					Property: NonRotational,
+					Status:   DeviceStatus{State: Available},
+				},
+				{
+					DeviceID:  "/dev/disk/by-id/dm-uuid-mpath-0x2",
+					Path:      "/dev/dm-0",
+					Type:      MultipathType,
+					FSType:    "xfs",
+					Status:    DeviceStatus{State: NotAvailable},
+					WWN:       "0x2",
+					Paths:     2,
+					PathState: "degraded",
+				},
+			},
+		},
+	}
+	hub := &localv1.LocalVolumeDiscoveryResult{}
+	assert.NoError(t, result.ConvertTo(hub))
+	assert.Equal(t, localv1.Available, hub.Status.DiscoveredDevices[0].Status.State)
+
+	converted := &LocalVolumeDiscoveryResult{}
+	assert.NoError(t, converted.ConvertFrom(hub))
+	assert.True(t, equality.Semantic.DeepEqual(result, converted), "round trip lost data:\n%+v\n%+v", result, converted)
+}
+
+func TestLocalStorageOperatorConfigConversion(t *testing.T) {
+	config := &LocalStorageOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: LocalStorageOperatorConfigName, Generation: 2},
+		Spec: LocalStorageOperatorConfigSpec{
+			DiskMaker: NodeDaemonConfig{
+				Image:     "registry.example.com/diskmaker:manager",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: *quantity("10m")}},
+			},
+			Discovery:         NodeDaemonConfig{Image: "registry.example.com/diskmaker:discovery"},
+			ImagePullPolicy:   corev1.PullAlways,
+			ImagePullSecrets:  []corev1.LocalObjectReference{{Name: "pull-secret"}},
+			PriorityClassName: "system-node-critical",
+			LogVerbosity:      4,
+			Tolerations:       []corev1.Toleration{{Key: "storage", Operator: corev1.TolerationOpExists}},
+			SymlinkDir:        "/var/lib/local-storage",
+		},
+	}
+	hub := &localv1.LocalStorageOperatorConfig{}
+	assert.NoError(t, config.ConvertTo(hub))
+	assert.Equal(t, "/var/lib/local-storage", hub.Spec.SymlinkDir)
+	assert.Equal(t, "registry.example.com/diskmaker:manager", hub.Spec.DiskMaker.Image)
+
+	converted := &LocalStorageOperatorConfig{}
+	assert.NoError(t, converted.ConvertFrom(hub))
+	assert.True(t, equality.Semantic.DeepEqual(config, converted), "round trip lost data:\n%+v\n%+v", config, converted)
+}
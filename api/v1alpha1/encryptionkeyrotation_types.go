@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KeyRotationPhase is the progress of the rotation of the key of a device
+type KeyRotationPhase string
+
+const (
+	// KeyRotationRotating is the phase of a device whose new key is being added
+	KeyRotationRotating KeyRotationPhase = "Rotating"
+	// KeyRotationCompleted is the phase of a device that only opens with the new key
+	KeyRotationCompleted KeyRotationPhase = "Completed"
+	// KeyRotationFailed is the phase of a device whose key could not be rotated. The rotation is retried.
+	KeyRotationFailed KeyRotationPhase = "Failed"
+)
+
+// EncryptionKeyRotationSpec defines the encrypted devices whose keys are rotated
+type EncryptionKeyRotationSpec struct {
+	// TargetKind is the kind of the object that provisioned the encrypted devices
+	// +kubebuilder:validation:Enum=LocalVolume;LocalVolumeSet
+	TargetKind string `json:"targetKind"`
+	// TargetName is the name of the object that provisioned the encrypted devices, in the namespace of the EncryptionKeyRotation
+	TargetName string `json:"targetName"`
+	// NewKeySecretRef references the Secret holding the new key of all the devices in its "key" entry.
+	// A new key is generated for each device when it is not set.
+	// +optional
+	NewKeySecretRef *corev1.LocalObjectReference `json:"newKeySecretRef,omitempty"`
+}
+
+// DeviceKeyRotation is the progress of the rotation of the key of a device
+type DeviceKeyRotation struct {
+	// NodeName is the node of the device
+	NodeName string `json:"nodeName"`
+	// DeviceID is the /dev/disk/by-id path of the device, or its /dev path if it doesn't have one.
+	DeviceID string `json:"deviceID"`
+	// Phase of the rotation
+	Phase KeyRotationPhase `json:"phase"`
+	// Message is a human readable description of the failure
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the time of the last phase change
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// EncryptionKeyRotationStatus defines the observed state of EncryptionKeyRotation
+type EncryptionKeyRotationStatus struct {
+	// Devices is the progress of the rotation on each encrypted device
+	// +optional
+	Devices []DeviceKeyRotation `json:"devices,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:path=encryptionkeyrotations,scope=Namespaced
+
+// EncryptionKeyRotation requests the rotation of the LUKS keys of the devices encrypted by a LocalVolume or a LocalVolumeSet.
+// The diskmaker of each node adds a keyslot with the new key, verifies it and removes the keyslot of the old key.
+type EncryptionKeyRotation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EncryptionKeyRotationSpec   `json:"spec,omitempty"`
+	Status EncryptionKeyRotationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// EncryptionKeyRotationList contains a list of EncryptionKeyRotation
+type EncryptionKeyRotationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EncryptionKeyRotation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EncryptionKeyRotation{}, &EncryptionKeyRotationList{})
+}
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -43,6 +44,10 @@ const (
 	PartType DiscoveredDeviceType = "part"
 	// LVMType is an LVM type
 	LVMType DiscoveredDeviceType = "lvm"
+	// RAIDType is the type of the md arrays of all the RAID levels
+	RAIDType DiscoveredDeviceType = "raid"
+	// MultipathType is the type of the dm-multipath devices
+	MultipathType DiscoveredDeviceType = "mpath"
 )
 
 // LocalVolumeDiscoverySpec defines the desired state of LocalVolumeDiscovery
@@ -54,6 +59,28 @@ type LocalVolumeDiscoverySpec struct {
 	// LocalVolumeDiscovery Daemon
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// ProbeInterval is the interval between two periodic scans of the devices on a node.
+	// Defaults to 5m
+	// +optional
+	ProbeInterval *metav1.Duration `json:"probeInterval,omitempty"`
+	// UdevEventPeriod is the period over which udev events are collapsed into a single scan.
+	// Defaults to 5s
+	// +optional
+	UdevEventPeriod *metav1.Duration `json:"udevEventPeriod,omitempty"`
+	// UdevExclusionFilter is a list of case-insensitive regular expressions. udev events on devices
+	// matching any of them don't trigger a scan. Defaults to dm-[0-9]+, rbd[0-9] and nbd[0-9]+
+	// The events on multipath devices are never excluded.
+	// +optional
+	UdevExclusionFilter []string `json:"udevExclusionFilter,omitempty"`
+	// SupportedDeviceTypes is the list of device types that are discovered.
+	// Defaults to disk, part, lvm, raid and mpath
+	// +optional
+	SupportedDeviceTypes []DiscoveredDeviceType `json:"supportedDeviceTypes,omitempty"`
+	// ManagementState of the LocalVolumeDiscovery. Unmanaged leaves the discovery daemonset as is and freezes
+	// the LocalVolumeDiscoveryResults, Removed deletes the daemonset and the results. It will default to Managed.
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	// +optional
+	ManagementState operatorv1.ManagementState `json:"managementState,omitempty"`
 }
 
 // LocalVolumeDiscoveryStatus defines the observed state of LocalVolumeDiscovery
@@ -67,6 +94,64 @@ type LocalVolumeDiscoveryStatus struct {
 	// observedGeneration is the last generation change the operator has dealt with
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Summary aggregates the Available devices reported by the LocalVolumeDiscoveryResults of all the nodes
+	// +optional
+	Summary *DiscoverySummary `json:"summary,omitempty"`
+}
+
+// DiscoverySummary is the cluster level summary of the discovered devices
+type DiscoverySummary struct {
+	// TotalNodes is the number of nodes that reported a LocalVolumeDiscoveryResult
+	TotalNodes int32 `json:"totalNodes"`
+	// StaleNodes is the number of nodes whose LocalVolumeDiscoveryResult has not been refreshed recently
+	StaleNodes int32 `json:"staleNodes"`
+	// OldestStaleResultTimeStamp is the discovery time of the oldest stale LocalVolumeDiscoveryResult
+	// +optional
+	OldestStaleResultTimeStamp *metav1.Time `json:"oldestStaleResultTimeStamp,omitempty"`
+	// AvailableDeviceCount is the total number of Available devices in the cluster
+	AvailableDeviceCount int32 `json:"availableDeviceCount"`
+	// AvailableCapacity is the total capacity of Available devices in the cluster
+	AvailableCapacity resource.Quantity `json:"availableCapacity"`
+	// DeviceGroups is the cluster wide list of Available devices, grouped by their properties
+	// +optional
+	DeviceGroups []DeviceGroupSummary `json:"deviceGroups,omitempty"`
+	// Nodes contains the summary of Available devices on each node
+	// +optional
+	Nodes []NodeDiscoverySummary `json:"nodes,omitempty"`
+}
+
+// NodeDiscoverySummary is the summary of the discovered devices on a single node
+type NodeDiscoverySummary struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+	// Stale is set when the LocalVolumeDiscoveryResult of the node has not been refreshed recently
+	// +optional
+	Stale bool `json:"stale,omitempty"`
+	// AvailableDeviceCount is the number of Available devices on the node
+	AvailableDeviceCount int32 `json:"availableDeviceCount"`
+	// AvailableCapacity is the total capacity of Available devices on the node
+	AvailableCapacity resource.Quantity `json:"availableCapacity"`
+	// DeviceGroups is the list of Available devices on the node, grouped by their properties
+	// +optional
+	DeviceGroups []DeviceGroupSummary `json:"deviceGroups,omitempty"`
+}
+
+// DeviceGroupSummary counts the Available devices that share the same type, mechanical property, model and size bucket
+type DeviceGroupSummary struct {
+	// Type of the devices in the group
+	Type DiscoveredDeviceType `json:"type"`
+	// Property represents whether the devices in the group are rotational or not
+	// +optional
+	Property DeviceMechanicalProperty `json:"property,omitempty"`
+	// Model of the devices in the group
+	// +optional
+	Model string `json:"model,omitempty"`
+	// SizeBucket is the size range of the devices in the group. For eg, 512Gi-1Ti
+	SizeBucket string `json:"sizeBucket"`
+	// Count is the number of devices in the group
+	Count int32 `json:"count"`
+	// Capacity is the total capacity of the devices in the group
+	Capacity resource.Quantity `json:"capacity"`
 }
 
 //+kubebuilder:object:root=true
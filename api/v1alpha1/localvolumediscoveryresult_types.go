@@ -60,6 +60,17 @@ type DiscoveredDevice struct {
 	FSType string `json:"fstype"`
 	// Status defines whether the device is available for use or not
 	Status DeviceStatus `json:"status"`
+	// WWN is the World Wide Name of the device, shared by all the paths to a LUN
+	// +optional
+	WWN string `json:"wwn,omitempty"`
+	// Paths is the number of paths to the LUN of the device. It is only set when there are several,
+	// the paths themselves are not listed
+	// +optional
+	Paths int32 `json:"paths,omitempty"`
+	// PathState is running when all the paths to the LUN of the device are running, degraded when some are not
+	// and failed when none is. It is only set when there are several paths
+	// +optional
+	PathState string `json:"pathState,omitempty"`
 }
 
 // LocalVolumeDiscoveryResultSpec defines the desired state of LocalVolumeDiscoveryResult
@@ -45,13 +45,28 @@ const (
 	Partition DeviceType = "part"
 	// Loop type device
 	Loop DeviceType = "loop"
+	// RAID represents the md arrays of all the RAID levels
+	RAID DeviceType = "raid"
+	// Multipath represents the dm-multipath devices. The paths of a multipath device are never selected
+	Multipath DeviceType = "mpath"
+)
+
+// ClaimPolicy determines when the matching devices are provisioned
+type ClaimPolicy string
+
+const (
+	// ClaimPolicyAutomatic provisions the matching devices once they are older than the minimum device age
+	ClaimPolicyAutomatic ClaimPolicy = "Automatic"
+	// ClaimPolicyManual records the matching devices as pending in the LocalVolumeSetApproval of their node,
+	// and provisions them only once they are approved
+	ClaimPolicyManual ClaimPolicy = "Manual"
 )
 
 // DeviceInclusionSpec holds the inclusion filter spec
 type DeviceInclusionSpec struct {
 	// Devices is the list of devices that should be used for automatic detection.
 	// This would be one of the types supported by the local-storage operator. Currently,
-	// the supported types are: disk, part. If the list is empty only `disk` types will be selected
+	// the supported types are: disk, part, raid, mpath. If the list is empty only `disk` types will be selected
 	// +optional
 	DeviceTypes []DeviceType `json:"deviceTypes,omitempty"`
 	// DeviceMechanicalProperty denotes whether Rotational or NonRotational disks should be used.
@@ -72,6 +87,49 @@ type DeviceInclusionSpec struct {
 	// to contain at least one of these strings.
 	// +optional
 	Vendors []string `json:"vendors,omitempty"`
+	// DevicePaths is a list of device paths, such as /dev/disk/by-id links. If not empty, only the devices
+	// these paths point to are included.
+	// +optional
+	DevicePaths []string `json:"devicePaths,omitempty"`
+	// ExcludedDevicePaths is a list of device paths, such as /dev/disk/by-id links, whose devices are never included.
+	// +optional
+	ExcludedDevicePaths []string `json:"excludedDevicePaths,omitempty"`
+}
+
+// SharedFilesystemSpec configures the directory PVs carved out of a shared XFS filesystem.
+// The first matching device of each node is formatted with XFS and mounted with project quotas,
+// and each directory is limited by its own XFS project quota.
+type SharedFilesystemSpec struct {
+	// VolumeCount is the number of directory PVs created on each node.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	VolumeCount int32 `json:"volumeCount"`
+	// VolumeSize is the project quota of each directory.
+	// It will default to the size of the filesystem divided by volumeCount.
+	// +optional
+	VolumeSize *resource.Quantity `json:"volumeSize,omitempty"`
+}
+
+// RAIDLevel is the RAID level of an md array
+type RAIDLevel string
+
+const (
+	RAID0  RAIDLevel = "raid0"
+	RAID1  RAIDLevel = "raid1"
+	RAID5  RAIDLevel = "raid5"
+	RAID6  RAIDLevel = "raid6"
+	RAID10 RAIDLevel = "raid10"
+)
+
+// RAIDSpec configures the md arrays the matching devices are grouped into.
+// A degraded array is rebuilt with the next matching device.
+type RAIDSpec struct {
+	// Level of the arrays
+	// +kubebuilder:validation:Enum=raid0;raid1;raid5;raid6;raid10
+	Level RAIDLevel `json:"level"`
+	// Width is the number of devices of each array
+	// +kubebuilder:validation:Minimum=2
+	Width int32 `json:"width"`
 }
 
 // LocalVolumeSetSpec defines the desired state of LocalVolumeSet
@@ -92,12 +150,48 @@ type LocalVolumeSetSpec struct {
 	// FSType type to create when volumeMode is Filesystem
 	// +optional
 	FSType string `json:"fsType,omitempty"`
+	// Filesystem configures how the devices are formatted with fsType and mounted.
+	// It only applies when volumeMode is Filesystem and fsType is set.
+	// +optional
+	Filesystem *localv1.FilesystemSpec `json:"filesystem,omitempty"`
 	// If specified, a list of tolerations to pass to the discovery daemons.
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 	// DeviceInclusionSpec is the filtration rule for including a device in the device discovery
 	// +optional
 	DeviceInclusionSpec *DeviceInclusionSpec `json:"deviceInclusionSpec,omitempty"`
+	// ClaimPolicy determines whether the matching devices are provisioned automatically,
+	// or only once approved in the LocalVolumeSetApproval of their node. It will default to Automatic.
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	// +optional
+	ClaimPolicy ClaimPolicy `json:"claimPolicy,omitempty"`
+	// Encryption of the devices with LUKS. The devices are not encrypted when it is not set.
+	// +optional
+	Encryption *localv1.EncryptionSpec `json:"encryption,omitempty"`
+	// SharedFilesystem publishes directories of a single XFS formatted device per node as PVs, instead of whole devices.
+	// volumeMode must be Filesystem, and encryption is not supported.
+	// +optional
+	SharedFilesystem *SharedFilesystemSpec `json:"sharedFilesystem,omitempty"`
+	// RAID groups the matching devices of each node into md arrays, and provisions a PV for each array instead of each device.
+	// maxDeviceCount limits the number of arrays per node.
+	// +optional
+	RAID *RAIDSpec `json:"raid,omitempty"`
+	// IdentityPolicy is the ordered list of persistent names tried for the devices, the device is symlinked
+	// by the first one that exists. Defaults to by-id, wwn, by-path, partuuid and by-lso.
+	// The device is symlinked by its kernel name, which can change after a reboot, when it has none of them.
+	// +optional
+	IdentityPolicy []localv1.DeviceIdentity `json:"identityPolicy,omitempty"`
+	// Priority arbitrates between the LocalVolumeSets that match the same devices of a node.
+	// A device is claimed by the LocalVolumeSet with the highest priority that can still claim it,
+	// then by the oldest one. It will default to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// ManagementState of the LocalVolumeSet. Unmanaged freezes the provisioning and the cleanup of its PVs on the nodes,
+	// Removed deletes its PVs that are not bound to a claim with their symlinks, the bound ones once they are released.
+	// It will default to Managed.
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	// +optional
+	ManagementState operatorv1.ManagementState `json:"managementState,omitempty"`
 }
 
 // LocalVolumeSetStatus defines the observed state of LocalVolumeSet
@@ -109,6 +203,28 @@ type LocalVolumeSetStatus struct {
 	// observedGeneration is the last generation change the operator has dealt with
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// RAIDArrays is the health of the md arrays provisioned on each node, reported by the diskmakers
+	// +optional
+	RAIDArrays []RAIDArrayStatus `json:"raidArrays,omitempty"`
+}
+
+// RAIDArrayStatus is the health of an md array, as listed in /proc/mdstat
+type RAIDArrayStatus struct {
+	// NodeName is the node of the array
+	NodeName string `json:"nodeName"`
+	// Name of the array, it is assembled as /dev/md/<name>
+	Name string `json:"name"`
+	// State is the state of the array, with the progress of its recovery or resync
+	State string `json:"state"`
+	// Degraded is true when members of the array are missing
+	Degraded bool `json:"degraded"`
+	// RAIDDevices is the number of members of the healthy array
+	RAIDDevices int32 `json:"raidDevices"`
+	// ActiveDevices is the number of working members of the array
+	ActiveDevices int32 `json:"activeDevices"`
+	// Members are the kernel names of the members of the array
+	// +optional
+	Members []string `json:"members,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Local Storage Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LocalVolumeSetApprovalSpec defines the devices of a node that an admin approved or rejected for a LocalVolumeSet
+type LocalVolumeSetApprovalSpec struct {
+	// LocalVolumeSetName is the name of the LocalVolumeSet that claims the devices
+	LocalVolumeSetName string `json:"localVolumeSetName"`
+	// NodeName is the node of the devices
+	NodeName string `json:"nodeName"`
+	// ApprovedDevices is the list of devices that can be provisioned, identified by their
+	// /dev/disk/by-id path, or by their /dev path if they don't have one.
+	// +optional
+	ApprovedDevices []string `json:"approvedDevices,omitempty"`
+	// RejectedDevices is the list of devices that are never provisioned, identified like ApprovedDevices.
+	// A device that is both approved and rejected is rejected.
+	// +optional
+	RejectedDevices []string `json:"rejectedDevices,omitempty"`
+}
+
+// PendingDevice is a matching device waiting for approval
+type PendingDevice struct {
+	// DeviceID is the /dev/disk/by-id path of the device, or its /dev path if it doesn't have one.
+	// It is the value to add to approvedDevices or rejectedDevices.
+	DeviceID string `json:"deviceID"`
+	// DeviceName is the kernel name of the device. For eg, sdb
+	DeviceName string `json:"deviceName"`
+	// Size of the device in bytes
+	Size int64 `json:"size"`
+	// Model of the device
+	// +optional
+	Model string `json:"model,omitempty"`
+	// Serial number of the device
+	// +optional
+	Serial string `json:"serial,omitempty"`
+}
+
+// LocalVolumeSetApprovalStatus defines the observed state of LocalVolumeSetApproval
+type LocalVolumeSetApprovalStatus struct {
+	// PendingDevices is the list of devices that match the LocalVolumeSet and are neither approved nor rejected
+	// +optional
+	PendingDevices []PendingDevice `json:"pendingDevices,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumesetapprovals,scope=Namespaced
+
+// LocalVolumeSetApproval records the devices of a node that match a LocalVolumeSet with the Manual claimPolicy,
+// and the decision of an admin about each of them. It is created by the diskmaker of the node.
+type LocalVolumeSetApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeSetApprovalSpec   `json:"spec,omitempty"`
+	Status LocalVolumeSetApprovalStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LocalVolumeSetApprovalList contains a list of LocalVolumeSetApproval
+type LocalVolumeSetApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeSetApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeSetApproval{}, &LocalVolumeSetApprovalList{})
+}
@@ -22,10 +22,29 @@ package v1alpha1
 
 import (
 	operatorv1 "github.com/openshift/api/operator/v1"
-	"k8s.io/api/core/v1"
+	apiv1 "github.com/openshift/local-storage-operator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceGroupSummary) DeepCopyInto(out *DeviceGroupSummary) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceGroupSummary.
+func (in *DeviceGroupSummary) DeepCopy() *DeviceGroupSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceGroupSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceInclusionSpec) DeepCopyInto(out *DeviceInclusionSpec) {
 	*out = *in
@@ -59,6 +78,16 @@ func (in *DeviceInclusionSpec) DeepCopyInto(out *DeviceInclusionSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DevicePaths != nil {
+		in, out := &in.DevicePaths, &out.DevicePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedDevicePaths != nil {
+		in, out := &in.ExcludedDevicePaths, &out.ExcludedDevicePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceInclusionSpec.
@@ -71,6 +100,22 @@ func (in *DeviceInclusionSpec) DeepCopy() *DeviceInclusionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceKeyRotation) DeepCopyInto(out *DeviceKeyRotation) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceKeyRotation.
+func (in *DeviceKeyRotation) DeepCopy() *DeviceKeyRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceKeyRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceStatus) DeepCopyInto(out *DeviceStatus) {
 	*out = *in
@@ -102,6 +147,228 @@ func (in *DiscoveredDevice) DeepCopy() *DiscoveredDevice {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoverySummary) DeepCopyInto(out *DiscoverySummary) {
+	*out = *in
+	if in.OldestStaleResultTimeStamp != nil {
+		in, out := &in.OldestStaleResultTimeStamp, &out.OldestStaleResultTimeStamp
+		*out = (*in).DeepCopy()
+	}
+	out.AvailableCapacity = in.AvailableCapacity.DeepCopy()
+	if in.DeviceGroups != nil {
+		in, out := &in.DeviceGroups, &out.DeviceGroups
+		*out = make([]DeviceGroupSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeDiscoverySummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoverySummary.
+func (in *DiscoverySummary) DeepCopy() *DiscoverySummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoverySummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionKeyRotation) DeepCopyInto(out *EncryptionKeyRotation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKeyRotation.
+func (in *EncryptionKeyRotation) DeepCopy() *EncryptionKeyRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKeyRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EncryptionKeyRotation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionKeyRotationList) DeepCopyInto(out *EncryptionKeyRotationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EncryptionKeyRotation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKeyRotationList.
+func (in *EncryptionKeyRotationList) DeepCopy() *EncryptionKeyRotationList {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKeyRotationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EncryptionKeyRotationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionKeyRotationSpec) DeepCopyInto(out *EncryptionKeyRotationSpec) {
+	*out = *in
+	if in.NewKeySecretRef != nil {
+		in, out := &in.NewKeySecretRef, &out.NewKeySecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKeyRotationSpec.
+func (in *EncryptionKeyRotationSpec) DeepCopy() *EncryptionKeyRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKeyRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionKeyRotationStatus) DeepCopyInto(out *EncryptionKeyRotationStatus) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]DeviceKeyRotation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKeyRotationStatus.
+func (in *EncryptionKeyRotationStatus) DeepCopy() *EncryptionKeyRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKeyRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalStorageOperatorConfig) DeepCopyInto(out *LocalStorageOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalStorageOperatorConfig.
+func (in *LocalStorageOperatorConfig) DeepCopy() *LocalStorageOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalStorageOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalStorageOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalStorageOperatorConfigList) DeepCopyInto(out *LocalStorageOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalStorageOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalStorageOperatorConfigList.
+func (in *LocalStorageOperatorConfigList) DeepCopy() *LocalStorageOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalStorageOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalStorageOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalStorageOperatorConfigSpec) DeepCopyInto(out *LocalStorageOperatorConfigSpec) {
+	*out = *in
+	in.DiskMaker.DeepCopyInto(&out.DiskMaker)
+	in.Discovery.DeepCopyInto(&out.Discovery)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalStorageOperatorConfigSpec.
+func (in *LocalStorageOperatorConfigSpec) DeepCopy() *LocalStorageOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalStorageOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalVolumeDiscovery) DeepCopyInto(out *LocalVolumeDiscovery) {
 	*out = *in
@@ -270,6 +537,26 @@ func (in *LocalVolumeDiscoverySpec) DeepCopyInto(out *LocalVolumeDiscoverySpec)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ProbeInterval != nil {
+		in, out := &in.ProbeInterval, &out.ProbeInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.UdevEventPeriod != nil {
+		in, out := &in.UdevEventPeriod, &out.UdevEventPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.UdevExclusionFilter != nil {
+		in, out := &in.UdevExclusionFilter, &out.UdevExclusionFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SupportedDeviceTypes != nil {
+		in, out := &in.SupportedDeviceTypes, &out.SupportedDeviceTypes
+		*out = make([]DiscoveredDeviceType, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeDiscoverySpec.
@@ -292,6 +579,11 @@ func (in *LocalVolumeDiscoveryStatus) DeepCopyInto(out *LocalVolumeDiscoveryStat
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(DiscoverySummary)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeDiscoveryStatus.
@@ -331,6 +623,110 @@ func (in *LocalVolumeSet) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetApproval) DeepCopyInto(out *LocalVolumeSetApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetApproval.
+func (in *LocalVolumeSetApproval) DeepCopy() *LocalVolumeSetApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeSetApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetApprovalList) DeepCopyInto(out *LocalVolumeSetApprovalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeSetApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetApprovalList.
+func (in *LocalVolumeSetApprovalList) DeepCopy() *LocalVolumeSetApprovalList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetApprovalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeSetApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetApprovalSpec) DeepCopyInto(out *LocalVolumeSetApprovalSpec) {
+	*out = *in
+	if in.ApprovedDevices != nil {
+		in, out := &in.ApprovedDevices, &out.ApprovedDevices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RejectedDevices != nil {
+		in, out := &in.RejectedDevices, &out.RejectedDevices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetApprovalSpec.
+func (in *LocalVolumeSetApprovalSpec) DeepCopy() *LocalVolumeSetApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSetApprovalStatus) DeepCopyInto(out *LocalVolumeSetApprovalStatus) {
+	*out = *in
+	if in.PendingDevices != nil {
+		in, out := &in.PendingDevices, &out.PendingDevices
+		*out = make([]PendingDevice, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetApprovalStatus.
+func (in *LocalVolumeSetApprovalStatus) DeepCopy() *LocalVolumeSetApprovalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSetApprovalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalVolumeSetList) DeepCopyInto(out *LocalVolumeSetList) {
 	*out = *in
@@ -376,6 +772,11 @@ func (in *LocalVolumeSetSpec) DeepCopyInto(out *LocalVolumeSetSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Filesystem != nil {
+		in, out := &in.Filesystem, &out.Filesystem
+		*out = new(apiv1.FilesystemSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -388,6 +789,26 @@ func (in *LocalVolumeSetSpec) DeepCopyInto(out *LocalVolumeSetSpec) {
 		*out = new(DeviceInclusionSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(apiv1.EncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedFilesystem != nil {
+		in, out := &in.SharedFilesystem, &out.SharedFilesystem
+		*out = new(SharedFilesystemSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RAID != nil {
+		in, out := &in.RAID, &out.RAID
+		*out = new(RAIDSpec)
+		**out = **in
+	}
+	if in.IdentityPolicy != nil {
+		in, out := &in.IdentityPolicy, &out.IdentityPolicy
+		*out = make([]apiv1.DeviceIdentity, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetSpec.
@@ -415,6 +836,13 @@ func (in *LocalVolumeSetStatus) DeepCopyInto(out *LocalVolumeSetStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.RAIDArrays != nil {
+		in, out := &in.RAIDArrays, &out.RAIDArrays
+		*out = make([]RAIDArrayStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSetStatus.
@@ -426,3 +854,117 @@ func (in *LocalVolumeSetStatus) DeepCopy() *LocalVolumeSetStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDaemonConfig) DeepCopyInto(out *NodeDaemonConfig) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(appsv1.DaemonSetUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDaemonConfig.
+func (in *NodeDaemonConfig) DeepCopy() *NodeDaemonConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDaemonConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDiscoverySummary) DeepCopyInto(out *NodeDiscoverySummary) {
+	*out = *in
+	out.AvailableCapacity = in.AvailableCapacity.DeepCopy()
+	if in.DeviceGroups != nil {
+		in, out := &in.DeviceGroups, &out.DeviceGroups
+		*out = make([]DeviceGroupSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDiscoverySummary.
+func (in *NodeDiscoverySummary) DeepCopy() *NodeDiscoverySummary {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDiscoverySummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingDevice) DeepCopyInto(out *PendingDevice) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingDevice.
+func (in *PendingDevice) DeepCopy() *PendingDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RAIDArrayStatus) DeepCopyInto(out *RAIDArrayStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RAIDArrayStatus.
+func (in *RAIDArrayStatus) DeepCopy() *RAIDArrayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RAIDArrayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RAIDSpec) DeepCopyInto(out *RAIDSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RAIDSpec.
+func (in *RAIDSpec) DeepCopy() *RAIDSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RAIDSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedFilesystemSpec) DeepCopyInto(out *SharedFilesystemSpec) {
+	*out = *in
+	if in.VolumeSize != nil {
+		in, out := &in.VolumeSize, &out.VolumeSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedFilesystemSpec.
+func (in *SharedFilesystemSpec) DeepCopy() *SharedFilesystemSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedFilesystemSpec)
+	in.DeepCopyInto(out)
+	return out
+}
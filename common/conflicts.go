@@ -0,0 +1,282 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConflictingCondition is the condition of the LocalVolumes and LocalVolumeSets that share a storage class,
+// or devices of a node, with another LocalVolume or LocalVolumeSet
+const ConflictingCondition = "Conflicting"
+
+var defaultMinSize = resource.MustParse("1Gi")
+
+// Conflict is a LocalVolume or LocalVolumeSet that shares a storage class or devices with another one
+type Conflict struct {
+	Kind string
+	Name string
+	// StorageClassNames are the storage classes both provision
+	StorageClassNames []string
+	// Nodes are the nodes where both may match the same devices
+	Nodes []string
+	// Precedes is true when the other LocalVolumeSet claims the devices both match
+	Precedes bool
+	// arbitrated is true between two LocalVolumeSets, the devices of a LocalVolume are claimed by whichever is first
+	arbitrated bool
+}
+
+// String describes the conflict
+func (c Conflict) String() string {
+	reasons := []string{}
+	if len(c.StorageClassNames) > 0 {
+		reasons = append(reasons, fmt.Sprintf("provisions storage class %s", strings.Join(c.StorageClassNames, ", ")))
+	}
+	if len(c.Nodes) > 0 {
+		reason := fmt.Sprintf("may match the same devices on nodes %s", strings.Join(c.Nodes, ", "))
+		if c.arbitrated {
+			if c.Precedes {
+				reason += ", it has precedence"
+			} else {
+				reason += ", it yields them"
+			}
+		}
+		reasons = append(reasons, reason)
+	}
+	return fmt.Sprintf("%s %s %s", c.Kind, c.Name, strings.Join(reasons, " and "))
+}
+
+// ConflictsMessage returns the message of the Conflicting condition
+func ConflictsMessage(conflicts []Conflict) string {
+	if len(conflicts) == 0 {
+		return "No conflict"
+	}
+	messages := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		messages = append(messages, conflict.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// HasPrecedence returns whether the LocalVolumeSet claims the devices it shares with the other one,
+// by the highest priority, then the oldest creation and the name
+func HasPrecedence(lvset, other *localv1.LocalVolumeSet) bool {
+	if lvset.Spec.Priority != other.Spec.Priority {
+		return lvset.Spec.Priority > other.Spec.Priority
+	}
+	if !lvset.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return lvset.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	return lvset.Name < other.Name
+}
+
+// FindConflicts returns the LocalVolumes and LocalVolumeSets of the namespace that share a storage class,
+// or may match the same devices of a node, with the LocalVolume or LocalVolumeSet
+func FindConflicts(ctx context.Context, c client.Reader, obj client.Object) ([]Conflict, error) {
+	lvs := &localv1.LocalVolumeList{}
+	err := c.List(ctx, lvs, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return nil, fmt.Errorf("could not list the LocalVolumes: %w", err)
+	}
+	lvsets := &localv1.LocalVolumeSetList{}
+	err = c.List(ctx, lvsets, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return nil, fmt.Errorf("could not list the LocalVolumeSets: %w", err)
+	}
+	nodes := &corev1.NodeList{}
+	err = c.List(ctx, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the nodes: %w", err)
+	}
+
+	self := newClaimer(obj)
+	others := make([]claimer, 0, len(lvs.Items)+len(lvsets.Items))
+	for i := range lvs.Items {
+		others = append(others, newClaimer(&lvs.Items[i]))
+	}
+	for i := range lvsets.Items {
+		others = append(others, newClaimer(&lvsets.Items[i]))
+	}
+
+	conflicts := make([]Conflict, 0)
+	for _, other := range others {
+		if other.kind == self.kind && other.obj.GetName() == obj.GetName() {
+			continue
+		}
+		conflict := Conflict{
+			Kind:              other.kind,
+			Name:              other.obj.GetName(),
+			StorageClassNames: self.storageClassNames.Intersection(other.storageClassNames).List(),
+		}
+		if self.mayMatchSameDevices(other) {
+			for i := range nodes.Items {
+				node := &nodes.Items[i]
+				if self.selectsNode(node) && other.selectsNode(node) {
+					conflict.Nodes = append(conflict.Nodes, node.Name)
+				}
+			}
+			sort.Strings(conflict.Nodes)
+		}
+		if len(conflict.StorageClassNames) == 0 && len(conflict.Nodes) == 0 {
+			continue
+		}
+		if self.lvset != nil && other.lvset != nil {
+			conflict.arbitrated = true
+			conflict.Precedes = HasPrecedence(other.lvset, self.lvset)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Kind != conflicts[j].Kind {
+			return conflicts[i].Kind < conflicts[j].Kind
+		}
+		return conflicts[i].Name < conflicts[j].Name
+	})
+	return conflicts, nil
+}
+
+// claimer is a LocalVolume or LocalVolumeSet, as far as the devices and storage classes it claims
+type claimer struct {
+	obj               client.Object
+	kind              string
+	lvset             *localv1.LocalVolumeSet
+	nodeSelector      *corev1.NodeSelector
+	storageClassNames sets.String
+	// devicePaths are the devices of a LocalVolume
+	devicePaths sets.String
+}
+
+func newClaimer(obj client.Object) claimer {
+	c := claimer{obj: obj, storageClassNames: sets.NewString(), devicePaths: sets.NewString()}
+	switch o := obj.(type) {
+	case *localv1.LocalVolume:
+		c.kind = localv1.LocalVolumeKind
+		c.nodeSelector = o.Spec.NodeSelector
+		for _, storageClassDevice := range o.Spec.StorageClassDevices {
+			c.storageClassNames.Insert(storageClassDevice.StorageClassName)
+			c.devicePaths.Insert(storageClassDevice.DevicePaths...)
+		}
+	case *localv1.LocalVolumeSet:
+		c.kind = localv1.LocalVolumeSetKind
+		c.lvset = o
+		c.nodeSelector = o.Spec.NodeSelector
+		c.storageClassNames.Insert(o.Spec.StorageClassName)
+	}
+	return c
+}
+
+func (c claimer) selectsNode(node *corev1.Node) bool {
+	matches, err := NodeSelectorMatchesNodeLabels(node, c.nodeSelector)
+	return err == nil && matches
+}
+
+// mayMatchSameDevices returns whether the filters of both may match a device.
+// The device paths are compared as they are written, links to the same device are not resolved.
+func (c claimer) mayMatchSameDevices(other claimer) bool {
+	switch {
+	case c.lvset == nil && other.lvset == nil:
+		return c.devicePaths.HasAny(other.devicePaths.UnsortedList()...)
+	case c.lvset == nil:
+		return inclusionMayMatchPaths(other.lvset.Spec.DeviceInclusionSpec, c.devicePaths)
+	case other.lvset == nil:
+		return inclusionMayMatchPaths(c.lvset.Spec.DeviceInclusionSpec, other.devicePaths)
+	}
+	return inclusionsMayOverlap(c.lvset.Spec.DeviceInclusionSpec, other.lvset.Spec.DeviceInclusionSpec)
+}
+
+// inclusionMayMatchPaths returns whether the inclusion spec may match one of the device paths
+func inclusionMayMatchPaths(spec *localv1.DeviceInclusionSpec, devicePaths sets.String) bool {
+	if spec == nil {
+		return devicePaths.Len() > 0
+	}
+	candidates := devicePaths.Difference(sets.NewString(spec.ExcludedDevicePaths...))
+	if len(spec.DevicePaths) > 0 {
+		candidates = candidates.Intersection(sets.NewString(spec.DevicePaths...))
+	}
+	return candidates.Len() > 0
+}
+
+// inclusionsMayOverlap returns whether a device may match both inclusion specs
+func inclusionsMayOverlap(a, b *localv1.DeviceInclusionSpec) bool {
+	if a == nil {
+		a = &localv1.DeviceInclusionSpec{}
+	}
+	if b == nil {
+		b = &localv1.DeviceInclusionSpec{}
+	}
+
+	deviceTypes := func(spec *localv1.DeviceInclusionSpec) sets.String {
+		if len(spec.DeviceTypes) == 0 {
+			return sets.NewString(string(localv1.RawDisk))
+		}
+		types := sets.NewString()
+		for _, deviceType := range spec.DeviceTypes {
+			types.Insert(string(deviceType))
+		}
+		return types
+	}
+	if !deviceTypes(a).HasAny(deviceTypes(b).UnsortedList()...) {
+		return false
+	}
+
+	if len(a.DeviceMechanicalProperties) > 0 && len(b.DeviceMechanicalProperties) > 0 {
+		properties := sets.NewString()
+		for _, property := range a.DeviceMechanicalProperties {
+			properties.Insert(string(property))
+		}
+		overlap := false
+		for _, property := range b.DeviceMechanicalProperties {
+			overlap = overlap || properties.Has(string(property))
+		}
+		if !overlap {
+			return false
+		}
+	}
+
+	minSize := func(spec *localv1.DeviceInclusionSpec) resource.Quantity {
+		if spec.MinSize == nil {
+			return defaultMinSize
+		}
+		return *spec.MinSize
+	}
+	aMin, bMin := minSize(a), minSize(b)
+	if a.MaxSize != nil && a.MaxSize.Cmp(bMin) < 0 {
+		return false
+	}
+	if b.MaxSize != nil && b.MaxSize.Cmp(aMin) < 0 {
+		return false
+	}
+
+	// the models and vendors match when the device's contains them, one has to contain the other to match both
+	if !substringsMayOverlap(a.Models, b.Models) || !substringsMayOverlap(a.Vendors, b.Vendors) {
+		return false
+	}
+
+	if len(a.DevicePaths) > 0 && len(b.DevicePaths) > 0 {
+		if !sets.NewString(a.DevicePaths...).HasAny(b.DevicePaths...) {
+			return false
+		}
+	}
+	return true
+}
+
+func substringsMayOverlap(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if strings.Contains(x, y) || strings.Contains(y, x) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHasPrecedence(t *testing.T) {
+	now := metav1.NewTime(time.Now())
+	a := &localv1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "a", CreationTimestamp: now}}
+	b := &localv1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "b", CreationTimestamp: now}}
+	assert.True(t, HasPrecedence(a, b))
+	assert.False(t, HasPrecedence(b, a))
+
+	b.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+	assert.True(t, HasPrecedence(b, a))
+
+	a.Spec.Priority = 1
+	assert.True(t, HasPrecedence(a, b))
+	assert.False(t, HasPrecedence(b, a))
+}
+
+func TestInclusionsMayOverlap(t *testing.T) {
+	tenGi := resource.MustParse("10Gi")
+	hundredGi := resource.MustParse("100Gi")
+	testTable := []struct {
+		desc    string
+		a       *localv1.DeviceInclusionSpec
+		b       *localv1.DeviceInclusionSpec
+		overlap bool
+	}{
+		{desc: "no filters", overlap: true},
+		{
+			desc:    "default disk type",
+			a:       &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.Partition}},
+			overlap: false,
+		},
+		{
+			desc:    "mechanical properties",
+			a:       &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational}},
+			b:       &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.NonRotational}},
+			overlap: false,
+		},
+		{
+			desc:    "size ranges",
+			a:       &localv1.DeviceInclusionSpec{MaxSize: &tenGi},
+			b:       &localv1.DeviceInclusionSpec{MinSize: &hundredGi},
+			overlap: false,
+		},
+		{
+			desc:    "overlapping size ranges",
+			a:       &localv1.DeviceInclusionSpec{MaxSize: &hundredGi},
+			b:       &localv1.DeviceInclusionSpec{MinSize: &tenGi},
+			overlap: true,
+		},
+		{
+			desc:    "models",
+			a:       &localv1.DeviceInclusionSpec{Models: []string{"ST1000"}},
+			b:       &localv1.DeviceInclusionSpec{Models: []string{"Samsung"}},
+			overlap: false,
+		},
+		{
+			desc:    "model prefix",
+			a:       &localv1.DeviceInclusionSpec{Models: []string{"ST1000"}},
+			b:       &localv1.DeviceInclusionSpec{Models: []string{"ST1000NM0055"}},
+			overlap: true,
+		},
+		{
+			desc:    "device paths",
+			a:       &localv1.DeviceInclusionSpec{DevicePaths: []string{"/dev/sdb"}},
+			b:       &localv1.DeviceInclusionSpec{DevicePaths: []string{"/dev/sdc"}},
+			overlap: false,
+		},
+	}
+	for _, tc := range testTable {
+		assert.Equal(t, tc.overlap, inclusionsMayOverlap(tc.a, tc.b), "[%s] unexpected overlap", tc.desc)
+		assert.Equal(t, tc.overlap, inclusionsMayOverlap(tc.b, tc.a), "[%s] unexpected reversed overlap", tc.desc)
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, localv1.AddToScheme(scheme))
+	assert.NoError(t, localv1alpha1.AddToScheme(scheme))
+
+	// the creation timestamps of the API have a precision of a second
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	newNode := func(name, role string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"role": role}}}
+	}
+	selector := func(role string) *corev1.NodeSelector {
+		return &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "role", Operator: corev1.NodeSelectorOpIn, Values: []string{role}}},
+		}}}
+	}
+	ssd := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ssd", Namespace: "openshift-local-storage", CreationTimestamp: now},
+		Spec: localv1.LocalVolumeSetSpec{
+			StorageClassName:    "fast",
+			Priority:            10,
+			DeviceInclusionSpec: &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.NonRotational}},
+		},
+	}
+	all := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "all", Namespace: "openshift-local-storage", CreationTimestamp: now},
+		Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "all"},
+	}
+	// only selects the storage nodes
+	hdd := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "hdd", Namespace: "openshift-local-storage", CreationTimestamp: now},
+		Spec: localv1.LocalVolumeSetSpec{
+			StorageClassName:    "slow",
+			NodeSelector:        selector("storage"),
+			DeviceInclusionSpec: &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational}},
+		},
+	}
+	lv := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "disks", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSpec{
+			NodeSelector: selector("worker"),
+			StorageClassDevices: []localv1.StorageClassDevice{
+				{StorageClassName: "fast", DevicePaths: []string{"/dev/sdb"}},
+			},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, newNode("worker-0", "worker"), newNode("storage-0", "storage"), ssd, all, hdd, lv)
+
+	conflicts, err := FindConflicts(context.TODO(), client, all)
+	assert.NoError(t, err)
+	if assert.Len(t, conflicts, 3) {
+		assert.Equal(t, "LocalVolume disks may match the same devices on nodes worker-0", conflicts[0].String())
+		assert.Equal(t, "LocalVolumeSet hdd may match the same devices on nodes storage-0, it yields them", conflicts[1].String())
+		assert.Equal(t, "LocalVolumeSet ssd may match the same devices on nodes storage-0, worker-0, it has precedence", conflicts[2].String())
+	}
+
+	conflicts, err = FindConflicts(context.TODO(), client, ssd)
+	assert.NoError(t, err)
+	assert.Equal(t, "LocalVolume disks provisions storage class fast and may match the same devices on nodes worker-0; "+
+		"LocalVolumeSet all may match the same devices on nodes storage-0, worker-0, it yields them", ConflictsMessage(conflicts))
+
+	conflicts, err = FindConflicts(context.TODO(), client, hdd)
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+}
@@ -22,3 +22,13 @@ func NodeSelectorMatchesNodeLabels(node *corev1.Node, nodeSelector *corev1.NodeS
 	matches, err := v1helper.MatchNodeSelectorTerms(node, nodeSelector)
 	return matches, err
 }
+
+// IsNodeInMaintenance returns true when the node has the NodeMaintenanceLabel,
+// the diskmakers don't provision nor clean up the devices of the node then
+func IsNodeInMaintenance(node *corev1.Node) bool {
+	if node == nil {
+		return false
+	}
+	_, found := node.Labels[NodeMaintenanceLabel]
+	return found
+}
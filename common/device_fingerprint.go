@@ -0,0 +1,213 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/local-storage-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FencedPV is a PV whose symlink pointed to a device that didn't match the fingerprint of the PV
+type FencedPV struct {
+	PVName      string
+	SymlinkPath string
+	// Source is the device the symlink pointed to
+	Source string
+	// Mismatch describes the property of the device that didn't match
+	Mismatch string
+	// Unfenced is set when the label of the PV was removed and its symlink was pointed back to its device
+	Unfenced bool
+	// Err is set when the symlink could not be verified or fenced
+	Err error
+}
+
+// setDeviceFingerprint records the fingerprint of the device symLinkPath points to on the new PV.
+// The symlinks of shared filesystem directories are not devices and have none.
+// The fingerprint of an encrypted device is the one of the device under its LUKS header.
+func setDeviceFingerprint(pvLogger logr.Logger, pv *corev1.PersistentVolume, symLinkPath string) {
+	target, err := os.Readlink(symLinkPath)
+	if err != nil {
+		return
+	}
+	devPath := symLinkPath
+	if internal.IsLUKSMapperPath(target) {
+		devPath, _ = getEncryptedDeviceID(symLinkPath, target)
+	}
+	devPath, err = internal.FilePathEvalSymLinks(devPath)
+	if err != nil {
+		pvLogger.Error(err, "could not record the fingerprint of the device")
+		return
+	}
+	fingerprint, err := internal.GetFingerprint(devPath)
+	if err != nil {
+		pvLogger.Error(err, "could not record the fingerprint of the device")
+		return
+	}
+	InitMapIfNil(&pv.ObjectMeta.Annotations)
+	for key, value := range map[string]string{
+		PVDeviceSerialAnnotation:   fingerprint.Serial,
+		PVDeviceWWNAnnotation:      fingerprint.WWN,
+		PVDevicePartUUIDAnnotation: fingerprint.PartUUID,
+		PVDeviceSizeAnnotation:     strconv.FormatInt(fingerprint.Size, 10),
+	} {
+		if value != "" {
+			pv.Annotations[key] = value
+		}
+	}
+}
+
+// getDeviceFingerprint returns the fingerprint recorded on the PV, and false if it has none
+func getDeviceFingerprint(pv *corev1.PersistentVolume) (internal.Fingerprint, bool) {
+	size, err := strconv.ParseInt(pv.Annotations[PVDeviceSizeAnnotation], 10, 64)
+	if err != nil {
+		return internal.Fingerprint{}, false
+	}
+	return internal.Fingerprint{
+		Serial:   pv.Annotations[PVDeviceSerialAnnotation],
+		WWN:      pv.Annotations[PVDeviceWWNAnnotation],
+		PartUUID: pv.Annotations[PVDevicePartUUIDAnnotation],
+		Size:     size,
+	}, true
+}
+
+// FenceMismatchedPVs compares the devices the symlinks of symlinkDir point to with the fingerprint recorded on their PV.
+// A symlink that points to another device, such as the kernel name of a device given to another disk after a reboot,
+// is pointed to a path in internal.FencedDir, so that the other disk is neither used nor wiped through the PV,
+// and the PV is labelled with PVFencedLabel. Only the fenced and unfenced PVs, and the symlinks that could not be verified, are returned.
+// Removing the label unfences the PV: see unfencePV.
+func FenceMismatchedPVs(ctx context.Context, c client.Client, symlinkDir, nodeName, storageClassName string) []FencedPV {
+	paths, err := filepath.Glob(filepath.Join(symlinkDir, "*"))
+	if err != nil {
+		return []FencedPV{{Err: fmt.Errorf("could not list the symlinks in %q: %w", symlinkDir, err)}}
+	}
+	fencedPVs := make([]FencedPV, 0)
+	for _, symlinkPath := range paths {
+		source, err := os.Readlink(symlinkPath)
+		// encrypted devices are verified before they are opened, see ReopenEncryptedDevice
+		if err != nil || internal.IsLUKSMapperPath(source) {
+			continue
+		}
+		fencedPV := FencedPV{
+			PVName:      GeneratePVName(filepath.Base(symlinkPath), nodeName, storageClassName),
+			SymlinkPath: symlinkPath,
+			Source:      source,
+		}
+		pv := &corev1.PersistentVolume{}
+		err = c.Get(ctx, types.NamespacedName{Name: fencedPV.PVName}, pv)
+		if kerrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			fencedPV.Err = err
+			fencedPVs = append(fencedPVs, fencedPV)
+			continue
+		}
+		recorded, found := getDeviceFingerprint(pv)
+		if !found {
+			continue
+		}
+		if internal.IsFencedPath(source) {
+			if _, fenced := pv.Labels[PVFencedLabel]; fenced {
+				continue
+			}
+			fencedPVs = append(fencedPVs, unfencePV(ctx, c, pv, recorded, fencedPV))
+			continue
+		}
+		// a missing device is not a mismatch, it may be reattached
+		devPath, err := internal.FilePathEvalSymLinks(symlinkPath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			fencedPV.Err = err
+			fencedPVs = append(fencedPVs, fencedPV)
+			continue
+		}
+		current, err := internal.GetFingerprint(devPath)
+		if err != nil {
+			fencedPV.Err = err
+			fencedPVs = append(fencedPVs, fencedPV)
+			continue
+		}
+		fencedPV.Mismatch = recorded.Mismatch(current)
+		if fencedPV.Mismatch == "" {
+			continue
+		}
+
+		fencedPV.Err = replaceSymlink(internal.GetFencedPath(symlinkPath), symlinkPath)
+		if fencedPV.Err == nil {
+			fencedPV.Err = fencePV(ctx, c, fencedPV.PVName, source)
+		}
+		fencedPVs = append(fencedPVs, fencedPV)
+	}
+	return fencedPVs
+}
+
+// fencePV labels the PV as fenced, and records the device its symlink pointed to
+func fencePV(ctx context.Context, c client.Client, pvName, source string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pv := &corev1.PersistentVolume{}
+		err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv)
+		if err != nil {
+			return err
+		}
+		InitMapIfNil(&pv.ObjectMeta.Labels)
+		InitMapIfNil(&pv.ObjectMeta.Annotations)
+		pv.Labels[PVFencedLabel] = "true"
+		pv.Annotations[PVFencedSourceAnnotation] = source
+		return c.Update(ctx, pv)
+	})
+}
+
+// unfencePV points the symlink of a PV whose PVFencedLabel was removed back to its device. The device is looked for
+// at PVFencedSourceAnnotation, which can be set to the right device before removing the label, then in
+// internal.DiskByIDDir. The device must have the serial or WWN recorded on the PV, and no other property of the
+// fingerprint may differ. If no device matches, the PV is labelled again and Mismatch is set.
+func unfencePV(ctx context.Context, c client.Client, pv *corev1.PersistentVolume, recorded internal.Fingerprint, fencedPV FencedPV) FencedPV {
+	candidates := []string{}
+	if source := pv.Annotations[PVFencedSourceAnnotation]; source != "" {
+		candidates = append(candidates, source)
+	}
+	byIDPaths, err := internal.FilePathGlob(filepath.Join(internal.DiskByIDDir, "*"))
+	if err != nil {
+		fencedPV.Err = fmt.Errorf("could not list files in %q: %w", internal.DiskByIDDir, err)
+		return fencedPV
+	}
+	candidates = append(candidates, byIDPaths...)
+	for _, candidate := range candidates {
+		devPath, err := internal.FilePathEvalSymLinks(candidate)
+		if err != nil {
+			continue
+		}
+		current, err := internal.GetFingerprint(devPath)
+		if err != nil || !recorded.Identifies(current) {
+			continue
+		}
+		fencedPV.Source = candidate
+		fencedPV.Unfenced = true
+		fencedPV.Err = replaceSymlink(candidate, fencedPV.SymlinkPath)
+		if fencedPV.Err == nil {
+			fencedPV.Err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				pv := &corev1.PersistentVolume{}
+				err := c.Get(ctx, types.NamespacedName{Name: fencedPV.PVName}, pv)
+				if err != nil {
+					return err
+				}
+				delete(pv.Annotations, PVFencedSourceAnnotation)
+				return c.Update(ctx, pv)
+			})
+		}
+		return fencedPV
+	}
+	fencedPV.Source = pv.Annotations[PVFencedSourceAnnotation]
+	fencedPV.Mismatch = fmt.Sprintf("fingerprint is not the one of the PV, and no device in %s has it", internal.DiskByIDDir)
+	fencedPV.Err = fencePV(ctx, c, fencedPV.PVName, fencedPV.Source)
+	return fencedPV
+}
@@ -0,0 +1,170 @@
+package common
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// lsblkHelperCommand returns a fake lsblk that prints the fingerprint of the device it is run on
+func lsblkHelperCommand(fingerprints map[string]string) func(string, ...string) *exec.Cmd {
+	return func(command string, args ...string) *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=TestFilesystemHelperProcess", "--", command)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", "EXIT_CODE=0", "STDOUT=" + fingerprints[args[len(args)-1]]}
+		return cmd
+	}
+}
+
+func TestSetDeviceFingerprint(t *testing.T) {
+	internal.ExecCommand = lsblkHelperCommand(map[string]string{"/dev/sdb": `SERIAL="SERIAL1" WWN="" SIZE="10737418240" PARTUUID=""`})
+	internal.FilePathEvalSymLinks = func(path string) (string, error) { return "/dev/sdb", nil }
+	defer func() {
+		internal.ExecCommand = exec.Command
+		internal.FilePathEvalSymLinks = filepath.EvalSymlinks
+	}()
+	tmpDir, err := ioutil.TempDir("", "device-fingerprint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	symlinkPath := filepath.Join(tmpDir, "sdb")
+	assert.NoError(t, os.Symlink("/dev/sdb", symlinkPath))
+
+	pv := &corev1.PersistentVolume{}
+	setDeviceFingerprint(logr.Discard(), pv, symlinkPath)
+	assert.Equal(t, map[string]string{PVDeviceSerialAnnotation: "SERIAL1", PVDeviceSizeAnnotation: "10737418240"}, pv.Annotations)
+	fingerprint, found := getDeviceFingerprint(pv)
+	assert.True(t, found)
+	assert.Equal(t, internal.Fingerprint{Serial: "SERIAL1", Size: 10737418240}, fingerprint)
+
+	// the fingerprint of an encrypted device is the one of the device under its LUKS header
+	internal.FilePathEvalSymLinks = func(path string) (string, error) { return path, nil }
+	encryptedPath := filepath.Join(tmpDir, "encrypted", "sdb")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(encryptedPath), 0755))
+	assert.NoError(t, os.Symlink(filepath.Join(internal.MapperDir, internal.LUKSMapperName("/dev/sdb")), encryptedPath))
+	pv = &corev1.PersistentVolume{}
+	setDeviceFingerprint(logr.Discard(), pv, encryptedPath)
+	assert.Equal(t, "SERIAL1", pv.Annotations[PVDeviceSerialAnnotation])
+
+	// shared filesystem directories are not devices
+	pv = &corev1.PersistentVolume{}
+	setDeviceFingerprint(logr.Discard(), pv, tmpDir)
+	assert.Nil(t, pv.Annotations)
+	_, found = getDeviceFingerprint(pv)
+	assert.False(t, found)
+}
+
+func TestFenceMismatchedPVs(t *testing.T) {
+	// sdb and sdc swapped their names after a reboot
+	internal.ExecCommand = lsblkHelperCommand(map[string]string{
+		"/dev/sdb": `SERIAL="SERIAL2" WWN="" SIZE="10737418240" PARTUUID=""`,
+		"/dev/sdc": `SERIAL="SERIAL1" WWN="" SIZE="10737418240" PARTUUID=""`,
+	})
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		source, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if internal.IsFencedPath(source) {
+			return "", &os.PathError{Op: "lstat", Path: source, Err: os.ErrNotExist}
+		}
+		return source, nil
+	}
+	defer func() {
+		internal.ExecCommand = exec.Command
+		internal.FilePathEvalSymLinks = filepath.EvalSymlinks
+	}()
+	tmpDir, err := ioutil.TempDir("", "device-fingerprint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	symlinkDir := filepath.Join(tmpDir, "local-sc")
+	assert.NoError(t, os.MkdirAll(symlinkDir, 0755))
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	objects := []runtime.Object{}
+	for kname, serial := range map[string]string{"sdb": "SERIAL1", "sdc": "SERIAL1"} {
+		assert.NoError(t, os.Symlink("/dev/"+kname, filepath.Join(symlinkDir, kname)))
+		objects = append(objects, &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+			Name:        GeneratePVName(kname, "node1", "local-sc"),
+			Annotations: map[string]string{PVDeviceSerialAnnotation: serial, PVDeviceSizeAnnotation: "10737418240"},
+		}})
+	}
+	// PVs created before the fingerprints are not verified
+	assert.NoError(t, os.Symlink("/dev/sdd", filepath.Join(symlinkDir, "sdd")))
+	objects = append(objects, &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: GeneratePVName("sdd", "node1", "local-sc")}})
+	client := fake.NewFakeClientWithScheme(scheme, objects...)
+
+	fencedPVs := FenceMismatchedPVs(context.TODO(), client, symlinkDir, "node1", "local-sc")
+	assert.Len(t, fencedPVs, 1)
+	fencedPV := fencedPVs[0]
+	assert.NoError(t, fencedPV.Err)
+	assert.Equal(t, GeneratePVName("sdb", "node1", "local-sc"), fencedPV.PVName)
+	assert.Equal(t, "/dev/sdb", fencedPV.Source)
+	assert.Equal(t, `serial "SERIAL1" is "SERIAL2"`, fencedPV.Mismatch)
+
+	source, err := os.Readlink(filepath.Join(symlinkDir, "sdb"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/lso-fenced/sdb", source)
+	pv := &corev1.PersistentVolume{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: fencedPV.PVName}, pv)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", pv.Labels[PVFencedLabel])
+	assert.Equal(t, "/dev/sdb", pv.Annotations[PVFencedSourceAnnotation])
+
+	// fenced PVs are left alone
+	fencedPVs = FenceMismatchedPVs(context.TODO(), client, symlinkDir, "node1", "local-sc")
+	assert.Empty(t, fencedPVs)
+
+	// removing the label fences the PV again while no device has its fingerprint
+	byIDPaths := []string{}
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		return byIDPaths, nil
+	}
+	defer func() {
+		internal.FilePathGlob = filepath.Glob
+	}()
+	unlabel := func() {
+		pv := &corev1.PersistentVolume{}
+		assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: fencedPV.PVName}, pv))
+		delete(pv.Labels, PVFencedLabel)
+		assert.NoError(t, client.Update(context.TODO(), pv))
+	}
+	unlabel()
+	fencedPVs = FenceMismatchedPVs(context.TODO(), client, symlinkDir, "node1", "local-sc")
+	assert.Len(t, fencedPVs, 1)
+	assert.NoError(t, fencedPVs[0].Err)
+	assert.False(t, fencedPVs[0].Unfenced)
+	assert.NotEmpty(t, fencedPVs[0].Mismatch)
+	pv = &corev1.PersistentVolume{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: fencedPV.PVName}, pv))
+	assert.Equal(t, "true", pv.Labels[PVFencedLabel])
+
+	// and points its symlink to the device that has it
+	byIDLink := filepath.Join(tmpDir, "scsi-SERIAL1")
+	assert.NoError(t, os.Symlink("/dev/sdc", byIDLink))
+	byIDPaths = []string{byIDLink}
+	unlabel()
+	fencedPVs = FenceMismatchedPVs(context.TODO(), client, symlinkDir, "node1", "local-sc")
+	assert.Len(t, fencedPVs, 1)
+	assert.NoError(t, fencedPVs[0].Err)
+	assert.True(t, fencedPVs[0].Unfenced)
+	assert.Equal(t, byIDLink, fencedPVs[0].Source)
+	source, err = os.Readlink(filepath.Join(symlinkDir, "sdb"))
+	assert.NoError(t, err)
+	assert.Equal(t, byIDLink, source)
+	pv = &corev1.PersistentVolume{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: fencedPV.PVName}, pv))
+	assert.NotContains(t, pv.Labels, PVFencedLabel)
+	assert.NotContains(t, pv.Annotations, PVFencedSourceAnnotation)
+}
@@ -0,0 +1,503 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EncryptionKeySecretKey is the Secret entry holding a LUKS key
+	EncryptionKeySecretKey = "key"
+	// EncryptionNodeAnnotation references the node of the device a generated key belongs to
+	EncryptionNodeAnnotation = "local.storage.openshift.io/encryption-node"
+	// EncryptionDeviceAnnotation references the device a generated key belongs to
+	EncryptionDeviceAnnotation = "local.storage.openshift.io/encryption-device"
+
+	deviceKeySecretPrefix = "luks-key-"
+	generatedKeySize      = 64
+	// pendingKeySecretKey is the Secret entry of the new key of a rotation, until it opens the device
+	pendingKeySecretKey = "pendingKey"
+	// previousKeySecretKey is the Secret entry of the old key of a rotation, until its keyslot is removed
+	previousKeySecretKey = "previousKey"
+)
+
+// EscrowHostDirPrefix is the host directory that the escrow directories of the keys must be under,
+// so that the diskmaker doesn't mount and write to arbitrary host paths
+var EscrowHostDirPrefix = "/var/lib/local-storage/escrow"
+
+// EncryptedDevice is an opened LUKS device symlinked in a symlink dir
+type EncryptedDevice struct {
+	// SymlinkPath is the path of the symlink to the device-mapper node
+	SymlinkPath string
+	// DeviceID is the persistent link the device was opened by, such as its /dev/disk/by-id or /dev/disk/by-path link,
+	// or its /dev/KNAME path if it had none
+	DeviceID string
+	// KName is the kernel name of the encrypted device
+	KName string
+	// IDExists is set if the DeviceID is a persistent link
+	IDExists bool
+	// Opened is set if the device-mapper node exists
+	Opened bool
+}
+
+// EncryptionKeyLabels returns the labels of the Secrets of the device keys of an object
+func EncryptionKeyLabels(kind, namespace, name string) map[string]string {
+	return map[string]string{
+		PVOwnerKindLabel:      kind,
+		PVOwnerNamespaceLabel: namespace,
+		PVOwnerNameLabel:      name,
+	}
+}
+
+// GetDeviceKeySecretName returns the name of the Secret holding the key of the device on the node,
+// once the key was generated or rotated
+func GetDeviceKeySecretName(nodeName, deviceID string) string {
+	sum := sha256.Sum256([]byte(nodeName + "/" + deviceID))
+	return deviceKeySecretPrefix + hex.EncodeToString(sum[:])[:32]
+}
+
+// GetEncryptionKey returns the LUKS key of the device.
+// The key is read from the Secret of the device if it exists, the KeyPolicy of the EncryptionSpec applies otherwise.
+// With the Generated KeyPolicy, the key is generated and stored in the Secret of the device the first time.
+func GetEncryptionKey(ctx context.Context, c client.Client, spec *localv1.EncryptionSpec, namespace, nodeName, deviceID string, labels map[string]string) ([]byte, error) {
+	secret, err := getDeviceKeySecret(ctx, c, namespace, nodeName, deviceID)
+	if err != nil {
+		return nil, err
+	} else if secret != nil {
+		return secret.Data[EncryptionKeySecretKey], nil
+	}
+	switch spec.KeyPolicy {
+	case localv1.EncryptionKeySecret:
+		return getKeySecretRefKey(ctx, c, spec, namespace)
+	case localv1.EncryptionKeyGenerated:
+		key, err := generateKey()
+		if err != nil {
+			return nil, err
+		}
+		err = c.Create(ctx, newDeviceKeySecret(namespace, nodeName, deviceID, labels, key))
+		if err != nil {
+			return nil, fmt.Errorf("could not store the generated encryption key: %w", err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unknown encryption keyPolicy %q", spec.KeyPolicy)
+	}
+}
+
+// regenerateEncryptionKey returns the key of the device before it is formatted again.
+// Generated keys are replaced, rotated keys are dropped in favor of the key of keySecretRef.
+func regenerateEncryptionKey(ctx context.Context, c client.Client, spec *localv1.EncryptionSpec, namespace, nodeName, deviceID string, labels map[string]string) ([]byte, error) {
+	secret, err := getDeviceKeySecret(ctx, c, namespace, nodeName, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if spec.KeyPolicy != localv1.EncryptionKeyGenerated {
+		if secret != nil {
+			err = c.Delete(ctx, secret)
+			if err != nil && !kerrors.IsNotFound(err) {
+				return nil, fmt.Errorf("could not delete the rotated encryption key: %w", err)
+			}
+		}
+		return getKeySecretRefKey(ctx, c, spec, namespace)
+	}
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		err = c.Create(ctx, newDeviceKeySecret(namespace, nodeName, deviceID, labels, key))
+	} else {
+		secret.Data = map[string][]byte{EncryptionKeySecretKey: key}
+		err = c.Update(ctx, secret)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not store the generated encryption key: %w", err)
+	}
+	return key, nil
+}
+
+func getKeySecretRefKey(ctx context.Context, c client.Client, spec *localv1.EncryptionSpec, namespace string) ([]byte, error) {
+	if spec.KeySecretRef == nil || spec.KeySecretRef.Name == "" {
+		return nil, fmt.Errorf("keySecretRef is required with the %q keyPolicy", localv1.EncryptionKeySecret)
+	}
+	return GetSecretKey(ctx, c, spec.KeySecretRef.Name, namespace)
+}
+
+// GetSecretKey returns the "key" entry of the Secret
+func GetSecretKey(ctx context.Context, c client.Client, name, namespace string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the encryption key secret: %w", err)
+	}
+	key, found := secret.Data[EncryptionKeySecretKey]
+	if !found || len(key) == 0 {
+		return nil, fmt.Errorf("secret %q has no %q entry", name, EncryptionKeySecretKey)
+	}
+	return key, nil
+}
+
+// getDeviceKeySecret returns the Secret of the device, nil if it doesn't exist
+func getDeviceKeySecret(ctx context.Context, c client.Client, namespace, nodeName, deviceID string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: GetDeviceKeySecretName(nodeName, deviceID), Namespace: namespace}, secret)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get the encryption key of the device: %w", err)
+	}
+	return secret, nil
+}
+
+func newDeviceKeySecret(namespace, nodeName, deviceID string, labels map[string]string, key []byte) *corev1.Secret {
+	// the secret is not owned by the LocalVolume or LocalVolumeSet,
+	// the data on released PVs would be lost with the key when they are deleted
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetDeviceKeySecretName(nodeName, deviceID),
+			Namespace: namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				EncryptionNodeAnnotation:   nodeName,
+				EncryptionDeviceAnnotation: deviceID,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{EncryptionKeySecretKey: key},
+	}
+}
+
+// RotateEncryptionKey replaces the key of the device with newKey, or with a generated key if newKey is nil.
+// The new key is added to a keyslot and verified before it replaces the key in the Secret of the device,
+// then the keyslot of the old key is removed. Every step is recorded in the Secret of the device,
+// an interrupted rotation resumes with the same new key.
+func RotateEncryptionKey(ctx context.Context, c client.Client, spec *localv1.EncryptionSpec, namespace, nodeName, deviceID string, newKey []byte, labels map[string]string) error {
+	key, err := GetEncryptionKey(ctx, c, spec, namespace, nodeName, deviceID, labels)
+	if err != nil {
+		return err
+	}
+	secret, err := getDeviceKeySecret(ctx, c, namespace, nodeName, deviceID)
+	if err != nil {
+		return err
+	} else if secret == nil {
+		// keys of keySecretRef are copied to the Secret of the device, which holds the rotated key
+		secret = newDeviceKeySecret(namespace, nodeName, deviceID, labels, key)
+		err = c.Create(ctx, secret)
+		if err != nil {
+			return fmt.Errorf("could not store the encryption key of the device: %w", err)
+		}
+	}
+
+	if _, found := secret.Data[previousKeySecretKey]; !found {
+		pendingKey, found := secret.Data[pendingKeySecretKey]
+		if !found {
+			pendingKey = newKey
+			if pendingKey == nil {
+				pendingKey, err = generateKey()
+				if err != nil {
+					return err
+				}
+			}
+			if bytes.Equal(pendingKey, key) {
+				return fmt.Errorf("the new key is the current key of the device")
+			}
+			secret.Data[pendingKeySecretKey] = pendingKey
+			err = c.Update(ctx, secret)
+			if err != nil {
+				return fmt.Errorf("could not store the new encryption key: %w", err)
+			}
+		}
+
+		added, err := internal.LUKSTestKey(deviceID, pendingKey)
+		if err != nil {
+			return err
+		}
+		if !added {
+			err = internal.LUKSAddKey(deviceID, key, pendingKey)
+			if err != nil {
+				return err
+			}
+			added, err = internal.LUKSTestKey(deviceID, pendingKey)
+			if err != nil {
+				return err
+			} else if !added {
+				return fmt.Errorf("the new key does not open %q after it was added", deviceID)
+			}
+		}
+
+		secret.Data = map[string][]byte{
+			EncryptionKeySecretKey: pendingKey,
+			previousKeySecretKey:   key,
+		}
+		err = c.Update(ctx, secret)
+		if err != nil {
+			return fmt.Errorf("could not store the new encryption key: %w", err)
+		}
+		err = escrowKey(ctx, c, spec, namespace, nodeName, deviceID, pendingKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	previousKey := secret.Data[previousKeySecretKey]
+	found, err := internal.LUKSTestKey(deviceID, previousKey)
+	if err != nil {
+		return err
+	}
+	if found {
+		err = internal.LUKSRemoveKey(deviceID, previousKey)
+		if err != nil {
+			return err
+		}
+	}
+	delete(secret.Data, previousKeySecretKey)
+	err = c.Update(ctx, secret)
+	if err != nil {
+		return fmt.Errorf("could not remove the old encryption key: %w", err)
+	}
+	return nil
+}
+
+// escrowKey copies the key of the device to the escrow Secret and host directory of the EncryptionSpec
+func escrowKey(ctx context.Context, c client.Client, spec *localv1.EncryptionSpec, namespace, nodeName, deviceID string, key []byte) error {
+	if spec.Escrow == nil {
+		return nil
+	}
+	if spec.Escrow.SecretName != "" {
+		// the escrow Secret is shared by the diskmakers of all the nodes
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			secret := &corev1.Secret{}
+			err := c.Get(ctx, types.NamespacedName{Name: spec.Escrow.SecretName, Namespace: namespace}, secret)
+			if kerrors.IsNotFound(err) {
+				secret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: spec.Escrow.SecretName, Namespace: namespace},
+					Type:       corev1.SecretTypeOpaque,
+					Data:       map[string][]byte{GetDeviceKeySecretName(nodeName, deviceID): key},
+				}
+				return c.Create(ctx, secret)
+			} else if err != nil {
+				return err
+			}
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			secret.Data[GetDeviceKeySecretName(nodeName, deviceID)] = key
+			return c.Update(ctx, secret)
+		})
+		if err != nil {
+			return fmt.Errorf("could not escrow the encryption key to secret %q: %w", spec.Escrow.SecretName, err)
+		}
+	}
+	if spec.Escrow.HostDir != "" {
+		if !IsAllowedEscrowHostDir(spec.Escrow.HostDir) {
+			return fmt.Errorf("could not escrow the encryption key to %q: the directory is not under %s", spec.Escrow.HostDir, EscrowHostDirPrefix)
+		}
+		err := writeKeyFile(filepath.Join(spec.Escrow.HostDir, internal.LUKSMapperName(deviceID)+".key"), key)
+		if err != nil {
+			return fmt.Errorf("could not escrow the encryption key to %q: %w", spec.Escrow.HostDir, err)
+		}
+	}
+	return nil
+}
+
+// IsAllowedEscrowHostDir returns true if hostDir is EscrowHostDirPrefix or one of its subdirectories
+func IsAllowedEscrowHostDir(hostDir string) bool {
+	hostDir = filepath.Clean(hostDir)
+	return hostDir == EscrowHostDirPrefix || strings.HasPrefix(hostDir, EscrowHostDirPrefix+"/")
+}
+
+// writeKeyFile replaces the key file atomically, so that an escrowed key is never truncated
+func writeKeyFile(path string, key []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(key)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func generateKey() ([]byte, error) {
+	key := make([]byte, generatedKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate an encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// ErrEncryptedDeviceWiped is returned by ReopenEncryptedDevice when the device has no LUKS header anymore
+var ErrEncryptedDeviceWiped = errors.New("the encrypted device has no LUKS header")
+
+// OpenEncryptedDevice formats the device with LUKS if it has no LUKS header yet and opens it.
+// It is only called on the first provisioning of a device, matched by the device filters or the devicePaths,
+// and refuses to format a device with a filesystem or a partition table.
+// It returns the path of the device-mapper node to symlink instead of the device.
+func OpenEncryptedDevice(ctx context.Context, c client.Client, spec *localv1.EncryptionSpec, namespace, nodeName, deviceID string, labels map[string]string) (string, error) {
+	isLUKS, err := internal.IsLUKS(deviceID)
+	if err != nil {
+		return "", err
+	}
+	var key []byte
+	if isLUKS {
+		key, err = GetEncryptionKey(ctx, c, spec, namespace, nodeName, deviceID, labels)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		fsType, err := internal.GetFilesystemType(deviceID)
+		if err != nil {
+			return "", err
+		} else if fsType != "" {
+			return "", fmt.Errorf("refusing to format %q, it has a %s signature", deviceID, fsType)
+		}
+		key, err = regenerateEncryptionKey(ctx, c, spec, namespace, nodeName, deviceID, labels)
+		if err != nil {
+			return "", err
+		}
+		err = internal.LUKSFormat(deviceID, key, spec.Cipher)
+		if err != nil {
+			return "", err
+		}
+		err = escrowKey(ctx, c, spec, namespace, nodeName, deviceID, key)
+		if err != nil {
+			return "", err
+		}
+	}
+	return internal.LUKSOpen(deviceID, key, internal.LUKSMapperName(deviceID))
+}
+
+// ReopenEncryptedDevice opens an encrypted device of GetEncryptedDevices again, after a reboot or once its PV was deleted.
+// It never formats the device: ErrEncryptedDeviceWiped is returned when it has no LUKS header anymore.
+// The kernel name the symlink of a device without persistent link falls back to may be given to another disk
+// after a reboot, the device must match the serial or WWN recorded on pv before it is opened.
+// pv is nil when the PV of the device doesn't exist.
+func ReopenEncryptedDevice(ctx context.Context, c client.Client, spec *localv1.EncryptionSpec, namespace, nodeName string, device EncryptedDevice, pv *corev1.PersistentVolume, labels map[string]string) error {
+	err := verifyEncryptedDevice(device, pv)
+	if err != nil {
+		return err
+	}
+	isLUKS, err := internal.IsLUKS(device.DeviceID)
+	if err != nil {
+		return err
+	} else if !isLUKS {
+		return fmt.Errorf("%w: %q", ErrEncryptedDeviceWiped, device.DeviceID)
+	}
+	key, err := GetEncryptionKey(ctx, c, spec, namespace, nodeName, device.DeviceID, labels)
+	if err != nil {
+		return err
+	}
+	_, err = internal.LUKSOpen(device.DeviceID, key, internal.LUKSMapperName(device.DeviceID))
+	return err
+}
+
+// verifyEncryptedDevice returns an error if the device is not the one the PV was provisioned on.
+// The serial or the WWN recorded on the PV must match the device. Without them, only a device found
+// through a persistent link is trusted.
+func verifyEncryptedDevice(device EncryptedDevice, pv *corev1.PersistentVolume) error {
+	recorded := internal.Fingerprint{}
+	if pv != nil {
+		recorded, _ = getDeviceFingerprint(pv)
+	}
+	if recorded.Serial == "" && recorded.WWN == "" {
+		if device.IDExists {
+			return nil
+		}
+		return fmt.Errorf("the identity of %q can not be verified, it has no persistent link and its PV has no serial or WWN", device.DeviceID)
+	}
+	current, err := internal.GetFingerprint(device.DeviceID)
+	if err != nil {
+		return err
+	}
+	if mismatch := recorded.Mismatch(current); mismatch != "" {
+		return fmt.Errorf("%q is not the device of its PV, %s", device.DeviceID, mismatch)
+	}
+	if (recorded.Serial == "" || recorded.Serial != current.Serial) && (recorded.WWN == "" || recorded.WWN != current.WWN) {
+		return fmt.Errorf("the identity of %q can not be verified, its serial and WWN are unknown", device.DeviceID)
+	}
+	return nil
+}
+
+// encryptedDeviceDirs are the directories of the persistent links an encrypted device can have been opened by
+var encryptedDeviceDirs = []string{internal.DiskByIDDir, internal.DiskByPathDir, internal.DiskByPartUUIDDir, internal.DiskByLSODir}
+
+// getEncryptedDeviceID returns the device an encrypted symlink is named after, and whether it is a persistent link.
+// The symlink is named after the link the device was opened by, whatever its identity, and points to the
+// device-mapper node named after the path of that link: the link is the one whose mapper name matches.
+// The device is found by its /dev/KNAME path when none does.
+func getEncryptedDeviceID(symlinkPath, mapperPath string) (string, bool) {
+	name := filepath.Base(symlinkPath)
+	for _, dir := range encryptedDeviceDirs {
+		deviceID := filepath.Join(dir, name)
+		if internal.LUKSMapperName(deviceID) == filepath.Base(mapperPath) {
+			return deviceID, true
+		}
+	}
+	return filepath.Join("/dev", name), false
+}
+
+// GetEncryptedDevices returns the encrypted devices symlinked in symLinkDir.
+// Their device-mapper nodes are missing after a reboot or once the cleaner erased them,
+// they have to be opened again with ReopenEncryptedDevice so that their PVs can be recreated.
+// Opened devices have children and a LUKS signature, they are never matched again by the device filters.
+func GetEncryptedDevices(symLinkDir string) ([]EncryptedDevice, error) {
+	paths, err := filepath.Glob(filepath.Join(symLinkDir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]EncryptedDevice, 0)
+	for _, path := range paths {
+		target, err := os.Readlink(path)
+		if err != nil || !internal.IsLUKSMapperPath(target) {
+			continue
+		}
+		// the symlink is named after the device
+		device := EncryptedDevice{SymlinkPath: path}
+		device.DeviceID, device.IDExists = getEncryptedDeviceID(path, target)
+		realPath, err := internal.FilePathEvalSymLinks(device.DeviceID)
+		if err != nil {
+			// the device is gone
+			continue
+		}
+		if target != filepath.Join(internal.MapperDir, internal.LUKSMapperName(device.DeviceID)) {
+			// not opened by the diskmaker for this device
+			continue
+		}
+		device.KName = filepath.Base(realPath)
+		_, err = os.Stat(target)
+		device.Opened = err == nil
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
@@ -0,0 +1,282 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetEncryptionKey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.AddToScheme(scheme)
+	assert.NoError(t, err)
+	ctx := context.TODO()
+	deviceID := "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"
+	labels := map[string]string{PVOwnerNameLabel: "lvset"}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "luks", Namespace: "local-storage"},
+		Data:       map[string][]byte{EncryptionKeySecretKey: []byte("secret-key")},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, secret)
+
+	// keys from a secret
+	spec := &localv1.EncryptionSpec{
+		KeyPolicy:    localv1.EncryptionKeySecret,
+		KeySecretRef: &corev1.LocalObjectReference{Name: "luks"},
+	}
+	key, err := GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret-key"), key)
+	key, err = regenerateEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret-key"), key, "keys from a secret are not regenerated")
+
+	// a rotated key overrides the key of the secret until the device is formatted again
+	rotated := newDeviceKeySecret("local-storage", "node-a", deviceID, labels, []byte("rotated-key"))
+	err = client.Create(ctx, rotated)
+	assert.NoError(t, err)
+	key, err = GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("rotated-key"), key)
+	key, err = regenerateEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret-key"), key)
+	key, err = GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret-key"), key, "the rotated key is dropped when the device is formatted again")
+
+	spec.KeySecretRef = &corev1.LocalObjectReference{Name: "missing"}
+	_, err = GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.Error(t, err)
+	spec.KeySecretRef = nil
+	_, err = GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.Error(t, err)
+
+	// generated keys
+	spec = &localv1.EncryptionSpec{KeyPolicy: localv1.EncryptionKeyGenerated}
+	key, err = GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Len(t, key, generatedKeySize)
+
+	generated := &corev1.Secret{}
+	name := GetDeviceKeySecretName("node-a", deviceID)
+	err = client.Get(ctx, types.NamespacedName{Name: name, Namespace: "local-storage"}, generated)
+	assert.NoError(t, err)
+	assert.Equal(t, "node-a", generated.Annotations[EncryptionNodeAnnotation])
+	assert.Equal(t, deviceID, generated.Annotations[EncryptionDeviceAnnotation])
+	assert.Equal(t, labels, generated.Labels)
+
+	sameKey, err := GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, key, sameKey, "the generated key is reused")
+
+	otherKey, err := GetEncryptionKey(ctx, client, spec, "local-storage", "node-b", deviceID, labels)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherKey, "keys are generated per node and device")
+
+	newKey, err := regenerateEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, newKey)
+	sameKey, err = GetEncryptionKey(ctx, client, spec, "local-storage", "node-a", deviceID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, newKey, sameKey, "the regenerated key is stored")
+}
+
+func TestEscrowKey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.AddToScheme(scheme)
+	assert.NoError(t, err)
+	ctx := context.TODO()
+	client := fake.NewFakeClientWithScheme(scheme)
+	escrowDir, err := ioutil.TempDir("", "escrow")
+	assert.NoError(t, err)
+	defer os.RemoveAll(escrowDir)
+	defer func(prefix string) { EscrowHostDirPrefix = prefix }(EscrowHostDirPrefix)
+	EscrowHostDirPrefix = escrowDir
+	hostDir := filepath.Join(escrowDir, "keys")
+
+	// the keys are never written outside of the escrow prefix
+	outside := &localv1.EncryptionSpec{
+		KeyPolicy: localv1.EncryptionKeyGenerated,
+		Escrow:    &localv1.KeyEscrowSpec{HostDir: filepath.Join(escrowDir, "..", "outside")},
+	}
+	err = escrowKey(ctx, client, outside, "local-storage", "node-a", "/dev/sdb", []byte("key"))
+	assert.Error(t, err)
+
+	spec := &localv1.EncryptionSpec{
+		KeyPolicy: localv1.EncryptionKeyGenerated,
+		Escrow:    &localv1.KeyEscrowSpec{SecretName: "escrow", HostDir: hostDir},
+	}
+	devices := []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", "/dev/disk/by-id/wwn-0x5000c500a0b1c2d4"}
+	for i, deviceID := range devices {
+		err = escrowKey(ctx, client, spec, "local-storage", "node-a", deviceID, []byte(fmt.Sprintf("key-%d", i)))
+		assert.NoError(t, err)
+	}
+	// a rotated key replaces the escrowed key
+	err = escrowKey(ctx, client, spec, "local-storage", "node-a", devices[0], []byte("key-rotated"))
+	assert.NoError(t, err)
+
+	escrow := &corev1.Secret{}
+	err = client.Get(ctx, types.NamespacedName{Name: "escrow", Namespace: "local-storage"}, escrow)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		GetDeviceKeySecretName("node-a", devices[0]): []byte("key-rotated"),
+		GetDeviceKeySecretName("node-a", devices[1]): []byte("key-1"),
+	}, escrow.Data)
+
+	for deviceID, expected := range map[string]string{devices[0]: "key-rotated", devices[1]: "key-1"} {
+		path := filepath.Join(hostDir, internal.LUKSMapperName(deviceID)+".key")
+		key, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(key))
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	// no escrow
+	spec.Escrow = nil
+	err = escrowKey(ctx, client, spec, "local-storage", "node-a", devices[0], []byte("key"))
+	assert.NoError(t, err)
+}
+
+func TestReopenEncryptedDevice(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	ctx := context.TODO()
+	spec := &localv1.EncryptionSpec{KeyPolicy: localv1.EncryptionKeyGenerated}
+	fingerprints := map[string]string{
+		"/dev/sdb": `SERIAL="SERIAL1" WWN="" SIZE="10737418240" PARTUUID=""`,
+		"/dev/sdc": `SERIAL="SERIAL2" WWN="" SIZE="10737418240" PARTUUID=""`,
+	}
+	pvWithSerial := func(serial string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+			Name:        "local-pv",
+			Annotations: map[string]string{PVDeviceSerialAnnotation: serial, PVDeviceSizeAnnotation: "10737418240"},
+		}}
+	}
+
+	testcases := []struct {
+		label    string
+		device   EncryptedDevice
+		pv       *corev1.PersistentVolume
+		isLUKS   bool
+		opened   bool
+		expected error
+	}{
+		{
+			label:  "the serial of the PV matches",
+			device: EncryptedDevice{DeviceID: "/dev/sdb"},
+			pv:     pvWithSerial("SERIAL1"),
+			isLUKS: true,
+			opened: true,
+		},
+		{
+			label:  "the kernel name was given to another disk",
+			device: EncryptedDevice{DeviceID: "/dev/sdb"},
+			pv:     pvWithSerial("SERIAL2"),
+			isLUKS: true,
+		},
+		{
+			label:  "a kernel name can't be verified without fingerprint",
+			device: EncryptedDevice{DeviceID: "/dev/sdb"},
+			isLUKS: true,
+		},
+		{
+			label:  "a by-id link is trusted without fingerprint",
+			device: EncryptedDevice{DeviceID: "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", IDExists: true},
+			isLUKS: true,
+			opened: true,
+		},
+		{
+			label:    "a device without LUKS header is never formatted",
+			device:   EncryptedDevice{DeviceID: "/dev/sdc"},
+			pv:       pvWithSerial("SERIAL2"),
+			expected: ErrEncryptedDeviceWiped,
+		},
+	}
+	for _, tc := range testcases {
+		commands := []string{}
+		internal.ExecCommand = func(command string, args ...string) *exec.Cmd {
+			commands = append(commands, command+" "+args[0])
+			stdout, exitCode := "", 0
+			switch {
+			case command == "lsblk":
+				stdout = fingerprints[args[len(args)-1]]
+			case command == "cryptsetup" && args[0] == "isLuks" && !tc.isLUKS:
+				exitCode = 1
+			}
+			cmd := exec.Command(os.Args[0], "-test.run=TestFilesystemHelperProcess", "--", command)
+			cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", fmt.Sprintf("EXIT_CODE=%d", exitCode), "STDOUT=" + stdout}
+			return cmd
+		}
+		client := fake.NewFakeClientWithScheme(scheme)
+
+		err := ReopenEncryptedDevice(ctx, client, spec, "local-storage", "node-a", tc.device, tc.pv, nil)
+		if tc.expected != nil {
+			assert.Truef(t, errors.Is(err, tc.expected), "[%s] unexpected error %v", tc.label, err)
+		} else if !tc.opened {
+			assert.Errorf(t, err, "[%s]", tc.label)
+		} else {
+			assert.NoErrorf(t, err, "[%s]", tc.label)
+		}
+		assert.NotContainsf(t, commands, "cryptsetup luksFormat", "[%s]", tc.label)
+		if tc.opened {
+			assert.Containsf(t, commands, "cryptsetup luksOpen", "[%s]", tc.label)
+		} else {
+			assert.NotContainsf(t, commands, "cryptsetup luksOpen", "[%s]", tc.label)
+		}
+	}
+	internal.ExecCommand = exec.Command
+}
+
+func TestGetEncryptedDevices(t *testing.T) {
+	symlinkDir, err := ioutil.TempDir("", "encrypted")
+	assert.NoError(t, err)
+	defer os.RemoveAll(symlinkDir)
+
+	// devices opened by their by-path, by-lso and kernel name links, and a device-mapper node of another device
+	links := map[string]string{
+		"pci-0000:00:1f.2-ata-1": filepath.Join(internal.DiskByPathDir, "pci-0000:00:1f.2-ata-1"),
+		"SERIAL1":                filepath.Join(internal.DiskByLSODir, "SERIAL1"),
+		"sdd":                    "/dev/sdd",
+		"sde":                    "/dev/sdf",
+	}
+	for name, deviceID := range links {
+		mapperPath := filepath.Join(internal.MapperDir, internal.LUKSMapperName(deviceID))
+		assert.NoError(t, os.Symlink(mapperPath, filepath.Join(symlinkDir, name)))
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		switch path {
+		case links["pci-0000:00:1f.2-ata-1"]:
+			return "/dev/sdb", nil
+		case links["SERIAL1"]:
+			return "/dev/sdc", nil
+		}
+		return path, nil
+	}
+	defer func() { internal.FilePathEvalSymLinks = filepath.EvalSymlinks }()
+
+	devices, err := GetEncryptedDevices(symlinkDir)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []EncryptedDevice{
+		{SymlinkPath: filepath.Join(symlinkDir, "pci-0000:00:1f.2-ata-1"), DeviceID: links["pci-0000:00:1f.2-ata-1"], KName: "sdb", IDExists: true},
+		{SymlinkPath: filepath.Join(symlinkDir, "SERIAL1"), DeviceID: links["SERIAL1"], KName: "sdc", IDExists: true},
+		{SymlinkPath: filepath.Join(symlinkDir, "sdd"), DeviceID: "/dev/sdd", KName: "sdd"},
+	}, devices)
+}
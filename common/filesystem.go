@@ -0,0 +1,64 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetMountOptions returns the mount options of the storage class of the volumes
+func GetMountOptions(volumeMode localv1.PersistentVolumeMode, filesystem *localv1.FilesystemSpec) []string {
+	if filesystem == nil || volumeMode == localv1.PersistentVolumeBlock {
+		return nil
+	}
+	return filesystem.MountOptions
+}
+
+// getFilesystemSpec returns the filesystem configured by the LocalVolume or LocalVolumeSet for the storage class
+func getFilesystemSpec(obj runtime.Object, storageClassName string) *localv1.FilesystemSpec {
+	switch o := obj.(type) {
+	case *localv1.LocalVolumeSet:
+		return o.Spec.Filesystem
+	case *localv1.LocalVolume:
+		for _, storageClassDevice := range o.Spec.StorageClassDevices {
+			if storageClassDevice.StorageClassName == storageClassName {
+				return storageClassDevice.Filesystem
+			}
+		}
+	}
+	return nil
+}
+
+// formatDevice formats the device of a new PV with the filesystem, so that the kubelet doesn't format it with the defaults.
+// Devices that have a filesystem or whose PV exists are never formatted.
+func formatDevice(c client.Client, pvLogger logr.Logger, pvName, devicePath, fsType string, filesystem *localv1.FilesystemSpec) error {
+	err := c.Get(context.TODO(), types.NamespacedName{Name: pvName}, &corev1.PersistentVolume{})
+	if err == nil {
+		return nil
+	} else if !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	existingFSType, err := internal.GetFilesystemType(devicePath)
+	if err != nil {
+		return err
+	}
+	if existingFSType != "" {
+		return nil
+	}
+
+	pvLogger.Info("formatting device", "fsType", fsType, "options", filesystem.MkfsOptions)
+	err = internal.Mkfs(devicePath, fsType, filesystem.Label, filesystem.MkfsOptions)
+	if err != nil {
+		return fmt.Errorf("could not format the device: %w", err)
+	}
+	return nil
+}
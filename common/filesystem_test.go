@@ -0,0 +1,93 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/go-logr/logr"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fsHelperCommand returns a fake exec.Cmd that prints the filesystem type for blkid,
+// and records the mkfs invocations in mkfsCalls.
+func fsHelperCommand(fsType string, mkfsCalls *[]string) func(string, ...string) *exec.Cmd {
+	return func(command string, args ...string) *exec.Cmd {
+		stdout, exitCode := "", 0
+		switch command {
+		case "blkid":
+			stdout = "TYPE=" + fsType
+			if fsType == "" {
+				exitCode = 2
+			}
+		default:
+			*mkfsCalls = append(*mkfsCalls, fmt.Sprint(append([]string{command}, args...)))
+		}
+		cmd := exec.Command(os.Args[0], "-test.run=TestFilesystemHelperProcess", "--", command)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", fmt.Sprintf("EXIT_CODE=%d", exitCode), "STDOUT=" + stdout}
+		return cmd
+	}
+}
+
+func TestFilesystemHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	var exitCode int
+	fmt.Sscanf(os.Getenv("EXIT_CODE"), "%d", &exitCode)
+	fmt.Fprintln(os.Stdout, os.Getenv("STDOUT"))
+	os.Exit(exitCode)
+}
+
+func TestGetFilesystemSpec(t *testing.T) {
+	filesystem := &localv1.FilesystemSpec{MkfsOptions: []string{"-m", "0"}, MountOptions: []string{"noatime"}}
+	lvset := &localv1.LocalVolumeSet{Spec: localv1.LocalVolumeSetSpec{StorageClassName: "fast", Filesystem: filesystem}}
+	assert.Equal(t, filesystem, getFilesystemSpec(lvset, "fast"))
+
+	lv := &localv1.LocalVolume{Spec: localv1.LocalVolumeSpec{StorageClassDevices: []localv1.StorageClassDevice{
+		{StorageClassName: "slow"},
+		{StorageClassName: "fast", Filesystem: filesystem},
+	}}}
+	assert.Equal(t, filesystem, getFilesystemSpec(lv, "fast"))
+	assert.Nil(t, getFilesystemSpec(lv, "slow"))
+
+	assert.Equal(t, []string{"noatime"}, GetMountOptions("", filesystem))
+	assert.Equal(t, []string{"noatime"}, GetMountOptions(localv1.PersistentVolumeFilesystem, filesystem))
+	assert.Nil(t, GetMountOptions(localv1.PersistentVolumeBlock, filesystem))
+	assert.Nil(t, GetMountOptions(localv1.PersistentVolumeFilesystem, nil))
+}
+
+func TestFormatDevice(t *testing.T) {
+	defer func() { internal.ExecCommand = exec.Command }()
+	scheme := runtime.NewScheme()
+	err := corev1.AddToScheme(scheme)
+	assert.NoError(t, err)
+	filesystem := &localv1.FilesystemSpec{MkfsOptions: []string{"-m", "0"}, Label: "data"}
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "local-pv-existing"}}
+	client := fake.NewFakeClientWithScheme(scheme, pv)
+
+	testcases := []struct {
+		label    string
+		pvName   string
+		fsType   string
+		expected []string
+	}{
+		{label: "new device", pvName: "local-pv-new", fsType: "", expected: []string{"[mkfs.ext4 -m 0 -L data /dev/sdb]"}},
+		{label: "device with a filesystem", pvName: "local-pv-new", fsType: "xfs"},
+		{label: "existing PV", pvName: "local-pv-existing", fsType: ""},
+	}
+	for _, tc := range testcases {
+		var mkfsCalls []string
+		internal.ExecCommand = fsHelperCommand(tc.fsType, &mkfsCalls)
+		err := formatDevice(client, logr.Discard(), tc.pvName, "/dev/sdb", "ext4", filesystem)
+		assert.NoError(t, err, tc.label)
+		assert.Equal(t, tc.expected, mkfsCalls, tc.label)
+	}
+}
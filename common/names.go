@@ -34,6 +34,17 @@ const (
 	// DiscoveryNodeLabelKey is the label key on the discovery result CR used to identify the node it belongs to.
 	// the value is the node's name
 	DiscoveryNodeLabel = "discovery-result-node"
+
+	// DiscoveryRescanAnnotation requests an immediate scan of the devices when set on the LocalVolumeDiscovery.
+	// The value is the name of the node to scan, or empty to scan all the nodes.
+	DiscoveryRescanAnnotation = "local.storage.openshift.io/rescan"
+	// DiscoveryRescanRequestedAnnotation requests an immediate scan of the devices of a single node
+	// when set on its LocalVolumeDiscoveryResult. The discovery daemon removes it once the scan is done.
+	DiscoveryRescanRequestedAnnotation = "local.storage.openshift.io/rescan-requested"
+
+	// NodeMaintenanceLabel pauses the provisioning and the cleanup of the devices of a node when it is set on the node,
+	// whatever its value. The diskmakers resume once it is removed.
+	NodeMaintenanceLabel = "local.storage.openshift.io/maintenance"
 )
 
 // GetLocalProvisionerImage return the image to be used for provisioner daemonset
@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"os"
 	"path/filepath"
 
 	"github.com/go-logr/logr"
+	"github.com/openshift/local-storage-operator/internal"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -91,15 +95,20 @@ func CreateLocalPV(
 		return fmt.Errorf("could not read the device's volume mode from the node: %w", err)
 	}
 
+	// the cleanup table is shared with the deleter, which removes the entry of a succeeded cleanup
+	// when it deletes the PV: it is only read here
 	if cleanupTracker.InProgress(pvName, useJob) {
 		pvLogger.Info("PV is still being cleaned, not going to recreate it")
 		return nil
 	}
 
-	_, _, err = cleanupTracker.RemoveStatus(pvName, useJob)
-	if err != nil {
-		pvLogger.Error(err, "expected status exists and fail to remove cleanup status")
-		return err
+	if desiredVolumeMode == corev1.PersistentVolumeFilesystem && actualVolumeMode == corev1.PersistentVolumeBlock && mountConfig.FsType != "" {
+		if filesystem := getFilesystemSpec(obj, storageClass.GetName()); filesystem != nil {
+			err = formatDevice(client, pvLogger, pvName, symLinkPath, mountConfig.FsType, filesystem)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	var capacityBytes int64
@@ -159,6 +168,23 @@ func CreateLocalPV(
 	if idExists {
 		annotations[PVDeviceIDLabel] = filepath.Base(symLinkPath)
 	}
+	// the symlinks of the devices point to the persistent link they were found by,
+	// or to the kernel name of the devices that had none when they were provisioned.
+	// The ones of the encrypted devices point to their device-mapper node, named after that link.
+	if source, err := os.Readlink(symLinkPath); err == nil {
+		if internal.IsLUKSMapperPath(source) {
+			source, _ = getEncryptedDeviceID(symLinkPath, source)
+		}
+		switch identity := internal.GetIdentity(source); identity {
+		case "":
+		case internal.IdentityKName:
+			delete(annotations, PVDeviceIDLabel)
+			annotations[PVDeviceIdentityAnnotation] = identity
+		default:
+			annotations[PVDeviceIDLabel] = filepath.Base(source)
+			annotations[PVDeviceIdentityAnnotation] = identity
+		}
+	}
 
 	var reclaimPolicy corev1.PersistentVolumeReclaimPolicy
 	if storageClass.ReclaimPolicy == nil {
@@ -199,6 +225,7 @@ func CreateLocalPV(
 		if existingPV.CreationTimestamp.IsZero() {
 			// operations for create
 			newPV.DeepCopyInto(existingPV)
+			setDeviceFingerprint(pvLogger, existingPV, symLinkPath)
 		}
 		// operations for update only
 
@@ -250,3 +277,15 @@ func GeneratePVName(file, node, class string) string {
 	// This is the FNV-1a 32-bit hash
 	return fmt.Sprintf("local-pv-%x", h.Sum32())
 }
+
+// GetPV returns the PV, nil if it doesn't exist
+func GetPV(ctx context.Context, c client.Client, pvName string) (*corev1.PersistentVolume, error) {
+	pv := &corev1.PersistentVolume{}
+	err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get PV %q: %w", pvName, err)
+	}
+	return pv, nil
+}
@@ -0,0 +1,196 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	"github.com/openshift/local-storage-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrNoFingerprint is returned for the symlinks whose device has no serial or WWN recorded in its claim or on its PV
+var ErrNoFingerprint = errors.New("no serial or WWN was recorded")
+
+// SymlinkMigration is the migration of a symlink from the kernel name of a device to its persistent link
+type SymlinkMigration struct {
+	// SymlinkPath is the migrated symlink. Its name, and so the name of its PV, is kept
+	SymlinkPath string
+	// OldSource is the /dev/KNAME path the symlink pointed to
+	OldSource string
+	// Source is the persistent link the symlink points to once migrated
+	Source string
+	// Device is the device of the PV
+	Device internal.BlockDevice
+	// Err is set when the symlink could not be migrated
+	Err error
+}
+
+// MigrateKNameSymlinks points the symlinks of symlinkDir created on the kernel name of a device, because it had no
+// persistent link, to the persistent link the device has now. The device is the one whose serial or WWN, and size, match
+// the fingerprint recorded when it was claimed, see findClaimedDevice.
+// The kernel names can change after a reboot, the symlink is pointed to the device that matches even if it has another
+// kernel name now, unless its PV is bound.
+// The symlinks whose device has no persistent link yet are left unchanged and not returned. The ones whose device
+// can't be verified are left unchanged and returned with an error.
+func MigrateKNameSymlinks(
+	ctx context.Context,
+	c client.Client,
+	store *state.Store,
+	symlinkDir string,
+	nodeName string,
+	storageClassName string,
+	blockDevices []internal.BlockDevice,
+	identityPolicy []localv1.DeviceIdentity,
+) []SymlinkMigration {
+	paths, err := filepath.Glob(filepath.Join(symlinkDir, "*"))
+	if err != nil {
+		return []SymlinkMigration{{Err: fmt.Errorf("could not list the symlinks in %q: %w", symlinkDir, err)}}
+	}
+	migrations := make([]SymlinkMigration, 0)
+	for _, symlinkPath := range paths {
+		oldSource, err := os.Readlink(symlinkPath)
+		if err != nil || internal.GetIdentity(oldSource) != internal.IdentityKName {
+			continue
+		}
+		pvName := GeneratePVName(filepath.Base(symlinkPath), nodeName, storageClassName)
+		// the cleaner wipes the device through the symlink
+		if cleanup, found := store.GetCleanup(pvName); found && cleanup.Status != state.CleanupSucceeded {
+			continue
+		}
+		pv := &corev1.PersistentVolume{}
+		err = c.Get(ctx, types.NamespacedName{Name: pvName}, pv)
+		if kerrors.IsNotFound(err) {
+			pv = nil
+		} else if err != nil {
+			migrations = append(migrations, SymlinkMigration{SymlinkPath: symlinkPath, OldSource: oldSource, Err: err})
+			continue
+		}
+
+		claim, _ := store.GetClaim(oldSource)
+		device, found, err := findClaimedDevice(claim, pv, filepath.Base(oldSource), blockDevices)
+		if err != nil || !found {
+			if err != nil {
+				migrations = append(migrations, SymlinkMigration{SymlinkPath: symlinkPath, OldSource: oldSource, Err: err})
+			}
+			continue
+		}
+		migration := SymlinkMigration{SymlinkPath: symlinkPath, OldSource: oldSource, Device: device}
+		if device.KName != filepath.Base(oldSource) && pv != nil && pv.Status.Phase == corev1.VolumeBound {
+			migration.Err = fmt.Errorf("%q is now device %q, the PV %q is bound to a different device", oldSource, device.KName, pvName)
+			migrations = append(migrations, migration)
+			continue
+		}
+		source, _, idExists, err := GetSymLinkSourceAndTarget(device, symlinkDir, identityPolicy)
+		if err != nil {
+			migration.Err = err
+			migrations = append(migrations, migration)
+			continue
+		} else if !idExists {
+			continue
+		}
+		migration.Source = source
+
+		migration.Err = replaceSymlink(source, symlinkPath)
+		if migration.Err == nil && pv != nil {
+			migration.Err = setDeviceIdentityAnnotations(ctx, c, pvName, device.KName, source)
+		}
+		if migration.Err == nil {
+			migration.Err = store.MoveClaim(oldSource, source)
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations
+}
+
+// findClaimedDevice returns the device whose serial or WWN matches the fingerprint of the claim, or the one recorded on
+// the PV when the claim has none, and whose size matches too. The capacity of Block PVs is compared as well, but a device
+// is never told apart by its size alone: the PVs with no serial or WWN recorded, such as the Filesystem PVs created
+// before the fingerprints, are left on the kernel name and ErrNoFingerprint is returned.
+func findClaimedDevice(claim state.Claim, pv *corev1.PersistentVolume, kname string, blockDevices []internal.BlockDevice) (internal.BlockDevice, bool, error) {
+	fingerprint := internal.Fingerprint{Serial: claim.Serial, WWN: claim.WWN, Size: claim.Size}
+	if !claim.HasFingerprint() && pv != nil {
+		fingerprint, _ = getDeviceFingerprint(pv)
+	}
+	if fingerprint.Serial == "" && fingerprint.WWN == "" {
+		return internal.BlockDevice{}, false, fmt.Errorf("%w: the device claimed as %q can't be told apart from the device that has its kernel name now", ErrNoFingerprint, kname)
+	}
+	var capacity int64
+	// the capacity of Filesystem PVs is the one of their filesystem
+	if pv != nil && pv.Spec.VolumeMode != nil && *pv.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		quantity := pv.Spec.Capacity[corev1.ResourceStorage]
+		capacity = quantity.Value()
+	}
+
+	matches := make([]internal.BlockDevice, 0)
+	for _, device := range blockDevices {
+		size, err := device.GetSize()
+		if err != nil {
+			continue
+		}
+		if (fingerprint.Serial != "" && device.Serial != fingerprint.Serial) ||
+			(fingerprint.WWN != "" && device.WWN != fingerprint.WWN) ||
+			(fingerprint.Size != 0 && size != fingerprint.Size) {
+			continue
+		}
+		if capacity != 0 && RoundDownCapacityPretty(size) != capacity {
+			continue
+		}
+		// the device still has the kernel name of the symlink
+		if device.KName == kname {
+			return device, true, nil
+		}
+		matches = append(matches, device)
+	}
+	switch len(matches) {
+	case 0:
+		return internal.BlockDevice{}, false, fmt.Errorf("no device matches the device claimed as %q", kname)
+	case 1:
+		return matches[0], true, nil
+	}
+	return internal.BlockDevice{}, false, fmt.Errorf("%d devices match the device claimed as %q", len(matches), kname)
+}
+
+// replaceSymlink atomically points the symlink to source.
+// The temporary symlink is created out of the symlink directory, so that it's never provisioned.
+func replaceSymlink(source, symlinkPath string) error {
+	tmpPath := filepath.Join(filepath.Dir(filepath.Dir(symlinkPath)), "."+filepath.Base(symlinkPath)+".migrating")
+	err := os.Remove(tmpPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err = os.Symlink(source, tmpPath)
+	if err != nil {
+		return fmt.Errorf("could not create symlink: %w", err)
+	}
+	err = os.Rename(tmpPath, symlinkPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("could not replace symlink %q: %w", symlinkPath, err)
+	}
+	return nil
+}
+
+// setDeviceIdentityAnnotations records the device name and persistent link of the device on its PV
+func setDeviceIdentityAnnotations(ctx context.Context, c client.Client, pvName, kname, source string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pv := &corev1.PersistentVolume{}
+		err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv)
+		if err != nil {
+			return err
+		}
+		InitMapIfNil(&pv.ObjectMeta.Annotations)
+		pv.Annotations[PVDeviceNameLabel] = kname
+		pv.Annotations[PVDeviceIDLabel] = filepath.Base(source)
+		pv.Annotations[PVDeviceIdentityAnnotation] = internal.GetIdentity(source)
+		return c.Update(ctx, pv)
+	})
+}
@@ -0,0 +1,195 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMigrateKNameSymlinks(t *testing.T) {
+	// sdb was renamed sdc after a reboot, and now has a by-id link
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		if pattern == "/dev/disk/by-id/*" {
+			return []string{"/dev/disk/by-id/scsi-SERIAL1"}, nil
+		}
+		return []string{}, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		return "/dev/sdc", nil
+	}
+	defer func() {
+		internal.FilePathGlob = filepath.Glob
+		internal.FilePathEvalSymLinks = filepath.EvalSymlinks
+	}()
+	tmpDir, err := ioutil.TempDir("", "symlink-migration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	symlinkDir := filepath.Join(tmpDir, "local-sc")
+	assert.NoError(t, os.MkdirAll(symlinkDir, 0755))
+	symlinkPath := filepath.Join(symlinkDir, "sdb")
+	assert.NoError(t, os.Symlink("/dev/sdb", symlinkPath))
+	assert.NoError(t, os.Symlink("/dev/disk/by-id/scsi-SERIAL2", filepath.Join(symlinkDir, "scsi-SERIAL2")))
+
+	store, err := state.Open(filepath.Join(tmpDir, state.FileName))
+	assert.NoError(t, err)
+	pvName := GeneratePVName("sdb", "node1", "local-sc")
+	assert.NoError(t, store.RecordClaim("/dev/sdb", state.Claim{DeviceName: "sdb", PVName: pvName, Serial: "SERIAL1", Size: 10737418240}))
+
+	blockMode := corev1.PersistentVolumeBlock
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: pvName},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:   corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			VolumeMode: &blockMode,
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	client := fake.NewFakeClientWithScheme(scheme, pv)
+	blockDevices := []internal.BlockDevice{
+		{Name: "sdb", KName: "sdb", Serial: "SERIAL3", Size: "10737418240"},
+		{Name: "sdc", KName: "sdc", Serial: "SERIAL1", Size: "10737418240"},
+	}
+
+	migrations := MigrateKNameSymlinks(context.TODO(), client, store, symlinkDir, "node1", "local-sc", blockDevices, nil)
+	assert.Len(t, migrations, 1)
+	assert.NoError(t, migrations[0].Err)
+	assert.Equal(t, "/dev/disk/by-id/scsi-SERIAL1", migrations[0].Source)
+	assert.Equal(t, "sdc", migrations[0].Device.KName)
+
+	source, err := os.Readlink(symlinkPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/disk/by-id/scsi-SERIAL1", source, "the symlink keeps its name")
+	_, err = os.Lstat(filepath.Join(tmpDir, ".sdb.migrating"))
+	assert.True(t, os.IsNotExist(err))
+
+	err = client.Get(context.TODO(), types.NamespacedName{Name: pvName}, pv)
+	assert.NoError(t, err)
+	assert.Equal(t, "scsi-SERIAL1", pv.Annotations[PVDeviceIDLabel])
+	assert.Equal(t, "sdc", pv.Annotations[PVDeviceNameLabel])
+	assert.Equal(t, internal.IdentityByID, pv.Annotations[PVDeviceIdentityAnnotation])
+
+	claim, found := store.GetClaim("/dev/disk/by-id/scsi-SERIAL1")
+	assert.True(t, found)
+	assert.Equal(t, pvName, claim.PVName)
+	_, found = store.GetClaim("/dev/sdb")
+	assert.False(t, found)
+
+	// migrated symlinks are left alone
+	migrations = MigrateKNameSymlinks(context.TODO(), client, store, symlinkDir, "node1", "local-sc", blockDevices, nil)
+	assert.Empty(t, migrations)
+}
+
+func TestMigrateKNameSymlinksBoundPV(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "symlink-migration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	symlinkPath := filepath.Join(tmpDir, "sdb")
+	assert.NoError(t, os.Symlink("/dev/sdb", symlinkPath))
+
+	pvName := GeneratePVName("sdb", "node1", "local-sc")
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: pvName},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	client := fake.NewFakeClientWithScheme(scheme, pv)
+	store, err := state.Open(filepath.Join(tmpDir, state.FileName))
+	assert.NoError(t, err)
+	blockDevices := []internal.BlockDevice{{Name: "sdc", KName: "sdc", Serial: "SERIAL1", Size: "10737418240"}}
+
+	// nothing to verify the device with
+	migrations := MigrateKNameSymlinks(context.TODO(), client, store, tmpDir, "node1", "local-sc", blockDevices, nil)
+	assert.Len(t, migrations, 1)
+	assert.True(t, errors.Is(migrations[0].Err, ErrNoFingerprint))
+
+	assert.NoError(t, store.RecordClaim("/dev/sdb", state.Claim{DeviceName: "sdb", PVName: pvName, Serial: "SERIAL1", Size: 10737418240}))
+	migrations = MigrateKNameSymlinks(context.TODO(), client, store, tmpDir, "node1", "local-sc", blockDevices, nil)
+	assert.Len(t, migrations, 1)
+	assert.Error(t, migrations[0].Err, "the device of a bound PV is never changed")
+	source, err := os.Readlink(symlinkPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/sdb", source)
+}
+
+func TestFindClaimedDevice(t *testing.T) {
+	filesystemMode := corev1.PersistentVolumeFilesystem
+	blockMode := corev1.PersistentVolumeBlock
+	blockDevices := []internal.BlockDevice{
+		{Name: "sdb", KName: "sdb", Serial: "SERIAL3", Size: "10737418240"},
+		{Name: "sdc", KName: "sdc", Serial: "SERIAL1", WWN: "0x5000c500a0b1c2d3", Size: "10737418240"},
+	}
+	testTable := []struct {
+		desc          string
+		claim         state.Claim
+		pv            *corev1.PersistentVolume
+		expectedKName string
+		expectedErr   error
+	}{
+		{
+			desc:          "serial of the claim",
+			claim:         state.Claim{Serial: "SERIAL1", Size: 10737418240},
+			expectedKName: "sdc",
+		},
+		{
+			desc:          "WWN of the claim",
+			claim:         state.Claim{WWN: "0x5000c500a0b1c2d3", Size: 10737418240},
+			expectedKName: "sdc",
+		},
+		{
+			desc:        "size of the claim alone",
+			claim:       state.Claim{Size: 10737418240},
+			expectedErr: ErrNoFingerprint,
+		},
+		{
+			desc: "capacity of a Block PV alone",
+			pv: &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{
+				Capacity:   corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				VolumeMode: &blockMode,
+			}},
+			expectedErr: ErrNoFingerprint,
+		},
+		{
+			desc:        "Filesystem PV created before the fingerprints",
+			pv:          &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{VolumeMode: &filesystemMode}},
+			expectedErr: ErrNoFingerprint,
+		},
+		{
+			desc: "fingerprint of a Filesystem PV",
+			pv: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					PVDeviceSerialAnnotation: "SERIAL1",
+					PVDeviceSizeAnnotation:   "10737418240",
+				}},
+				Spec: corev1.PersistentVolumeSpec{VolumeMode: &filesystemMode},
+			},
+			expectedKName: "sdc",
+		},
+	}
+	for _, tc := range testTable {
+		device, found, err := findClaimedDevice(tc.claim, tc.pv, "sdb", blockDevices)
+		if tc.expectedErr != nil {
+			assert.Truef(t, errors.Is(err, tc.expectedErr), "%s: expected error %v, got %v", tc.desc, tc.expectedErr, err)
+			assert.Falsef(t, found, "%s", tc.desc)
+			continue
+		}
+		assert.NoErrorf(t, err, "%s", tc.desc)
+		assert.Truef(t, found, "%s", tc.desc)
+		assert.Equalf(t, tc.expectedKName, device.KName, "%s", tc.desc)
+	}
+}
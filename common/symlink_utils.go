@@ -1,19 +1,20 @@
 package common
 
 import (
-	"errors"
 	"path"
 	"path/filepath"
 
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/internal"
 )
 
 // GetSymLinkSourceAndTarget returns
-// `source`: the /dev/disk/by-id path of the device if it exists, /dev/KNAME if it doesn't
-// `target`: the path in the symlinkdir to symlink to. device-id if it exists, KNAME if it doesn't
-// `idExists`: is set if the device-id exists
+// `source`: the first persistent link of the device in the identityPolicy, /dev/KNAME if it has none
+// `target`: the path in the symlinkdir to symlink to. the name of the persistent link if it exists, KNAME if it doesn't
+// `idExists`: is set if a persistent link exists
 // `err`
-func GetSymLinkSourceAndTarget(dev internal.BlockDevice, symlinkDir string) (string, string, bool, error) {
+// The default policy is used when identityPolicy is empty.
+func GetSymLinkSourceAndTarget(dev internal.BlockDevice, symlinkDir string, identityPolicy []localv1.DeviceIdentity) (string, string, bool, error) {
 	var source string
 	var target string
 	var idExists = true
@@ -25,15 +26,33 @@ func GetSymLinkSourceAndTarget(dev internal.BlockDevice, symlinkDir string) (str
 		return source, target, false, err
 	}
 	// determine symlink source
-	source, err = dev.GetPathByID()
-	if errors.As(err, &internal.IDPathNotFoundError{}) {
-		// no disk-by-id
+	for _, identity := range getIdentityPolicy(identityPolicy) {
+		source, err = dev.GetPathByIdentity(identity)
+		if err != nil {
+			return source, target, false, err
+		}
+		if source != "" {
+			break
+		}
+	}
+	if source == "" {
+		// no persistent link
 		idExists = false
 		source = devLabelPath
-	} else if err != nil {
-		return source, target, false, err
 	}
 	target = path.Join(symlinkDir, filepath.Base(source))
 	return source, target, idExists, nil
 
 }
+
+// getIdentityPolicy returns the identities of the policy, or the default policy if it is empty
+func getIdentityPolicy(identityPolicy []localv1.DeviceIdentity) []string {
+	if len(identityPolicy) == 0 {
+		return internal.DefaultIdentityPolicy
+	}
+	identities := make([]string, 0, len(identityPolicy))
+	for _, identity := range identityPolicy {
+		identities = append(identities, string(identity))
+	}
+	return identities
+}
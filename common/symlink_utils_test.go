@@ -0,0 +1,48 @@
+package common
+
+import (
+	"path/filepath"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSymLinkSourceAndTarget(t *testing.T) {
+	internal.FilePathGlob = func(pattern string) ([]string, error) {
+		switch pattern {
+		case "/dev/disk/by-id/wwn-*":
+			return []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"}, nil
+		case "/dev/disk/by-path/*":
+			return []string{"/dev/disk/by-path/pci-0000:00:1f.2-ata-1"}, nil
+		}
+		return []string{}, nil
+	}
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		return "/dev/sdb", nil
+	}
+	defer func() {
+		internal.FilePathGlob = filepath.Glob
+		internal.FilePathEvalSymLinks = filepath.EvalSymlinks
+	}()
+	dev := internal.BlockDevice{Name: "sdb", KName: "sdb"}
+
+	source, target, idExists, err := GetSymLinkSourceAndTarget(dev, "/mnt/local-storage/local-sc", nil)
+	assert.NoError(t, err)
+	assert.True(t, idExists)
+	assert.Equal(t, "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", source, "wwn is tried when there is no other by-id link")
+	assert.Equal(t, "/mnt/local-storage/local-sc/wwn-0x5000c500a0b1c2d3", target)
+
+	source, target, idExists, err = GetSymLinkSourceAndTarget(dev, "/mnt/local-storage/local-sc", []localv1.DeviceIdentity{localv1.DeviceIdentityByPath})
+	assert.NoError(t, err)
+	assert.True(t, idExists)
+	assert.Equal(t, "/dev/disk/by-path/pci-0000:00:1f.2-ata-1", source)
+	assert.Equal(t, "/mnt/local-storage/local-sc/pci-0000:00:1f.2-ata-1", target)
+
+	source, target, idExists, err = GetSymLinkSourceAndTarget(dev, "/mnt/local-storage/local-sc", []localv1.DeviceIdentity{localv1.DeviceIdentityPartUUID})
+	assert.NoError(t, err)
+	assert.False(t, idExists)
+	assert.Equal(t, "/dev/sdb", source)
+	assert.Equal(t, "/mnt/local-storage/local-sc/sdb", target)
+}
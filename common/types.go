@@ -21,6 +21,20 @@ const (
 	PVDeviceNameLabel = "storage.openshift.com/device-name"
 	// PVDeviceIDLabel is the id of the device
 	PVDeviceIDLabel = "storage.openshift.com/device-id"
+	// PVDeviceIdentityAnnotation is the identity the device is symlinked by, such as by-id, by-path or kname
+	PVDeviceIdentityAnnotation = "storage.openshift.com/device-identity"
+	// PVDeviceSerialAnnotation, PVDeviceWWNAnnotation, PVDeviceSizeAnnotation and PVDevicePartUUIDAnnotation are the
+	// fingerprint of the device the PV was created on
+	PVDeviceSerialAnnotation   = "storage.openshift.com/device-serial"
+	PVDeviceWWNAnnotation      = "storage.openshift.com/device-wwn"
+	PVDeviceSizeAnnotation     = "storage.openshift.com/device-size"
+	PVDevicePartUUIDAnnotation = "storage.openshift.com/device-partuuid"
+	// PVFencedLabel is set on the PVs whose symlink pointed to a device that didn't match their fingerprint, removing it unfences the PV
+	PVFencedLabel = "storage.openshift.com/fenced"
+	// PVFencedSourceAnnotation is the device the symlink of a fenced PV pointed to
+	PVFencedSourceAnnotation = "storage.openshift.com/fenced-source"
+	// PVQuarantinedLabel is set on the released PVs whose cleanup was interrupted too many times, removing it releases the PV to be cleaned up again
+	PVQuarantinedLabel = "storage.openshift.com/quarantined"
 )
 
 // DeprecatedLabels: these labels were deprecated because the potential values weren't all compatible label values
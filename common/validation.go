@@ -0,0 +1,185 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidateLocalVolume returns the errors of the spec of the LocalVolume.
+// They are rejected by the validating webhook for the new objects, and reported by the controllers for the objects
+// admitted without it, or before the rules were added.
+func ValidateLocalVolume(lv *localv1.LocalVolume) field.ErrorList {
+	allErrs := field.ErrorList{}
+	storageClassNames := map[string]bool{}
+	for i, storageClassDevice := range lv.Spec.StorageClassDevices {
+		fldPath := field.NewPath("spec", "storageClassDevices").Index(i)
+		allErrs = append(allErrs, validateStorageClassName(fldPath.Child("storageClassName"), storageClassDevice.StorageClassName)...)
+		if storageClassNames[storageClassDevice.StorageClassName] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("storageClassName"), storageClassDevice.StorageClassName))
+		}
+		storageClassNames[storageClassDevice.StorageClassName] = true
+
+		allErrs = append(allErrs, validateVolumeMode(fldPath, storageClassDevice.VolumeMode, storageClassDevice.FSType)...)
+		if len(storageClassDevice.DevicePaths) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("devicePaths"), "at least one device path is required"))
+		}
+		allErrs = append(allErrs, validateDevicePaths(fldPath.Child("devicePaths"), storageClassDevice.DevicePaths)...)
+		allErrs = append(allErrs, validateEncryption(fldPath.Child("encryption"), storageClassDevice.Encryption)...)
+	}
+	return allErrs
+}
+
+// ValidateLocalVolumeSet returns the errors of the spec of the LocalVolumeSet.
+// They are rejected by the validating webhook for the new objects, and reported by the controllers for the objects
+// admitted without it, or before the rules were added.
+func ValidateLocalVolumeSet(lvset *localv1.LocalVolumeSet) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	spec := lvset.Spec
+	allErrs = append(allErrs, validateStorageClassName(specPath.Child("storageClassName"), spec.StorageClassName)...)
+	allErrs = append(allErrs, validateVolumeMode(specPath, spec.VolumeMode, spec.FSType)...)
+	allErrs = append(allErrs, validateEncryption(specPath.Child("encryption"), spec.Encryption)...)
+
+	if inclusion := spec.DeviceInclusionSpec; inclusion != nil {
+		inclusionPath := specPath.Child("deviceInclusionSpec")
+		if inclusion.MinSize != nil && inclusion.MaxSize != nil && inclusion.MinSize.Cmp(*inclusion.MaxSize) > 0 {
+			allErrs = append(allErrs, field.Invalid(inclusionPath.Child("minSize"), inclusion.MinSize.String(),
+				fmt.Sprintf("must not be greater than maxSize %s", inclusion.MaxSize.String())))
+		}
+		allErrs = append(allErrs, validateDevicePaths(inclusionPath.Child("devicePaths"), inclusion.DevicePaths)...)
+		allErrs = append(allErrs, validateDevicePaths(inclusionPath.Child("excludedDevicePaths"), inclusion.ExcludedDevicePaths)...)
+	}
+
+	if spec.SharedFilesystem != nil {
+		if spec.VolumeMode == localv1.PersistentVolumeBlock {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("volumeMode"), spec.VolumeMode, "sharedFilesystem requires volumeMode Filesystem"))
+		}
+		if spec.Encryption != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("encryption"), "sharedFilesystem can not be encrypted"))
+		}
+	}
+	if spec.RAID != nil {
+		if spec.Encryption != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("raid"), "raid can not be combined with encryption"))
+		}
+		if spec.SharedFilesystem != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("raid"), "raid can not be combined with sharedFilesystem"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateStorageClassOwners returns an error for each storage class of the LocalVolume or LocalVolumeSet
+// that another LocalVolume or LocalVolumeSet provisions with a different volume mode. The storage classes are
+// cluster-scoped, so are the other owners.
+func ValidateStorageClassOwners(ctx context.Context, c client.Reader, obj client.Object) (field.ErrorList, error) {
+	lvs := &localv1.LocalVolumeList{}
+	err := c.List(ctx, lvs)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the LocalVolumes: %w", err)
+	}
+	lvsets := &localv1.LocalVolumeSetList{}
+	err = c.List(ctx, lvsets)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the LocalVolumeSets: %w", err)
+	}
+
+	// the volume mode of each storage class, by the other owners
+	owners := map[string]string{}
+	volumeModes := map[string]localv1.PersistentVolumeMode{}
+	_, isLocalVolume := obj.(*localv1.LocalVolume)
+	for _, lv := range lvs.Items {
+		if isLocalVolume && lv.Name == obj.GetName() && lv.Namespace == obj.GetNamespace() {
+			continue
+		}
+		for _, storageClassDevice := range lv.Spec.StorageClassDevices {
+			owners[storageClassDevice.StorageClassName] = fmt.Sprintf("%s %s/%s", localv1.LocalVolumeKind, lv.Namespace, lv.Name)
+			volumeModes[storageClassDevice.StorageClassName] = getVolumeMode(storageClassDevice.VolumeMode)
+		}
+	}
+	_, isLocalVolumeSet := obj.(*localv1.LocalVolumeSet)
+	for _, lvset := range lvsets.Items {
+		if isLocalVolumeSet && lvset.Name == obj.GetName() && lvset.Namespace == obj.GetNamespace() {
+			continue
+		}
+		owners[lvset.Spec.StorageClassName] = fmt.Sprintf("%s %s/%s", localv1.LocalVolumeSetKind, lvset.Namespace, lvset.Name)
+		volumeModes[lvset.Spec.StorageClassName] = getVolumeMode(lvset.Spec.VolumeMode)
+	}
+
+	allErrs := field.ErrorList{}
+	validate := func(fldPath *field.Path, storageClassName string, volumeMode localv1.PersistentVolumeMode) {
+		otherVolumeMode, found := volumeModes[storageClassName]
+		if found && otherVolumeMode != getVolumeMode(volumeMode) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storageClassName"), storageClassName,
+				fmt.Sprintf("storage class is provisioned with volumeMode %s by %s", otherVolumeMode, owners[storageClassName])))
+		}
+	}
+	switch o := obj.(type) {
+	case *localv1.LocalVolume:
+		for i, storageClassDevice := range o.Spec.StorageClassDevices {
+			validate(field.NewPath("spec", "storageClassDevices").Index(i), storageClassDevice.StorageClassName, storageClassDevice.VolumeMode)
+		}
+	case *localv1.LocalVolumeSet:
+		validate(field.NewPath("spec"), o.Spec.StorageClassName, o.Spec.VolumeMode)
+	}
+	return allErrs, nil
+}
+
+// getVolumeMode returns the volume mode of the PVs, Filesystem when it is not set
+func getVolumeMode(volumeMode localv1.PersistentVolumeMode) localv1.PersistentVolumeMode {
+	if volumeMode == "" {
+		return localv1.PersistentVolumeFilesystem
+	}
+	return volumeMode
+}
+
+func validateStorageClassName(fldPath *field.Path, storageClassName string) field.ErrorList {
+	if storageClassName == "" {
+		return field.ErrorList{field.Required(fldPath, "storage class name is required")}
+	}
+	return nil
+}
+
+func validateVolumeMode(fldPath *field.Path, volumeMode localv1.PersistentVolumeMode, fsType string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch volumeMode {
+	case "", localv1.PersistentVolumeFilesystem:
+	case localv1.PersistentVolumeBlock:
+		if fsType != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("fsType"), fsType, "fsType can not be set with volumeMode Block"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("volumeMode"), volumeMode,
+			[]string{string(localv1.PersistentVolumeBlock), string(localv1.PersistentVolumeFilesystem)}))
+	}
+	return allErrs
+}
+
+func validateDevicePaths(fldPath *field.Path, devicePaths []string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, devicePath := range devicePaths {
+		if !strings.HasPrefix(devicePath, "/dev/") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), devicePath, "expected a path in /dev/"))
+		}
+	}
+	return allErrs
+}
+
+func validateEncryption(fldPath *field.Path, encryption *localv1.EncryptionSpec) field.ErrorList {
+	if encryption == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	if encryption.KeyPolicy == localv1.EncryptionKeySecret && (encryption.KeySecretRef == nil || encryption.KeySecretRef.Name == "") {
+		allErrs = append(allErrs, field.Required(fldPath.Child("keySecretRef"), fmt.Sprintf("keySecretRef is required with the %q keyPolicy", localv1.EncryptionKeySecret)))
+	}
+	if encryption.Escrow != nil && encryption.Escrow.HostDir != "" && !IsAllowedEscrowHostDir(encryption.Escrow.HostDir) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("escrow", "hostDir"), encryption.Escrow.HostDir, fmt.Sprintf("must be under %s", EscrowHostDirPrefix)))
+	}
+	return allErrs
+}
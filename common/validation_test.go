@@ -0,0 +1,192 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateLocalVolume(t *testing.T) {
+	testTable := []struct {
+		desc           string
+		devices        []localv1.StorageClassDevice
+		expectedFields []string
+	}{
+		{
+			desc: "valid",
+			devices: []localv1.StorageClassDevice{
+				{StorageClassName: "fast", VolumeMode: localv1.PersistentVolumeFilesystem, FSType: "xfs", DevicePaths: []string{"/dev/sdb"}},
+				{StorageClassName: "block", VolumeMode: localv1.PersistentVolumeBlock, DevicePaths: []string{"/dev/disk/by-id/wwn-0x1"}},
+			},
+		},
+		{
+			desc: "invalid",
+			devices: []localv1.StorageClassDevice{
+				{StorageClassName: "", DevicePaths: []string{"/tmp/sdb"}},
+				{StorageClassName: "block", VolumeMode: localv1.PersistentVolumeBlock, FSType: "ext4"},
+				{StorageClassName: "block", VolumeMode: "Raw", DevicePaths: []string{"/dev/sdc"},
+					Encryption: &localv1.EncryptionSpec{KeyPolicy: localv1.EncryptionKeySecret}},
+			},
+			expectedFields: []string{
+				"spec.storageClassDevices[0].storageClassName",
+				"spec.storageClassDevices[0].devicePaths[0]",
+				"spec.storageClassDevices[1].fsType",
+				"spec.storageClassDevices[1].devicePaths",
+				"spec.storageClassDevices[2].storageClassName",
+				"spec.storageClassDevices[2].volumeMode",
+				"spec.storageClassDevices[2].encryption.keySecretRef",
+			},
+		},
+	}
+	for _, tc := range testTable {
+		lv := &localv1.LocalVolume{Spec: localv1.LocalVolumeSpec{StorageClassDevices: tc.devices}}
+		fields := []string{}
+		for _, err := range ValidateLocalVolume(lv) {
+			fields = append(fields, err.Field)
+		}
+		assert.ElementsMatch(t, tc.expectedFields, fields, "[%s] unexpected errors", tc.desc)
+	}
+}
+
+func TestValidateLocalVolumeSet(t *testing.T) {
+	small := resource.MustParse("10Gi")
+	large := resource.MustParse("100Gi")
+	testTable := []struct {
+		desc           string
+		spec           localv1.LocalVolumeSetSpec
+		expectedFields []string
+	}{
+		{
+			desc: "valid",
+			spec: localv1.LocalVolumeSetSpec{
+				StorageClassName: "fast",
+				DeviceInclusionSpec: &localv1.DeviceInclusionSpec{
+					MinSize:     &small,
+					MaxSize:     &large,
+					DevicePaths: []string{"/dev/disk/by-id/wwn-0x1"},
+				},
+			},
+		},
+		{
+			desc: "invalid sizes and paths",
+			spec: localv1.LocalVolumeSetSpec{
+				StorageClassName: "fast",
+				VolumeMode:       localv1.PersistentVolumeBlock,
+				FSType:           "xfs",
+				DeviceInclusionSpec: &localv1.DeviceInclusionSpec{
+					MinSize:             &large,
+					MaxSize:             &small,
+					DevicePaths:         []string{"/dev/sdb", "sdc"},
+					ExcludedDevicePaths: []string{"/mnt/sdd"},
+				},
+			},
+			expectedFields: []string{
+				"spec.fsType",
+				"spec.deviceInclusionSpec.minSize",
+				"spec.deviceInclusionSpec.devicePaths[1]",
+				"spec.deviceInclusionSpec.excludedDevicePaths[0]",
+			},
+		},
+		{
+			desc: "escrow outside of the escrow prefix",
+			spec: localv1.LocalVolumeSetSpec{
+				StorageClassName: "sc",
+				Encryption: &localv1.EncryptionSpec{
+					KeyPolicy: localv1.EncryptionKeyGenerated,
+					Escrow:    &localv1.KeyEscrowSpec{HostDir: "/var/lib/local-storage/escrow/../../kubelet"},
+				},
+			},
+			expectedFields: []string{
+				"spec.encryption.escrow.hostDir",
+			},
+		},
+		{
+			desc: "invalid combinations",
+			spec: localv1.LocalVolumeSetSpec{
+				VolumeMode:       localv1.PersistentVolumeBlock,
+				SharedFilesystem: &localv1.SharedFilesystemSpec{VolumeCount: 2},
+				RAID:             &localv1.RAIDSpec{Level: localv1.RAID1, Width: 2},
+				Encryption:       &localv1.EncryptionSpec{KeyPolicy: localv1.EncryptionKeyGenerated},
+			},
+			expectedFields: []string{
+				"spec.storageClassName",
+				"spec.volumeMode",
+				"spec.encryption",
+				"spec.raid",
+				"spec.raid",
+			},
+		},
+	}
+	for _, tc := range testTable {
+		lvset := &localv1.LocalVolumeSet{Spec: tc.spec}
+		fields := []string{}
+		for _, err := range ValidateLocalVolumeSet(lvset) {
+			fields = append(fields, err.Field)
+		}
+		assert.ElementsMatch(t, tc.expectedFields, fields, "[%s] unexpected errors", tc.desc)
+	}
+}
+
+func TestValidateStorageClassOwners(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, localv1.AddToScheme(scheme))
+	assert.NoError(t, localv1alpha1.AddToScheme(scheme))
+	lv := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "disks", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSpec{StorageClassDevices: []localv1.StorageClassDevice{
+			{StorageClassName: "fast", DevicePaths: []string{"/dev/sdb"}},
+		}},
+	}
+	lvset := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "block", Namespace: "openshift-local-storage"},
+		Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "block", VolumeMode: localv1.PersistentVolumeBlock},
+	}
+	// storage classes are cluster-scoped
+	otherNamespaceLVSet := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "shared-block", VolumeMode: localv1.PersistentVolumeBlock},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, lv, lvset, otherNamespaceLVSet)
+
+	// the same volume mode, and the object itself, are not conflicts
+	sameMode := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "fast", Namespace: "openshift-local-storage"},
+		Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "fast", VolumeMode: localv1.PersistentVolumeFilesystem},
+	}
+	allErrs, err := ValidateStorageClassOwners(context.TODO(), client, sameMode)
+	assert.NoError(t, err)
+	assert.Empty(t, allErrs)
+	updatedLVSet := lvset.DeepCopy()
+	updatedLVSet.Spec.VolumeMode = localv1.PersistentVolumeFilesystem
+	allErrs, err = ValidateStorageClassOwners(context.TODO(), client, updatedLVSet)
+	assert.NoError(t, err)
+	assert.Empty(t, allErrs)
+
+	conflicting := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "more-disks", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSpec{StorageClassDevices: []localv1.StorageClassDevice{
+			{StorageClassName: "fast", VolumeMode: localv1.PersistentVolumeBlock, DevicePaths: []string{"/dev/sdc"}},
+			{StorageClassName: "block", DevicePaths: []string{"/dev/sdd"}},
+			{StorageClassName: "shared-block", DevicePaths: []string{"/dev/sde"}},
+		}},
+	}
+	allErrs, err = ValidateStorageClassOwners(context.TODO(), client, conflicting)
+	assert.NoError(t, err)
+	if assert.Len(t, allErrs, 3) {
+		assert.Equal(t, "spec.storageClassDevices[0].storageClassName", allErrs[0].Field)
+		assert.Contains(t, allErrs[0].Detail, "volumeMode Filesystem by LocalVolume openshift-local-storage/disks")
+		assert.Equal(t, "spec.storageClassDevices[1].storageClassName", allErrs[1].Field)
+		assert.Contains(t, allErrs[1].Detail, "volumeMode Block by LocalVolumeSet openshift-local-storage/block")
+		assert.Equal(t, "spec.storageClassDevices[2].storageClassName", allErrs[2].Field)
+		assert.Contains(t, allErrs[2].Detail, "volumeMode Block by LocalVolumeSet default/other")
+	}
+}
@@ -14,14 +14,18 @@ const (
 
 	udevVolName = "run-udev"
 	udevPath    = "/run/udev"
+
+	udevRulesVolName = "udev-rules"
+	udevRulesPath    = "/etc/udev/rules.d"
 )
 
 var (
-	hostContainerPropagation = corev1.MountPropagationHostToContainer
-	directoryHostPath        = corev1.HostPathDirectory
+	hostContainerPropagation  = corev1.MountPropagationHostToContainer
+	directoryHostPath         = corev1.HostPathDirectory
+	directoryOrCreateHostPath = corev1.HostPathDirectoryOrCreate
 
 	// SymlinkHostDirVolume is the corev1.Volume definition for the lso symlink host directory.
-	// "/mnt/local-storage" is the default, but it can be controlled by env vars.
+	// "/mnt/local-storage" is the default, but it can be controlled by env vars and the LocalStorageOperatorConfig.
 	// SymlinkMount is the corresponding mount
 	SymlinkHostDirVolume = corev1.Volume{
 		Name: "local-disks",
@@ -93,4 +97,22 @@ var (
 		MountPath:        udevPath,
 		MountPropagation: &hostContainerPropagation,
 	}
+
+	// UDevRulesHostDirVolume is the corev1.Volume definition for the host's udev rules directory,
+	// that the rules of the /dev/disk/by-lso links are written to.
+	// UDevRulesMount is the corresponding mount
+	UDevRulesHostDirVolume = corev1.Volume{
+		Name: udevRulesVolName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: udevRulesPath,
+				Type: &directoryOrCreateHostPath,
+			},
+		},
+	}
+	// UDevRulesMount is the corresponding mount for UDevRulesHostDirVolume
+	UDevRulesMount = corev1.VolumeMount{
+		Name:      udevRulesVolName,
+		MountPath: udevRulesPath,
+	}
 )
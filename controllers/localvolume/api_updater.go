@@ -14,8 +14,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -68,21 +70,46 @@ func (s *sdkAPIUpdater) updateLocalVolume(lv *localv1.LocalVolume) error {
 	return err
 }
 
+// syncStatus writes the status of newInstance computed from oldInstance.
+// The diskmakers update the status of their node concurrently, so the status is merged into
+// the latest LocalVolume and conflicting updates are retried.
 func (s *sdkAPIUpdater) syncStatus(oldInstance, newInstance *localv1.LocalVolume) error {
 	klog.V(4).Infof("Syncing LocalVolume.Status of %s", commontypes.LocalVolumeKey(newInstance))
 
-	if !equality.Semantic.DeepEqual(oldInstance.Status, newInstance.Status) {
-		klog.V(4).Infof("Updating LocalVolume.Status of %s", commontypes.LocalVolumeKey(newInstance))
-		ctx, cancel := s.apiContext()
-		defer cancel()
-		err := s.client.Status().Update(ctx, newInstance)
-		if err != nil && errors.IsConflict(err) {
-			msg := fmt.Sprintf("updating localvolume %s failed: %v", commontypes.LocalVolumeKey(newInstance), err)
-			s.recordEvent(newInstance, corev1.EventTypeWarning, localVolumeUpdateFailed, msg)
-		}
-		return err
+	if equality.Semantic.DeepEqual(oldInstance.Status, newInstance.Status) {
+		return nil
+	}
+	klog.V(4).Infof("Updating LocalVolume.Status of %s", commontypes.LocalVolumeKey(newInstance))
+	// the nodes dropped by pruneNodeStatuses, the other node statuses belong to the diskmakers
+	pruned := map[string]bool{}
+	for _, node := range oldInstance.Status.Nodes {
+		pruned[node.NodeName] = true
 	}
-	return nil
+	for _, node := range newInstance.Status.Nodes {
+		delete(pruned, node.NodeName)
+	}
+
+	ctx, cancel := s.apiContext()
+	defer cancel()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &localv1.LocalVolume{}
+		err := s.client.Get(ctx, types.NamespacedName{Name: newInstance.Name, Namespace: newInstance.Namespace}, current)
+		if err != nil {
+			return err
+		}
+		status := newInstance.Status.DeepCopy()
+		status.Nodes = nil
+		for _, node := range current.Status.Nodes {
+			if !pruned[node.NodeName] {
+				status.Nodes = append(status.Nodes, node)
+			}
+		}
+		if equality.Semantic.DeepEqual(current.Status, *status) {
+			return nil
+		}
+		current.Status = *status
+		return s.client.Status().Update(ctx, current)
+	})
 }
 
 func (s *sdkAPIUpdater) applyStorageClass(ctx context.Context, sc *storagev1.StorageClass) (*storagev1.StorageClass, bool, error) {
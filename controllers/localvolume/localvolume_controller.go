@@ -39,8 +39,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -83,6 +85,8 @@ func (r *LocalVolumeReconciler) deregisterLVFromStorageClass(lv localv1.LocalVol
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=*
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;rolebindings,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=local.storage.openshift.io,resources=localstorageoperatorconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=local.storage.openshift.io,resources=localvolumes;localvolumesets,verbs=list
 
 func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	klog.Info("Reconciling LocalVolume")
@@ -135,6 +139,29 @@ func (r *LocalVolumeReconciler) syncLocalVolumeProvider(ctx context.Context, ins
 		return nil
 	}
 
+	conflicts, err := common.FindConflicts(ctx, r.Client, o)
+	if err != nil {
+		klog.Errorf("failed to find conflicting objects: %v", err)
+		return r.addFailureCondition(instance, o, err)
+	}
+	setConflictingCondition(o, conflicts)
+
+	// the diskmakers report the devicePaths of their node in the status
+	nodes := &corev1.NodeList{}
+	err = r.Client.List(ctx, nodes)
+	if err != nil {
+		klog.Errorf("failed to list the nodes: %v", err)
+		return r.addFailureCondition(instance, o, err)
+	}
+	pruneNodeStatuses(o, nodes.Items)
+	// new invalid specs are rejected by the validating webhook, the ones admitted before a rule was added,
+	// or without the webhook, are reported
+	specErrs := common.ValidateLocalVolume(o)
+	if len(specErrs) > 0 {
+		klog.Errorf("invalid LocalVolume %s: %v", commontypes.LocalVolumeKey(o), specErrs.ToAggregate())
+	}
+	setDegradedCondition(o, specErrs)
+
 	err = r.syncStorageClass(ctx, o)
 	if err != nil {
 		klog.Errorf("failed to create storageClass: %v", err)
@@ -182,7 +209,7 @@ func (r *LocalVolumeReconciler) addFailureCondition(oldLv *localv1.LocalVolume,
 		LastTransitionTime: metav1.Now(),
 	}
 	newConditions := []operatorv1.OperatorCondition{condition}
-	lv.Status.Conditions = newConditions
+	lv.Status.Conditions = append(newConditions, getKeptConditions(lv)...)
 	syncErr := r.apiClient.syncStatus(oldLv, lv)
 	if syncErr != nil {
 		klog.Errorf("error syncing condition: %v", syncErr)
@@ -207,10 +234,44 @@ func (r *LocalVolumeReconciler) addSuccessCondition(lv *localv1.LocalVolume) *lo
 			return lv
 		}
 	}
-	lv.Status.Conditions = newConditions
+	lv.Status.Conditions = append(newConditions, getKeptConditions(lv)...)
 	return lv
 }
 
+// setConflictingCondition sets the Conflicting condition of the LocalVolume, with the objects it conflicts with
+func setConflictingCondition(lv *localv1.LocalVolume, conflicts []common.Conflict) {
+	condition := operatorv1.OperatorCondition{
+		Type:               common.ConflictingCondition,
+		Status:             operatorv1.ConditionFalse,
+		Message:            common.ConflictsMessage(conflicts),
+		LastTransitionTime: metav1.Now(),
+	}
+	if len(conflicts) > 0 {
+		condition.Status = operatorv1.ConditionTrue
+	}
+	for i, c := range lv.Status.Conditions {
+		if c.Type == common.ConflictingCondition {
+			if c.Status == condition.Status {
+				condition.LastTransitionTime = c.LastTransitionTime
+			}
+			lv.Status.Conditions[i] = condition
+			return
+		}
+	}
+	lv.Status.Conditions = append(lv.Status.Conditions, condition)
+}
+
+// getKeptConditions returns the Conflicting and Degraded conditions of the LocalVolume, that are kept when the Available condition is replaced
+func getKeptConditions(lv *localv1.LocalVolume) []operatorv1.OperatorCondition {
+	conditions := []operatorv1.OperatorCondition{}
+	for _, c := range lv.Status.Conditions {
+		if c.Type == common.ConflictingCondition || c.Type == operatorv1.OperatorStatusTypeDegraded {
+			conditions = append(conditions, c)
+		}
+	}
+	return conditions
+}
+
 func (r *LocalVolumeReconciler) cleanupLocalVolumeDeployment(ctx context.Context, lv *localv1.LocalVolume) error {
 	klog.Infof("Deleting localvolume: %s", commontypes.LocalVolumeKey(lv))
 	childPersistentVolumes, err := r.apiClient.listPersistentVolumes(metav1.ListOptions{LabelSelector: commontypes.GetPVOwnerSelector(lv).String()})
@@ -248,7 +309,7 @@ func (r *LocalVolumeReconciler) syncStorageClass(ctx context.Context, cr *localv
 	for _, storageClassDevice := range storageClassDevices {
 		storageClassName := storageClassDevice.StorageClassName
 		expectedStorageClasses.Insert(storageClassName)
-		storageClass := generateStorageClass(cr, storageClassName)
+		storageClass := generateStorageClass(cr, storageClassDevice)
 		_, _, err := r.apiClient.applyStorageClass(ctx, storageClass)
 		if err != nil {
 			return fmt.Errorf("error creating storageClass %s: %v", storageClassName, err)
@@ -330,7 +391,7 @@ func addOwnerLabels(meta *metav1.ObjectMeta, cr *localv1.LocalVolume) bool {
 	return changed
 }
 
-func generateStorageClass(cr *localv1.LocalVolume, scName string) *storagev1.StorageClass {
+func generateStorageClass(cr *localv1.LocalVolume, storageClassDevice localv1.StorageClassDevice) *storagev1.StorageClass {
 	deleteReclaimPolicy := corev1.PersistentVolumeReclaimDelete
 	firstConsumerBinding := storagev1.VolumeBindingWaitForFirstConsumer
 	sc := &storagev1.StorageClass{
@@ -339,11 +400,12 @@ func generateStorageClass(cr *localv1.LocalVolume, scName string) *storagev1.Sto
 			APIVersion: "storage.k8s.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: scName,
+			Name: storageClassDevice.StorageClassName,
 		},
 		Provisioner:       "kubernetes.io/no-provisioner",
 		ReclaimPolicy:     &deleteReclaimPolicy,
 		VolumeBindingMode: &firstConsumerBinding,
+		MountOptions:      common.GetMountOptions(storageClassDevice.VolumeMode, storageClassDevice.Filesystem),
 	}
 	addOwnerLabels(&sc.ObjectMeta, cr)
 	return sc
@@ -427,5 +489,25 @@ func (r *LocalVolumeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ownerName, Namespace: ownerNamespace}}
 				return []reconcile.Request{req}
 			})).
+		// the conflicts of the LocalVolumes change with the other LocalVolumes and LocalVolumeSets of the namespace
+		Watches(&source.Kind{Type: &localv1.LocalVolume{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceLocalVolumes),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &localv1.LocalVolumeSet{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceLocalVolumes),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
+
+// enqueueNamespaceLocalVolumes enqueues all the LocalVolumes of the namespace of the object
+func (r *LocalVolumeReconciler) enqueueNamespaceLocalVolumes(obj client.Object) []reconcile.Request {
+	lvs := &localv1.LocalVolumeList{}
+	err := r.Client.List(context.TODO(), lvs, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		klog.Errorf("failed to list localvolumes: %v", err)
+		return []reconcile.Request{}
+	}
+	reqs := make([]reconcile.Request, 0, len(lvs.Items))
+	for _, lv := range lvs.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: lv.Name, Namespace: lv.Namespace}})
+	}
+	return reqs
+}
@@ -0,0 +1,88 @@
+package localvolume
+
+import (
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog"
+)
+
+// maxDegradedNodes is the number of nodes detailed in the message of the Degraded condition
+const maxDegradedNodes = 5
+
+// pruneNodeStatuses drops the status of the nodes that were deleted or that the LocalVolume doesn't select anymore,
+// there is no diskmaker left to report them
+func pruneNodeStatuses(lv *localv1.LocalVolume, nodes []corev1.Node) {
+	selected := map[string]bool{}
+	for i := range nodes {
+		matches, err := common.NodeSelectorMatchesNodeLabels(&nodes[i], lv.Spec.NodeSelector)
+		if err != nil {
+			klog.Errorf("failed to match nodeSelector to the labels of node %q: %v", nodes[i].Name, err)
+			// keep the status of the node
+			matches = true
+		}
+		selected[nodes[i].Name] = matches
+	}
+	var kept []localv1.LocalVolumeNodeStatus
+	for _, node := range lv.Status.Nodes {
+		if selected[node.NodeName] {
+			kept = append(kept, node)
+		}
+	}
+	lv.Status.Nodes = kept
+}
+
+// setDegradedCondition sets the Degraded condition of the LocalVolume, with the errors of its spec, or else the nodes
+// where devicePaths are not linked. A LocalVolume admitted with an invalid spec is still reconciled as far as possible.
+func setDegradedCondition(lv *localv1.LocalVolume, specErrs field.ErrorList) {
+	condition := operatorv1.OperatorCondition{
+		Type:               operatorv1.OperatorStatusTypeDegraded,
+		Status:             operatorv1.ConditionFalse,
+		Message:            fmt.Sprintf("all the devicePaths are linked on %d nodes", len(lv.Status.Nodes)),
+		LastTransitionTime: metav1.Now(),
+	}
+	degraded := make([]string, 0)
+	for _, node := range lv.Status.Nodes {
+		devices := make([]string, 0)
+		for _, device := range node.Devices {
+			if device.State != localv1.DevicePathLinked {
+				devices = append(devices, fmt.Sprintf("%s is %s", device.DevicePath, device.State))
+			}
+		}
+		if len(devices) > 0 {
+			degraded = append(degraded, fmt.Sprintf("%s (%s)", node.NodeName, strings.Join(devices, ", ")))
+		}
+	}
+	switch {
+	case len(specErrs) > 0:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Message = fmt.Sprintf("invalid LocalVolume: %v", specErrs.ToAggregate())
+	case len(lv.Status.Nodes) == 0:
+		condition.Status = operatorv1.ConditionUnknown
+		condition.Message = "no diskmaker has reported the devicePaths yet"
+	case len(degraded) > 0:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Message = fmt.Sprintf("devicePaths are not linked on %d of %d nodes: ", len(degraded), len(lv.Status.Nodes))
+		if len(degraded) > maxDegradedNodes {
+			condition.Message += strings.Join(degraded[:maxDegradedNodes], ", ") + fmt.Sprintf(" and %d more", len(degraded)-maxDegradedNodes)
+		} else {
+			condition.Message += strings.Join(degraded, ", ")
+		}
+	}
+	for i, c := range lv.Status.Conditions {
+		if c.Type == operatorv1.OperatorStatusTypeDegraded {
+			if c.Status == condition.Status {
+				condition.LastTransitionTime = c.LastTransitionTime
+			}
+			lv.Status.Conditions[i] = condition
+			return
+		}
+	}
+	lv.Status.Conditions = append(lv.Status.Conditions, condition)
+}
@@ -0,0 +1,124 @@
+package localvolume
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNodeStatus(nodeName string, states ...localv1.DevicePathState) localv1.LocalVolumeNodeStatus {
+	node := localv1.LocalVolumeNodeStatus{NodeName: nodeName}
+	for i, state := range states {
+		node.Devices = append(node.Devices, localv1.DevicePathStatus{
+			StorageClassName: "fast",
+			DevicePath:       []string{"/dev/sdb", "/dev/sdc"}[i],
+			State:            state,
+		})
+	}
+	return node
+}
+
+func getCondition(lv *localv1.LocalVolume, conditionType string) *operatorv1.OperatorCondition {
+	for i := range lv.Status.Conditions {
+		if lv.Status.Conditions[i].Type == conditionType {
+			return &lv.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestPruneNodeStatuses(t *testing.T) {
+	lv := &localv1.LocalVolume{
+		Spec: localv1.LocalVolumeSpec{NodeSelector: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "storage", Operator: corev1.NodeSelectorOpExists}},
+		}}}},
+		Status: localv1.LocalVolumeStatus{Nodes: []localv1.LocalVolumeNodeStatus{
+			newNodeStatus("deleted", localv1.DevicePathLinked),
+			newNodeStatus("selected", localv1.DevicePathLinked),
+			newNodeStatus("unselected", localv1.DevicePathLinked),
+		}},
+	}
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: map[string]string{"storage": ""}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unselected"}},
+	}
+	pruneNodeStatuses(lv, nodes)
+	assert.Equal(t, []localv1.LocalVolumeNodeStatus{newNodeStatus("selected", localv1.DevicePathLinked)}, lv.Status.Nodes)
+}
+
+func TestSetDegradedCondition(t *testing.T) {
+	lv := &localv1.LocalVolume{}
+	setDegradedCondition(lv, nil)
+	assert.Equal(t, operatorv1.ConditionUnknown, getCondition(lv, operatorv1.OperatorStatusTypeDegraded).Status)
+
+	lv.Status.Nodes = []localv1.LocalVolumeNodeStatus{
+		newNodeStatus("node1", localv1.DevicePathLinked, localv1.DevicePathLinked),
+		newNodeStatus("node2", localv1.DevicePathLinked, localv1.DevicePathLinked),
+	}
+	setDegradedCondition(lv, nil)
+	condition := getCondition(lv, operatorv1.OperatorStatusTypeDegraded)
+	assert.Equal(t, operatorv1.ConditionFalse, condition.Status)
+	assert.Equal(t, "all the devicePaths are linked on 2 nodes", condition.Message)
+
+	lv.Status.Nodes[1] = newNodeStatus("node2", localv1.DevicePathLinked, localv1.DevicePathMissing)
+	setDegradedCondition(lv, nil)
+	assert.Len(t, lv.Status.Conditions, 1)
+	condition = getCondition(lv, operatorv1.OperatorStatusTypeDegraded)
+	assert.Equal(t, operatorv1.ConditionTrue, condition.Status)
+	assert.Equal(t, "devicePaths are not linked on 1 of 2 nodes: node2 (/dev/sdc is Missing)", condition.Message)
+
+	// the message lists a few nodes
+	lv.Status.Nodes = nil
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		lv.Status.Nodes = append(lv.Status.Nodes, newNodeStatus(name, localv1.DevicePathRefused))
+	}
+	setDegradedCondition(lv, nil)
+	condition = getCondition(lv, operatorv1.OperatorStatusTypeDegraded)
+	assert.Equal(t, "devicePaths are not linked on 7 of 7 nodes: a (/dev/sdb is Refused), b (/dev/sdb is Refused), "+
+		"c (/dev/sdb is Refused), d (/dev/sdb is Refused), e (/dev/sdb is Refused) and 2 more", condition.Message)
+}
+
+func TestSyncStatus(t *testing.T) {
+	scheme, err := localv1.SchemeBuilder.Build()
+	assert.NoError(t, err)
+	oldLv := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-disks", Namespace: "local-storage"},
+		Status: localv1.LocalVolumeStatus{Nodes: []localv1.LocalVolumeNodeStatus{
+			newNodeStatus("node1", localv1.DevicePathLinked),
+			newNodeStatus("node2", localv1.DevicePathMissing),
+			newNodeStatus("deleted", localv1.DevicePathLinked),
+		}},
+	}
+	// a diskmaker reported its node after the LocalVolume was read
+	current := oldLv.DeepCopy()
+	current.Status.Nodes[1] = newNodeStatus("node2", localv1.DevicePathLinked)
+	current.Status.Nodes = append(current.Status.Nodes, newNodeStatus("node3", localv1.DevicePathLinked))
+	recorder := record.NewFakeRecorder(10)
+	apiClient := &sdkAPIUpdater{recorder: recorder, client: fake.NewFakeClientWithScheme(scheme, current)}
+
+	newLv := oldLv.DeepCopy()
+	pruneNodeStatuses(newLv, []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}, {ObjectMeta: metav1.ObjectMeta{Name: "node2"}}})
+	setDegradedCondition(newLv, nil)
+	err = apiClient.syncStatus(oldLv, newLv)
+	assert.NoError(t, err)
+
+	updated := &localv1.LocalVolume{}
+	err = apiClient.client.Get(context.TODO(), types.NamespacedName{Name: "local-disks", Namespace: "local-storage"}, updated)
+	assert.NoError(t, err)
+	// the pruned node is dropped, the nodes reported concurrently are kept
+	assert.Equal(t, []localv1.LocalVolumeNodeStatus{
+		newNodeStatus("node1", localv1.DevicePathLinked),
+		newNodeStatus("node2", localv1.DevicePathLinked),
+		newNodeStatus("node3", localv1.DevicePathLinked),
+	}, updated.Status.Nodes)
+	assert.NotNil(t, getCondition(updated, operatorv1.OperatorStatusTypeDegraded))
+	assert.Empty(t, recorder.Events)
+}
@@ -19,6 +19,8 @@ package localvolumediscovery
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -33,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	v1helper "k8s.io/component-helpers/scheduling/corev1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -40,7 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/controllers/nodedaemon"
 )
 
@@ -53,6 +56,16 @@ const (
 	DiskMakerDiscovery = "diskmaker-discovery"
 )
 
+// The reasons of the discovery conditions
+const (
+	reasonDaemonSetFailed   = "DaemonSetFailed"
+	reasonNoDaemonScheduled = "NoDaemonScheduled"
+	reasonDaemonsNotReady   = "DaemonsNotReady"
+	reasonDaemonsReady      = "DaemonsReady"
+	reasonRemoved           = "Removed"
+	reasonInvalidSpec       = "InvalidSpec"
+)
+
 // LocalVolumeDiscoveryReconciler reconciles a LocalVolumeDiscovery object
 type LocalVolumeDiscoveryReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
@@ -71,7 +84,7 @@ func (r *LocalVolumeDiscoveryReconciler) Reconcile(ctx context.Context, request
 	reqLogger.Info("Reconciling LocalVolumeDiscovery")
 
 	// Fetch the LocalVolumeDiscovery instance
-	instance := &localv1alpha1.LocalVolumeDiscovery{}
+	instance := &localv1.LocalVolumeDiscovery{}
 	err := r.Client.Get(ctx, request.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -84,15 +97,55 @@ func (r *LocalVolumeDiscoveryReconciler) Reconcile(ctx context.Context, request
 		return ctrl.Result{}, err
 	}
 
+	err = r.updateManagementState(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	switch instance.Spec.ManagementState {
+	case operatorv1.Unmanaged:
+		// the discovery daemonset and the discovery results are left as they are
+		reqLogger.Info("LocalVolumeDiscovery is unmanaged, skipping reconcile")
+		return ctrl.Result{}, nil
+	case operatorv1.Removed:
+		err = r.removeDiscovery(ctx, instance)
+		if err != nil {
+			reqLogger.Error(err, "failed to remove the discovery daemonset and results")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	err = r.propagateRescanRequest(ctx, instance)
+	if err != nil {
+		reqLogger.Error(err, "failed to request a rescan of the devices")
+		return ctrl.Result{}, err
+	}
+
+	// the daemonset is left as it is until the spec is fixed, the daemons would fail on it
+	if err := validateDiscoverySpec(instance.Spec); err != nil {
+		reqLogger.Error(err, "invalid LocalVolumeDiscovery spec")
+		err := r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeDegraded, reasonInvalidSpec, err.Error(),
+			metav1.ConditionTrue, localv1.DiscoveryFailed)
+		return ctrl.Result{}, err
+	}
+
+	config, err := nodedaemon.GetOperatorConfig(ctx, r.Client)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the operator config")
+		return ctrl.Result{}, err
+	}
+
 	diskMakerDSMutateFn := getDiskMakerDiscoveryDSMutateFn(request, instance.Spec.Tolerations,
 		getEnvVars(instance.Name, string(instance.UID)),
+		getDiscoveryArgs(instance.Spec),
 		getOwnerRefs(instance),
-		instance.Spec.NodeSelector)
+		instance.Spec.NodeSelector,
+		config)
 	ds, opResult, err := nodedaemon.CreateOrUpdateDaemonset(ctx, r.Client, diskMakerDSMutateFn)
 	if err != nil {
 		message := fmt.Sprintf("failed to create discovery daemonset. Error %+v", err)
-		err := r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeDegraded, message,
-			operatorv1.ConditionFalse, localv1alpha1.DiscoveryFailed)
+		err := r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeDegraded, reasonDaemonSetFailed, message,
+			metav1.ConditionFalse, localv1.DiscoveryFailed)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -100,6 +153,12 @@ func (r *LocalVolumeDiscoveryReconciler) Reconcile(ctx context.Context, request
 		reqLogger.Info("daemonset changed", "daemonset.Name", ds.GetName(), "op.Result", opResult)
 	}
 
+	err = r.updateDiscoverySummary(ctx, instance)
+	if err != nil {
+		reqLogger.Error(err, "failed to update discovery summary")
+		return ctrl.Result{}, err
+	}
+
 	desiredDaemons, readyDaemons, err := r.getDaemonSetStatus(ctx, instance.Namespace)
 	if err != nil {
 		reqLogger.Error(err, "failed to get discovery daemonset")
@@ -108,8 +167,8 @@ func (r *LocalVolumeDiscoveryReconciler) Reconcile(ctx context.Context, request
 
 	if desiredDaemons == 0 {
 		message := "no discovery daemons are scheduled for running"
-		err := r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeDegraded, message,
-			operatorv1.ConditionFalse, localv1alpha1.DiscoveryFailed)
+		err := r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeDegraded, reasonNoDaemonScheduled, message,
+			metav1.ConditionFalse, localv1.DiscoveryFailed)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -117,8 +176,8 @@ func (r *LocalVolumeDiscoveryReconciler) Reconcile(ctx context.Context, request
 
 	} else if !(desiredDaemons == readyDaemons) {
 		message := fmt.Sprintf("running %d out of %d discovery daemons", readyDaemons, desiredDaemons)
-		err := r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeProgressing, message,
-			operatorv1.ConditionFalse, localv1alpha1.Discovering)
+		err := r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeProgressing, reasonDaemonsNotReady, message,
+			metav1.ConditionFalse, localv1.Discovering)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -126,8 +185,8 @@ func (r *LocalVolumeDiscoveryReconciler) Reconcile(ctx context.Context, request
 	}
 
 	message := fmt.Sprintf("successfully running %d out of %d discovery daemons", desiredDaemons, readyDaemons)
-	err = r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeAvailable, message,
-		operatorv1.ConditionTrue, localv1alpha1.Discovering)
+	err = r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeAvailable, reasonDaemonsReady, message,
+		metav1.ConditionTrue, localv1.Discovering)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -138,14 +197,18 @@ func (r *LocalVolumeDiscoveryReconciler) Reconcile(ctx context.Context, request
 		reqLogger.Error(err, "failed to delete orphan discovery results")
 		return ctrl.Result{}, err
 	}
-	return ctrl.Result{}, nil
+
+	// requeue to notice discovery results that go stale without any update
+	return ctrl.Result{RequeueAfter: getStaleResultThreshold(instance.Spec)}, nil
 }
 
 func getDiskMakerDiscoveryDSMutateFn(request reconcile.Request,
 	tolerations []corev1.Toleration,
 	envVars []corev1.EnvVar,
+	args []string,
 	ownerRefs []metav1.OwnerReference,
-	nodeSelector *corev1.NodeSelector) func(*appsv1.DaemonSet) error {
+	nodeSelector *corev1.NodeSelector,
+	config *localv1.LocalStorageOperatorConfig) func(*appsv1.DaemonSet) error {
 	maxUnavailable := intstr.FromString("10%")
 
 	return func(ds *appsv1.DaemonSet) error {
@@ -173,27 +236,31 @@ func getDiskMakerDiscoveryDSMutateFn(request reconcile.Request,
 		ds.Spec.Template.Spec.Containers[0].Env = append(ds.Spec.Template.Spec.Containers[0].Env, envVars...)
 		ds.Spec.Template.Spec.Containers[0].Image = common.GetDiskMakerImage()
 		ds.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
-		ds.Spec.Template.Spec.Containers[0].Args = []string{"discover"}
+		ds.Spec.Template.Spec.Containers[0].Args = append([]string{"discover"}, args...)
 		ds.Spec.Template.Spec.HostPID = true
 
+		nodedaemon.MutateOperatorConfig(ds, config, config.Spec.Discovery)
+
 		return nil
 	}
 }
 
 // updateDiscoveryStatus updates the discovery state with conditions and phase
-func (r *LocalVolumeDiscoveryReconciler) updateDiscoveryStatus(ctx context.Context, instance *localv1alpha1.LocalVolumeDiscovery,
-	conditionType, message string,
-	status operatorv1.ConditionStatus,
-	phase localv1alpha1.DiscoveryPhase) error {
+func (r *LocalVolumeDiscoveryReconciler) updateDiscoveryStatus(ctx context.Context, instance *localv1.LocalVolumeDiscovery,
+	conditionType, reason, message string,
+	status metav1.ConditionStatus,
+	phase localv1.DiscoveryPhase) error {
 	// avoid frequently updating the same status in the CR
 	if len(instance.Status.Conditions) < 1 || instance.Status.Conditions[0].Message != message {
-		condition := operatorv1.OperatorCondition{
+		condition := metav1.Condition{
 			Type:               conditionType,
 			Status:             status,
+			ObservedGeneration: instance.Generation,
+			Reason:             reason,
 			Message:            message,
 			LastTransitionTime: metav1.Now(),
 		}
-		newConditions := []operatorv1.OperatorCondition{condition}
+		newConditions := []metav1.Condition{condition}
 		instance.Status.Conditions = newConditions
 		instance.Status.Phase = phase
 		instance.Status.ObservedGeneration = instance.Generation
@@ -206,13 +273,54 @@ func (r *LocalVolumeDiscoveryReconciler) updateDiscoveryStatus(ctx context.Conte
 	return nil
 }
 
-func (r *LocalVolumeDiscoveryReconciler) deleteOrphanDiscoveryResults(ctx context.Context, instance *localv1alpha1.LocalVolumeDiscovery) error {
+// updateManagementState reports the managementState of the LocalVolumeDiscovery in its status
+func (r *LocalVolumeDiscoveryReconciler) updateManagementState(ctx context.Context, instance *localv1.LocalVolumeDiscovery) error {
+	state := instance.Spec.ManagementState
+	if state == "" {
+		state = operatorv1.Managed
+	}
+	if instance.Status.State == state {
+		return nil
+	}
+	instance.Status.State = state
+	return r.updateStatus(ctx, instance)
+}
+
+// removeDiscovery deletes the discovery daemonset, then the LocalVolumeDiscoveryResults it can't update anymore
+func (r *LocalVolumeDiscoveryReconciler) removeDiscovery(ctx context.Context, instance *localv1.LocalVolumeDiscovery) error {
+	ds := &appsv1.DaemonSet{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: DiskMakerDiscovery, Namespace: instance.Namespace}, ds)
+	if err == nil {
+		err = r.Client.Delete(ctx, ds)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the discovery daemonset: %w", err)
+	}
+
+	discoveryResultList := &localv1.LocalVolumeDiscoveryResultList{}
+	err = r.Client.List(ctx, discoveryResultList, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list LocalVolumeDiscoveryResult instances in namespace %q: %w", instance.Namespace, err)
+	}
+	for _, discoveryResult := range discoveryResultList.Items {
+		err = r.Client.Delete(ctx, discoveryResult.DeepCopy())
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete discovery result %q: %w", discoveryResult.Name, err)
+		}
+	}
+
+	instance.Status.Summary = nil
+	return r.updateDiscoveryStatus(ctx, instance, operatorv1.OperatorStatusTypeAvailable, reasonRemoved,
+		"the discovery daemonset and the discovery results are removed", metav1.ConditionFalse, instance.Status.Phase)
+}
+
+func (r *LocalVolumeDiscoveryReconciler) deleteOrphanDiscoveryResults(ctx context.Context, instance *localv1.LocalVolumeDiscovery) error {
 	if instance.Spec.NodeSelector == nil || len(instance.Spec.NodeSelector.NodeSelectorTerms) == 0 {
 		r.ReqLogger.Info("skip deleting orphan discovery results as no NodeSelectors are provided")
 		return nil
 	}
 
-	discoveryResultList := &localv1alpha1.LocalVolumeDiscoveryResultList{}
+	discoveryResultList := &localv1.LocalVolumeDiscoveryResultList{}
 	err := r.Client.List(ctx, discoveryResultList, client.InNamespace(instance.Namespace))
 	if err != nil {
 		return fmt.Errorf("failed to list LocalVolumeDiscoveryResult instances in namespace %q", instance.Namespace)
@@ -241,7 +349,7 @@ func (r *LocalVolumeDiscoveryReconciler) deleteOrphanDiscoveryResults(ctx contex
 	return nil
 }
 
-func (r *LocalVolumeDiscoveryReconciler) updateStatus(ctx context.Context, lvd *localv1alpha1.LocalVolumeDiscovery) error {
+func (r *LocalVolumeDiscoveryReconciler) updateStatus(ctx context.Context, lvd *localv1.LocalVolumeDiscovery) error {
 	err := r.Client.Status().Update(ctx, lvd)
 	if err != nil {
 		return err
@@ -274,11 +382,11 @@ func getVolumesAndMounts() ([]corev1.Volume, []corev1.VolumeMount) {
 	return volumes, volumeMounts
 }
 
-func getOwnerRefs(cr *localv1alpha1.LocalVolumeDiscovery) []metav1.OwnerReference {
+func getOwnerRefs(cr *localv1.LocalVolumeDiscovery) []metav1.OwnerReference {
 	trueVal := true
 	return []metav1.OwnerReference{
 		{
-			APIVersion: localv1alpha1.GroupVersion.String(),
+			APIVersion: localv1.GroupVersion.String(),
 			Kind:       "LocalVolumeDiscovery",
 			Name:       cr.Name,
 			UID:        cr.UID,
@@ -300,10 +408,104 @@ func getEnvVars(objName, uid string) []corev1.EnvVar {
 	}
 }
 
+// validateDiscoverySpec returns an error if the discovery daemons can not run with the cadence and filters of spec
+func validateDiscoverySpec(spec localv1.LocalVolumeDiscoverySpec) error {
+	errs := []string{}
+	if spec.ProbeInterval != nil && spec.ProbeInterval.Duration <= 0 {
+		errs = append(errs, fmt.Sprintf("probeInterval must be greater than 0, got %s", spec.ProbeInterval.Duration))
+	}
+	if spec.UdevEventPeriod != nil && spec.UdevEventPeriod.Duration <= 0 {
+		errs = append(errs, fmt.Sprintf("udevEventPeriod must be greater than 0, got %s", spec.UdevEventPeriod.Duration))
+	}
+	for i, filter := range spec.UdevExclusionFilter {
+		if _, err := regexp.Compile(filter); err != nil {
+			errs = append(errs, fmt.Sprintf("udevExclusionFilter[%d] %q is not a valid regular expression: %v", i, filter, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid spec: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// getDiscoveryArgs returns the discovery daemon arguments for the cadence and filters overridden in spec
+func getDiscoveryArgs(spec localv1.LocalVolumeDiscoverySpec) []string {
+	args := []string{}
+	if spec.ProbeInterval != nil {
+		args = append(args, fmt.Sprintf("--probe-interval=%s", spec.ProbeInterval.Duration))
+	}
+	if spec.UdevEventPeriod != nil {
+		args = append(args, fmt.Sprintf("--udev-event-period=%s", spec.UdevEventPeriod.Duration))
+	}
+	for _, filter := range spec.UdevExclusionFilter {
+		args = append(args, fmt.Sprintf("--udev-exclusion-filter=%s", filter))
+	}
+	if len(spec.SupportedDeviceTypes) > 0 {
+		deviceTypes := make([]string, 0, len(spec.SupportedDeviceTypes))
+		for _, deviceType := range spec.SupportedDeviceTypes {
+			deviceTypes = append(deviceTypes, string(deviceType))
+		}
+		args = append(args, fmt.Sprintf("--supported-device-types=%s", strings.Join(deviceTypes, ",")))
+	}
+	return args
+}
+
+// propagateRescanRequest moves the rescan annotation of the LocalVolumeDiscovery to the
+// LocalVolumeDiscoveryResults of the requested nodes, where the discovery daemons pick it up
+func (r *LocalVolumeDiscoveryReconciler) propagateRescanRequest(ctx context.Context, instance *localv1.LocalVolumeDiscovery) error {
+	nodeName, found := instance.Annotations[common.DiscoveryRescanAnnotation]
+	if !found {
+		return nil
+	}
+
+	discoveryResultList := &localv1.LocalVolumeDiscoveryResultList{}
+	err := r.Client.List(ctx, discoveryResultList, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list LocalVolumeDiscoveryResult instances in namespace %q: %w", instance.Namespace, err)
+	}
+
+	requestTime := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, discoveryResult := range discoveryResultList.Items {
+		if nodeName != "" && discoveryResult.Spec.NodeName != nodeName {
+			continue
+		}
+		result := discoveryResult.DeepCopy()
+		if result.Annotations == nil {
+			result.Annotations = map[string]string{}
+		}
+		result.Annotations[common.DiscoveryRescanRequestedAnnotation] = requestTime
+		err = r.Client.Update(ctx, result)
+		if err != nil {
+			return fmt.Errorf("failed to request a rescan on node %q: %w", result.Spec.NodeName, err)
+		}
+		r.ReqLogger.Info("requested a rescan of the devices", "nodeName", result.Spec.NodeName)
+	}
+
+	delete(instance.Annotations, common.DiscoveryRescanAnnotation)
+	return r.Client.Update(ctx, instance)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LocalVolumeDiscoveryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&localv1alpha1.LocalVolumeDiscovery{}).
-		Watches(&source.Kind{Type: &appsv1.DaemonSet{}}, &handler.EnqueueRequestForOwner{OwnerType: &localv1alpha1.LocalVolumeDiscovery{}}).
+		For(&localv1.LocalVolumeDiscovery{}).
+		Watches(&source.Kind{Type: &appsv1.DaemonSet{}}, &handler.EnqueueRequestForOwner{OwnerType: &localv1.LocalVolumeDiscovery{}}).
+		Watches(&source.Kind{Type: &localv1.LocalVolumeDiscoveryResult{}}, &handler.EnqueueRequestForOwner{OwnerType: &localv1.LocalVolumeDiscovery{}}).
+		Watches(&source.Kind{Type: &localv1.LocalStorageOperatorConfig{}}, handler.EnqueueRequestsFromMapFunc(r.getDiscoveryRequests), builder.WithPredicates(nodedaemon.OnlyOperatorConfig)).
 		Complete(r)
 }
+
+// getDiscoveryRequests returns a request for each LocalVolumeDiscovery, their daemonsets are configured by the LocalStorageOperatorConfig
+func (r *LocalVolumeDiscoveryReconciler) getDiscoveryRequests(obj client.Object) []reconcile.Request {
+	discoveries := &localv1.LocalVolumeDiscoveryList{}
+	err := r.Client.List(context.TODO(), discoveries)
+	if err != nil {
+		log.Error(err, "failed to list LocalVolumeDiscovery instances")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(discoveries.Items))
+	for _, discovery := range discoveries.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: discovery.Name, Namespace: discovery.Namespace}})
+	}
+	return requests
+}
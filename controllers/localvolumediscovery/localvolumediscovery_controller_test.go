@@ -3,12 +3,16 @@ package localvolumediscovery
 import (
 	"context"
 	"testing"
+	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/common"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -66,7 +70,7 @@ var mockNodeList = &corev1.NodeList{
 	},
 }
 
-var localVolumeDiscoveryCR = localv1alpha1.LocalVolumeDiscovery{
+var localVolumeDiscoveryCR = localv1.LocalVolumeDiscovery{
 	ObjectMeta: metav1.ObjectMeta{
 		Name:      name,
 		Namespace: namespace,
@@ -74,7 +78,7 @@ var localVolumeDiscoveryCR = localv1alpha1.LocalVolumeDiscovery{
 	TypeMeta: metav1.TypeMeta{
 		Kind: "LocalVolumeDiscovery",
 	},
-	Spec: localv1alpha1.LocalVolumeDiscoverySpec{
+	Spec: localv1.LocalVolumeDiscoverySpec{
 		NodeSelector: &corev1.NodeSelector{
 			NodeSelectorTerms: []corev1.NodeSelectorTerm{
 				{MatchExpressions: []corev1.NodeSelectorRequirement{
@@ -89,17 +93,17 @@ var localVolumeDiscoveryCR = localv1alpha1.LocalVolumeDiscovery{
 	},
 }
 
-var localVolumeDiscoveryResultList = localv1alpha1.LocalVolumeDiscoveryResultList{
+var localVolumeDiscoveryResultList = localv1.LocalVolumeDiscoveryResultList{
 	TypeMeta: metav1.TypeMeta{
 		Kind: "LocalVolumeDiscoveryResultList",
 	},
-	Items: []localv1alpha1.LocalVolumeDiscoveryResult{
+	Items: []localv1.LocalVolumeDiscoveryResult{
 		{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "discovery-result-node1",
 				Namespace: namespace,
 			},
-			Spec: localv1alpha1.LocalVolumeDiscoveryResultSpec{
+			Spec: localv1.LocalVolumeDiscoveryResultSpec{
 				NodeName: "Node1",
 			},
 		},
@@ -108,7 +112,7 @@ var localVolumeDiscoveryResultList = localv1alpha1.LocalVolumeDiscoveryResultLis
 				Name:      "discovery-result-node2",
 				Namespace: namespace,
 			},
-			Spec: localv1alpha1.LocalVolumeDiscoveryResultSpec{
+			Spec: localv1.LocalVolumeDiscoveryResultSpec{
 				NodeName: "Node2",
 			},
 		},
@@ -116,7 +120,7 @@ var localVolumeDiscoveryResultList = localv1alpha1.LocalVolumeDiscoveryResultLis
 }
 
 func newFakeLocalVolumeDiscoveryReconciler(t *testing.T, objs ...runtime.Object) *LocalVolumeDiscoveryReconciler {
-	scheme, err := localv1alpha1.SchemeBuilder.Build()
+	scheme, err := localv1.SchemeBuilder.Build()
 	assert.NoErrorf(t, err, "creating scheme")
 
 	err = corev1.AddToScheme(scheme)
@@ -125,6 +129,9 @@ func newFakeLocalVolumeDiscoveryReconciler(t *testing.T, objs ...runtime.Object)
 	err = appsv1.AddToScheme(scheme)
 	assert.NoErrorf(t, err, "adding appsv1 to scheme")
 
+	err = localv1alpha1.AddToScheme(scheme)
+	assert.NoErrorf(t, err, "adding localv1alpha1 to scheme")
+
 	client := fake.NewFakeClientWithScheme(scheme, objs...)
 
 	return &LocalVolumeDiscoveryReconciler{
@@ -141,45 +148,45 @@ func TestDiscoveryReconciler(t *testing.T) {
 		discoveryDaemonCreated       bool
 		discoveryDesiredDaemonsCount int32
 		discoveryReadyDaemonsCount   int32
-		expectedPhase                localv1alpha1.DiscoveryPhase
+		expectedPhase                localv1.DiscoveryPhase
 		conditionType                string
-		conditionStatus              operatorv1.ConditionStatus
+		conditionStatus              metav1.ConditionStatus
 	}{
 		{
 			label:                        "case 1", // all the desired discovery daemonset pods are running
 			discoveryDaemonCreated:       true,
 			discoveryDesiredDaemonsCount: 1,
 			discoveryReadyDaemonsCount:   1,
-			expectedPhase:                localv1alpha1.Discovering,
+			expectedPhase:                localv1.Discovering,
 			conditionType:                "Available",
-			conditionStatus:              operatorv1.ConditionTrue,
+			conditionStatus:              metav1.ConditionTrue,
 		},
 		{
 			label:                        "case 2", // all the desired discovery daemonset pods are running
 			discoveryDaemonCreated:       true,
 			discoveryDesiredDaemonsCount: 100,
 			discoveryReadyDaemonsCount:   100,
-			expectedPhase:                localv1alpha1.Discovering,
+			expectedPhase:                localv1.Discovering,
 			conditionType:                "Available",
-			conditionStatus:              operatorv1.ConditionTrue,
+			conditionStatus:              metav1.ConditionTrue,
 		},
 		{
 			label:                        "case 3", // ready discovery daemonset pods are less than the desired count
 			discoveryDaemonCreated:       true,
 			discoveryDesiredDaemonsCount: 100,
 			discoveryReadyDaemonsCount:   80,
-			expectedPhase:                localv1alpha1.Discovering,
+			expectedPhase:                localv1.Discovering,
 			conditionType:                "Progressing",
-			conditionStatus:              operatorv1.ConditionFalse,
+			conditionStatus:              metav1.ConditionFalse,
 		},
 		{
 			label:                        "case 4", // no discovery daemonset pods are running
 			discoveryDaemonCreated:       true,
 			discoveryDesiredDaemonsCount: 0,
 			discoveryReadyDaemonsCount:   0,
-			expectedPhase:                localv1alpha1.DiscoveryFailed,
+			expectedPhase:                localv1.DiscoveryFailed,
 			conditionType:                "Degraded",
-			conditionStatus:              operatorv1.ConditionFalse,
+			conditionStatus:              metav1.ConditionFalse,
 		},
 
 		{
@@ -187,9 +194,9 @@ func TestDiscoveryReconciler(t *testing.T) {
 			discoveryDaemonCreated:       false,
 			discoveryDesiredDaemonsCount: 0,
 			discoveryReadyDaemonsCount:   0,
-			expectedPhase:                localv1alpha1.DiscoveryFailed,
+			expectedPhase:                localv1.DiscoveryFailed,
 			conditionType:                "Degraded",
-			conditionStatus:              operatorv1.ConditionFalse,
+			conditionStatus:              metav1.ConditionFalse,
 		},
 	}
 
@@ -199,7 +206,7 @@ func TestDiscoveryReconciler(t *testing.T) {
 		discoveryDS.Status.NumberReady = tc.discoveryReadyDaemonsCount
 		discoveryDS.Status.DesiredNumberScheduled = tc.discoveryDesiredDaemonsCount
 
-		discoveryObj := &localv1alpha1.LocalVolumeDiscovery{
+		discoveryObj := &localv1.LocalVolumeDiscovery{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      name,
 				Namespace: namespace,
@@ -238,10 +245,10 @@ func TestDeleteOrphanDiscoveryResults(t *testing.T) {
 	discoveryDS := &appsv1.DaemonSet{}
 	discoveryDaemonSet.DeepCopyInto(discoveryDS)
 
-	discoveryObj := &localv1alpha1.LocalVolumeDiscovery{}
+	discoveryObj := &localv1.LocalVolumeDiscovery{}
 	localVolumeDiscoveryCR.DeepCopyInto(discoveryObj)
 
-	discoveryResults := &localv1alpha1.LocalVolumeDiscoveryResultList{}
+	discoveryResults := &localv1.LocalVolumeDiscoveryResultList{}
 	localVolumeDiscoveryResultList.DeepCopyInto(discoveryResults)
 
 	objects := []runtime.Object{
@@ -258,7 +265,7 @@ func TestDeleteOrphanDiscoveryResults(t *testing.T) {
 
 	_, err := fakeReconciler.Reconcile(context.TODO(), req)
 	assert.NoError(t, err)
-	results := &localv1alpha1.LocalVolumeDiscoveryResultList{}
+	results := &localv1.LocalVolumeDiscoveryResultList{}
 	fakeReconciler.Client.List(context.TODO(), results, client.InNamespace(namespace))
 	assert.Equal(t, 2, len(results.Items))
 
@@ -268,15 +275,194 @@ func TestDeleteOrphanDiscoveryResults(t *testing.T) {
 	err = fakeReconciler.deleteOrphanDiscoveryResults(context.TODO(), discoveryObj)
 	assert.NoError(t, err)
 	// assert that discovery result object on "Node2" is deleted
-	results = &localv1alpha1.LocalVolumeDiscoveryResultList{}
+	results = &localv1.LocalVolumeDiscoveryResultList{}
 	fakeReconciler.Client.List(context.TODO(), results, client.InNamespace(namespace))
 	assert.Equal(t, 1, len(results.Items))
 	assert.Equal(t, "Node1", results.Items[0].Spec.NodeName)
 
 	// skip deletion of orphan results when no NodeSelector is provided
-	discoveryObj.Spec = localv1alpha1.LocalVolumeDiscoverySpec{}
+	discoveryObj.Spec = localv1.LocalVolumeDiscoverySpec{}
 	err = fakeReconciler.deleteOrphanDiscoveryResults(context.TODO(), discoveryObj)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(results.Items))
 	assert.Equal(t, "Node1", results.Items[0].Spec.NodeName)
 }
+
+func TestGetDiscoveryArgs(t *testing.T) {
+	assert.Empty(t, getDiscoveryArgs(localv1.LocalVolumeDiscoverySpec{}))
+
+	spec := localv1.LocalVolumeDiscoverySpec{
+		ProbeInterval:        &metav1.Duration{Duration: 30 * time.Minute},
+		UdevEventPeriod:      &metav1.Duration{Duration: time.Second},
+		UdevExclusionFilter:  []string{"(?i)dm-[0-9]+", "(?i)sd[a-z]{1,2}"},
+		SupportedDeviceTypes: []localv1.DiscoveredDeviceType{localv1.DiskType, localv1.PartType},
+	}
+	expected := []string{
+		"--probe-interval=30m0s",
+		"--udev-event-period=1s",
+		"--udev-exclusion-filter=(?i)dm-[0-9]+",
+		"--udev-exclusion-filter=(?i)sd[a-z]{1,2}",
+		"--supported-device-types=disk,part",
+	}
+	assert.Equal(t, expected, getDiscoveryArgs(spec))
+}
+
+func TestInvalidDiscoverySpec(t *testing.T) {
+	assert.NoError(t, validateDiscoverySpec(localv1.LocalVolumeDiscoverySpec{}))
+
+	discoveryObj := &localv1.LocalVolumeDiscovery{}
+	localVolumeDiscoveryCR.DeepCopyInto(discoveryObj)
+	discoveryObj.Spec.ProbeInterval = &metav1.Duration{}
+	discoveryObj.Spec.UdevExclusionFilter = []string{"(?i)dm-[0-9]+", "sd[a-z"}
+	discoveryDS := &appsv1.DaemonSet{}
+	discoveryDaemonSet.DeepCopyInto(discoveryDS)
+
+	fakeReconciler := newFakeLocalVolumeDiscoveryReconciler(t, discoveryObj, discoveryDS)
+	key := types.NamespacedName{Name: discoveryObj.Name, Namespace: discoveryObj.Namespace}
+	_, err := fakeReconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	ds := &appsv1.DaemonSet{}
+	err = fakeReconciler.Client.Get(context.TODO(), types.NamespacedName{Name: DiskMakerDiscovery, Namespace: namespace}, ds)
+	assert.NoError(t, err)
+	assert.Empty(t, ds.Spec.Template.Spec.Containers, "the daemonset is not updated with an invalid spec")
+
+	discoveryObj = &localv1.LocalVolumeDiscovery{}
+	err = fakeReconciler.Client.Get(context.TODO(), key, discoveryObj)
+	assert.NoError(t, err)
+	assert.Equal(t, localv1.DiscoveryFailed, discoveryObj.Status.Phase)
+	assert.Len(t, discoveryObj.Status.Conditions, 1)
+	condition := discoveryObj.Status.Conditions[0]
+	assert.Equal(t, operatorv1.OperatorStatusTypeDegraded, condition.Type)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonInvalidSpec, condition.Reason)
+	assert.Contains(t, condition.Message, "probeInterval must be greater than 0")
+	assert.Contains(t, condition.Message, `udevExclusionFilter[1] "sd[a-z"`)
+}
+
+func TestPropagateRescanRequest(t *testing.T) {
+	testcases := []struct {
+		label            string
+		rescanAnnotation string
+		expectedNodes    []string
+	}{
+		{
+			label:            "case 1: rescan all the nodes",
+			rescanAnnotation: "",
+			expectedNodes:    []string{"Node1", "Node2"},
+		},
+		{
+			label:            "case 2: rescan a single node",
+			rescanAnnotation: "Node2",
+			expectedNodes:    []string{"Node2"},
+		},
+	}
+
+	for _, tc := range testcases {
+		discoveryObj := &localv1.LocalVolumeDiscovery{}
+		localVolumeDiscoveryCR.DeepCopyInto(discoveryObj)
+		discoveryObj.Annotations = map[string]string{common.DiscoveryRescanAnnotation: tc.rescanAnnotation}
+		discoveryResults := &localv1.LocalVolumeDiscoveryResultList{}
+		localVolumeDiscoveryResultList.DeepCopyInto(discoveryResults)
+
+		fakeReconciler := newFakeLocalVolumeDiscoveryReconciler(t, discoveryObj, discoveryResults)
+		err := fakeReconciler.propagateRescanRequest(context.TODO(), discoveryObj)
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+
+		results := &localv1.LocalVolumeDiscoveryResultList{}
+		err = fakeReconciler.Client.List(context.TODO(), results, client.InNamespace(namespace))
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+		requestedNodes := []string{}
+		for _, result := range results.Items {
+			if _, found := result.Annotations[common.DiscoveryRescanRequestedAnnotation]; found {
+				requestedNodes = append(requestedNodes, result.Spec.NodeName)
+			}
+		}
+		assert.ElementsMatchf(t, tc.expectedNodes, requestedNodes, "[%s] invalid rescan requests", tc.label)
+
+		// the rescan annotation is consumed
+		err = fakeReconciler.Client.Get(context.TODO(), types.NamespacedName{Name: discoveryObj.Name, Namespace: discoveryObj.Namespace}, discoveryObj)
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+		assert.NotContainsf(t, discoveryObj.Annotations, common.DiscoveryRescanAnnotation, "[%s]", tc.label)
+	}
+}
+
+func TestDiscoveryManagementState(t *testing.T) {
+	testcases := []struct {
+		label           string
+		managementState operatorv1.ManagementState
+		expectedObjects int
+	}{
+		{
+			label:           "case 1: the daemonset and the results are frozen",
+			managementState: operatorv1.Unmanaged,
+			expectedObjects: 2,
+		},
+		{
+			label:           "case 2: the daemonset and the results are deleted",
+			managementState: operatorv1.Removed,
+			expectedObjects: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		discoveryObj := &localv1.LocalVolumeDiscovery{}
+		localVolumeDiscoveryCR.DeepCopyInto(discoveryObj)
+		discoveryObj.Spec.ManagementState = tc.managementState
+		discoveryDS := &appsv1.DaemonSet{}
+		discoveryDaemonSet.DeepCopyInto(discoveryDS)
+		discoveryResults := &localv1.LocalVolumeDiscoveryResultList{}
+		localVolumeDiscoveryResultList.DeepCopyInto(discoveryResults)
+
+		fakeReconciler := newFakeLocalVolumeDiscoveryReconciler(t, discoveryObj, discoveryDS, discoveryResults)
+		key := types.NamespacedName{Name: discoveryObj.Name, Namespace: discoveryObj.Namespace}
+		_, err := fakeReconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+
+		ds := &appsv1.DaemonSet{}
+		err = fakeReconciler.Client.Get(context.TODO(), types.NamespacedName{Name: DiskMakerDiscovery, Namespace: namespace}, ds)
+		if tc.expectedObjects > 0 {
+			assert.NoErrorf(t, err, "[%s]", tc.label)
+			assert.Emptyf(t, ds.Spec.Template.Spec.Containers, "[%s] the daemonset is not updated", tc.label)
+		} else {
+			assert.Truef(t, errors.IsNotFound(err), "[%s] the daemonset is not deleted", tc.label)
+		}
+		results := &localv1.LocalVolumeDiscoveryResultList{}
+		err = fakeReconciler.Client.List(context.TODO(), results, client.InNamespace(namespace))
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+		assert.Lenf(t, results.Items, tc.expectedObjects, "[%s]", tc.label)
+
+		discoveryObj = &localv1.LocalVolumeDiscovery{}
+		err = fakeReconciler.Client.Get(context.TODO(), key, discoveryObj)
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+		assert.Equalf(t, tc.managementState, discoveryObj.Status.State, "[%s]", tc.label)
+	}
+}
+
+func TestDiscoveryOperatorConfig(t *testing.T) {
+	discoveryObj := &localv1.LocalVolumeDiscovery{}
+	localVolumeDiscoveryCR.DeepCopyInto(discoveryObj)
+	config := &localv1.LocalStorageOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: localv1.LocalStorageOperatorConfigName},
+		Spec: localv1.LocalStorageOperatorConfigSpec{
+			DiskMaker:    localv1.NodeDaemonConfig{Image: "registry.example.com/diskmaker:manager"},
+			Discovery:    localv1.NodeDaemonConfig{Image: "registry.example.com/diskmaker:discovery"},
+			LogVerbosity: 4,
+			SymlinkDir:   "/var/local-storage",
+		},
+	}
+
+	fakeReconciler := newFakeLocalVolumeDiscoveryReconciler(t, discoveryObj, config)
+	key := types.NamespacedName{Name: discoveryObj.Name, Namespace: discoveryObj.Namespace}
+	_, err := fakeReconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.Error(t, err, "the daemonset has no status yet")
+
+	ds := &appsv1.DaemonSet{}
+	err = fakeReconciler.Client.Get(context.TODO(), types.NamespacedName{Name: DiskMakerDiscovery, Namespace: namespace}, ds)
+	assert.NoError(t, err)
+	container := ds.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "registry.example.com/diskmaker:discovery", container.Image)
+	assert.Equal(t, "discover", container.Args[0])
+	assert.Contains(t, container.Args, "--v=4")
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: common.LocalDiskLocationEnv, Value: "/var/local-storage"})
+}
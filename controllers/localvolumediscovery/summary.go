@@ -0,0 +1,192 @@
+package localvolumediscovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultProbeInterval is the probe interval of the discovery daemons, when not set in the spec
+	defaultProbeInterval = 5 * time.Minute
+	// staleResultProbeCount is the number of missed probes after which a LocalVolumeDiscoveryResult is
+	// reported as stale. The daemons refresh their result on every probe.
+	staleResultProbeCount = 3
+)
+
+// sizeBuckets are the upper bounds used to group devices by size, in ascending order
+var sizeBuckets = []int64{
+	64 * common.GiB,
+	128 * common.GiB,
+	256 * common.GiB,
+	512 * common.GiB,
+	common.TiB,
+	2 * common.TiB,
+	4 * common.TiB,
+	8 * common.TiB,
+	16 * common.TiB,
+}
+
+// deviceGroupKey identifies a group of devices with the same properties
+type deviceGroupKey struct {
+	deviceType localv1.DiscoveredDeviceType
+	property   localv1.DeviceMechanicalProperty
+	model      string
+	sizeBucket string
+}
+
+// updateDiscoverySummary aggregates the discovery results of all the nodes into the status of the LocalVolumeDiscovery
+func (r *LocalVolumeDiscoveryReconciler) updateDiscoverySummary(ctx context.Context, instance *localv1.LocalVolumeDiscovery) error {
+	discoveryResultList := &localv1.LocalVolumeDiscoveryResultList{}
+	err := r.Client.List(ctx, discoveryResultList, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list LocalVolumeDiscoveryResult instances in namespace %q: %w", instance.Namespace, err)
+	}
+
+	summary := getDiscoverySummary(discoveryResultList.Items, time.Now(), getStaleResultThreshold(instance.Spec))
+	if equality.Semantic.DeepEqual(instance.Status.Summary, summary) {
+		return nil
+	}
+	instance.Status.Summary = summary
+	return r.updateStatus(ctx, instance)
+}
+
+// getStaleResultThreshold returns the age after which a LocalVolumeDiscoveryResult that was not refreshed is stale
+func getStaleResultThreshold(spec localv1.LocalVolumeDiscoverySpec) time.Duration {
+	probeInterval := defaultProbeInterval
+	if spec.ProbeInterval != nil && spec.ProbeInterval.Duration > 0 {
+		probeInterval = spec.ProbeInterval.Duration
+	}
+	return staleResultProbeCount * probeInterval
+}
+
+// getDiscoverySummary returns the summary of the Available devices in results.
+// Results whose DiscoveredTimeStamp is older than staleAfter are reported as stale.
+// The timestamps themselves are left out, the results are refreshed on every probe and the summary
+// only changes with the devices and the staleness of the nodes.
+func getDiscoverySummary(results []localv1.LocalVolumeDiscoveryResult, now time.Time, staleAfter time.Duration) *localv1.DiscoverySummary {
+	summary := &localv1.DiscoverySummary{
+		AvailableCapacity: *resource.NewQuantity(0, resource.BinarySI),
+	}
+	clusterGroups := map[deviceGroupKey]*localv1.DeviceGroupSummary{}
+
+	for _, result := range results {
+		nodeSummary := localv1.NodeDiscoverySummary{
+			NodeName:          result.Spec.NodeName,
+			AvailableCapacity: *resource.NewQuantity(0, resource.BinarySI),
+		}
+
+		discoveredTime, err := time.Parse(time.RFC3339, result.Status.DiscoveredTimeStamp)
+		if err != nil || now.Sub(discoveredTime) > staleAfter {
+			nodeSummary.Stale = true
+			summary.StaleNodes++
+			if err == nil && (summary.OldestStaleResultTimeStamp == nil || discoveredTime.Before(summary.OldestStaleResultTimeStamp.Time)) {
+				oldest := metav1.NewTime(discoveredTime)
+				summary.OldestStaleResultTimeStamp = &oldest
+			}
+		}
+
+		nodeGroups := map[deviceGroupKey]*localv1.DeviceGroupSummary{}
+		for _, device := range result.Status.DiscoveredDevices {
+			if device.Status.State != localv1.Available {
+				continue
+			}
+			key := deviceGroupKey{
+				deviceType: device.Type,
+				property:   device.Property,
+				model:      device.Model,
+				sizeBucket: getSizeBucket(device.Size),
+			}
+			addToGroup(nodeGroups, key, device.Size)
+			addToGroup(clusterGroups, key, device.Size)
+			nodeSummary.AvailableDeviceCount++
+			nodeSummary.AvailableCapacity.Add(*resource.NewQuantity(device.Size, resource.BinarySI))
+		}
+		nodeSummary.DeviceGroups = sortedGroups(nodeGroups)
+
+		summary.TotalNodes++
+		summary.AvailableDeviceCount += nodeSummary.AvailableDeviceCount
+		summary.AvailableCapacity.Add(nodeSummary.AvailableCapacity)
+		summary.Nodes = append(summary.Nodes, nodeSummary)
+	}
+	summary.DeviceGroups = sortedGroups(clusterGroups)
+	sort.Slice(summary.Nodes, func(i, j int) bool {
+		return summary.Nodes[i].NodeName < summary.Nodes[j].NodeName
+	})
+
+	return summary
+}
+
+func addToGroup(groups map[deviceGroupKey]*localv1.DeviceGroupSummary, key deviceGroupKey, size int64) {
+	group, found := groups[key]
+	if !found {
+		group = &localv1.DeviceGroupSummary{
+			Type:       key.deviceType,
+			Property:   key.property,
+			Model:      key.model,
+			SizeBucket: key.sizeBucket,
+			Capacity:   *resource.NewQuantity(0, resource.BinarySI),
+		}
+		groups[key] = group
+	}
+	group.Count++
+	group.Capacity.Add(*resource.NewQuantity(size, resource.BinarySI))
+}
+
+// sortedGroups returns the groups ordered by type, property, model and size bucket
+func sortedGroups(groups map[deviceGroupKey]*localv1.DeviceGroupSummary) []localv1.DeviceGroupSummary {
+	if len(groups) == 0 {
+		return nil
+	}
+	sorted := make([]localv1.DeviceGroupSummary, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, *group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Property != b.Property {
+			return a.Property < b.Property
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return sizeBucketIndex(a.SizeBucket) < sizeBucketIndex(b.SizeBucket)
+	})
+	return sorted
+}
+
+// getSizeBucket returns the size range the device size falls in. For eg, 512Gi-1Ti
+func getSizeBucket(size int64) string {
+	lower := int64(0)
+	for _, upper := range sizeBuckets {
+		if size <= upper {
+			return fmt.Sprintf("%s-%s", formatBucketBound(lower), formatBucketBound(upper))
+		}
+		lower = upper
+	}
+	return fmt.Sprintf("%s+", formatBucketBound(lower))
+}
+
+func sizeBucketIndex(bucket string) int {
+	for i, upper := range sizeBuckets {
+		if bucket == getSizeBucket(upper) {
+			return i
+		}
+	}
+	return len(sizeBuckets)
+}
+
+func formatBucketBound(size int64) string {
+	return resource.NewQuantity(size, resource.BinarySI).String()
+}
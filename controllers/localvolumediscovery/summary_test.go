@@ -0,0 +1,141 @@
+package localvolumediscovery
+
+import (
+	"testing"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetSizeBucket(t *testing.T) {
+	testcases := []struct {
+		size     int64
+		expected string
+	}{
+		{size: 10 * common.GiB, expected: "0-64Gi"},
+		{size: 64 * common.GiB, expected: "0-64Gi"},
+		{size: 64*common.GiB + 1, expected: "64Gi-128Gi"},
+		{size: 600 * common.GiB, expected: "512Gi-1Ti"},
+		{size: 3 * common.TiB, expected: "2Ti-4Ti"},
+		{size: 20 * common.TiB, expected: "16Ti+"},
+	}
+	for _, tc := range testcases {
+		assert.Equalf(t, tc.expected, getSizeBucket(tc.size), "size %d", tc.size)
+	}
+}
+
+func TestGetDiscoverySummary(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-time.Minute).Format(time.RFC3339)
+	stale := now.Add(-time.Hour).Format(time.RFC3339)
+	staler := now.Add(-2 * time.Hour).Format(time.RFC3339)
+
+	ssd := func(size int64, state localv1.DeviceState) localv1.DiscoveredDevice {
+		return localv1.DiscoveredDevice{
+			Type:     localv1.DiskType,
+			Property: localv1.NonRotational,
+			Model:    "ssd-model",
+			Size:     size,
+			Status:   localv1.DeviceStatus{State: state},
+		}
+	}
+	hdd := localv1.DiscoveredDevice{
+		Type:     localv1.DiskType,
+		Property: localv1.Rotational,
+		Model:    "hdd-model",
+		Size:     4 * common.TiB,
+		Status:   localv1.DeviceStatus{State: localv1.Available},
+	}
+
+	results := []localv1.LocalVolumeDiscoveryResult{
+		{
+			Spec: localv1.LocalVolumeDiscoveryResultSpec{NodeName: "Node2"},
+			Status: localv1.LocalVolumeDiscoveryResultStatus{
+				DiscoveredTimeStamp: stale,
+				DiscoveredDevices:   []localv1.DiscoveredDevice{ssd(100*common.GiB, localv1.Available), hdd},
+			},
+		},
+		{
+			Spec: localv1.LocalVolumeDiscoveryResultSpec{NodeName: "Node1"},
+			Status: localv1.LocalVolumeDiscoveryResultStatus{
+				DiscoveredTimeStamp: fresh,
+				DiscoveredDevices: []localv1.DiscoveredDevice{
+					ssd(100*common.GiB, localv1.Available),
+					ssd(120*common.GiB, localv1.Available),
+					ssd(100*common.GiB, localv1.NotAvailable),
+				},
+			},
+		},
+		{
+			Spec: localv1.LocalVolumeDiscoveryResultSpec{NodeName: "Node3"},
+			Status: localv1.LocalVolumeDiscoveryResultStatus{
+				DiscoveredTimeStamp: staler,
+			},
+		},
+	}
+
+	summary := getDiscoverySummary(results, now, getStaleResultThreshold(localv1.LocalVolumeDiscoverySpec{}))
+	assert.Equal(t, int32(3), summary.TotalNodes)
+	assert.Equal(t, int32(2), summary.StaleNodes)
+	assert.NotNil(t, summary.OldestStaleResultTimeStamp)
+	assert.Equal(t, staler, summary.OldestStaleResultTimeStamp.UTC().Format(time.RFC3339))
+	assert.Equal(t, int32(4), summary.AvailableDeviceCount)
+	assert.Equal(t, 0, summary.AvailableCapacity.Cmp(*resource.NewQuantity(320*common.GiB+4*common.TiB, resource.BinarySI)))
+
+	// cluster wide groups are sorted by type, property, model and size bucket
+	assert.Len(t, summary.DeviceGroups, 2)
+	assert.Equal(t, localv1.NonRotational, summary.DeviceGroups[0].Property)
+	assert.Equal(t, "64Gi-128Gi", summary.DeviceGroups[0].SizeBucket)
+	assert.Equal(t, int32(3), summary.DeviceGroups[0].Count)
+	assert.Equal(t, localv1.Rotational, summary.DeviceGroups[1].Property)
+	assert.Equal(t, "2Ti-4Ti", summary.DeviceGroups[1].SizeBucket)
+	assert.Equal(t, int32(1), summary.DeviceGroups[1].Count)
+
+	// nodes are sorted by name
+	assert.Len(t, summary.Nodes, 3)
+	assert.Equal(t, "Node1", summary.Nodes[0].NodeName)
+	assert.False(t, summary.Nodes[0].Stale)
+	assert.Equal(t, int32(2), summary.Nodes[0].AvailableDeviceCount)
+	assert.Len(t, summary.Nodes[0].DeviceGroups, 1)
+	assert.Equal(t, 0, summary.Nodes[0].AvailableCapacity.Cmp(*resource.NewQuantity(220*common.GiB, resource.BinarySI)))
+	assert.Equal(t, "Node2", summary.Nodes[1].NodeName)
+	assert.True(t, summary.Nodes[1].Stale)
+	assert.Len(t, summary.Nodes[1].DeviceGroups, 2)
+	assert.Equal(t, "Node3", summary.Nodes[2].NodeName)
+	assert.True(t, summary.Nodes[2].Stale)
+	assert.Equal(t, int32(0), summary.Nodes[2].AvailableDeviceCount)
+	assert.Nil(t, summary.Nodes[2].DeviceGroups)
+}
+
+func TestGetDiscoverySummaryIgnoresRefresh(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	staleAfter := getStaleResultThreshold(localv1.LocalVolumeDiscoverySpec{})
+	device := localv1.DiscoveredDevice{
+		Type:   localv1.DiskType,
+		Size:   100 * common.GiB,
+		Status: localv1.DeviceStatus{State: localv1.Available},
+	}
+	result := localv1.LocalVolumeDiscoveryResult{
+		Spec: localv1.LocalVolumeDiscoveryResultSpec{NodeName: "Node1"},
+		Status: localv1.LocalVolumeDiscoveryResultStatus{
+			DiscoveredTimeStamp: now.Add(-time.Minute).Format(time.RFC3339),
+			DiscoveredDevices:   []localv1.DiscoveredDevice{device},
+		},
+	}
+	summary := getDiscoverySummary([]localv1.LocalVolumeDiscoveryResult{result}, now, staleAfter)
+
+	// the next probe refreshes the result without changing the devices
+	later := now.Add(defaultProbeInterval)
+	result.Status.DiscoveredTimeStamp = later.Format(time.RFC3339)
+	refreshed := getDiscoverySummary([]localv1.LocalVolumeDiscoveryResult{result}, later, staleAfter)
+	assert.True(t, equality.Semantic.DeepEqual(summary, refreshed), "the summary changed on refresh:\n%+v\n%+v", summary, refreshed)
+
+	// a device changing state changes the summary
+	result.Status.DiscoveredDevices[0].Status.State = localv1.NotAvailable
+	changed := getDiscoverySummary([]localv1.LocalVolumeDiscoveryResult{result}, later, staleAfter)
+	assert.False(t, equality.Semantic.DeepEqual(summary, changed))
+}
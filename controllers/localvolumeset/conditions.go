@@ -1,7 +1,7 @@
 package localvolumeset
 
 import (
-	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -10,35 +10,35 @@ const (
 	DaemonSetsAvailableAndConfigured = "DaemonSetsAvailable"
 )
 
-// SetCondition creates or updates a condition of type conditionType in conditions and returns changed
-func SetCondition(conditions *[]operatorv1.OperatorCondition, conditionType, conditionMessage string, conditionStatus operatorv1.ConditionStatus) bool {
-	newCondition := operatorv1.OperatorCondition{
+// The reasons of the conditions
+const (
+	reasonDiskMakerAvailable   = "DiskMakerAvailable"
+	reasonDiskMakerNotFound    = "DiskMakerNotFound"
+	reasonDiskMakerUnavailable = "DiskMakerUnavailable"
+	reasonConflictsFound       = "ConflictsFound"
+	reasonNoConflict           = "NoConflict"
+	reasonReconciled           = "Reconciled"
+	reasonReconcileFailed      = "ReconcileFailed"
+	reasonNodesReconciled      = "NodesReconciled"
+	reasonNodesFailing         = "NodesFailing"
+	reasonNoNodeReported       = "NoNodeReported"
+	reasonInvalidSpec          = "InvalidSpec"
+)
+
+// SetCondition creates or updates a condition of type conditionType in conditions and returns changed.
+// The last transition time is only updated when the status changes.
+func SetCondition(conditions *[]metav1.Condition, conditionType, reason, conditionMessage string, conditionStatus metav1.ConditionStatus, generation int64) bool {
+	existing := meta.FindStatusCondition(*conditions, conditionType)
+	if existing != nil && existing.Status == conditionStatus && existing.Reason == reason && existing.Message == conditionMessage &&
+		existing.ObservedGeneration == generation && !existing.LastTransitionTime.IsZero() {
+		return false
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
 		Type:               conditionType,
 		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
 		Message:            conditionMessage,
-		LastTransitionTime: metav1.Now(),
-	}
-	if len(*conditions) < 1 {
-		*conditions = []operatorv1.OperatorCondition{newCondition}
-		return true
-	}
-	for i, condition := range *conditions {
-		if condition.Type == conditionType {
-			changed := false
-			newCondition.LastTransitionTime = condition.LastTransitionTime
-			if condition.Status != conditionStatus || condition.LastTransitionTime.IsZero() {
-				changed = true
-			} else {
-				newCondition.LastTransitionTime = condition.LastTransitionTime
-			}
-			if condition.Message != conditionMessage {
-				changed = true
-			}
-			(*conditions)[i] = newCondition
-			return changed
-		}
-	}
-	*conditions = append(*conditions, newCondition)
+	})
 	return true
-
 }
@@ -25,6 +25,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,10 +34,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 )
 
 const (
@@ -71,7 +73,7 @@ func (r *LocalVolumeSetReconciler) reconcile(ctx context.Context, request reconc
 	r.ReqLogger.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	r.ReqLogger.Info("Reconciling LocalVolumeSet")
 	// Fetch the LocalVolumeSet instance
-	lvSet := &localv1alpha1.LocalVolumeSet{}
+	lvSet := &localv1.LocalVolumeSet{}
 	err := r.Client.Get(ctx, request.NamespacedName, lvSet)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
@@ -105,6 +107,12 @@ func (r *LocalVolumeSetReconciler) reconcile(ctx context.Context, request reconc
 		return ctrl.Result{}, err
 	}
 
+	err = r.updateConflictingCondition(ctx, request)
+	if err != nil {
+		r.ReqLogger.Error(err, "failed to update status")
+		return ctrl.Result{}, err
+	}
+
 	err = r.updateTotalProvisionedDeviceCountStatus(ctx, request)
 	if err != nil {
 		r.ReqLogger.Error(err, "failed to update status")
@@ -114,7 +122,7 @@ func (r *LocalVolumeSetReconciler) reconcile(ctx context.Context, request reconc
 	return ctrl.Result{}, nil
 }
 
-func (r *LocalVolumeSetReconciler) syncStorageClass(ctx context.Context, lvs *localv1alpha1.LocalVolumeSet) error {
+func (r *LocalVolumeSetReconciler) syncStorageClass(ctx context.Context, lvs *localv1.LocalVolumeSet) error {
 	deleteReclaimPolicy := corev1.PersistentVolumeReclaimDelete
 	firstConsumerBinding := storagev1.VolumeBindingWaitForFirstConsumer
 	storageClass := &storagev1.StorageClass{
@@ -129,13 +137,26 @@ func (r *LocalVolumeSetReconciler) syncStorageClass(ctx context.Context, lvs *lo
 		Provisioner:       "kubernetes.io/no-provisioner",
 		ReclaimPolicy:     &deleteReclaimPolicy,
 		VolumeBindingMode: &firstConsumerBinding,
+		MountOptions:      common.GetMountOptions(lvs.Spec.VolumeMode, lvs.Spec.Filesystem),
 	}
 
 	err := r.Client.Create(ctx, storageClass)
-	if err != nil && !kerrors.IsAlreadyExists(err) {
+	if err == nil || !kerrors.IsAlreadyExists(err) {
 		return err
 	}
-	return nil
+
+	// the mount options are the only field of the storage class that can be updated
+	existing := &storagev1.StorageClass{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: storageClass.Name}, existing)
+	if err != nil {
+		return err
+	}
+	if existing.Labels[common.OwnerNameLabel] != lvs.GetName() || existing.Labels[common.OwnerNamespaceLabel] != lvs.GetNamespace() ||
+		equality.Semantic.DeepEqual(existing.MountOptions, storageClass.MountOptions) {
+		return nil
+	}
+	existing.MountOptions = storageClass.MountOptions
+	return r.Client.Update(ctx, existing)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -154,9 +175,9 @@ func (r *LocalVolumeSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&localv1alpha1.LocalVolumeSet{}).
+		For(&localv1.LocalVolumeSet{}).
 		// watch provisioner, diskmaker-manager daemonsets and enqueue owning object to update status.conditions
-		Watches(&source.Kind{Type: &appsv1.DaemonSet{}}, &handler.EnqueueRequestForOwner{OwnerType: &localv1alpha1.LocalVolumeSet{}}, builder.WithPredicates(common.EnqueueOnlyLabeledSubcomponents(nodedaemon.DiskMakerName, nodedaemon.ProvisionerName))).
+		Watches(&source.Kind{Type: &appsv1.DaemonSet{}}, &handler.EnqueueRequestForOwner{OwnerType: &localv1.LocalVolumeSet{}}, builder.WithPredicates(common.EnqueueOnlyLabeledSubcomponents(nodedaemon.DiskMakerName, nodedaemon.ProvisionerName))).
 		//  watch for storageclass, enqueue owner
 		Watches(&source.Kind{Type: &corev1.PersistentVolume{}}, handler.EnqueueRequestsFromMapFunc(
 			func(obj client.Object) []reconcile.Request {
@@ -193,12 +214,32 @@ func (r *LocalVolumeSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 				// skip LocalVolume owned PVs
 				ownerKind, found := pv.Labels[common.PVOwnerKindLabel]
-				if ownerKind != localv1alpha1.LocalVolumeSetKind || !found {
+				if ownerKind != localv1.LocalVolumeSetKind || !found {
 					return []reconcile.Request{}
 				}
 				req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ownerName, Namespace: ownerNamespace}}
 
 				return []reconcile.Request{req}
 			})).
+		// the conflicts of the LocalVolumeSets change with the other LocalVolumes and LocalVolumeSets of the namespace
+		Watches(&source.Kind{Type: &localv1.LocalVolumeSet{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceLocalVolumeSets),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &localv1.LocalVolume{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceLocalVolumeSets),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
+
+// enqueueNamespaceLocalVolumeSets enqueues all the LocalVolumeSets of the namespace of the object
+func (r *LocalVolumeSetReconciler) enqueueNamespaceLocalVolumeSets(obj client.Object) []reconcile.Request {
+	lvSets := &localv1.LocalVolumeSetList{}
+	err := r.Client.List(context.TODO(), lvSets, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.ReqLogger.Error(err, "failed to list localvolumesets")
+		return []reconcile.Request{}
+	}
+	reqs := make([]reconcile.Request, 0, len(lvSets.Items))
+	for _, lvSet := range lvSets.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: lvSet.Name, Namespace: lvSet.Namespace}})
+	}
+	return reqs
+}
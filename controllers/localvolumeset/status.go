@@ -3,25 +3,34 @@ package localvolumeset
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
 	"github.com/openshift/local-storage-operator/controllers/nodedaemon"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// maxDegradedNodes is the number of nodes listed in the message of the Degraded condition
+const maxDegradedNodes = 5
+
 func (r *LocalVolumeSetReconciler) updateDaemonSetsCondition(ctx context.Context, request reconcile.Request) error {
 	var diskMakerMessage string
 	diskMakerFound := true
 	conditionType := DaemonSetsAvailableAndConfigured
-	conditionStatus := operatorv1.ConditionTrue
+	conditionStatus := metav1.ConditionTrue
+	reason := reasonDiskMakerAvailable
 	diskMakerDS := &appsv1.DaemonSet{}
 	key := types.NamespacedName{Name: nodedaemon.DiskMakerName, Namespace: request.Namespace}
 	err := r.Client.Get(ctx, key, diskMakerDS)
@@ -32,16 +41,18 @@ func (r *LocalVolumeSetReconciler) updateDaemonSetsCondition(ctx context.Context
 	}
 	if !diskMakerFound {
 		diskMakerMessage = "Not found."
-		conditionStatus = operatorv1.ConditionFalse
+		conditionStatus = metav1.ConditionFalse
+		reason = reasonDiskMakerNotFound
 	} else if diskMakerDS.Status.NumberUnavailable > 0 {
 		diskMakerMessage = fmt.Sprintf("%d/%d Unavailable.", diskMakerDS.Status.NumberUnavailable, diskMakerDS.Status.CurrentNumberScheduled)
-		conditionStatus = operatorv1.ConditionFalse
+		conditionStatus = metav1.ConditionFalse
+		reason = reasonDiskMakerUnavailable
 	} else {
 		diskMakerMessage = "Available"
 	}
 	conditionMessage := fmt.Sprintf("DiskMaker: %s", diskMakerMessage)
 
-	lvSet := &localv1alpha1.LocalVolumeSet{}
+	lvSet := &localv1.LocalVolumeSet{}
 	err = r.Client.Get(ctx, request.NamespacedName, lvSet)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
@@ -51,7 +62,7 @@ func (r *LocalVolumeSetReconciler) updateDaemonSetsCondition(ctx context.Context
 		return fmt.Errorf("failed to get localvolumeset: %w", err)
 	}
 
-	changed := SetCondition(&lvSet.Status.Conditions, conditionType, conditionMessage, conditionStatus)
+	changed := SetCondition(&lvSet.Status.Conditions, conditionType, reason, conditionMessage, conditionStatus, lvSet.Generation)
 	if changed {
 		err := r.Client.Status().Update(ctx, lvSet)
 		if err != nil {
@@ -63,39 +74,90 @@ func (r *LocalVolumeSetReconciler) updateDaemonSetsCondition(ctx context.Context
 	return nil
 }
 
-func (r *LocalVolumeSetReconciler) updateTotalProvisionedDeviceCountStatus(ctx context.Context, request reconcile.Request) error {
-
-	lvSet := &localv1alpha1.LocalVolumeSet{}
+func (r *LocalVolumeSetReconciler) updateConflictingCondition(ctx context.Context, request reconcile.Request) error {
+	lvSet := &localv1.LocalVolumeSet{}
 	err := r.Client.Get(ctx, request.NamespacedName, lvSet)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
-			r.LvSetMap.DeregisterStorageClassOwner(lvSet.Spec.StorageClassName, request.NamespacedName)
 			return nil
 		}
 		return fmt.Errorf("failed to get localvolumeset: %w", err)
 	}
 
-	// fetch PVs that match the storageclass
-	pvs := &corev1.PersistentVolumeList{}
-	err = r.Client.List(ctx, pvs, client.MatchingFields{pvStorageClassField: lvSet.Spec.StorageClassName})
+	conflicts, err := common.FindConflicts(ctx, r.Client, lvSet)
 	if err != nil {
-		return fmt.Errorf("failed to list persistent volumes: %w", err)
+		return fmt.Errorf("failed to find conflicting objects: %w", err)
+	}
+	conditionStatus := metav1.ConditionFalse
+	reason := reasonNoConflict
+	if len(conflicts) > 0 {
+		conditionStatus = metav1.ConditionTrue
+		reason = reasonConflictsFound
+	}
+	conditionMessage := common.ConflictsMessage(conflicts)
+
+	changed := SetCondition(&lvSet.Status.Conditions, common.ConflictingCondition, reason, conditionMessage, conditionStatus, lvSet.Generation)
+	if changed {
+		err := r.Client.Status().Update(ctx, lvSet)
+		if err != nil {
+			r.ReqLogger.Error(err, "failed to update localvolumeset condition", common.ConflictingCondition, conditionStatus, "message", conditionMessage)
+			return err
+		}
 	}
+	return nil
+}
 
-	totalPVCount := int32(len(pvs.Items))
-	lvSet.Status.TotalProvisionedDeviceCount = &totalPVCount
-	lvSet.Status.ObservedGeneration = lvSet.Generation
-	err = r.Client.Status().Update(ctx, lvSet)
+func (r *LocalVolumeSetReconciler) updateTotalProvisionedDeviceCountStatus(ctx context.Context, request reconcile.Request) error {
+	// the diskmakers report the provisioning on their node in the status
+	nodes := &corev1.NodeList{}
+	err := r.Client.List(ctx, nodes)
 	if err != nil {
-		return fmt.Errorf("failed to update status: %w", err)
+		return fmt.Errorf("failed to list nodes: %w", err)
 	}
 
-	return nil
+	// the diskmakers update the status of their node concurrently, so the status is computed again
+	// from the latest LocalVolumeSet when the update conflicts
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		lvSet := &localv1.LocalVolumeSet{}
+		err := r.Client.Get(ctx, request.NamespacedName, lvSet)
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				r.LvSetMap.DeregisterStorageClassOwner(lvSet.Spec.StorageClassName, request.NamespacedName)
+				return nil
+			}
+			return fmt.Errorf("failed to get localvolumeset: %w", err)
+		}
+
+		// fetch PVs that match the storageclass
+		pvs := &corev1.PersistentVolumeList{}
+		err = r.Client.List(ctx, pvs, client.MatchingFields{pvStorageClassField: lvSet.Spec.StorageClassName})
+		if err != nil {
+			return fmt.Errorf("failed to list persistent volumes: %w", err)
+		}
+
+		totalPVCount := int32(len(pvs.Items))
+		lvSet.Status.TotalProvisionedDeviceCount = &totalPVCount
+		lvSet.Status.ObservedGeneration = lvSet.Generation
+		lvSet.Status.State = lvSet.Spec.ManagementState
+		if lvSet.Status.State == "" {
+			lvSet.Status.State = operatorv1.Managed
+		}
+
+		r.pruneNodeStatuses(lvSet, nodes.Items)
+		// new invalid specs are rejected by the validating webhook, the ones admitted before a rule was added,
+		// or without the webhook, are reported
+		setDegradedCondition(lvSet, common.ValidateLocalVolumeSet(lvSet))
+		err = r.Client.Status().Update(ctx, lvSet)
+		if err != nil {
+			return fmt.Errorf("failed to update status: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *LocalVolumeSetReconciler) addAvailabilityConditions(ctx context.Context, request reconcile.Request, result ctrl.Result, reconcileError error) (ctrl.Result, error) {
 	// can't set conditions if lvset can't be fetched
-	lvSet := &localv1alpha1.LocalVolumeSet{}
+	lvSet := &localv1.LocalVolumeSet{}
 	err := r.Client.Get(ctx, request.NamespacedName, lvSet)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
@@ -107,16 +169,18 @@ func (r *LocalVolumeSetReconciler) addAvailabilityConditions(ctx context.Context
 
 	// success values
 	conditionType := operatorv1.OperatorStatusTypeAvailable
-	conditionStatus := operatorv1.ConditionTrue
+	conditionStatus := metav1.ConditionTrue
+	reason := reasonReconciled
 	conditionMessage := "Operator reconciled successfully."
 
 	// failure values
 	if reconcileError != nil {
 		r.ReqLogger.Error(reconcileError, "reconcile error")
-		conditionStatus = operatorv1.ConditionFalse
+		conditionStatus = metav1.ConditionFalse
+		reason = reasonReconcileFailed
 		conditionMessage = fmt.Sprintf("Operator error: %+v", reconcileError)
 	}
-	changed := SetCondition(&lvSet.Status.Conditions, conditionType, conditionMessage, conditionStatus)
+	changed := SetCondition(&lvSet.Status.Conditions, conditionType, reason, conditionMessage, conditionStatus, lvSet.Generation)
 	if changed {
 		err := r.Client.Status().Update(context.TODO(), lvSet)
 		if err != nil {
@@ -126,3 +190,59 @@ func (r *LocalVolumeSetReconciler) addAvailabilityConditions(ctx context.Context
 	}
 	return result, reconcileError
 }
+
+// pruneNodeStatuses drops the status of the nodes that were deleted or that the LocalVolumeSet doesn't select anymore,
+// there is no diskmaker left to report them
+func (r *LocalVolumeSetReconciler) pruneNodeStatuses(lvSet *localv1.LocalVolumeSet, nodes []corev1.Node) {
+	selected := map[string]bool{}
+	for i := range nodes {
+		matches, err := common.NodeSelectorMatchesNodeLabels(&nodes[i], lvSet.Spec.NodeSelector)
+		if err != nil {
+			r.ReqLogger.Error(err, "failed to match nodeSelector to node labels", "Node.Name", nodes[i].Name)
+			// keep the status of the node
+			matches = true
+		}
+		selected[nodes[i].Name] = matches
+	}
+	var kept []localv1.LocalVolumeSetNodeStatus
+	for _, node := range lvSet.Status.Nodes {
+		if selected[node.NodeName] {
+			kept = append(kept, node)
+		}
+	}
+	lvSet.Status.Nodes = kept
+}
+
+// setDegradedCondition sets the Degraded condition of the LocalVolumeSet, with the errors of its spec, or else the nodes
+// where the last reconcile failed. A LocalVolumeSet admitted with an invalid spec is still reconciled as far as possible.
+func setDegradedCondition(lvSet *localv1.LocalVolumeSet, specErrs field.ErrorList) {
+	conditionStatus := metav1.ConditionFalse
+	reason := reasonNodesReconciled
+	conditionMessage := fmt.Sprintf("the last reconcile succeeded on %d nodes", len(lvSet.Status.Nodes))
+	failed := make([]string, 0)
+	for _, node := range lvSet.Status.Nodes {
+		if node.LastError != "" {
+			failed = append(failed, node.NodeName)
+		}
+	}
+	switch {
+	case len(specErrs) > 0:
+		conditionStatus = metav1.ConditionTrue
+		reason = reasonInvalidSpec
+		conditionMessage = fmt.Sprintf("invalid LocalVolumeSet: %v", specErrs.ToAggregate())
+	case len(lvSet.Status.Nodes) == 0:
+		conditionStatus = metav1.ConditionUnknown
+		reason = reasonNoNodeReported
+		conditionMessage = "no diskmaker has reported its node yet"
+	case len(failed) > 0:
+		conditionStatus = metav1.ConditionTrue
+		reason = reasonNodesFailing
+		conditionMessage = fmt.Sprintf("the last reconcile failed on %d of %d nodes: ", len(failed), len(lvSet.Status.Nodes))
+		if len(failed) > maxDegradedNodes {
+			conditionMessage += strings.Join(failed[:maxDegradedNodes], ", ") + fmt.Sprintf(" and %d more", len(failed)-maxDegradedNodes)
+		} else {
+			conditionMessage += strings.Join(failed, ", ")
+		}
+	}
+	SetCondition(&lvSet.Status.Conditions, operatorv1.OperatorStatusTypeDegraded, reason, conditionMessage, conditionStatus, lvSet.Generation)
+}
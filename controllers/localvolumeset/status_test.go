@@ -6,12 +6,13 @@ import (
 	"testing"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/common"
 	"github.com/openshift/local-storage-operator/controllers/nodedaemon"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -24,7 +25,7 @@ const (
 )
 
 func newFakeLocalVolumeSetReconciler(t *testing.T, objs ...runtime.Object) *LocalVolumeSetReconciler {
-	scheme, err := localv1alpha1.SchemeBuilder.Build()
+	scheme, err := localv1.SchemeBuilder.Build()
 	assert.NoErrorf(t, err, "creating scheme")
 
 	err = corev1.AddToScheme(scheme)
@@ -82,10 +83,10 @@ func TestDaemonSetCondition(t *testing.T) {
 		if kr.diskMakerFound {
 			objs = append(objs, diskmaker)
 		}
-		var lvsets []*localv1alpha1.LocalVolumeSet
+		var lvsets []*localv1.LocalVolumeSet
 
 		for i := 0; i <= 5; i++ {
-			lvset := &localv1alpha1.LocalVolumeSet{
+			lvset := &localv1.LocalVolumeSet{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      fmt.Sprintf("s-%d", i),
 					Namespace: testNamespace,
@@ -104,16 +105,16 @@ func TestDaemonSetCondition(t *testing.T) {
 			err := fakeReconciler.updateDaemonSetsCondition(context.TODO(), req)
 			assert.NoErrorf(t, err, "updateDaemonSetsCondition")
 
-			reconciledLVSet := &localv1alpha1.LocalVolumeSet{}
+			reconciledLVSet := &localv1.LocalVolumeSet{}
 			err = fakeReconciler.Client.Get(context.TODO(), lvsetKey, reconciledLVSet)
 			assert.NoErrorf(t, err, "get lvset from fake client")
 
 			conditions := reconciledLVSet.Status.Conditions
 
 			conditionFound := false
-			expectedConditionStatus := operatorv1.ConditionFalse
+			expectedConditionStatus := metav1.ConditionFalse
 			if kr.condition {
-				expectedConditionStatus = operatorv1.ConditionTrue
+				expectedConditionStatus = metav1.ConditionTrue
 			}
 
 			for _, condition := range conditions {
@@ -127,3 +128,53 @@ func TestDaemonSetCondition(t *testing.T) {
 
 	}
 }
+
+func TestPruneNodeStatuses(t *testing.T) {
+	lvSet := &localv1.LocalVolumeSet{
+		Spec: localv1.LocalVolumeSetSpec{NodeSelector: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "storage", Operator: corev1.NodeSelectorOpExists}},
+		}}}},
+		Status: localv1.LocalVolumeSetStatus{Nodes: []localv1.LocalVolumeSetNodeStatus{
+			{NodeName: "deleted", ProvisionedDeviceCount: 1},
+			{NodeName: "selected", ProvisionedDeviceCount: 2},
+			{NodeName: "unselected", ProvisionedDeviceCount: 3},
+		}},
+	}
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: map[string]string{"storage": ""}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unselected"}},
+	}
+	fakeReconciler := newFakeLocalVolumeSetReconciler(t)
+	fakeReconciler.pruneNodeStatuses(lvSet, nodes)
+	assert.Equal(t, []localv1.LocalVolumeSetNodeStatus{{NodeName: "selected", ProvisionedDeviceCount: 2}}, lvSet.Status.Nodes)
+}
+
+func TestSetDegradedCondition(t *testing.T) {
+	lvSet := &localv1.LocalVolumeSet{}
+	setDegradedCondition(lvSet, nil)
+	condition := meta.FindStatusCondition(lvSet.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	assert.Equal(t, metav1.ConditionUnknown, condition.Status)
+
+	lvSet.Status.Nodes = []localv1.LocalVolumeSetNodeStatus{{NodeName: "node1"}, {NodeName: "node2"}}
+	setDegradedCondition(lvSet, nil)
+	condition = meta.FindStatusCondition(lvSet.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "the last reconcile succeeded on 2 nodes", condition.Message)
+
+	lvSet.Status.Nodes[1].LastError = "could not list block devices"
+	setDegradedCondition(lvSet, nil)
+	assert.Len(t, lvSet.Status.Conditions, 1)
+	condition = meta.FindStatusCondition(lvSet.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonNodesFailing, condition.Reason)
+	assert.Equal(t, "the last reconcile failed on 1 of 2 nodes: node2", condition.Message)
+
+	// the message lists a few nodes
+	lvSet.Status.Nodes = nil
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		lvSet.Status.Nodes = append(lvSet.Status.Nodes, localv1.LocalVolumeSetNodeStatus{NodeName: name, LastError: "failed"})
+	}
+	setDegradedCondition(lvSet, nil)
+	condition = meta.FindStatusCondition(lvSet.Status.Conditions, operatorv1.OperatorStatusTypeDegraded)
+	assert.Equal(t, "the last reconcile failed on 7 of 7 nodes: a, b, c, d, e and 2 more", condition.Message)
+}
@@ -9,26 +9,27 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
 	v1 "github.com/openshift/local-storage-operator/api/v1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func (r *DaemonReconciler) aggregateDeamonInfo(ctx context.Context, request reconcile.Request) (localv1alpha1.LocalVolumeSetList, v1.LocalVolumeList, []corev1.Toleration, []metav1.OwnerReference, *corev1.NodeSelector, error) {
+func (r *DaemonReconciler) aggregateDeamonInfo(ctx context.Context, request reconcile.Request) (v1.LocalVolumeSetList, v1.LocalVolumeList, []corev1.Toleration, []metav1.OwnerReference, *corev1.NodeSelector, error) {
 	//list
-	lvSetList := localv1alpha1.LocalVolumeSetList{}
+	lvSetList := v1.LocalVolumeSetList{}
 	err := r.Client.List(ctx, &lvSetList, client.InNamespace(request.Namespace))
 	if err != nil {
-		return localv1alpha1.LocalVolumeSetList{}, v1.LocalVolumeList{}, []corev1.Toleration{}, []metav1.OwnerReference{}, nil, fmt.Errorf("could not fetch localvolumeset link: %w", err)
+		return v1.LocalVolumeSetList{}, v1.LocalVolumeList{}, []corev1.Toleration{}, []metav1.OwnerReference{}, nil, fmt.Errorf("could not fetch localvolumeset link: %w", err)
 	}
 
+	lvSetList.Items = withoutRemovedLVSets(lvSetList.Items)
 	lvSets := lvSetList.Items
 	tolerations, ownerRefs, terms := extractLVSetInfo(lvSets)
 	lvList := v1.LocalVolumeList{}
 	err = r.Client.List(ctx, &lvList, client.InNamespace(request.Namespace))
 	if err != nil {
-		return localv1alpha1.LocalVolumeSetList{}, v1.LocalVolumeList{}, []corev1.Toleration{}, []metav1.OwnerReference{}, nil, fmt.Errorf("could not fetch localvolume link: %w", err)
+		return v1.LocalVolumeSetList{}, v1.LocalVolumeList{}, []corev1.Toleration{}, []metav1.OwnerReference{}, nil, fmt.Errorf("could not fetch localvolume link: %w", err)
 	}
 
 	lvs := lvList.Items
@@ -46,7 +47,32 @@ func (r *DaemonReconciler) aggregateDeamonInfo(ctx context.Context, request reco
 	return lvSetList, lvList, tolerations, ownerRefs, nodeSelector, err
 }
 
-func extractLVSetInfo(lvsets []localv1alpha1.LocalVolumeSet) ([]corev1.Toleration, []metav1.OwnerReference, []corev1.NodeSelectorTerm) {
+// withoutRemovedLVSets drops the Removed LocalVolumeSets whose PVs are all deleted, and whose teardown every node reported done
+// in its status, since the symlinks are only removed by the teardown: the daemons have nothing left to do for them.
+// Unmanaged LocalVolumeSets are kept, the diskmakers and the deleter leave their PVs as they are.
+func withoutRemovedLVSets(lvsets []v1.LocalVolumeSet) []v1.LocalVolumeSet {
+	kept := make([]v1.LocalVolumeSet, 0, len(lvsets))
+	for _, lvset := range lvsets {
+		count := lvset.Status.TotalProvisionedDeviceCount
+		if lvset.Spec.ManagementState == operatorv1.Removed && count != nil && *count == 0 && isTornDown(lvset) {
+			continue
+		}
+		kept = append(kept, lvset)
+	}
+	return kept
+}
+
+// isTornDown returns true when the diskmakers of all the nodes in the status of the LocalVolumeSet reported its teardown done
+func isTornDown(lvset v1.LocalVolumeSet) bool {
+	for _, node := range lvset.Status.Nodes {
+		if !node.TornDown {
+			return false
+		}
+	}
+	return true
+}
+
+func extractLVSetInfo(lvsets []v1.LocalVolumeSet) ([]corev1.Toleration, []metav1.OwnerReference, []corev1.NodeSelectorTerm) {
 	tolerations := make([]corev1.Toleration, 0)
 	ownerRefs := make([]metav1.OwnerReference, 0)
 	terms := make([]corev1.NodeSelectorTerm, 0)
@@ -4,7 +4,8 @@ import (
 	"reflect"
 	"testing"
 
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -12,12 +13,12 @@ import (
 
 func TestExtractLVSetInfo(t *testing.T) {
 
-	lvSets := []localv1alpha1.LocalVolumeSet{
+	lvSets := []localv1.LocalVolumeSet{
 		{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: "a",
 			},
-			Spec: localv1alpha1.LocalVolumeSetSpec{
+			Spec: localv1.LocalVolumeSetSpec{
 				NodeSelector: &corev1.NodeSelector{
 					// 2 terms
 					NodeSelectorTerms: []corev1.NodeSelectorTerm{
@@ -44,7 +45,7 @@ func TestExtractLVSetInfo(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{
 				Name: "b",
 			},
-			Spec: localv1alpha1.LocalVolumeSetSpec{
+			Spec: localv1.LocalVolumeSetSpec{
 				NodeSelector: &corev1.NodeSelector{
 					// 2 terms
 					NodeSelectorTerms: []corev1.NodeSelectorTerm{
@@ -114,11 +115,11 @@ func TestExtractLVSetInfo(t *testing.T) {
 
 func TestExtractLVSetInfoWithNilNodeSelector(t *testing.T) {
 
-	lvSetWithNodeSelector := localv1alpha1.LocalVolumeSet{
+	lvSetWithNodeSelector := localv1.LocalVolumeSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
 		},
-		Spec: localv1alpha1.LocalVolumeSetSpec{
+		Spec: localv1.LocalVolumeSetSpec{
 			NodeSelector: &corev1.NodeSelector{
 				// 2 terms
 				NodeSelectorTerms: []corev1.NodeSelectorTerm{
@@ -141,11 +142,11 @@ func TestExtractLVSetInfoWithNilNodeSelector(t *testing.T) {
 			},
 		},
 	}
-	lvSetWithoutNodeSelector := localv1alpha1.LocalVolumeSet{
+	lvSetWithoutNodeSelector := localv1.LocalVolumeSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "b",
 		},
-		Spec: localv1alpha1.LocalVolumeSetSpec{
+		Spec: localv1.LocalVolumeSetSpec{
 			// no nodeSelector
 			// 2 tolerations
 			Tolerations: []corev1.Toleration{
@@ -167,7 +168,7 @@ func TestExtractLVSetInfoWithNilNodeSelector(t *testing.T) {
 
 	count := 3
 	for i := 0; i <= count; i++ {
-		lvSets := []localv1alpha1.LocalVolumeSet{}
+		lvSets := []localv1.LocalVolumeSet{}
 		for j := 0; j <= count; j++ {
 			// place empty nodeSelector in ith place
 			if i == j {
@@ -183,7 +184,7 @@ func TestExtractLVSetInfoWithNilNodeSelector(t *testing.T) {
 
 	}
 	for i := 0; i <= count; i++ {
-		lvSets := []localv1alpha1.LocalVolumeSet{}
+		lvSets := []localv1.LocalVolumeSet{}
 		for j := 0; j <= count; j++ {
 			lvSets = append(lvSets, lvSetWithNodeSelector)
 		}
@@ -194,3 +195,31 @@ func TestExtractLVSetInfoWithNilNodeSelector(t *testing.T) {
 	}
 
 }
+
+func TestWithoutRemovedLVSets(t *testing.T) {
+	zero, one := int32(0), int32(1)
+	newLVSet := func(name string, state operatorv1.ManagementState, count *int32) localv1.LocalVolumeSet {
+		return localv1.LocalVolumeSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       localv1.LocalVolumeSetSpec{ManagementState: state},
+			Status:     localv1.LocalVolumeSetStatus{TotalProvisionedDeviceCount: count},
+		}
+	}
+	lvSets := []localv1.LocalVolumeSet{
+		newLVSet("default", "", &zero),
+		newLVSet("managed", operatorv1.Managed, &zero),
+		newLVSet("unmanaged", operatorv1.Unmanaged, &zero),
+		newLVSet("removing", operatorv1.Removed, &one),
+		newLVSet("removed", operatorv1.Removed, &zero),
+		newLVSet("unknown", operatorv1.Removed, nil),
+		newLVSet("tearing-down", operatorv1.Removed, &zero),
+		newLVSet("torn-down", operatorv1.Removed, &zero),
+	}
+	lvSets[6].Status.Nodes = []localv1.LocalVolumeSetNodeStatus{{NodeName: "node1", TornDown: true}, {NodeName: "node2"}}
+	lvSets[7].Status.Nodes = []localv1.LocalVolumeSetNodeStatus{{NodeName: "node1", TornDown: true}, {NodeName: "node2", TornDown: true}}
+	var names []string
+	for _, lvSet := range withoutRemovedLVSets(lvSets) {
+		names = append(names, lvSet.Name)
+	}
+	assert.Equal(t, []string{"default", "managed", "unmanaged", "removing", "unknown", "tearing-down"}, names)
+}
@@ -3,9 +3,12 @@ package nodedaemon
 import (
 	"testing"
 
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -38,3 +41,155 @@ func TestMutateAggregatedSpecWithNilNodeSelector(t *testing.T) {
 	assert.NotNilf(t, ds.Spec.Template.Spec.Affinity, "DaemonSet affinity should not be nil if nodeSelector is not nil")
 
 }
+
+func TestMutateAggregatedSpecResetsHostNetwork(t *testing.T) {
+	ds := &appsv1.DaemonSet{}
+	ds.Spec.Template.Spec.HostNetwork = true
+	ds.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	MutateAggregatedSpec(
+		ds,
+		reconcile.Request{},
+		[]corev1.Toleration{},
+		[]metav1.OwnerReference{},
+		nil,
+		"",
+	)
+	assert.False(t, ds.Spec.Template.Spec.HostNetwork)
+	assert.Equal(t, corev1.DNSClusterFirst, ds.Spec.Template.Spec.DNSPolicy)
+}
+
+func TestGetEscrowDirs(t *testing.T) {
+	escrow := func(hostDir string) *localv1.EncryptionSpec {
+		return &localv1.EncryptionSpec{KeyPolicy: localv1.EncryptionKeyGenerated, Escrow: &localv1.KeyEscrowSpec{HostDir: hostDir}}
+	}
+	lvSets := []localv1.LocalVolumeSet{
+		{Spec: localv1.LocalVolumeSetSpec{Encryption: escrow("/var/lib/local-storage/escrow/b")}},
+		{Spec: localv1.LocalVolumeSetSpec{Encryption: &localv1.EncryptionSpec{KeyPolicy: localv1.EncryptionKeyGenerated}}},
+		{Spec: localv1.LocalVolumeSetSpec{}},
+		// never mounted outside of the escrow prefix
+		{Spec: localv1.LocalVolumeSetSpec{Encryption: escrow("/etc")}},
+		{Spec: localv1.LocalVolumeSetSpec{Encryption: escrow("/var/lib/local-storage/escrow/../../kubelet")}},
+	}
+	lvs := []localv1.LocalVolume{
+		{Spec: localv1.LocalVolumeSpec{StorageClassDevices: []localv1.StorageClassDevice{
+			{Encryption: escrow("/var/lib/local-storage/escrow/a")},
+			{Encryption: escrow("/var/lib/local-storage/escrow/b")},
+			{},
+		}}},
+	}
+	assert.Equal(t, []string{"/var/lib/local-storage/escrow/a", "/var/lib/local-storage/escrow/b"}, getEscrowDirs(lvSets, lvs))
+
+	ds := &appsv1.DaemonSet{}
+	err := getDiskMakerDSMutateFn(reconcile.Request{}, nil, nil, nil, "", getEscrowDirs(lvSets, lvs), false, &localv1.LocalStorageOperatorConfig{})(ds)
+	assert.NoError(t, err)
+	mounts := map[string]string{}
+	for _, mount := range ds.Spec.Template.Spec.Containers[0].VolumeMounts {
+		mounts[mount.Name] = mount.MountPath
+	}
+	assert.Equal(t, "/var/lib/local-storage/escrow/a", mounts["key-escrow-0"])
+	assert.Equal(t, "/var/lib/local-storage/escrow/b", mounts["key-escrow-1"])
+}
+
+func TestSharedFilesystemMountPropagation(t *testing.T) {
+	lvSets := []localv1.LocalVolumeSet{
+		{Spec: localv1.LocalVolumeSetSpec{}},
+		{Spec: localv1.LocalVolumeSetSpec{SharedFilesystem: &localv1.SharedFilesystemSpec{VolumeCount: 10}}},
+	}
+	assert.False(t, hasSharedFilesystems(lvSets[:1]))
+	assert.True(t, hasSharedFilesystems(lvSets))
+
+	for _, sharedFilesystems := range []bool{false, true} {
+		ds := &appsv1.DaemonSet{}
+		err := getDiskMakerDSMutateFn(reconcile.Request{}, nil, nil, nil, "", nil, sharedFilesystems, &localv1.LocalStorageOperatorConfig{})(ds)
+		assert.NoError(t, err)
+		for _, mount := range ds.Spec.Template.Spec.Containers[0].VolumeMounts {
+			if mount.Name != common.SymlinkMount.Name {
+				continue
+			}
+			if sharedFilesystems {
+				assert.Equal(t, corev1.MountPropagationBidirectional, *mount.MountPropagation)
+			} else {
+				assert.Equal(t, corev1.MountPropagationHostToContainer, *mount.MountPropagation)
+			}
+		}
+	}
+	assert.Equal(t, corev1.MountPropagationHostToContainer, *common.SymlinkMount.MountPropagation, "the shared mount definition is not modified")
+}
+
+func TestMutateOperatorConfig(t *testing.T) {
+	getDiskMakerDS := func(config *localv1.LocalStorageOperatorConfig) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		tolerations := []corev1.Toleration{{Key: "lv", Operator: corev1.TolerationOpExists}}
+		err := getDiskMakerDSMutateFn(reconcile.Request{}, tolerations, nil, nil, "", nil, false, config)(ds)
+		assert.NoError(t, err)
+		return ds
+	}
+	getSymlinkDirs := func(ds *appsv1.DaemonSet) (string, string, string) {
+		var hostPath, mountPath, env string
+		for _, volume := range ds.Spec.Template.Spec.Volumes {
+			if volume.Name == common.SymlinkHostDirVolume.Name {
+				hostPath = volume.HostPath.Path
+			}
+		}
+		container := ds.Spec.Template.Spec.Containers[0]
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == common.SymlinkMount.Name {
+				mountPath = mount.MountPath
+			}
+		}
+		for _, envVar := range container.Env {
+			if envVar.Name == common.LocalDiskLocationEnv {
+				env = envVar.Value
+			}
+		}
+		return hostPath, mountPath, env
+	}
+
+	// the defaults are kept without a config
+	ds := getDiskMakerDS(&localv1.LocalStorageOperatorConfig{})
+	container := ds.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, common.GetDiskMakerImage(), container.Image)
+	assert.Equal(t, corev1.PullIfNotPresent, container.ImagePullPolicy)
+	assert.Equal(t, []string{"lv-manager"}, container.Args)
+	assert.Empty(t, container.Resources)
+	assert.Equal(t, "10%", ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable.String())
+	assert.Equal(t, common.PriorityClassName, ds.Spec.Template.Spec.PriorityClassName)
+	assert.Len(t, ds.Spec.Template.Spec.Tolerations, 1)
+	hostPath, mountPath, env := getSymlinkDirs(ds)
+	assert.Equal(t, common.GetLocalDiskLocationPath(), hostPath)
+	assert.Equal(t, common.GetLocalDiskLocationPath(), mountPath)
+	assert.Equal(t, common.GetLocalDiskLocationPath(), env)
+
+	config := &localv1.LocalStorageOperatorConfig{
+		Spec: localv1.LocalStorageOperatorConfigSpec{
+			DiskMaker: localv1.NodeDaemonConfig{
+				Image: "registry.example.com/diskmaker:latest",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("50Mi")},
+				},
+				UpdateStrategy: &appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+			},
+			ImagePullPolicy:   corev1.PullAlways,
+			ImagePullSecrets:  []corev1.LocalObjectReference{{Name: "registry"}},
+			PriorityClassName: "system-node-critical",
+			LogVerbosity:      2,
+			Tolerations:       []corev1.Toleration{{Key: "storage", Operator: corev1.TolerationOpExists}},
+			SymlinkDir:        "/var/local-storage",
+		},
+	}
+	ds = getDiskMakerDS(config)
+	container = ds.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "registry.example.com/diskmaker:latest", container.Image)
+	assert.Equal(t, corev1.PullAlways, container.ImagePullPolicy)
+	assert.Equal(t, []string{"lv-manager", "--v=2"}, container.Args)
+	assert.Equal(t, config.Spec.DiskMaker.Resources, container.Resources)
+	assert.Equal(t, appsv1.OnDeleteDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+	assert.Equal(t, config.Spec.ImagePullSecrets, ds.Spec.Template.Spec.ImagePullSecrets)
+	assert.Equal(t, "system-node-critical", ds.Spec.Template.Spec.PriorityClassName)
+	assert.Equal(t, []string{"lv", "storage"}, []string{ds.Spec.Template.Spec.Tolerations[0].Key, ds.Spec.Template.Spec.Tolerations[1].Key})
+	hostPath, mountPath, env = getSymlinkDirs(ds)
+	assert.Equal(t, "/var/local-storage", hostPath)
+	assert.Equal(t, "/var/local-storage", mountPath)
+	assert.Equal(t, "/var/local-storage", env)
+	assert.Equal(t, common.GetLocalDiskLocationPath(), common.SymlinkHostDirVolume.HostPath.Path, "the shared volume definition is not modified")
+}
@@ -3,12 +3,15 @@ package nodedaemon
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/common"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -54,6 +57,41 @@ func CreateOrUpdateDaemonset(
 // ds.Spec.MinReadySeconds
 // ds.Spec.RevisionHistoryLimit
 
+var (
+	hostPathDirectoryOrCreate = corev1.HostPathDirectoryOrCreate
+	bidirectionalPropagation  = corev1.MountPropagationBidirectional
+)
+
+// getEscrowDirs returns the sorted host directories that the encryption keys of lvSets and lvs are escrowed to.
+// The directories outside of common.EscrowHostDirPrefix are never mounted.
+func getEscrowDirs(lvSets []localv1.LocalVolumeSet, lvs []localv1.LocalVolume) []string {
+	escrowDirs := sets.NewString()
+	insert := func(encryption *localv1.EncryptionSpec) {
+		if encryption != nil && encryption.Escrow != nil && encryption.Escrow.HostDir != "" && common.IsAllowedEscrowHostDir(encryption.Escrow.HostDir) {
+			escrowDirs.Insert(filepath.Clean(encryption.Escrow.HostDir))
+		}
+	}
+	for _, lvSet := range lvSets {
+		insert(lvSet.Spec.Encryption)
+	}
+	for _, lv := range lvs {
+		for _, devices := range lv.Spec.StorageClassDevices {
+			insert(devices.Encryption)
+		}
+	}
+	return escrowDirs.List()
+}
+
+// hasSharedFilesystems returns true if one of the lvSets publishes directories of a shared filesystem
+func hasSharedFilesystems(lvSets []localv1.LocalVolumeSet) bool {
+	for _, lvSet := range lvSets {
+		if lvSet.Spec.SharedFilesystem != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // Diskmaker Daemonset
 // to be consumed by createOrUpdateDaemonset
 func getDiskMakerDSMutateFn(
@@ -62,6 +100,9 @@ func getDiskMakerDSMutateFn(
 	ownerRefs []metav1.OwnerReference,
 	nodeSelector *corev1.NodeSelector,
 	dataHash string,
+	escrowDirs []string,
+	sharedFilesystems bool,
+	config *localv1.LocalStorageOperatorConfig,
 ) func(*appsv1.DaemonSet) error {
 	maxUnavailable := intstr.FromString("10%")
 
@@ -84,6 +125,32 @@ func getDiskMakerDSMutateFn(
 			return fmt.Errorf("can't add volumeMount to container, the daemonset has not specified any containers: %+v", ds)
 		}
 		ds.Spec.Template.Spec.Containers[0].VolumeMounts = append(ds.Spec.Template.Spec.Containers[0].VolumeMounts, common.UDevMount)
+		// bind mount the host's udev rules, the persistent links of the devices without any are created with udev rules
+		ds.Spec.Template.Spec.Volumes = append(ds.Spec.Template.Spec.Volumes, common.UDevRulesHostDirVolume)
+		ds.Spec.Template.Spec.Containers[0].VolumeMounts = append(ds.Spec.Template.Spec.Containers[0].VolumeMounts, common.UDevRulesMount)
+		// bind mount the host directories that the encryption keys are escrowed to
+		for i, escrowDir := range escrowDirs {
+			volumeName := fmt.Sprintf("key-escrow-%d", i)
+			ds.Spec.Template.Spec.Volumes = append(ds.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: volumeName,
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: escrowDir, Type: &hostPathDirectoryOrCreate},
+				},
+			})
+			ds.Spec.Template.Spec.Containers[0].VolumeMounts = append(ds.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: escrowDir,
+			})
+		}
+		// the shared filesystems and their directory PVs are mounted by the diskmaker, the mounts must propagate to the host
+		if sharedFilesystems {
+			volumeMounts := ds.Spec.Template.Spec.Containers[0].VolumeMounts
+			for i := range volumeMounts {
+				if volumeMounts[i].Name == common.SymlinkMount.Name {
+					volumeMounts[i].MountPropagation = &bidirectionalPropagation
+				}
+			}
+		}
 		// add provisioner configmap hash
 		initMapIfNil(&ds.ObjectMeta.Annotations)
 		ds.ObjectMeta.Annotations[dataHashAnnotationKey] = dataHash
@@ -101,6 +168,8 @@ func getDiskMakerDSMutateFn(
 		// to read /proc/1/mountinfo
 		ds.Spec.Template.Spec.HostPID = true
 
+		MutateOperatorConfig(ds, config, config.Spec.DiskMaker)
+
 		return nil
 	}
 }
@@ -204,6 +273,11 @@ func MutateAggregatedSpec(
 	// tolerations
 	ds.Spec.Template.Spec.Tolerations = tolerations
 
+	// the daemons listen to the kernel uevents, that reach the pod network namespace.
+	// Reset the host network of the daemonsets that were created with it
+	ds.Spec.Template.Spec.HostNetwork = false
+	ds.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+
 	// nodeSelector if non-nil
 	if nodeSelector != nil {
 		ds.Spec.Template.Spec.Affinity = &corev1.Affinity{
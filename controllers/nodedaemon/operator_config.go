@@ -0,0 +1,85 @@
+package nodedaemon
+
+import (
+	"context"
+	"fmt"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// GetOperatorConfig returns the LocalStorageOperatorConfig of the cluster, or an empty one when it doesn't exist
+func GetOperatorConfig(ctx context.Context, c client.Client) (*localv1.LocalStorageOperatorConfig, error) {
+	config := &localv1.LocalStorageOperatorConfig{}
+	err := c.Get(ctx, types.NamespacedName{Name: localv1.LocalStorageOperatorConfigName}, config)
+	if errors.IsNotFound(err) {
+		return &localv1.LocalStorageOperatorConfig{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get LocalStorageOperatorConfig %q: %w", localv1.LocalStorageOperatorConfigName, err)
+	}
+	return config, nil
+}
+
+// OnlyOperatorConfig filters the events of the LocalStorageOperatorConfigs that are not read by the operator
+var OnlyOperatorConfig = predicate.NewPredicateFuncs(func(obj client.Object) bool {
+	return obj.GetName() == localv1.LocalStorageOperatorConfigName
+})
+
+// GetSymlinkDir returns the host directory of the symlinks configured in config
+func GetSymlinkDir(config *localv1.LocalStorageOperatorConfig) string {
+	if config.Spec.SymlinkDir != "" {
+		return config.Spec.SymlinkDir
+	}
+	return common.GetLocalDiskLocationPath()
+}
+
+// MutateOperatorConfig applies the LocalStorageOperatorConfig to a daemonset built by MutateAggregatedSpec,
+// daemonConfig being the settings of this daemon in config.
+// It must be applied last, as it overrides the image, the update strategy and the symlink volume of the daemonset.
+func MutateOperatorConfig(ds *appsv1.DaemonSet, config *localv1.LocalStorageOperatorConfig, daemonConfig localv1.NodeDaemonConfig) {
+	podSpec := &ds.Spec.Template.Spec
+	container := &podSpec.Containers[0]
+
+	if daemonConfig.Image != "" {
+		container.Image = daemonConfig.Image
+	}
+	if config.Spec.ImagePullPolicy != "" {
+		container.ImagePullPolicy = config.Spec.ImagePullPolicy
+	}
+	podSpec.ImagePullSecrets = config.Spec.ImagePullSecrets
+	container.Resources = daemonConfig.Resources
+	if daemonConfig.UpdateStrategy != nil {
+		ds.Spec.UpdateStrategy = *daemonConfig.UpdateStrategy
+	}
+	if config.Spec.PriorityClassName != "" {
+		podSpec.PriorityClassName = config.Spec.PriorityClassName
+	}
+	if config.Spec.LogVerbosity > 0 {
+		container.Args = append(container.Args, fmt.Sprintf("--v=%d", config.Spec.LogVerbosity))
+	}
+	if len(config.Spec.Tolerations) > 0 {
+		tolerations := make([]corev1.Toleration, 0, len(podSpec.Tolerations)+len(config.Spec.Tolerations))
+		tolerations = append(tolerations, podSpec.Tolerations...)
+		podSpec.Tolerations = append(tolerations, config.Spec.Tolerations...)
+	}
+
+	// the daemons find the symlink dir in their env, like the operator
+	symlinkDir := GetSymlinkDir(config)
+	for i := range podSpec.Volumes {
+		if podSpec.Volumes[i].Name == common.SymlinkHostDirVolume.Name {
+			podSpec.Volumes[i].HostPath = &corev1.HostPathVolumeSource{Path: symlinkDir}
+		}
+	}
+	for i := range container.VolumeMounts {
+		if container.VolumeMounts[i].Name == common.SymlinkMount.Name {
+			container.VolumeMounts[i].MountPath = symlinkDir
+		}
+	}
+	container.Env = append(container.Env, corev1.EnvVar{Name: common.LocalDiskLocationEnv, Value: symlinkDir})
+}
@@ -17,16 +17,20 @@ import (
 	localStaticProvisioner "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
 
 	v1 "github.com/openshift/local-storage-operator/api/v1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/common"
 )
 
+// encryptedBlockCleanerCommand closes and erases the LUKS devices of the released PVs,
+// instead of wiping the device-mapper node with the default quick_reset.sh
+var encryptedBlockCleanerCommand = []string{"/scripts/luks_reset.sh"}
+
 func (r *DaemonReconciler) reconcileProvisionerConfigMap(
 	ctx context.Context,
 	request reconcile.Request,
-	lvSets []localv1alpha1.LocalVolumeSet,
+	lvSets []v1.LocalVolumeSet,
 	lvs []v1.LocalVolume,
 	ownerRefs []metav1.OwnerReference,
+	symlinkBaseDir string,
 ) (*corev1.ConfigMap, controllerutil.OperationResult, error) {
 	// object meta
 	objectMeta := metav1.ObjectMeta{
@@ -40,25 +44,31 @@ func (r *DaemonReconciler) reconcileProvisionerConfigMap(
 	storageClassConfig := make(map[string]localStaticProvisioner.MountConfig)
 	for _, lvSet := range lvSets {
 		storageClassName := lvSet.Spec.StorageClassName
-		symlinkDir := path.Join(common.GetLocalDiskLocationPath(), storageClassName)
+		symlinkDir := path.Join(symlinkBaseDir, storageClassName)
 		mountConfig := localStaticProvisioner.MountConfig{
 			FsType:     lvSet.Spec.FSType,
 			HostDir:    symlinkDir,
 			MountDir:   symlinkDir,
 			VolumeMode: string(lvSet.Spec.VolumeMode),
 		}
+		if lvSet.Spec.Encryption != nil {
+			mountConfig.BlockCleanerCommand = encryptedBlockCleanerCommand
+		}
 		storageClassConfig[storageClassName] = mountConfig
 	}
 	for _, lv := range lvs {
 		for _, devices := range lv.Spec.StorageClassDevices {
 			storageClassName := devices.StorageClassName
-			symlinkDir := path.Join(common.GetLocalDiskLocationPath(), storageClassName)
+			symlinkDir := path.Join(symlinkBaseDir, storageClassName)
 			mountConfig := localStaticProvisioner.MountConfig{
 				FsType:     devices.FSType,
 				HostDir:    symlinkDir,
 				MountDir:   symlinkDir,
 				VolumeMode: string(devices.VolumeMode),
 			}
+			if devices.Encryption != nil {
+				mountConfig.BlockCleanerCommand = encryptedBlockCleanerCommand
+			}
 			storageClassConfig[storageClassName] = mountConfig
 		}
 	}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/go-logr/logr"
 	v1 "github.com/openshift/local-storage-operator/api/v1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/common"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -17,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -80,7 +80,12 @@ func (r *DaemonReconciler) Reconcile(ctx context.Context, request ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
-	configMap, opResult, err := r.reconcileProvisionerConfigMap(ctx, request, lvSets.Items, lvs.Items, ownerRefs)
+	config, err := GetOperatorConfig(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	configMap, opResult, err := r.reconcileProvisionerConfigMap(ctx, request, lvSets.Items, lvs.Items, ownerRefs, GetSymlinkDir(config))
 	if err != nil {
 		return ctrl.Result{}, err
 	} else if opResult == controllerutil.OperationResultUpdated || opResult == controllerutil.OperationResultCreated {
@@ -89,7 +94,7 @@ func (r *DaemonReconciler) Reconcile(ctx context.Context, request ctrl.Request)
 
 	configMapDataHash := dataHash(configMap.Data)
 
-	diskMakerDSMutateFn := getDiskMakerDSMutateFn(request, tolerations, ownerRefs, nodeSelector, configMapDataHash)
+	diskMakerDSMutateFn := getDiskMakerDSMutateFn(request, tolerations, ownerRefs, nodeSelector, configMapDataHash, getEscrowDirs(lvSets.Items, lvs.Items), hasSharedFilesystems(lvSets.Items), config)
 	ds, opResult, err := CreateOrUpdateDaemonset(ctx, r.Client, diskMakerDSMutateFn)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -191,14 +196,47 @@ func (r *DaemonReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			}
 			return []reconcile.Request{req}
 		})
+	// the LocalStorageOperatorConfig is cluster-scoped, it is enqueued for each namespace with LocalVolumes or LocalVolumeSets
+	enqueueAllNamespaces := handler.EnqueueRequestsFromMapFunc(
+		func(obj client.Object) []reconcile.Request {
+			return r.getNamespaceRequests(context.TODO())
+		})
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1.LocalVolume{}).
-		Watches(&source.Kind{Type: &localv1alpha1.LocalVolumeSet{}}, enqueueOnlyNamespace).
+		Watches(&source.Kind{Type: &v1.LocalVolumeSet{}}, enqueueOnlyNamespace).
 		// watch provisioner, diskmaker-manager daemonsets
 		Watches(&source.Kind{Type: &appsv1.DaemonSet{}}, enqueueOnlyNamespace, builder.WithPredicates(common.EnqueueOnlyLabeledSubcomponents(DiskMakerName, ProvisionerName))).
 		// watch provisioner configmap
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueOnlyNamespace, builder.WithPredicates(common.EnqueueOnlyLabeledSubcomponents(common.ProvisionerConfigMapName))).
 		Watches(&source.Kind{Type: &v1.LocalVolume{}}, enqueueOnlyNamespace).
+		Watches(&source.Kind{Type: &v1.LocalStorageOperatorConfig{}}, enqueueAllNamespaces, builder.WithPredicates(OnlyOperatorConfig)).
 		Complete(r)
 }
+
+// getNamespaceRequests returns a request for each namespace that has LocalVolumes or LocalVolumeSets
+func (r *DaemonReconciler) getNamespaceRequests(ctx context.Context) []reconcile.Request {
+	namespaces := sets.NewString()
+	lvSets := &v1.LocalVolumeSetList{}
+	err := r.Client.List(ctx, lvSets)
+	if err != nil {
+		log.Error(err, "could not list LocalVolumeSets")
+	}
+	for _, lvSet := range lvSets.Items {
+		namespaces.Insert(lvSet.Namespace)
+	}
+	lvs := &v1.LocalVolumeList{}
+	err = r.Client.List(ctx, lvs)
+	if err != nil {
+		log.Error(err, "could not list LocalVolumes")
+	}
+	for _, lv := range lvs.Items {
+		namespaces.Insert(lv.Namespace)
+	}
+
+	requests := make([]reconcile.Request, 0, namespaces.Len())
+	for _, namespace := range namespaces.List() {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace}})
+	}
+	return requests
+}
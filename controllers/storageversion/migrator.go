@@ -0,0 +1,111 @@
+package storageversion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// retryInterval is the interval between the attempts of a failed migration
+const retryInterval = time.Minute
+
+// migratedResources are the CustomResourceDefinitions whose objects were stored as v1alpha1, with the list of their objects
+var migratedResources = map[string]func() client.ObjectList{
+	"localvolumesets.local.storage.openshift.io":             func() client.ObjectList { return &localv1.LocalVolumeSetList{} },
+	"localvolumediscoveries.local.storage.openshift.io":      func() client.ObjectList { return &localv1.LocalVolumeDiscoveryList{} },
+	"localvolumediscoveryresults.local.storage.openshift.io": func() client.ObjectList { return &localv1.LocalVolumeDiscoveryResultList{} },
+	"localstorageoperatorconfigs.local.storage.openshift.io": func() client.ObjectList { return &localv1.LocalStorageOperatorConfigList{} },
+}
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update
+//+kubebuilder:rbac:groups=local.storage.openshift.io,resources=localstorageoperatorconfigs,verbs=update
+
+// Migrator rewrites the objects stored as v1alpha1 in the storage version v1,
+// then removes v1alpha1 from the stored versions of their CustomResourceDefinition
+// so that it can be dropped from the served versions in a later release.
+type Migrator struct {
+	Client client.Client
+	// APIReader reads the CustomResourceDefinitions and the objects from the API server, there is no need to cache them
+	APIReader client.Reader
+	Log       logr.Logger
+}
+
+var _ manager.LeaderElectionRunnable = &Migrator{}
+
+// SetupWithManager adds the Migrator to the runnables of the manager
+func (m *Migrator) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(m)
+}
+
+// NeedLeaderElection runs the migration on the leader only
+func (m *Migrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start migrates the objects of each CustomResourceDefinition, retrying until it succeeds or the manager stops
+func (m *Migrator) Start(ctx context.Context) error {
+	for crdName, newList := range migratedResources {
+		crdName, newList := crdName, newList
+		err := wait.PollImmediateUntil(retryInterval, func() (bool, error) {
+			if err := m.migrate(ctx, crdName, newList()); err != nil {
+				m.Log.Error(err, "could not migrate the storage version", "customResourceDefinition", crdName)
+				return false, nil
+			}
+			return true, nil
+		}, ctx.Done())
+		if err == wait.ErrWaitTimeout {
+			// the manager is stopping
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, crdName string, list client.ObjectList) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	err := m.APIReader.Get(ctx, client.ObjectKey{Name: crdName}, crd)
+	if err != nil {
+		return fmt.Errorf("could not get the CustomResourceDefinition: %w", err)
+	}
+	storedVersions := crd.Status.StoredVersions
+	if len(storedVersions) == 1 && storedVersions[0] == localv1.GroupVersion.Version {
+		return nil
+	}
+
+	err = m.APIReader.List(ctx, list)
+	if err != nil {
+		return fmt.Errorf("could not list the objects: %w", err)
+	}
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, o := range objects {
+		obj := o.(client.Object)
+		// an update without changes is enough to write the object in the storage version
+		err = m.Client.Update(ctx, obj)
+		// a conflicting update or a deletion has already rewritten the object
+		if err != nil && !kerrors.IsConflict(err) && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("could not rewrite %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+
+	crd.Status.StoredVersions = []string{localv1.GroupVersion.Version}
+	err = m.Client.Status().Update(ctx, crd)
+	if err != nil {
+		return fmt.Errorf("could not update the stored versions: %w", err)
+	}
+	m.Log.Info("migrated the storage version", "customResourceDefinition", crdName, "objects", len(objects), "previousStoredVersions", storedVersions)
+	return nil
+}
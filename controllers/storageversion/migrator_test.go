@@ -0,0 +1,58 @@
+package storageversion
+
+import (
+	"context"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newCRD(name string, storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, localv1.AddToScheme(scheme))
+	assert.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	lvset := &localv1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "lvset", Namespace: "openshift-local-storage"}}
+	config := &localv1.LocalStorageOperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: localv1.LocalStorageOperatorConfigName}}
+	fakeClient := fake.NewFakeClientWithScheme(scheme,
+		newCRD("localvolumesets.local.storage.openshift.io", "v1alpha1", "v1"),
+		newCRD("localvolumediscoveries.local.storage.openshift.io", "v1"),
+		newCRD("localvolumediscoveryresults.local.storage.openshift.io", "v1alpha1"),
+		newCRD("localstorageoperatorconfigs.local.storage.openshift.io", "v1alpha1"),
+		lvset,
+		config,
+	)
+	stored := &localv1.LocalVolumeSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(lvset), stored))
+	storedConfig := &localv1.LocalStorageOperatorConfig{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(config), storedConfig))
+	m := &Migrator{Client: fakeClient, APIReader: fakeClient, Log: logf.Log.WithName("test")}
+	assert.NoError(t, m.Start(context.TODO()))
+
+	for crdName := range migratedResources {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: crdName}, crd))
+		assert.Equal(t, []string{"v1"}, crd.Status.StoredVersions, crdName)
+	}
+	// the LocalVolumeSet has been rewritten
+	updated := &localv1.LocalVolumeSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(lvset), updated))
+	assert.NotEqual(t, stored.ResourceVersion, updated.ResourceVersion)
+	// and so has the cluster-scoped LocalStorageOperatorConfig
+	updatedConfig := &localv1.LocalStorageOperatorConfig{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(config), updatedConfig))
+	assert.NotEqual(t, storedConfig.ResourceVersion, updatedConfig.ResourceVersion)
+}
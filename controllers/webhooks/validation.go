@@ -0,0 +1,270 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/controllers/nodedaemon"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+const (
+	// LocalVolumeValidationPath is the path the LocalVolume validating webhook is served on
+	LocalVolumeValidationPath = "/validate-local-storage-openshift-io-v1-localvolume"
+	// LocalVolumeSetValidationPath is the path the LocalVolumeSet validating webhook is served on
+	LocalVolumeSetValidationPath = "/validate-local-storage-openshift-io-v1-localvolumeset"
+	// LocalStorageOperatorConfigValidationPath is the path the LocalStorageOperatorConfig validating webhook is served on
+	LocalStorageOperatorConfigValidationPath = "/validate-local-storage-openshift-io-v1-localstorageoperatorconfig"
+	// ConversionPath is the path the conversion webhook of the LocalVolumeSets, LocalVolumeDiscoveries,
+	// LocalVolumeDiscoveryResults and LocalStorageOperatorConfigs is served on
+	ConversionPath = "/convert"
+)
+
+//+kubebuilder:webhook:path=/validate-local-storage-openshift-io-v1-localvolume,mutating=false,failurePolicy=fail,sideEffects=None,groups=local.storage.openshift.io,resources=localvolumes,verbs=create;update,versions=v1,name=vlocalvolume.local.storage.openshift.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-local-storage-openshift-io-v1-localvolumeset,mutating=false,failurePolicy=fail,sideEffects=None,groups=local.storage.openshift.io,resources=localvolumesets,verbs=create;update,versions=v1,name=vlocalvolumeset.local.storage.openshift.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-local-storage-openshift-io-v1-localstorageoperatorconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=local.storage.openshift.io,resources=localstorageoperatorconfigs,verbs=create;update;delete,versions=v1,name=vlocalstorageoperatorconfig.local.storage.openshift.io,admissionReviewVersions=v1
+
+//+kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list
+
+// SetupWithManager registers the validating webhooks of the LocalVolumes, LocalVolumeSets and LocalStorageOperatorConfigs,
+// and the conversion webhook, on the webhook server of the manager.
+// The conversion webhook converts the v1alpha1 objects of the scheme through their v1 hub.
+// The validating webhooks read the owners of the storage classes in all the namespaces, and the PVs,
+// that the cache of the manager doesn't hold.
+func SetupWithManager(mgr ctrl.Manager) {
+	server := mgr.GetWebhookServer()
+	server.Register(LocalVolumeValidationPath, &webhook.Admission{Handler: &LocalVolumeValidator{Client: mgr.GetAPIReader()}})
+	server.Register(LocalVolumeSetValidationPath, &webhook.Admission{Handler: &LocalVolumeSetValidator{Client: mgr.GetAPIReader()}})
+	server.Register(LocalStorageOperatorConfigValidationPath, &webhook.Admission{Handler: &LocalStorageOperatorConfigValidator{Client: mgr.GetAPIReader()}})
+	server.Register(ConversionPath, &conversion.Webhook{})
+}
+
+// LocalVolumeValidator rejects the LocalVolumes with an invalid spec,
+// or that provision a storage class of another LocalVolume or LocalVolumeSet with a different volume mode.
+// The updates are only rejected for the errors the LocalVolume didn't have yet.
+type LocalVolumeValidator struct {
+	Client  client.Reader
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &LocalVolumeValidator{}
+
+// Handle validates the LocalVolume of the request
+func (v *LocalVolumeValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	lv := &localv1.LocalVolume{}
+	err := v.decoder.Decode(req, lv)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// the spec of a LocalVolume being deleted is not acted on anymore
+	if lv.DeletionTimestamp != nil {
+		return admission.Allowed("")
+	}
+
+	allErrs, err := validateLocalVolume(ctx, v.Client, lv)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	// the objects admitted before a rule was added are still updated, as long as they don't break another rule
+	if req.Operation == admissionv1.Update {
+		oldLV := &localv1.LocalVolume{}
+		err = v.decoder.DecodeRaw(req.OldObject, oldLV)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		oldErrs, err := validateLocalVolume(ctx, v.Client, oldLV)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		allErrs = withoutErrors(allErrs, oldErrs)
+	}
+	return validationResponse(localv1.GroupVersion.WithKind(localv1.LocalVolumeKind).GroupKind(), lv.Name, allErrs)
+}
+
+// InjectDecoder injects the decoder of the webhook server
+func (v *LocalVolumeValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// LocalVolumeSetValidator rejects the LocalVolumeSets with an invalid spec,
+// or that provision a storage class of another LocalVolume or LocalVolumeSet with a different volume mode.
+// The updates are only rejected for the errors the LocalVolumeSet didn't have yet.
+type LocalVolumeSetValidator struct {
+	Client  client.Reader
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &LocalVolumeSetValidator{}
+
+// Handle validates the LocalVolumeSet of the request
+func (v *LocalVolumeSetValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	lvset := &localv1.LocalVolumeSet{}
+	err := v.decoder.Decode(req, lvset)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// the spec of a LocalVolumeSet being deleted is not acted on anymore
+	if lvset.DeletionTimestamp != nil {
+		return admission.Allowed("")
+	}
+
+	allErrs, err := validateLocalVolumeSet(ctx, v.Client, lvset)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	// the objects admitted before a rule was added are still updated, as long as they don't break another rule
+	if req.Operation == admissionv1.Update {
+		oldLVSet := &localv1.LocalVolumeSet{}
+		err = v.decoder.DecodeRaw(req.OldObject, oldLVSet)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		oldErrs, err := validateLocalVolumeSet(ctx, v.Client, oldLVSet)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		allErrs = withoutErrors(allErrs, oldErrs)
+	}
+	return validationResponse(localv1.GroupVersion.WithKind(localv1.LocalVolumeSetKind).GroupKind(), lvset.Name, allErrs)
+}
+
+// InjectDecoder injects the decoder of the webhook server
+func (v *LocalVolumeSetValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// LocalStorageOperatorConfigValidator rejects the creations, updates and deletions of the LocalStorageOperatorConfig
+// that change the symlink dir while local PVs have their symlinks in it: the daemons would not mount it anymore,
+// and could neither clean up nor delete those PVs once they are released.
+type LocalStorageOperatorConfigValidator struct {
+	Client  client.Reader
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &LocalStorageOperatorConfigValidator{}
+
+// Handle validates the LocalStorageOperatorConfig of the request
+func (v *LocalStorageOperatorConfigValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	// the symlink dir defaults to the one of the operator when there is no config
+	oldConfig := &localv1.LocalStorageOperatorConfig{}
+	config := &localv1.LocalStorageOperatorConfig{}
+	if req.Operation != admissionv1.Create {
+		err := v.decoder.DecodeRaw(req.OldObject, oldConfig)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	if req.Operation != admissionv1.Delete {
+		err := v.decoder.Decode(req, config)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	if req.Name != localv1.LocalStorageOperatorConfigName {
+		return admission.Allowed("")
+	}
+
+	oldDir := nodedaemon.GetSymlinkDir(oldConfig)
+	if oldDir == nodedaemon.GetSymlinkDir(config) {
+		return admission.Allowed("")
+	}
+	pv, err := findPVInDir(ctx, v.Client, oldDir)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	allErrs := field.ErrorList{}
+	if pv != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "symlinkDir"),
+			fmt.Sprintf("the symlinks of local PVs such as %s are in %s, it can't be changed until they are deleted", pv.Name, oldDir)))
+	}
+	return validationResponse(localv1.GroupVersion.WithKind(localv1.LocalStorageOperatorConfigKind).GroupKind(), req.Name, allErrs)
+}
+
+// InjectDecoder injects the decoder of the webhook server
+func (v *LocalStorageOperatorConfigValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// findPVInDir returns a local PV whose path is in dir, or nil if there is none
+func findPVInDir(ctx context.Context, c client.Reader, dir string) (*corev1.PersistentVolume, error) {
+	pvs := &corev1.PersistentVolumeList{}
+	err := c.List(ctx, pvs)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the PVs: %w", err)
+	}
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	for i := range pvs.Items {
+		local := pvs.Items[i].Spec.Local
+		if local != nil && strings.HasPrefix(filepath.Clean(local.Path), prefix) {
+			return &pvs.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// validateLocalVolume returns the errors of the spec of the LocalVolume and of the owners of its storage classes
+func validateLocalVolume(ctx context.Context, c client.Reader, lv *localv1.LocalVolume) (field.ErrorList, error) {
+	allErrs := common.ValidateLocalVolume(lv)
+	ownerErrs, err := common.ValidateStorageClassOwners(ctx, c, lv)
+	if err != nil {
+		return nil, err
+	}
+	return append(allErrs, ownerErrs...), nil
+}
+
+// validateLocalVolumeSet returns the errors of the spec of the LocalVolumeSet and of the owners of its storage class
+func validateLocalVolumeSet(ctx context.Context, c client.Reader, lvset *localv1.LocalVolumeSet) (field.ErrorList, error) {
+	allErrs := common.ValidateLocalVolumeSet(lvset)
+	ownerErrs, err := common.ValidateStorageClassOwners(ctx, c, lvset)
+	if err != nil {
+		return nil, err
+	}
+	return append(allErrs, ownerErrs...), nil
+}
+
+// withoutErrors returns the errors of allErrs that are not in oldErrs
+func withoutErrors(allErrs, oldErrs field.ErrorList) field.ErrorList {
+	old := sets.NewString()
+	for _, err := range oldErrs {
+		old.Insert(err.Error())
+	}
+	newErrs := field.ErrorList{}
+	for _, err := range allErrs {
+		if !old.Has(err.Error()) {
+			newErrs = append(newErrs, err)
+		}
+	}
+	return newErrs
+}
+
+// validationResponse denies the request with an Invalid status listing the errors, if any
+func validationResponse(groupKind schema.GroupKind, name string, allErrs field.ErrorList) admission.Response {
+	if len(allErrs) == 0 {
+		return admission.Allowed("")
+	}
+	status := kerrors.NewInvalid(groupKind, name, allErrs).Status()
+	return admission.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result:  &status,
+		},
+	}
+}
@@ -0,0 +1,150 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newRequest(t *testing.T, obj runtime.Object) admission.Request {
+	raw, err := json.Marshal(obj)
+	assert.NoError(t, err)
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func TestValidators(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, localv1.AddToScheme(scheme))
+	assert.NoError(t, localv1alpha1.AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	assert.NoError(t, err)
+	existing := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "block", Namespace: "openshift-local-storage"},
+		Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "block", VolumeMode: localv1.PersistentVolumeBlock},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, existing)
+
+	lvValidator := &LocalVolumeValidator{Client: client}
+	assert.NoError(t, lvValidator.InjectDecoder(decoder))
+	lvsetValidator := &LocalVolumeSetValidator{Client: client}
+	assert.NoError(t, lvsetValidator.InjectDecoder(decoder))
+
+	lv := &localv1.LocalVolume{
+		TypeMeta:   metav1.TypeMeta{APIVersion: localv1.GroupVersion.String(), Kind: localv1.LocalVolumeKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "disks", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSpec{StorageClassDevices: []localv1.StorageClassDevice{
+			{StorageClassName: "fast", DevicePaths: []string{"/dev/sdb"}},
+		}},
+	}
+	response := lvValidator.Handle(context.TODO(), newRequest(t, lv))
+	assert.True(t, response.Allowed, "valid LocalVolume: %+v", response.Result)
+
+	lv.Spec.StorageClassDevices = append(lv.Spec.StorageClassDevices, localv1.StorageClassDevice{StorageClassName: "block", DevicePaths: []string{"/tmp/sdc"}})
+	response = lvValidator.Handle(context.TODO(), newRequest(t, lv))
+	assert.False(t, response.Allowed)
+	assert.Equal(t, metav1.StatusReasonInvalid, response.Result.Reason)
+	assert.Contains(t, response.Result.Message, `spec.storageClassDevices[1].devicePaths[0]: Invalid value: "/tmp/sdc": expected a path in /dev/`)
+	assert.Contains(t, response.Result.Message, "storage class is provisioned with volumeMode Block by LocalVolumeSet openshift-local-storage/block")
+
+	// a LocalVolume admitted before a rule was added can be updated, unless it breaks another rule
+	update := newRequest(t, lv)
+	update.Operation = admissionv1.Update
+	update.OldObject = update.Object
+	response = lvValidator.Handle(context.TODO(), update)
+	assert.True(t, response.Allowed, "unchanged errors: %+v", response.Result)
+	lv.Spec.StorageClassDevices[0].DevicePaths = []string{"/tmp/sdb"}
+	update.Object = newRequest(t, lv).Object
+	response = lvValidator.Handle(context.TODO(), update)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, `spec.storageClassDevices[0].devicePaths[0]: Invalid value: "/tmp/sdb"`)
+	assert.NotContains(t, response.Result.Message, "/tmp/sdc")
+
+	lvset := &localv1.LocalVolumeSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: localv1.GroupVersion.String(), Kind: localv1.LocalVolumeSetKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "more-block", Namespace: "openshift-local-storage"},
+		Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "block", VolumeMode: localv1.PersistentVolumeBlock},
+	}
+	response = lvsetValidator.Handle(context.TODO(), newRequest(t, lvset))
+	assert.True(t, response.Allowed, "valid LocalVolumeSet: %+v", response.Result)
+
+	lvset.Spec.FSType = "xfs"
+	response = lvsetValidator.Handle(context.TODO(), newRequest(t, lvset))
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, `spec.fsType: Invalid value: "xfs": fsType can not be set with volumeMode Block`)
+}
+
+func TestLocalStorageOperatorConfigValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, localv1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	assert.NoError(t, err)
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv-1"},
+		Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+			Local: &corev1.LocalVolumeSource{Path: "/mnt/local-storage/fast/wwn-0x1"},
+		}},
+	}
+	validator := &LocalStorageOperatorConfigValidator{Client: fake.NewFakeClientWithScheme(scheme, pv)}
+	assert.NoError(t, validator.InjectDecoder(decoder))
+
+	config := &localv1.LocalStorageOperatorConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: localv1.GroupVersion.String(), Kind: localv1.LocalStorageOperatorConfigKind},
+		ObjectMeta: metav1.ObjectMeta{Name: localv1.LocalStorageOperatorConfigName},
+		Spec:       localv1.LocalStorageOperatorConfigSpec{LogVerbosity: 2},
+	}
+	create := newRequest(t, config)
+	create.Name = config.Name
+	response := validator.Handle(context.TODO(), create)
+	assert.True(t, response.Allowed, "default symlink dir: %+v", response.Result)
+
+	// the PV has its symlink in the default dir
+	config.Spec.SymlinkDir = "/var/local-storage"
+	create = newRequest(t, config)
+	create.Name = config.Name
+	response = validator.Handle(context.TODO(), create)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, "spec.symlinkDir: Forbidden: the symlinks of local PVs such as local-pv-1 are in /mnt/local-storage")
+
+	// and can't be moved back to it by a deletion of the config either
+	pv.Spec.Local.Path = "/var/local-storage/fast/wwn-0x1"
+	validator.Client = fake.NewFakeClientWithScheme(scheme, pv)
+	deletion := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Delete,
+		Name:      config.Name,
+		OldObject: create.Object,
+	}}
+	response = validator.Handle(context.TODO(), deletion)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, "local-pv-1 are in /var/local-storage")
+
+	// the other settings can still be updated
+	update := newRequest(t, config)
+	update.Name = config.Name
+	update.Operation = admissionv1.Update
+	update.OldObject = create.Object
+	config.Spec.LogVerbosity = 4
+	update.Object = newRequest(t, config).Object
+	response = validator.Handle(context.TODO(), update)
+	assert.True(t, response.Allowed, "unchanged symlink dir: %+v", response.Result)
+
+	// the configs with another name are not read by the operator
+	config.Name = "other"
+	create = newRequest(t, config)
+	create.Name = config.Name
+	response = validator.Handle(context.TODO(), create)
+	assert.True(t, response.Allowed, "other config: %+v", response.Result)
+}
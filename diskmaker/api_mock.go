@@ -2,18 +2,21 @@ package diskmaker
 
 import (
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
-	"github.com/openshift/local-storage-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // MockAPIUpdater mocks all the ApiUpdater Commands
 type MockAPIUpdater struct {
 	events                          []*DiskEvent
-	MockGetDiscoveryResult          func(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error)
-	MockCreateDiscoveryResult       func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
-	MockUpdateDiscoveryResultStatus func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
-	MockUpdateDiscoveryResult       func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
-	MockGetLocalVolumeDiscovery     func(name, namespace string) (*v1alpha1.LocalVolumeDiscovery, error)
+	MockGetDiscoveryResult          func(name, namespace string) (*localv1.LocalVolumeDiscoveryResult, error)
+	MockCreateDiscoveryResult       func(lvdr *localv1.LocalVolumeDiscoveryResult) error
+	MockUpdateDiscoveryResultStatus func(lvdr *localv1.LocalVolumeDiscoveryResult) error
+	MockUpdateDiscoveryResult       func(lvdr *localv1.LocalVolumeDiscoveryResult) error
+	MockWatchDiscoveryResult        func(name, namespace string) (watch.Interface, error)
+	MockGetLocalVolumeDiscovery     func(name, namespace string) (*localv1.LocalVolumeDiscovery, error)
+	MockGetNode                     func(name string) (*corev1.Node, error)
 }
 
 var _ ApiUpdater = &MockAPIUpdater{}
@@ -27,16 +30,16 @@ func (f *MockAPIUpdater) getLocalVolume(lv *localv1.LocalVolume) (*localv1.Local
 }
 
 // GetDiscoveryResult mocks GetDiscoveryResult
-func (f *MockAPIUpdater) GetDiscoveryResult(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error) {
+func (f *MockAPIUpdater) GetDiscoveryResult(name, namespace string) (*localv1.LocalVolumeDiscoveryResult, error) {
 	if f.MockGetDiscoveryResult != nil {
 		return f.MockGetDiscoveryResult(name, namespace)
 	}
 
-	return &v1alpha1.LocalVolumeDiscoveryResult{}, nil
+	return &localv1.LocalVolumeDiscoveryResult{}, nil
 }
 
 // CreateDiscoveryResult mocks CreateDiscoveryResult
-func (f *MockAPIUpdater) CreateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+func (f *MockAPIUpdater) CreateDiscoveryResult(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 	if f.MockCreateDiscoveryResult != nil {
 		return f.MockCreateDiscoveryResult(lvdr)
 	}
@@ -45,7 +48,7 @@ func (f *MockAPIUpdater) CreateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscove
 }
 
 // UpdateDiscoveryResultStatus mocks UpdateDiscoveryResultStatus
-func (f *MockAPIUpdater) UpdateDiscoveryResultStatus(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+func (f *MockAPIUpdater) UpdateDiscoveryResultStatus(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 	if f.MockUpdateDiscoveryResultStatus != nil {
 		return f.MockUpdateDiscoveryResultStatus(lvdr)
 	}
@@ -54,7 +57,7 @@ func (f *MockAPIUpdater) UpdateDiscoveryResultStatus(lvdr *v1alpha1.LocalVolumeD
 }
 
 // UpdateDiscoveryResult mocks UpdateDiscoveryResult
-func (f *MockAPIUpdater) UpdateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+func (f *MockAPIUpdater) UpdateDiscoveryResult(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 	if f.MockUpdateDiscoveryResult != nil {
 		return f.MockUpdateDiscoveryResult(lvdr)
 	}
@@ -62,11 +65,29 @@ func (f *MockAPIUpdater) UpdateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscove
 	return nil
 }
 
+// WatchDiscoveryResult mocks WatchDiscoveryResult
+func (f *MockAPIUpdater) WatchDiscoveryResult(name, namespace string) (watch.Interface, error) {
+	if f.MockWatchDiscoveryResult != nil {
+		return f.MockWatchDiscoveryResult(name, namespace)
+	}
+
+	return watch.NewFake(), nil
+}
+
 // GetLocalVolumeDiscovery mocks GetLocalVolumeDiscovery
-func (f *MockAPIUpdater) GetLocalVolumeDiscovery(name, namespace string) (*v1alpha1.LocalVolumeDiscovery, error) {
+func (f *MockAPIUpdater) GetLocalVolumeDiscovery(name, namespace string) (*localv1.LocalVolumeDiscovery, error) {
 	if f.MockGetLocalVolumeDiscovery != nil {
 		return f.MockGetLocalVolumeDiscovery(name, namespace)
 	}
 
-	return &v1alpha1.LocalVolumeDiscovery{}, nil
+	return &localv1.LocalVolumeDiscovery{}, nil
+}
+
+// GetNode mocks GetNode
+func (f *MockAPIUpdater) GetNode(name string) (*corev1.Node, error) {
+	if f.MockGetNode != nil {
+		return f.MockGetNode(name)
+	}
+
+	return &corev1.Node{}, nil
 }
@@ -6,12 +6,12 @@ import (
 	"os"
 
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
-	"github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/prometheus/common/log"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -25,18 +25,20 @@ const componentName = "local-storage-diskmaker"
 type ApiUpdater interface {
 	recordEvent(obj runtime.Object, e *DiskEvent)
 	getLocalVolume(lv *localv1.LocalVolume) (*localv1.LocalVolume, error)
-	CreateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
-	GetDiscoveryResult(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error)
-	UpdateDiscoveryResultStatus(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
-	UpdateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
-	GetLocalVolumeDiscovery(name, namespace string) (*v1alpha1.LocalVolumeDiscovery, error)
+	CreateDiscoveryResult(lvdr *localv1.LocalVolumeDiscoveryResult) error
+	GetDiscoveryResult(name, namespace string) (*localv1.LocalVolumeDiscoveryResult, error)
+	UpdateDiscoveryResultStatus(lvdr *localv1.LocalVolumeDiscoveryResult) error
+	UpdateDiscoveryResult(lvdr *localv1.LocalVolumeDiscoveryResult) error
+	WatchDiscoveryResult(name, namespace string) (watch.Interface, error)
+	GetLocalVolumeDiscovery(name, namespace string) (*localv1.LocalVolumeDiscovery, error)
+	GetNode(name string) (*v1.Node, error)
 }
 
 type sdkAPIUpdater struct {
 	recorder record.EventRecorder
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
+	client client.WithWatch
 }
 
 func NewAPIUpdater(scheme *runtime.Scheme) (ApiUpdater, error) {
@@ -51,7 +53,7 @@ func NewAPIUpdater(scheme *runtime.Scheme) (ApiUpdater, error) {
 		log.Error(err, "failed to get rest.config")
 		return &sdkAPIUpdater{}, err
 	}
-	crClient, err := client.New(config, client.Options{})
+	crClient, err := client.NewWithWatch(config, client.Options{})
 	if err != nil {
 		log.Error(err, "failed to create controller-runtime client")
 		return &sdkAPIUpdater{}, err
@@ -98,26 +100,37 @@ func (s *sdkAPIUpdater) getLocalVolume(lv *localv1.LocalVolume) (*localv1.LocalV
 	return lv, err
 }
 
-func (s *sdkAPIUpdater) GetDiscoveryResult(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error) {
-	discoveryResult := &v1alpha1.LocalVolumeDiscoveryResult{}
+func (s *sdkAPIUpdater) GetDiscoveryResult(name, namespace string) (*localv1.LocalVolumeDiscoveryResult, error) {
+	discoveryResult := &localv1.LocalVolumeDiscoveryResult{}
 	err := s.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, discoveryResult)
 	return discoveryResult, err
 }
 
-func (s *sdkAPIUpdater) CreateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+func (s *sdkAPIUpdater) CreateDiscoveryResult(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 	return s.client.Create(context.TODO(), lvdr)
 }
 
-func (s *sdkAPIUpdater) UpdateDiscoveryResultStatus(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+func (s *sdkAPIUpdater) UpdateDiscoveryResultStatus(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 	return s.client.Status().Update(context.TODO(), lvdr)
 }
 
-func (s *sdkAPIUpdater) UpdateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+func (s *sdkAPIUpdater) UpdateDiscoveryResult(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 	return s.client.Update(context.TODO(), lvdr)
 }
 
-func (s *sdkAPIUpdater) GetLocalVolumeDiscovery(name, namespace string) (*v1alpha1.LocalVolumeDiscovery, error) {
-	discoveryCR := &v1alpha1.LocalVolumeDiscovery{}
+func (s *sdkAPIUpdater) WatchDiscoveryResult(name, namespace string) (watch.Interface, error) {
+	return s.client.Watch(context.TODO(), &localv1.LocalVolumeDiscoveryResultList{},
+		client.InNamespace(namespace), client.MatchingFields{"metadata.name": name})
+}
+
+func (s *sdkAPIUpdater) GetLocalVolumeDiscovery(name, namespace string) (*localv1.LocalVolumeDiscovery, error) {
+	discoveryCR := &localv1.LocalVolumeDiscovery{}
 	err := s.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, discoveryCR)
 	return discoveryCR, err
 }
+
+func (s *sdkAPIUpdater) GetNode(name string) (*v1.Node, error) {
+	node := &v1.Node{}
+	err := s.client.Get(context.TODO(), types.NamespacedName{Name: name}, node)
+	return node, err
+}
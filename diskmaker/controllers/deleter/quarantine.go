@@ -0,0 +1,66 @@
+package deleter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// syncQuarantinedPVs labels the PVs whose cleanup is quarantined with PVQuarantinedLabel, and reports their quarantine once.
+// The PVs whose label is removed afterwards are released from quarantine, the deleter cleans them up again.
+func syncQuarantinedPVs(ctx context.Context, c client.Client, store *state.Store, recorder record.EventRecorder) error {
+	log := logf.Log.WithName(ComponentName)
+	for _, pvName := range store.QuarantinedPVs() {
+		pv := &corev1.PersistentVolume{}
+		err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv)
+		if errors.IsNotFound(err) {
+			// the device of a deleted PV stays quarantined, it is not offered again
+			continue
+		} else if err != nil {
+			return fmt.Errorf("could not get quarantined PV %q: %w", pvName, err)
+		}
+		cleanup, _ := store.GetCleanup(pvName)
+		_, labelled := pv.Labels[common.PVQuarantinedLabel]
+
+		if !cleanup.Reported {
+			if !labelled {
+				if pv.Labels == nil {
+					pv.Labels = map[string]string{}
+				}
+				pv.Labels[common.PVQuarantinedLabel] = "true"
+				err = c.Update(ctx, pv)
+				if err != nil {
+					return fmt.Errorf("could not label quarantined PV %q: %w", pvName, err)
+				}
+			}
+			log.Info("PV is quarantined", "pvName", pvName, "interruptions", cleanup.Interruptions)
+			recorder.Eventf(pv, corev1.EventTypeWarning, diskmaker.QuarantinedPV,
+				"the cleanup of the PV was interrupted %d times, its device is quarantined. Remove the %s label to clean it up again",
+				cleanup.Interruptions, common.PVQuarantinedLabel)
+			err = store.MarkQuarantineReported(pvName)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !labelled {
+			log.Info("releasing PV from quarantine", "pvName", pvName)
+			err = store.ReleaseQuarantine(pvName)
+			if err != nil {
+				return err
+			}
+			recorder.Event(pv, corev1.EventTypeNormal, diskmaker.ReleasedQuarantinedPV, "the PV was released from quarantine, it will be cleaned up again")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package deleter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSyncQuarantinedPVs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "local-pv-1"}}
+	c := fake.NewFakeClientWithScheme(scheme, pv)
+	recorder := record.NewFakeRecorder(10)
+	ctx := context.TODO()
+
+	// the cleanup of the PV is interrupted by MaxCleanupInterruptions restarts, and of a deleted PV once more
+	path := filepath.Join(t.TempDir(), state.FileName)
+	store, err := state.Open(path)
+	assert.NoError(t, err)
+	for _, pvName := range []string{"local-pv-1", "local-pv-deleted"} {
+		assert.NoError(t, store.ProcTable().MarkRunning(pvName))
+	}
+	for i := 0; i < state.MaxCleanupInterruptions; i++ {
+		store, err = state.Open(path)
+		assert.NoError(t, err)
+		for _, pvName := range []string{"local-pv-1", "local-pv-deleted"} {
+			if !store.ProcTable().IsRunning(pvName) {
+				_, _, err = store.ProcTable().RemoveEntry(pvName)
+				assert.NoError(t, err)
+				assert.NoError(t, store.ProcTable().MarkRunning(pvName))
+			}
+		}
+	}
+	assert.Equal(t, []string{"local-pv-1", "local-pv-deleted"}, store.QuarantinedPVs())
+
+	// the quarantined PV is labelled and reported once
+	getPV := func() *corev1.PersistentVolume {
+		pv := &corev1.PersistentVolume{}
+		assert.NoError(t, c.Get(ctx, types.NamespacedName{Name: "local-pv-1"}, pv))
+		return pv
+	}
+	assert.NoError(t, syncQuarantinedPVs(ctx, c, store, recorder))
+	assert.Equal(t, "true", getPV().Labels[common.PVQuarantinedLabel])
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "QuarantinedPV")
+	assert.NoError(t, syncQuarantinedPVs(ctx, c, store, recorder))
+	assert.Len(t, recorder.Events, 0)
+	assert.True(t, store.ProcTable().IsRunning("local-pv-1"))
+
+	// removing the label releases the PV, the deleter cleans it up again
+	pv = getPV()
+	delete(pv.Labels, common.PVQuarantinedLabel)
+	assert.NoError(t, c.Update(ctx, pv))
+	assert.NoError(t, syncQuarantinedPVs(ctx, c, store, recorder))
+	assert.Contains(t, <-recorder.Events, "ReleasedQuarantinedPV")
+	assert.False(t, store.ProcTable().IsRunning("local-pv-1"))
+	assert.Equal(t, []string{"local-pv-deleted"}, store.QuarantinedPVs(), "the device of a deleted PV stays quarantined")
+}
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"time"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
 	"github.com/prometheus/common/log"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/mount"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -73,16 +77,22 @@ func (r *DeleteReconciler) Reconcile(ctx context.Context, request ctrl.Request)
 	r.runtimeConfig.UseAlphaAPI = provisionerConfig.UseAlphaAPI
 	r.runtimeConfig.LabelsForPV = provisionerConfig.LabelsForPV
 
+	r.runtimeConfig.Node = &corev1.Node{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: nodeName}, r.runtimeConfig.Node)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// initialize the pv cache
 	// initialize the deleter's pv cache on the first run
 	if !r.firstRunOver {
-		r.runtimeConfig.Node = &corev1.Node{}
-		err = r.Client.Get(ctx, types.NamespacedName{Name: nodeName}, r.runtimeConfig.Node)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
 		r.runtimeConfig.Name = common.GetProvisionedByValue(*r.runtimeConfig.Node)
 		reqLogger.Info("first run", "provisionerName", r.runtimeConfig.Name)
+		// the PV events received before the first run of the next start are cached with the recorded provisioner name
+		err = r.StateStore.SetProvisionerName(r.runtimeConfig.Name)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to record the provisioner name: %w", err)
+		}
 		reqLogger.Info("initializing PV cache")
 		pvList := &corev1.PersistentVolumeList{}
 		err := r.Client.List(context.TODO(), pvList)
@@ -101,11 +111,61 @@ func (r *DeleteReconciler) Reconcile(ctx context.Context, request ctrl.Request)
 		r.firstRunOver = true
 	}
 
+	err = syncQuarantinedPVs(ctx, r.Client, r.StateStore, r.runtimeConfig.Recorder)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// released PVs are not wiped on a node in maintenance, they are cleaned up once the label is removed
+	if common.IsNodeInMaintenance(r.runtimeConfig.Node) {
+		reqLogger.Info("node is in maintenance, skipping the cleanup of released PVs", "label", common.NodeMaintenanceLabel)
+		return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	}
+
+	deleter, err := r.getDeleter(ctx, request.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	reqLogger.Info("Deleting Pvs through sig storage deleter")
-	r.deleter.DeletePVs()
+	deleter.DeletePVs()
 	return ctrl.Result{RequeueAfter: time.Second * 30}, nil
 }
 
+// getDeleter returns the deleter of the released PVs, without the PVs of the Unmanaged LocalVolumeSets of the namespace.
+// They are left as they are, and wiped once their LocalVolumeSet is managed again.
+func (r *DeleteReconciler) getDeleter(ctx context.Context, namespace string) (*provDeleter.Deleter, error) {
+	lvsets := &localv1.LocalVolumeSetList{}
+	err := r.Client.List(ctx, lvsets, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("could not list LocalVolumeSets: %w", err)
+	}
+	unmanaged := sets.NewString()
+	for _, lvset := range lvsets.Items {
+		if lvset.Spec.ManagementState == operatorv1.Unmanaged {
+			unmanaged.Insert(lvset.Name)
+		}
+	}
+	if unmanaged.Len() == 0 {
+		return r.deleter, nil
+	}
+
+	pvCache := provCache.NewVolumeCache()
+	for _, pv := range r.runtimeConfig.Cache.ListPVs() {
+		if pv.Labels[common.PVOwnerKindLabel] == localv1.LocalVolumeSetKind &&
+			pv.Labels[common.PVOwnerNamespaceLabel] == namespace &&
+			unmanaged.Has(pv.Labels[common.PVOwnerNameLabel]) {
+			continue
+		}
+		pvCache.AddPV(pv)
+	}
+	runtimeConfig := *r.runtimeConfig
+	runtimeConfig.Cache = pvCache
+	return &provDeleter.Deleter{
+		RuntimeConfig: &runtimeConfig,
+		CleanupStatus: r.deleter.CleanupStatus,
+	}, nil
+}
+
 func addOrUpdatePV(r *provCommon.RuntimeConfig, pv corev1.PersistentVolume) {
 	_, exists := r.Cache.GetPV(pv.GetName())
 	if exists {
@@ -118,8 +178,10 @@ func addOrUpdatePV(r *provCommon.RuntimeConfig, pv corev1.PersistentVolume) {
 type DeleteReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	Client         client.Client
-	Scheme         *runtime.Scheme
+	Client client.Client
+	Scheme *runtime.Scheme
+	// StateStore keeps the provisioner name found by the first run across restarts, when set
+	StateStore     *state.Store
 	cleanupTracker *provDeleter.CleanupStatusTracker
 	runtimeConfig  *provCommon.RuntimeConfig
 	deleter        *provDeleter.Deleter
@@ -142,6 +204,9 @@ func (r *DeleteReconciler) SetupWithManager(mgr ctrl.Manager, cleanupTracker *pr
 		// InformerFactory: , // unused
 
 	}
+	// the PVs are cached as soon as they are watched when the provisioner name was found before a restart,
+	// the first run only lists them again
+	runtimeConfig.Name = r.StateStore.ProvisionerName()
 
 	r.runtimeConfig = runtimeConfig
 	r.deleter = &provDeleter.Deleter{
@@ -0,0 +1,238 @@
+package keyrotation
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const ComponentName = "key-rotation"
+
+var nodeName string
+
+func init() {
+	nodeName = common.GetNodeNameEnvVar()
+}
+
+// encryptedStorageClass is a storage class whose devices are encrypted by the target of a rotation
+type encryptedStorageClass struct {
+	storageClassName string
+	encryption       *localv1.EncryptionSpec
+	keyLabels        map[string]string
+}
+
+// Reconcile rotates the keys of the encrypted devices of this node that belong to the target of an EncryptionKeyRotation,
+// and reports the progress of each device in its status.
+func (r *KeyRotationReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := logf.Log.WithName(ComponentName).WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	rotation := &localv1alpha1.EncryptionKeyRotation{}
+	err := r.Client.Get(ctx, request.NamespacedName, rotation)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !rotation.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	node := &corev1.Node{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: r.nodeName}, node)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	storageClasses, err := r.getEncryptedStorageClasses(ctx, rotation, node)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(storageClasses) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	var newKey []byte
+	if rotation.Spec.NewKeySecretRef != nil {
+		newKey, err = common.GetSecretKey(ctx, r.Client, rotation.Spec.NewKeySecretRef.Name, rotation.Namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	reqLogger.Info("Rotating encryption keys")
+	failed := false
+	for _, storageClass := range storageClasses {
+		devices, err := common.GetEncryptedDevices(path.Join(common.GetLocalDiskLocationPath(), storageClass.storageClassName))
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("could not list the encrypted devices: %w", err)
+		}
+		for _, device := range devices {
+			devLogger := reqLogger.WithValues("Device.ID", device.DeviceID)
+			if getDeviceRotation(rotation.Status, r.nodeName, device.DeviceID).Phase == localv1alpha1.KeyRotationCompleted {
+				continue
+			}
+			// the cleaner erases the keyslots of the device
+			pvName := common.GeneratePVName(filepath.Base(device.SymlinkPath), r.nodeName, storageClass.storageClassName)
+			if cleanup, found := r.StateStore.GetCleanup(pvName); found && cleanup.Status != state.CleanupSucceeded {
+				devLogger.Info("not rotating the key of a device that is being cleaned up")
+				failed = true
+				continue
+			}
+
+			err = r.updateDeviceRotation(ctx, rotation, device.DeviceID, localv1alpha1.KeyRotationRotating, "")
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			err = common.RotateEncryptionKey(ctx, r.Client, storageClass.encryption, rotation.Namespace, r.nodeName, device.DeviceID, newKey, storageClass.keyLabels)
+			if err != nil {
+				devLogger.Error(err, "could not rotate the encryption key")
+				failed = true
+				err = r.updateDeviceRotation(ctx, rotation, device.DeviceID, localv1alpha1.KeyRotationFailed, err.Error())
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				continue
+			}
+			devLogger.Info("rotated the encryption key")
+			err = r.updateDeviceRotation(ctx, rotation, device.DeviceID, localv1alpha1.KeyRotationCompleted, "")
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	if failed {
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// getEncryptedStorageClasses returns the storage classes whose devices are encrypted by the target of the rotation on the node
+func (r *KeyRotationReconciler) getEncryptedStorageClasses(ctx context.Context, rotation *localv1alpha1.EncryptionKeyRotation, node *corev1.Node) ([]encryptedStorageClass, error) {
+	key := types.NamespacedName{Name: rotation.Spec.TargetName, Namespace: rotation.Namespace}
+	storageClasses := make([]encryptedStorageClass, 0)
+	switch rotation.Spec.TargetKind {
+	case localv1.LocalVolumeSetKind:
+		lvset := &localv1.LocalVolumeSet{}
+		err := r.Client.Get(ctx, key, lvset)
+		if kerrors.IsNotFound(err) {
+			return storageClasses, nil
+		} else if err != nil {
+			return nil, err
+		}
+		matches, err := common.NodeSelectorMatchesNodeLabels(node, lvset.Spec.NodeSelector)
+		if err != nil || !matches || lvset.Spec.Encryption == nil {
+			return storageClasses, err
+		}
+		storageClasses = append(storageClasses, encryptedStorageClass{
+			storageClassName: lvset.Spec.StorageClassName,
+			encryption:       lvset.Spec.Encryption,
+			keyLabels:        common.EncryptionKeyLabels(localv1.LocalVolumeSetKind, lvset.Namespace, lvset.Name),
+		})
+	case localv1.LocalVolumeKind:
+		lv := &localv1.LocalVolume{}
+		err := r.Client.Get(ctx, key, lv)
+		if kerrors.IsNotFound(err) {
+			return storageClasses, nil
+		} else if err != nil {
+			return nil, err
+		}
+		matches, err := common.NodeSelectorMatchesNodeLabels(node, lv.Spec.NodeSelector)
+		if err != nil || !matches {
+			return storageClasses, err
+		}
+		for _, storageClassDevice := range lv.Spec.StorageClassDevices {
+			if storageClassDevice.Encryption == nil {
+				continue
+			}
+			storageClasses = append(storageClasses, encryptedStorageClass{
+				storageClassName: storageClassDevice.StorageClassName,
+				encryption:       storageClassDevice.Encryption,
+				keyLabels:        common.EncryptionKeyLabels(localv1.LocalVolumeKind, lv.Namespace, lv.Name),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown targetKind %q", rotation.Spec.TargetKind)
+	}
+	return storageClasses, nil
+}
+
+// getDeviceRotation returns the progress of the rotation of the device on the node
+func getDeviceRotation(status localv1alpha1.EncryptionKeyRotationStatus, nodeName, deviceID string) localv1alpha1.DeviceKeyRotation {
+	for _, device := range status.Devices {
+		if device.NodeName == nodeName && device.DeviceID == deviceID {
+			return device
+		}
+	}
+	return localv1alpha1.DeviceKeyRotation{NodeName: nodeName, DeviceID: deviceID}
+}
+
+// updateDeviceRotation records the progress of the rotation of the device in the status.
+// The status is shared by the diskmakers of all the nodes, conflicting updates are retried.
+func (r *KeyRotationReconciler) updateDeviceRotation(ctx context.Context, rotation *localv1alpha1.EncryptionKeyRotation, deviceID string, phase localv1alpha1.KeyRotationPhase, message string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := r.Client.Get(ctx, types.NamespacedName{Name: rotation.Name, Namespace: rotation.Namespace}, rotation)
+		if err != nil {
+			return err
+		}
+		device := getDeviceRotation(rotation.Status, r.nodeName, deviceID)
+		if device.Phase == phase && device.Message == message {
+			return nil
+		}
+		if device.Phase != phase {
+			device.LastTransitionTime = metav1.Now()
+		}
+		device.Phase = phase
+		device.Message = message
+		setDeviceRotation(&rotation.Status, device)
+		return r.Client.Status().Update(ctx, rotation)
+	})
+}
+
+func setDeviceRotation(status *localv1alpha1.EncryptionKeyRotationStatus, device localv1alpha1.DeviceKeyRotation) {
+	for i := range status.Devices {
+		if status.Devices[i].NodeName == device.NodeName && status.Devices[i].DeviceID == device.DeviceID {
+			status.Devices[i] = device
+			return
+		}
+	}
+	status.Devices = append(status.Devices, device)
+}
+
+type KeyRotationReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	// StateStore is used to skip the devices that are being cleaned up, when set
+	StateStore *state.Store
+	nodeName   string
+}
+
+func (r *KeyRotationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.nodeName = nodeName
+	return ctrl.NewControllerManagedBy(mgr).
+		// the keys of a device are rotated by a single reconcile at a time
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		// status updates of the other nodes don't trigger a rotation, failed rotations are requeued
+		For(&localv1alpha1.EncryptionKeyRotation{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
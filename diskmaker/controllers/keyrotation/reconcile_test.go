@@ -0,0 +1,113 @@
+package keyrotation
+
+import (
+	"context"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestReconciler(t *testing.T, objs ...runtime.Object) *KeyRotationReconciler {
+	scheme, err := localv1alpha1.SchemeBuilder.Build()
+	assert.NoErrorf(t, err, "creating scheme")
+	err = localv1.AddToScheme(scheme)
+	assert.NoErrorf(t, err, "adding localv1 to scheme")
+	err = corev1.AddToScheme(scheme)
+	assert.NoErrorf(t, err, "adding corev1 to scheme")
+	return &KeyRotationReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme, objs...),
+		Scheme:   scheme,
+		nodeName: "node-a",
+	}
+}
+
+func TestGetEncryptedStorageClasses(t *testing.T) {
+	encryption := &localv1.EncryptionSpec{KeyPolicy: localv1.EncryptionKeyGenerated}
+	otherNode := &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+		MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-b"}}},
+	}}}
+	objs := []runtime.Object{
+		&localv1.LocalVolumeSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "encrypted", Namespace: "local-storage"},
+			Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "encrypted-sc", Encryption: encryption},
+		},
+		&localv1.LocalVolumeSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "local-storage"},
+			Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "plain-sc"},
+		},
+		&localv1.LocalVolumeSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-node", Namespace: "local-storage"},
+			Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "other-sc", Encryption: encryption, NodeSelector: otherNode},
+		},
+		&localv1.LocalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "lv", Namespace: "local-storage"},
+			Spec: localv1.LocalVolumeSpec{StorageClassDevices: []localv1.StorageClassDevice{
+				{StorageClassName: "lv-encrypted", Encryption: encryption},
+				{StorageClassName: "lv-plain"},
+			}},
+		},
+	}
+	r := newTestReconciler(t, objs...)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{corev1.LabelHostname: "node-a"}}}
+
+	testcases := []struct {
+		kind           string
+		name           string
+		storageClasses []string
+	}{
+		{kind: localv1.LocalVolumeSetKind, name: "encrypted", storageClasses: []string{"encrypted-sc"}},
+		{kind: localv1.LocalVolumeSetKind, name: "plain", storageClasses: []string{}},
+		{kind: localv1.LocalVolumeSetKind, name: "other-node", storageClasses: []string{}},
+		{kind: localv1.LocalVolumeSetKind, name: "missing", storageClasses: []string{}},
+		{kind: localv1.LocalVolumeKind, name: "lv", storageClasses: []string{"lv-encrypted"}},
+	}
+	for _, tc := range testcases {
+		rotation := &localv1alpha1.EncryptionKeyRotation{
+			ObjectMeta: metav1.ObjectMeta{Name: "rotation", Namespace: "local-storage"},
+			Spec:       localv1alpha1.EncryptionKeyRotationSpec{TargetKind: tc.kind, TargetName: tc.name},
+		}
+		storageClasses, err := r.getEncryptedStorageClasses(context.TODO(), rotation, node)
+		assert.NoError(t, err, tc.name)
+		names := make([]string, 0)
+		for _, storageClass := range storageClasses {
+			names = append(names, storageClass.storageClassName)
+			assert.Equal(t, tc.name, storageClass.keyLabels["storage.openshift.com/owner-name"])
+		}
+		assert.Equal(t, tc.storageClasses, names, tc.name)
+	}
+}
+
+func TestUpdateDeviceRotation(t *testing.T) {
+	rotation := &localv1alpha1.EncryptionKeyRotation{
+		ObjectMeta: metav1.ObjectMeta{Name: "rotation", Namespace: "local-storage"},
+		Spec:       localv1alpha1.EncryptionKeyRotationSpec{TargetKind: localv1.LocalVolumeSetKind, TargetName: "encrypted"},
+		Status: localv1alpha1.EncryptionKeyRotationStatus{Devices: []localv1alpha1.DeviceKeyRotation{
+			{NodeName: "node-b", DeviceID: "/dev/disk/by-id/wwn-b", Phase: localv1alpha1.KeyRotationCompleted},
+		}},
+	}
+	r := newTestReconciler(t, rotation)
+	ctx := context.TODO()
+
+	err := r.updateDeviceRotation(ctx, rotation, "/dev/disk/by-id/wwn-a", localv1alpha1.KeyRotationRotating, "")
+	assert.NoError(t, err)
+	err = r.updateDeviceRotation(ctx, rotation, "/dev/disk/by-id/wwn-a", localv1alpha1.KeyRotationFailed, "no key available")
+	assert.NoError(t, err)
+
+	updated := &localv1alpha1.EncryptionKeyRotation{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: "rotation", Namespace: "local-storage"}, updated)
+	assert.NoError(t, err)
+	assert.Len(t, updated.Status.Devices, 2, "the devices of the other nodes are kept")
+	device := getDeviceRotation(updated.Status, "node-a", "/dev/disk/by-id/wwn-a")
+	assert.Equal(t, localv1alpha1.KeyRotationFailed, device.Phase)
+	assert.Equal(t, "no key available", device.Message)
+	assert.False(t, device.LastTransitionTime.IsZero())
+	assert.Equal(t, localv1alpha1.KeyRotationCompleted, getDeviceRotation(updated.Status, "node-b", "/dev/disk/by-id/wwn-b").Phase)
+	assert.Equal(t, localv1alpha1.KeyRotationPhase(""), getDeviceRotation(updated.Status, "node-a", "/dev/disk/by-id/wwn-b").Phase)
+}
@@ -0,0 +1,108 @@
+package lv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+)
+
+// openEncryptedDevice formats the new device with LUKS if needed and opens it.
+// It returns the device-mapper node to symlink instead of the device.
+func (r *LocalVolumeReconciler) openEncryptedDevice(ctx context.Context, encryption *localv1.EncryptionSpec, deviceID string) (string, error) {
+	return common.OpenEncryptedDevice(ctx, r.Client, encryption, r.localVolume.Namespace, r.runtimeConfig.Node.Name, deviceID, common.EncryptionKeyLabels(localv1.LocalVolumeKind, r.localVolume.Namespace, r.localVolume.Name))
+}
+
+// getEncryption returns the encryption of the devices of the storage class, nil if they are not encrypted
+func (r *LocalVolumeReconciler) getEncryption(storageClassName string) *localv1.EncryptionSpec {
+	for _, storageClassDevice := range r.localVolume.Spec.StorageClassDevices {
+		if storageClassDevice.StorageClassName == storageClassName {
+			return storageClassDevice.Encryption
+		}
+	}
+	return nil
+}
+
+// ensureEncryptedPVs opens the encrypted devices already symlinked for the storage class and ensures their PVs exist.
+// Opened devices have children, they are ignored when matching the devicePaths.
+func (r *LocalVolumeReconciler) ensureEncryptedPVs(ctx context.Context, reqLogger logr.Logger, storageClassDevice localv1.StorageClassDevice) error {
+	devices, err := common.GetEncryptedDevices(path.Join(r.symlinkLocation, storageClassDevice.StorageClassName))
+	if err != nil {
+		return fmt.Errorf("could not list the encrypted devices: %w", err)
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+	storageClass := &storagev1.StorageClass{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: storageClassDevice.StorageClassName}, storageClass)
+	if err != nil {
+		return fmt.Errorf("failed to fetch storageClass: %w", err)
+	}
+	mountPointMap, err := common.GenerateMountMap(r.runtimeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to generate mountPointMap: %w", err)
+	}
+	lvOwnerLabels := map[string]string{
+		common.LocalVolumeOwnerNameForPV:      r.localVolume.Name,
+		common.LocalVolumeOwnerNamespaceForPV: r.localVolume.Namespace,
+	}
+	for _, device := range devices {
+		devLogger := reqLogger.WithValues("Device.Name", device.KName)
+		pvName := common.GeneratePVName(filepath.Base(device.SymlinkPath), r.runtimeConfig.Node.Name, storageClass.Name)
+		// the cleaner closes and erases the device, it must not be opened in between
+		if cleanup, found := r.StateStore.GetCleanup(pvName); found &&
+			(cleanup.Status == state.CleanupRunning || cleanup.Status == state.CleanupQuarantined) {
+			continue
+		}
+		if !device.Opened {
+			pv, err := common.GetPV(ctx, r.Client, pvName)
+			if err != nil {
+				return err
+			}
+			err = common.ReopenEncryptedDevice(ctx, r.Client, storageClassDevice.Encryption, r.localVolume.Namespace, r.runtimeConfig.Node.Name, device, pv,
+				common.EncryptionKeyLabels(localv1.LocalVolumeKind, r.localVolume.Namespace, r.localVolume.Name))
+			if errors.Is(err, common.ErrEncryptedDeviceWiped) && pv == nil {
+				// the cleaner wiped the device of the deleted PV, it is formatted again as a new device of the devicePaths
+				klog.Infof("removing the symlink %s of the wiped encrypted device %s", device.SymlinkPath, device.DeviceID)
+				err = os.Remove(device.SymlinkPath)
+				if err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("could not remove the symlink of the wiped encrypted device: %w", err)
+				}
+				continue
+			} else if err != nil {
+				msg := fmt.Sprintf("error opening encrypted device %s: %v", device.DeviceID, err)
+				r.eventSync.Report(r.localVolume, newDiskEvent(ErrorEncryptingDisk, msg, device.KName, corev1.EventTypeWarning))
+				klog.Errorf(msg)
+				continue
+			}
+		}
+		err = common.CreateLocalPV(
+			r.localVolume,
+			r.runtimeConfig,
+			r.cleanupTracker,
+			devLogger,
+			*storageClass,
+			mountPointMap,
+			r.Client,
+			device.SymlinkPath,
+			device.KName,
+			device.IDExists,
+			lvOwnerLabels,
+		)
+		if err != nil {
+			return fmt.Errorf("could not create local PV: %w", err)
+		}
+	}
+	return nil
+}
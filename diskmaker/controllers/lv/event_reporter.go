@@ -18,10 +18,16 @@ const (
 	ErrorListingDeviceID     = "ErrorListingDeviceID"
 	ErrorFindingMatchingDisk = "ErrorFindingMatchingDisk"
 	ErrorCreatingSymLink     = "ErrorCreatingSymLink"
+	ErrorEncryptingDisk      = "ErrorEncryptingDisk"
+	ErrorMigratingSymlink    = "ErrorMigratingSymlink"
+	FencedPV                 = "FencedPV"
+	UnfencedPV               = "UnfencedPV"
+	ErrorVerifyingDevice     = "ErrorVerifyingDevice"
 
 	FoundMatchingDisk     = "FoundMatchingDisk"
 	DeviceSymlinkExists   = "DeviceSymlinkExists"
 	SymLinkedOnDeviceName = "SymlinkedOnDeivceName"
+	MigratedSymlink       = "MigratedSymlink"
 )
 
 func newDiskEvent(eventReason, message, disk, eventType string) diskmaker.DiskEvent {
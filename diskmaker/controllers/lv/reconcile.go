@@ -13,6 +13,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
 	"github.com/openshift/local-storage-operator/internal"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/workqueue"
@@ -193,6 +194,7 @@ func addOwnerLabels(meta *metav1.ObjectMeta, cr *localv1.LocalVolume) bool {
 //+kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=use,resourceNames=privileged
 //+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups="";storage.k8s.io,resources=configmaps;storageclasses;persistentvolumeclaims;persistentvolumes,verbs=*
+//+kubebuilder:rbac:groups="",namespace=default,resources=secrets,verbs=get;list;watch;create;update;delete
 
 func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	var log = logf.Log.WithName(ComponentName)
@@ -237,6 +239,12 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		return ctrl.Result{}, nil
 	}
 
+	// no device is provisioned on a node in maintenance, the reconcile resumes once the label is removed
+	if common.IsNodeInMaintenance(r.runtimeConfig.Node) {
+		reqLogger.Info("node is in maintenance, skipping provisioning", "label", common.NodeMaintenanceLabel)
+		return ctrl.Result{Requeue: true, RequeueAfter: checkDuration}, nil
+	}
+
 	// get associated provisioner config
 	cm := &corev1.ConfigMap{}
 	err = r.Client.Get(ctx, types.NamespacedName{Name: common.ProvisionerConfigMapName, Namespace: request.Namespace}, cm)
@@ -270,6 +278,19 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		klog.Errorf("error creating local-storage directory %s: %v", r.symlinkLocation, err)
 		os.Exit(-1)
 	}
+
+	// opened encrypted devices are ignored when matching the devicePaths, their PVs are ensured separately
+	for _, storageClassDevice := range lv.Spec.StorageClassDevices {
+		if storageClassDevice.Encryption == nil {
+			continue
+		}
+		err = r.ensureEncryptedPVs(ctx, reqLogger, storageClassDevice)
+		if err != nil {
+			reqLogger.Error(err, "failed to provision encrypted devices", "storageClass.Name", storageClassDevice.StorageClassName)
+			return ctrl.Result{}, err
+		}
+	}
+
 	diskConfig := r.generateConfig()
 	// run command lsblk --all --noheadings --pairs --output "KNAME,PKNAME,TYPE,MOUNTPOINT"
 	// the reason we are using KNAME instead of NAME is because for lvm disks(and may be others)
@@ -298,6 +319,51 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		klog.Errorf(msg, "could not parse all the lsblk rows", "lsblk.BadRows", badRows)
 	}
 
+	// symlinks created on the kernel name of devices without a persistent link are pointed to the link they have now,
+	// and the symlinks that point to another device than the one their PV was created on are fenced
+	for _, storageClassDevice := range lv.Spec.StorageClassDevices {
+		symLinkDirPath := path.Join(r.symlinkLocation, storageClassDevice.StorageClassName)
+		migrations := common.MigrateKNameSymlinks(ctx, r.Client, r.StateStore, symLinkDirPath, r.runtimeConfig.Node.Name, storageClassDevice.StorageClassName, blockDevices, storageClassDevice.IdentityPolicy)
+		for _, migration := range migrations {
+			if migration.Err != nil {
+				msg := fmt.Sprintf("could not migrate symlink %q: %v", migration.SymlinkPath, migration.Err)
+				r.eventSync.Report(r.localVolume, newDiskEvent(ErrorMigratingSymlink, msg, migration.Device.KName, corev1.EventTypeWarning))
+				klog.Errorf(msg)
+				continue
+			}
+			msg := fmt.Sprintf("migrated symlink %q from %q to %q", migration.SymlinkPath, migration.OldSource, migration.Source)
+			r.eventSync.Report(r.localVolume, newDiskEvent(MigratedSymlink, msg, migration.Device.KName, corev1.EventTypeNormal))
+			klog.Infof(msg)
+		}
+		fencedPVs := common.FenceMismatchedPVs(ctx, r.Client, symLinkDirPath, r.runtimeConfig.Node.Name, storageClassDevice.StorageClassName)
+		for _, fencedPV := range fencedPVs {
+			if fencedPV.Unfenced {
+				if fencedPV.Err != nil {
+					msg := fmt.Sprintf("could not unfence PV %q: %v", fencedPV.PVName, fencedPV.Err)
+					r.eventSync.Report(r.localVolume, newDiskEvent(ErrorVerifyingDevice, msg, "", corev1.EventTypeWarning))
+					klog.Errorf(msg)
+					continue
+				}
+				msg := fmt.Sprintf("unfenced PV %q, its symlink points to %q again", fencedPV.PVName, fencedPV.Source)
+				r.eventSync.Report(r.localVolume, newDiskEvent(UnfencedPV, msg, "", corev1.EventTypeNormal))
+				klog.Infof(msg)
+				continue
+			}
+			if fencedPV.Mismatch == "" {
+				msg := fmt.Sprintf("could not verify the device of symlink %q: %v", fencedPV.SymlinkPath, fencedPV.Err)
+				r.eventSync.Report(r.localVolume, newDiskEvent(ErrorVerifyingDevice, msg, "", corev1.EventTypeWarning))
+				klog.Errorf(msg)
+				continue
+			}
+			msg := fmt.Sprintf("fenced PV %q, its symlink pointed to %q whose %s", fencedPV.PVName, fencedPV.Source, fencedPV.Mismatch)
+			if fencedPV.Err != nil {
+				msg = fmt.Sprintf("could not fence PV %q, its symlink points to %q whose %s: %v", fencedPV.PVName, fencedPV.Source, fencedPV.Mismatch, fencedPV.Err)
+			}
+			r.eventSync.Report(r.localVolume, newDiskEvent(FencedPV, msg, "", corev1.EventTypeWarning))
+			klog.Errorf(msg)
+		}
+	}
+
 	validBlockDevices := make([]internal.BlockDevice, 0)
 	for _, blockDevice := range blockDevices {
 		if ignoreDevices(blockDevice) {
@@ -308,7 +374,7 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 
 	if len(validBlockDevices) == 0 {
 		klog.V(3).Infof("unable to find any new disks")
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.updateNodeStatus(ctx, diskConfig, nil)
 	}
 
 	allDiskIds, err := filepath.Glob(diskByIDPath)
@@ -347,7 +413,7 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		}
 		r.eventSync.Report(r.localVolume, newDiskEvent(ErrorFindingMatchingDisk, msg, "", corev1.EventTypeWarning))
 		klog.Errorf(msg)
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.updateNodeStatus(ctx, diskConfig, deviceMap)
 	}
 
 	mountPointMap, err := common.GenerateMountMap(r.runtimeConfig)
@@ -362,13 +428,25 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 		for _, deviceNameLocation := range deviceArray {
 			devLogger := reqLogger.WithValues("Device.Name", deviceNameLocation.diskNamePath)
 			symLinkDirPath := path.Join(r.symlinkLocation, storageClassName)
-			source, target, idExists, err := common.GetSymLinkSourceAndTarget(deviceNameLocation.blockDevice, symLinkDirPath)
+			source, target, idExists, err := common.GetSymLinkSourceAndTarget(deviceNameLocation.blockDevice, symLinkDirPath, r.getIdentityPolicy(storageClassName))
 			if err != nil {
 				reqLogger.Error(err, "failed to get symlink source and target")
 				errors = append(errors, err)
 				break
 			}
-			shouldCreatePV := r.createSymlink(deviceNameLocation, source, target, devLogger, idExists)
+			// encrypted devices are symlinked through their device-mapper node
+			linkSource := source
+			if encryption := r.getEncryption(storageClassName); encryption != nil {
+				linkSource, err = r.openEncryptedDevice(ctx, encryption, source)
+				if err != nil {
+					msg := fmt.Sprintf("error encrypting device %s: %v", source, err)
+					r.eventSync.Report(r.localVolume, newDiskEvent(ErrorEncryptingDisk, msg, deviceNameLocation.diskNamePath, corev1.EventTypeWarning))
+					klog.Errorf(msg)
+					errors = append(errors, err)
+					break
+				}
+			}
+			shouldCreatePV := r.createSymlink(deviceNameLocation, linkSource, target, devLogger, idExists)
 			if shouldCreatePV {
 				storageClass := &storagev1.StorageClass{}
 				err := r.Client.Get(ctx, types.NamespacedName{Name: storageClassName}, storageClass)
@@ -400,13 +478,42 @@ func (r *LocalVolumeReconciler) Reconcile(ctx context.Context, request ctrl.Requ
 					errors = append(errors, err)
 					break
 				}
+				size, _ := deviceNameLocation.blockDevice.GetSize()
+				err = r.StateStore.RecordClaim(source, state.Claim{
+					DeviceName: deviceNameLocation.blockDevice.KName,
+					PVName:     common.GeneratePVName(filepath.Base(target), r.runtimeConfig.Node.Name, storageClass.Name),
+					Owner:      fmt.Sprintf("LocalVolume/%s/%s", r.localVolume.Namespace, r.localVolume.Name),
+					ClaimedAt:  time.Now(),
+					Serial:     deviceNameLocation.blockDevice.Serial,
+					WWN:        deviceNameLocation.blockDevice.WWN,
+					Size:       size,
+				})
+				if err != nil {
+					devLogger.Error(err, "could not persist the claim of the device")
+				}
 			}
 		}
 	}
 
+	// the state of the devicePaths is published once they are provisioned
+	err = r.updateNodeStatus(ctx, diskConfig, deviceMap)
+	if err != nil {
+		reqLogger.Error(err, "could not update the status of the node")
+	}
+
 	return ctrl.Result{Requeue: true, RequeueAfter: checkDuration}, nil
 }
 
+// getIdentityPolicy returns the identity policy of the devices of the storage class
+func (r *LocalVolumeReconciler) getIdentityPolicy(storageClassName string) []localv1.DeviceIdentity {
+	for _, storageClassDevice := range r.localVolume.Spec.StorageClassDevices {
+		if storageClassDevice.StorageClassName == storageClassName {
+			return storageClassDevice.IdentityPolicy
+		}
+	}
+	return nil
+}
+
 func ignoreDevices(dev internal.BlockDevice) bool {
 	if hasBindMounts, _, err := dev.HasBindMounts(); err != nil || hasBindMounts {
 		klog.Infof("ignoring mount device %q", dev.Name)
@@ -540,8 +647,13 @@ func fileExists(filename string) bool {
 }
 
 type LocalVolumeReconciler struct {
-	Client          client.Client
-	Scheme          *runtime.Scheme
+	Client client.Client
+	Scheme *runtime.Scheme
+	// DeviceEvents are the add and remove events of the block devices of the node.
+	// All the LocalVolumes are reconciled on each event, when set.
+	DeviceEvents <-chan event.GenericEvent
+	// StateStore persists the device claims across restarts, when set
+	StateStore      *state.Store
 	symlinkLocation string
 	localVolume     *localv1.LocalVolume
 	eventSync       *eventReporter
@@ -575,7 +687,7 @@ func (r *LocalVolumeReconciler) SetupWithManager(mgr ctrl.Manager, cleanupTracke
 	r.deleter = provDeleter.NewDeleter(runtimeConfig, cleanupTracker)
 	r.cleanupTracker = cleanupTracker
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		// set to 1 explicitly, despite it being the default, as the reconciler is not thread-safe.
 		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
 		For(&localv1.LocalVolume{}).
@@ -610,8 +722,28 @@ func (r *LocalVolumeReconciler) SetupWithManager(mgr ctrl.Manager, cleanupTracke
 					handlePVChange(runtimeConfig, pv, q, true)
 				}
 			},
-		}).
-		Complete(r)
+		})
+	// reconcile right away when a device is added or removed, instead of waiting for the next requeue
+	if r.DeviceEvents != nil {
+		builder = builder.Watches(&source.Channel{Source: r.DeviceEvents}, handler.EnqueueRequestsFromMapFunc(r.requestsForDeviceEvent))
+	}
+	return builder.Complete(r)
+}
+
+// requestsForDeviceEvent returns a request for each LocalVolume in the watched namespace
+func (r *LocalVolumeReconciler) requestsForDeviceEvent(obj client.Object) []reconcile.Request {
+	namespace, _ := common.GetWatchNamespace()
+	lvList := &localv1.LocalVolumeList{}
+	err := r.Client.List(context.TODO(), lvList, client.InNamespace(namespace))
+	if err != nil {
+		klog.Errorf("could not list LocalVolumes for device event on %q: %v", obj.GetName(), err)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(lvList.Items))
+	for _, lv := range lvList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: lv.Name, Namespace: lv.Namespace}})
+	}
+	return requests
 }
 
 func handlePVChange(runtimeConfig *provCommon.RuntimeConfig, pv *corev1.PersistentVolume, q workqueue.RateLimitingInterface, isDelete bool) {
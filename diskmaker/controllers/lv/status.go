@@ -0,0 +1,156 @@
+package lv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/internal"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// updateNodeStatus replaces the state of the devicePaths on this node in the status of the LocalVolume.
+// The status is shared by the diskmakers of all the nodes, conflicting updates are retried.
+func (r *LocalVolumeReconciler) updateNodeStatus(ctx context.Context, diskConfig *DiskConfig, deviceMap map[string][]DiskLocation) error {
+	nodeStatus := r.getNodeStatus(diskConfig, deviceMap)
+	lv := r.localVolume
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := r.Client.Get(ctx, types.NamespacedName{Name: lv.Name, Namespace: lv.Namespace}, lv)
+		if err != nil {
+			return err
+		}
+		nodes := setNodeStatus(lv.Status.Nodes, nodeStatus)
+		if equality.Semantic.DeepEqual(nodes, lv.Status.Nodes) {
+			return nil
+		}
+		lv.Status.Nodes = nodes
+		return r.Client.Status().Update(ctx, lv)
+	})
+}
+
+// getNodeStatus returns the state of each devicePath on this node, deviceMap being the devices matched by storage class
+func (r *LocalVolumeReconciler) getNodeStatus(diskConfig *DiskConfig, deviceMap map[string][]DiskLocation) localv1.LocalVolumeNodeStatus {
+	nodeName := r.runtimeConfig.Node.Name
+	nodeStatus := localv1.LocalVolumeNodeStatus{NodeName: nodeName}
+	for storageClassName, disks := range diskConfig.Disks {
+		linked := r.getLinkedDevices(storageClassName)
+		matched := map[string]bool{}
+		for _, location := range deviceMap[storageClassName] {
+			matched[location.blockDevice.KName] = true
+		}
+		for _, devicePath := range disks.DevicePaths {
+			nodeStatus.Devices = append(nodeStatus.Devices, r.getDevicePathStatus(storageClassName, devicePath, linked, matched))
+		}
+	}
+	sort.Slice(nodeStatus.Devices, func(i, j int) bool {
+		a, b := nodeStatus.Devices[i], nodeStatus.Devices[j]
+		if a.StorageClassName != b.StorageClassName {
+			return a.StorageClassName < b.StorageClassName
+		}
+		return a.DevicePath < b.DevicePath
+	})
+	return nodeStatus
+}
+
+// getDevicePathStatus returns the state of the devicePath of the storage class,
+// linked being the PVs of the devices symlinked for the storage class and matched the devices it can claim, by kernel name
+func (r *LocalVolumeReconciler) getDevicePathStatus(storageClassName, devicePath string, linked map[string]string, matched map[string]bool) localv1.DevicePathStatus {
+	status := localv1.DevicePathStatus{StorageClassName: storageClassName, DevicePath: devicePath}
+	realPath, err := internal.FilePathEvalSymLinks(devicePath)
+	if err != nil {
+		status.State = localv1.DevicePathMissing
+		status.Message = "no device exists at the path"
+		return status
+	}
+	fileInfo, err := os.Stat(realPath)
+	switch {
+	case err != nil:
+		status.State = localv1.DevicePathMissing
+		status.Message = fmt.Sprintf("could not examine %s: %v", realPath, err)
+		return status
+	case fileInfo.IsDir():
+		status.State = localv1.DevicePathRefused
+		status.Message = "the path is a directory, not a block device"
+		return status
+	case fileInfo.Mode().IsRegular():
+		status.State = localv1.DevicePathRefused
+		status.Message = "the path is a regular file, not a block device"
+		return status
+	}
+
+	status.DeviceName = filepath.Base(realPath)
+	if pvName, found := linked[status.DeviceName]; found {
+		status.PersistentVolumeName = pvName
+		if _, created := r.runtimeConfig.Cache.GetPV(pvName); !created {
+			status.State = localv1.DevicePathMatched
+			status.Message = "the device is symlinked, its PV is not created yet"
+			return status
+		}
+		status.State = localv1.DevicePathLinked
+		return status
+	}
+	if !matched[status.DeviceName] {
+		status.State = localv1.DevicePathRefused
+		status.Message = "the device is in use, it has partitions, holders or mounts"
+		return status
+	}
+	// the devices symlinked in another directory are claimed by another storage class
+	links, err := internal.GetMatchingSymlinksInDirs(realPath, r.symlinkLocation)
+	if err == nil && len(links) > 0 {
+		status.State = localv1.DevicePathRefused
+		status.Message = fmt.Sprintf("the device is already claimed by %s", links[0])
+		return status
+	}
+	status.State = localv1.DevicePathMatched
+	status.Message = "the device could not be symlinked, see the events of the LocalVolume"
+	return status
+}
+
+// getLinkedDevices returns the PVs of the devices symlinked in the directory of the storage class, by kernel name
+func (r *LocalVolumeReconciler) getLinkedDevices(storageClassName string) map[string]string {
+	symLinkDir := path.Join(r.symlinkLocation, storageClassName)
+	nodeName := r.runtimeConfig.Node.Name
+	linked := map[string]string{}
+	paths, err := internal.FilePathGlob(filepath.Join(symLinkDir, "*"))
+	if err != nil {
+		return linked
+	}
+	for _, symlinkPath := range paths {
+		realPath, err := internal.FilePathEvalSymLinks(symlinkPath)
+		if err != nil || internal.IsFencedPath(realPath) {
+			continue
+		}
+		linked[filepath.Base(realPath)] = common.GeneratePVName(filepath.Base(symlinkPath), nodeName, storageClassName)
+	}
+	// the encrypted devices are symlinked through their device-mapper node
+	encryptedDevices, err := common.GetEncryptedDevices(symLinkDir)
+	if err != nil {
+		return linked
+	}
+	for _, device := range encryptedDevices {
+		linked[device.KName] = common.GeneratePVName(filepath.Base(device.SymlinkPath), nodeName, storageClassName)
+	}
+	return linked
+}
+
+// setNodeStatus returns nodes with the entry of the node of nodeStatus replaced by it, sorted by node name
+func setNodeStatus(nodes []localv1.LocalVolumeNodeStatus, nodeStatus localv1.LocalVolumeNodeStatus) []localv1.LocalVolumeNodeStatus {
+	result := make([]localv1.LocalVolumeNodeStatus, 0, len(nodes)+1)
+	for _, node := range nodes {
+		if node.NodeName != nodeStatus.NodeName {
+			result = append(result, node)
+		}
+	}
+	result = append(result, nodeStatus)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NodeName < result[j].NodeName
+	})
+	return result
+}
@@ -0,0 +1,68 @@
+package lv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestUpdateNodeStatus(t *testing.T) {
+	symlinkLocation := createTmpDir(t, "", "local-storage")
+	defer os.RemoveAll(symlinkLocation)
+	notADevice := createTmpDir(t, "", "directory")
+	defer os.RemoveAll(notADevice)
+
+	lv := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "disks", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSpec{StorageClassDevices: []localv1.StorageClassDevice{
+			{StorageClassName: "fast", DevicePaths: []string{"/dev/null", "/dev/zero", "/dev/full", "/dev/missing", notADevice}},
+		}},
+		Status: localv1.LocalVolumeStatus{Nodes: []localv1.LocalVolumeNodeStatus{
+			{NodeName: "node2", Devices: []localv1.DevicePathStatus{{StorageClassName: "fast", DevicePath: "/dev/sdb", State: localv1.DevicePathLinked}}},
+		}},
+	}
+	d, _ := getFakeDiskMaker(t, symlinkLocation, lv)
+	d.localVolume = lv
+	d.runtimeConfig.Node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+
+	// /dev/null is symlinked with its PV, /dev/zero is matched but not symlinked
+	assert.NoError(t, os.MkdirAll(filepath.Join(symlinkLocation, "fast"), 0755))
+	assert.NoError(t, os.Symlink("/dev/null", filepath.Join(symlinkLocation, "fast", "null")))
+	pvName := common.GeneratePVName("null", "node1", "fast")
+	d.runtimeConfig.Cache.AddPV(&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: pvName}})
+	deviceMap := map[string][]DiskLocation{
+		"fast": {{diskNamePath: "/dev/zero", blockDevice: internal.BlockDevice{KName: "zero"}}},
+	}
+
+	assert.NoError(t, d.updateNodeStatus(context.TODO(), d.generateConfig(), deviceMap))
+
+	updated := &localv1.LocalVolume{}
+	assert.NoError(t, d.Client.Get(context.TODO(), types.NamespacedName{Name: lv.Name, Namespace: lv.Namespace}, updated))
+	if !assert.Len(t, updated.Status.Nodes, 2) {
+		return
+	}
+	assert.Equal(t, "node1", updated.Status.Nodes[0].NodeName)
+	assert.Equal(t, "node2", updated.Status.Nodes[1].NodeName, "the status of the other nodes is kept")
+
+	states := map[string]localv1.DevicePathStatus{}
+	for _, device := range updated.Status.Nodes[0].Devices {
+		states[device.DevicePath] = device
+	}
+	assert.Equal(t, localv1.DevicePathLinked, states["/dev/null"].State)
+	assert.Equal(t, pvName, states["/dev/null"].PersistentVolumeName)
+	assert.Equal(t, localv1.DevicePathMatched, states["/dev/zero"].State)
+	assert.Equal(t, "zero", states["/dev/zero"].DeviceName)
+	assert.Equal(t, localv1.DevicePathRefused, states["/dev/full"].State)
+	assert.Equal(t, localv1.DevicePathMissing, states["/dev/missing"].State)
+	assert.Equal(t, localv1.DevicePathRefused, states[notADevice].State)
+	assert.Contains(t, states[notADevice].Message, "directory")
+}
@@ -0,0 +1,104 @@
+package lvset
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/internal"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// getApprovalName returns the name of the LocalVolumeSetApproval of a LocalVolumeSet on a node.
+// Names that are too long are shortened with a hash, to keep them unique.
+func getApprovalName(lvsetName, nodeName string) string {
+	name := fmt.Sprintf("%s-%s", lvsetName, nodeName)
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:16]
+	return fmt.Sprintf("%s-%s", name[:validation.DNS1123SubdomainMaxLength-len(hash)-1], hash)
+}
+
+// getOrCreateApproval returns the LocalVolumeSetApproval of lvset on this node, and creates it if it doesn't exist.
+// It is owned by lvset, so that it is removed with it.
+func (r *LocalVolumeSetReconciler) getOrCreateApproval(ctx context.Context, lvset *localv1.LocalVolumeSet) (*localv1alpha1.LocalVolumeSetApproval, error) {
+	approval := &localv1alpha1.LocalVolumeSetApproval{}
+	name := getApprovalName(lvset.Name, r.nodeName)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: lvset.Namespace}, approval)
+	if err == nil {
+		return approval, nil
+	} else if !kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not get LocalVolumeSetApproval %q: %w", name, err)
+	}
+
+	approval = &localv1alpha1.LocalVolumeSetApproval{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: lvset.Namespace,
+			Labels: map[string]string{
+				common.OwnerNameLabel:      lvset.Name,
+				common.OwnerNamespaceLabel: lvset.Namespace,
+			},
+		},
+		Spec: localv1alpha1.LocalVolumeSetApprovalSpec{
+			LocalVolumeSetName: lvset.Name,
+			NodeName:           r.nodeName,
+		},
+	}
+	err = controllerutil.SetOwnerReference(lvset, approval, r.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("could not set the owner of LocalVolumeSetApproval %q: %w", name, err)
+	}
+	err = r.Client.Create(ctx, approval)
+	if err != nil {
+		return nil, fmt.Errorf("could not create LocalVolumeSetApproval %q: %w", name, err)
+	}
+	return approval, nil
+}
+
+// updatePendingDevices records the devices waiting for approval in the status of approval
+func (r *LocalVolumeSetReconciler) updatePendingDevices(ctx context.Context, approval *localv1alpha1.LocalVolumeSetApproval, pendingDevices []localv1alpha1.PendingDevice) error {
+	if equality.Semantic.DeepEqual(approval.Status.PendingDevices, pendingDevices) {
+		return nil
+	}
+	approval.Status.PendingDevices = pendingDevices
+	err := r.Client.Status().Update(ctx, approval)
+	if err != nil {
+		return fmt.Errorf("could not update the pending devices of LocalVolumeSetApproval %q: %w", approval.Name, err)
+	}
+	return nil
+}
+
+// newPendingDevice returns the PendingDevice of a device identified by deviceID
+func newPendingDevice(deviceID string, blockDevice internal.BlockDevice) localv1alpha1.PendingDevice {
+	size, _ := blockDevice.GetSize()
+	return localv1alpha1.PendingDevice{
+		DeviceID:   deviceID,
+		DeviceName: blockDevice.KName,
+		Size:       size,
+		Model:      blockDevice.Model,
+		Serial:     blockDevice.Serial,
+	}
+}
+
+// requestsForApproval returns a request for the LocalVolumeSet of an approval of this node
+func (r *LocalVolumeSetReconciler) requestsForApproval(obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*localv1alpha1.LocalVolumeSetApproval)
+	if !ok || approval.Spec.NodeName != r.nodeName {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: approval.Spec.LocalVolumeSetName, Namespace: approval.Namespace}},
+	}
+}
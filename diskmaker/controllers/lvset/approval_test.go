@@ -0,0 +1,76 @@
+package lvset
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	v1alphav1api "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGetApprovalName(t *testing.T) {
+	assert.Equal(t, "lvset-a-node1", getApprovalName("lvset-a", "node1"))
+
+	longNode := strings.Repeat("n", 250)
+	name := getApprovalName("lvset-a", longNode)
+	assert.Len(t, name, validation.DNS1123SubdomainMaxLength)
+	assert.NotEqual(t, name, getApprovalName("lvset-b", longNode), "truncated names should stay unique")
+}
+
+func TestGetOrCreateApproval(t *testing.T) {
+	lvset := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "lvset-a", Namespace: testNamespace, UID: "uid-a"},
+		Spec:       localv1.LocalVolumeSetSpec{ClaimPolicy: localv1.ClaimPolicyManual},
+	}
+	r, tc := newFakeLocalVolumeSetReconciler(t, lvset)
+	r.nodeName = "node1"
+
+	approval, err := r.getOrCreateApproval(context.TODO(), lvset)
+	assert.NoError(t, err)
+	assert.Equal(t, "lvset-a-node1", approval.Name)
+	assert.Equal(t, "node1", approval.Spec.NodeName)
+	assert.Equal(t, "lvset-a", approval.Spec.LocalVolumeSetName)
+	assert.Equal(t, "lvset-a", approval.Labels[common.OwnerNameLabel])
+	assert.Len(t, approval.OwnerReferences, 1)
+
+	// an admin approves a device, it is kept on the next reconcile
+	approval.Spec.ApprovedDevices = []string{"/dev/disk/by-id/wwn-0x1"}
+	err = tc.fakeClient.Update(context.TODO(), approval)
+	assert.NoError(t, err)
+
+	approval, err = r.getOrCreateApproval(context.TODO(), lvset)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/dev/disk/by-id/wwn-0x1"}, approval.Spec.ApprovedDevices)
+
+	pending := []v1alphav1api.PendingDevice{{DeviceID: "/dev/disk/by-id/wwn-0x2", DeviceName: "sdc", Size: 10 * common.GiB}}
+	err = r.updatePendingDevices(context.TODO(), approval, pending)
+	assert.NoError(t, err)
+
+	updated := &v1alphav1api.LocalVolumeSetApproval{}
+	err = tc.fakeClient.Get(context.TODO(), types.NamespacedName{Name: approval.Name, Namespace: testNamespace}, updated)
+	assert.NoError(t, err)
+	assert.Equal(t, pending, updated.Status.PendingDevices)
+}
+
+func TestRequestsForApproval(t *testing.T) {
+	r, _ := newFakeLocalVolumeSetReconciler(t)
+	r.nodeName = "node1"
+
+	approval := func(nodeName string) *v1alphav1api.LocalVolumeSetApproval {
+		return &v1alphav1api.LocalVolumeSetApproval{
+			ObjectMeta: metav1.ObjectMeta{Name: "lvset-a-" + nodeName, Namespace: testNamespace},
+			Spec:       v1alphav1api.LocalVolumeSetApprovalSpec{LocalVolumeSetName: "lvset-a", NodeName: nodeName},
+		}
+	}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "lvset-a", Namespace: testNamespace}},
+	}, r.requestsForApproval(approval("node1")))
+	assert.Empty(t, r.requestsForApproval(approval("node2")), "approvals of other nodes should be ignored")
+}
@@ -9,15 +9,17 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	provCommon "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
+	provDeleter "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/deleter"
 	provUtil "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/util"
 )
 
@@ -26,7 +28,7 @@ func TestCreatePV(t *testing.T) {
 	testTable := []struct {
 		desc      string
 		shouldErr bool
-		lvset     localv1alpha1.LocalVolumeSet
+		lvset     localv1.LocalVolumeSet
 		node      corev1.Node
 		sc        storagev1.StorageClass
 		// device stuff
@@ -40,12 +42,12 @@ func TestCreatePV(t *testing.T) {
 	}{
 		{
 			desc: "basic creation: block on block",
-			lvset: localv1alpha1.LocalVolumeSet{
+			lvset: localv1.LocalVolumeSet{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "lvset-a",
 					// Namespace: "a",
 				},
-				Spec: localv1alpha1.LocalVolumeSetSpec{
+				Spec: localv1.LocalVolumeSetSpec{
 					StorageClassName: "storageclass-a",
 				},
 			},
@@ -71,12 +73,12 @@ func TestCreatePV(t *testing.T) {
 		{
 			desc:      "basic creation: block on fs",
 			shouldErr: true,
-			lvset: localv1alpha1.LocalVolumeSet{
+			lvset: localv1.LocalVolumeSet{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "lvset-a",
 					// Namespace: "a",
 				},
-				Spec: localv1alpha1.LocalVolumeSetSpec{
+				Spec: localv1.LocalVolumeSetSpec{
 					StorageClassName: "storageclass-a",
 				},
 			},
@@ -101,12 +103,12 @@ func TestCreatePV(t *testing.T) {
 		},
 		{
 			desc: "basic creation: fs on block",
-			lvset: localv1alpha1.LocalVolumeSet{
+			lvset: localv1.LocalVolumeSet{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "lvset-a",
 					// Namespace: "a",
 				},
-				Spec: localv1alpha1.LocalVolumeSetSpec{
+				Spec: localv1.LocalVolumeSetSpec{
 					StorageClassName: "storageclass-a",
 				},
 			},
@@ -131,12 +133,12 @@ func TestCreatePV(t *testing.T) {
 		},
 		{
 			desc: "basic creation: fs",
-			lvset: localv1alpha1.LocalVolumeSet{
+			lvset: localv1.LocalVolumeSet{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "lvset-a",
 					// Namespace: "a",
 				},
-				Spec: localv1alpha1.LocalVolumeSetSpec{
+				Spec: localv1.LocalVolumeSetSpec{
 					StorageClassName: "storageclass-a",
 				},
 			},
@@ -162,12 +164,12 @@ func TestCreatePV(t *testing.T) {
 		{
 			desc:      "actual volume mode is fs, but is not mountpoint",
 			shouldErr: true,
-			lvset: localv1alpha1.LocalVolumeSet{
+			lvset: localv1.LocalVolumeSet{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "lvset-b",
 					// Namespace: "a",
 				},
-				Spec: localv1alpha1.LocalVolumeSetSpec{
+				Spec: localv1.LocalVolumeSetSpec{
 					StorageClassName: "storageclass-b",
 				},
 			},
@@ -200,7 +202,7 @@ func TestCreatePV(t *testing.T) {
 		if tc.lvset.Namespace == "" {
 			tc.lvset.Namespace = "default"
 		}
-		tc.lvset.Kind = localv1alpha1.LocalVolumeSetKind
+		tc.lvset.Kind = localv1.LocalVolumeSetKind
 		r, testConfig := newFakeLocalVolumeSetReconciler(t, &tc.lvset, &tc.node, &tc.sc)
 		r.nodeName = tc.node.Name
 		testConfig.runtimeConfig.Node = &tc.node
@@ -294,3 +296,65 @@ func TestCreatePV(t *testing.T) {
 	}
 
 }
+
+func TestCreatePVKeepsSucceededCleanup(t *testing.T) {
+	reclaimPolicyDelete := corev1.PersistentVolumeReclaimDelete
+	lvset := &localv1.LocalVolumeSet{
+		TypeMeta:   metav1.TypeMeta{Kind: localv1.LocalVolumeSetKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "lvset-a", Namespace: "default"},
+		Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "storageclass-a", VolumeMode: localv1.PersistentVolumeBlock},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "nodename-a", Labels: map[string]string{corev1.LabelHostname: "node-hostname-a"}}}
+	sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "storageclass-a"}, ReclaimPolicy: &reclaimPolicyDelete}
+	r, testConfig := newFakeLocalVolumeSetReconciler(t, lvset, node, sc)
+	r.nodeName = node.Name
+	testConfig.runtimeConfig.Node = node
+	testConfig.runtimeConfig.Name = common.GetProvisionedByValue(*node)
+	testConfig.runtimeConfig.DiscoveryMap[sc.Name] = provCommon.MountConfig{
+		HostDir:    "/mnt/local-storage/storageclass-a",
+		MountDir:   "/mnt/local-storage/storageclass-a",
+		VolumeMode: string(localv1.PersistentVolumeBlock),
+	}
+	testConfig.fakeVolUtil.AddNewDirEntries("/mnt/local-storage/", map[string][]*provUtil.FakeDirEntry{
+		sc.Name: {{Name: "device-a", Capacity: 10 * common.GiB, VolumeType: provUtil.FakeEntryBlock}},
+	})
+
+	// the provisioners and the deleter share the persistent cleanup table, like in the diskmaker
+	store, err := state.Open(filepath.Join(t.TempDir(), state.FileName))
+	assert.NoError(t, err)
+	procTable := store.ProcTable()
+	r.cleanupTracker = &provDeleter.CleanupStatusTracker{ProcTable: procTable}
+	r.deleter = provDeleter.NewDeleter(r.runtimeConfig, r.cleanupTracker)
+
+	symlinkPath := "/mnt/local-storage/storageclass-a/device-a"
+	createPV := func() error {
+		return common.CreateLocalPV(lvset, r.runtimeConfig, r.cleanupTracker, log.WithName("testLogger"), *sc,
+			sets.NewString(), r.Client, symlinkPath, "device-a", true, map[string]string{})
+	}
+	assert.NoError(t, createPV())
+	pvName := common.GeneratePVName("device-a", node.Name, sc.Name)
+	pv := &corev1.PersistentVolume{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: pvName}, pv))
+
+	// the PV is released and its device wiped
+	pv.Status.Phase = corev1.VolumeReleased
+	r.runtimeConfig.Cache.AddPV(pv)
+	assert.NoError(t, procTable.MarkRunning(pvName))
+	assert.NoError(t, procTable.MarkSucceeded(pvName))
+
+	// the LocalVolumeSet is reconciled before the deleter runs
+	assert.NoError(t, createPV())
+	cleanup, found := store.GetCleanup(pvName)
+	assert.True(t, found, "the succeeded cleanup was removed by the provisioner")
+	assert.Equal(t, state.CleanupSucceeded, cleanup.Status)
+
+	// the deleter deletes the PV without wiping the device again
+	r.deleter.DeletePVs()
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: pvName}, pv)
+	assert.Truef(t, kerrors.IsNotFound(err), "the PV was not deleted: %v", err)
+	_, found = store.GetCleanup(pvName)
+	assert.False(t, found, "a second cleanup was started")
+	stats := procTable.Stats()
+	assert.Equal(t, 0, stats.Running)
+	assert.Equal(t, 1, stats.Succeeded)
+}
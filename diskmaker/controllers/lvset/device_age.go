@@ -3,6 +3,9 @@ package lvset
 import (
 	"sync"
 	"time"
+
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 var (
@@ -26,12 +29,15 @@ type ageMap struct {
 	ageMap map[string]time.Time
 	mux    sync.RWMutex
 	clock  timeInterface
+	// store persists the ages across restarts, when set
+	store *state.Store
 }
 
-func newAgeMap(clock timeInterface) *ageMap {
+func newAgeMap(clock timeInterface, store *state.Store) *ageMap {
 	return &ageMap{
 		clock:  clock,
-		ageMap: map[string]time.Time{},
+		ageMap: store.DeviceAges(),
+		store:  store,
 	}
 }
 
@@ -57,5 +63,40 @@ func (a *ageMap) storeDeviceAge(key string) {
 	if !found {
 		firstObserved = a.clock.getCurrentTime()
 		a.ageMap[key] = firstObserved
+		if err := a.store.SetDeviceFirstSeen(key, firstObserved); err != nil {
+			log.Error(err, "could not persist the age of the device", "Device.Name", key)
+		}
+	}
+}
+
+// timeUntilOld returns how long until the device is older than deviceMinAge, or 0 if it already is
+func (a *ageMap) timeUntilOld(key string) time.Duration {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+
+	firstObserved, found := a.ageMap[key]
+	if !found {
+		return deviceMinAge
+	}
+	remaining := deviceMinAge - a.clock.getCurrentTime().Sub(firstObserved)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// forgetMissingDevices removes the devices that are no longer present,
+// so that a replacement device with the same name waits for deviceMinAge again
+func (a *ageMap) forgetMissingDevices(present sets.String) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	for key := range a.ageMap {
+		if !present.Has(key) {
+			delete(a.ageMap, key)
+		}
+	}
+	if err := a.store.ForgetDevices(present); err != nil {
+		log.Error(err, "could not persist the removal of devices")
 	}
 }
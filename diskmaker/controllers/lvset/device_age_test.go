@@ -5,9 +5,10 @@ import (
 	"testing"
 	"time"
 
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/internal"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type fakeClock struct {
@@ -21,15 +22,15 @@ func (f *fakeClock) getCurrentTime() time.Time {
 func TestDeviceAge(t *testing.T) {
 	// empty the filters and matchers
 	oldFilterMap := FilterMap
-	FilterMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	FilterMap = make(map[string]func(internal.BlockDevice, *localv1.DeviceInclusionSpec) (bool, error), 0)
 
-	oldMatcherMap := matcherMap
-	matcherMap = make(map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error), 0)
+	oldMatcherMap := MatcherMap
+	MatcherMap = make(map[string]func(internal.BlockDevice, *localv1.DeviceInclusionSpec) (bool, error), 0)
 
 	// reset the filters and matchers
 	defer func() {
 		FilterMap = oldFilterMap
-		matcherMap = oldMatcherMap
+		MatcherMap = oldMatcherMap
 	}()
 
 	r, tc := newFakeLocalVolumeSetReconciler(t)
@@ -74,3 +75,24 @@ func TestDeviceAge(t *testing.T) {
 
 	}
 }
+
+func TestDeviceAgeRequeue(t *testing.T) {
+	clock := &fakeClock{ftime: time.Unix(0, 0)}
+	ages := newAgeMap(clock, nil)
+
+	assert.Equal(t, deviceMinAge, ages.timeUntilOld("dev-0"), "unknown device")
+
+	ages.storeDeviceAge("dev-0")
+	clock.ftime = clock.ftime.Add(deviceMinAge / 4)
+	assert.Equal(t, deviceMinAge*3/4, ages.timeUntilOld("dev-0"))
+
+	clock.ftime = clock.ftime.Add(deviceMinAge)
+	assert.Equal(t, time.Duration(0), ages.timeUntilOld("dev-0"))
+	assert.True(t, ages.isOlderThan("dev-0"))
+
+	// a device replacing a removed one with the same name has to age again
+	ages.forgetMissingDevices(sets.NewString("dev-1"))
+	assert.False(t, ages.isOlderThan("dev-0"))
+	ages.storeDeviceAge("dev-0")
+	assert.Equal(t, deviceMinAge, ages.timeUntilOld("dev-0"))
+}
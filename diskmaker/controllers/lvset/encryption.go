@@ -0,0 +1,89 @@
+package lvset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// openEncryptedDevice formats the new device with LUKS if needed and opens it.
+// It returns the device-mapper node to symlink instead of the device.
+func (r *LocalVolumeSetReconciler) openEncryptedDevice(ctx context.Context, lvset *localv1.LocalVolumeSet, deviceID string) (string, error) {
+	return common.OpenEncryptedDevice(ctx, r.Client, lvset.Spec.Encryption, lvset.Namespace, r.nodeName, deviceID, common.EncryptionKeyLabels(localv1.LocalVolumeSetKind, lvset.Namespace, lvset.Name))
+}
+
+// ensureEncryptedPVs opens the encrypted devices already symlinked in symLinkDir and ensures their PVs exist.
+func (r *LocalVolumeSetReconciler) ensureEncryptedPVs(
+	ctx context.Context,
+	lvset *localv1.LocalVolumeSet,
+	reqLogger logr.Logger,
+	storageClass storagev1.StorageClass,
+	symLinkDir string,
+) error {
+	devices, err := common.GetEncryptedDevices(symLinkDir)
+	if err != nil {
+		return fmt.Errorf("could not list the encrypted devices: %w", err)
+	}
+	for _, device := range devices {
+		devLogger := reqLogger.WithValues("Device.Name", device.KName)
+		pvName := common.GeneratePVName(filepath.Base(device.SymlinkPath), r.nodeName, storageClass.Name)
+		// the cleaner closes and erases the device, it must not be opened in between
+		if cleanup, found := r.StateStore.GetCleanup(pvName); found &&
+			(cleanup.Status == state.CleanupRunning || cleanup.Status == state.CleanupQuarantined) {
+			continue
+		}
+		if !device.Opened {
+			pv, err := common.GetPV(ctx, r.Client, pvName)
+			if err != nil {
+				return err
+			}
+			devLogger.Info("opening encrypted device", "Device.ID", device.DeviceID)
+			err = common.ReopenEncryptedDevice(ctx, r.Client, lvset.Spec.Encryption, lvset.Namespace, r.nodeName, device, pv,
+				common.EncryptionKeyLabels(localv1.LocalVolumeSetKind, lvset.Namespace, lvset.Name))
+			if errors.Is(err, common.ErrEncryptedDeviceWiped) && pv == nil {
+				// the cleaner wiped the device of the deleted PV, it is matched and formatted again as a new device
+				devLogger.Info("removing the symlink of the wiped encrypted device", "Device.ID", device.DeviceID)
+				err = os.Remove(device.SymlinkPath)
+				if err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("could not remove the symlink of the wiped encrypted device: %w", err)
+				}
+				continue
+			} else if err != nil {
+				r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorEncryptingDisk, "could not open encrypted disk", device.KName, corev1.EventTypeWarning))
+				devLogger.Error(err, "could not open encrypted device")
+				continue
+			}
+		}
+		mountPointMap, err := common.GenerateMountMap(r.runtimeConfig)
+		if err != nil {
+			return err
+		}
+		err = common.CreateLocalPV(
+			lvset,
+			r.runtimeConfig,
+			r.cleanupTracker,
+			devLogger,
+			storageClass,
+			mountPointMap,
+			r.Client,
+			device.SymlinkPath,
+			device.KName,
+			device.IDExists,
+			map[string]string{},
+		)
+		if err != nil {
+			return fmt.Errorf("could not provision encrypted disk: %w", err)
+		}
+	}
+	return nil
+}
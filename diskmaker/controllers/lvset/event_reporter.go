@@ -17,6 +17,12 @@ const (
 	ErrorListingExistingSymlinks = "ErrorListingExistingSymlinks"
 	// DiscoveredNewDevice is an event reason string
 	DiscoveredNewDevice = "DiscoveredNewDevice"
+	// DeviceWaitingForApproval is an event reason string
+	DeviceWaitingForApproval = "DeviceWaitingForApproval"
+	// DeviceLeftToPrecedingSet is an event reason string
+	DeviceLeftToPrecedingSet = "DeviceLeftToPrecedingSet"
+	// SharedDeviceWithoutPersistentLink is an event reason string
+	SharedDeviceWithoutPersistentLink = "SharedDeviceWithoutPersistentLink"
 )
 
 func newDiskEvent(eventReason, message, disk, eventType string) diskmaker.DiskEvent {
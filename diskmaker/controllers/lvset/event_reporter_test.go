@@ -6,7 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	diskmaker "github.com/openshift/local-storage-operator/diskmaker"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,7 +29,7 @@ func TestEventReporer(t *testing.T) {
 	eventChannel := fakeRecorder.Events
 	fakeReporter := newEventReporter(fakeRecorder)
 
-	lvSet := &localv1alpha1.LocalVolumeSet{
+	lvSet := &localv1.LocalVolumeSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "lvset-1",
 			Namespace: "default",
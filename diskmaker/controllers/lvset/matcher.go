@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"strings"
 
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/internal"
 	"golang.org/x/sys/unix"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -29,50 +29,52 @@ const (
 	inMechanicalPropertyList = "inMechanicalPropertyList"
 	inVendorList             = "inVendorList"
 	inModelList              = "inModelList"
+	inDevicePathList         = "inDevicePathList"
+	notInExcludedPathList    = "notInExcludedPathList"
 )
 
 var defaultMinSize = resource.MustParse("1Gi")
 
 // maps of function identifier (for logs) to filter function.
-// These are passed the localv1alpha1.DeviceInclusionSpec to make testing easier,
+// These are passed the localv1.DeviceInclusionSpec to make testing easier,
 // but they aren't expected to use it
 // they verify that the device itself is good to use
-var FilterMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error){
-	notReadOnly: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+var FilterMap = map[string]func(internal.BlockDevice, *localv1.DeviceInclusionSpec) (bool, error){
+	notReadOnly: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		readOnly, err := dev.GetReadOnly()
 		return !readOnly, err
 	},
 
-	notRemovable: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	notRemovable: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		removable, err := dev.GetRemovable()
 		return !removable, err
 	},
 
-	notSuspended: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	notSuspended: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		matched := dev.State != internal.StateSuspended
 		return matched, nil
 	},
 
-	noBiosBootInPartLabel: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	noBiosBootInPartLabel: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		biosBootInPartLabel := strings.Contains(strings.ToLower(dev.PartLabel), strings.ToLower("bios")) ||
 			strings.Contains(strings.ToLower(dev.PartLabel), strings.ToLower("boot"))
 		return !biosBootInPartLabel, nil
 	},
 
-	noFilesystemSignature: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	noFilesystemSignature: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		matched := dev.FSType == ""
 		return matched, nil
 	},
-	noBindMounts: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	noBindMounts: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		hasBindMounts, _, err := dev.HasBindMounts()
 		return !hasBindMounts, err
 	},
 
-	noChildren: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	noChildren: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		hasChildren, err := dev.HasChildren()
 		return !hasChildren, err
 	},
-	canOpenExclusively: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	canOpenExclusively: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		pathname, err := dev.GetDevPath()
 		if err != nil {
 			return false, fmt.Errorf("pathname: %q: %w", pathname, err)
@@ -94,10 +96,10 @@ var FilterMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclu
 	},
 }
 
-// functions that match device by *localv1alpha1.DeviceInclusionSpec
-var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error){
+// MatcherMap maps the function identifiers (for logs) to the functions that match devices by *localv1.DeviceInclusionSpec
+var MatcherMap = map[string]func(internal.BlockDevice, *localv1.DeviceInclusionSpec) (bool, error){
 
-	inSizeRange: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	inSizeRange: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		if spec == nil {
 			return true, nil
 		}
@@ -124,24 +126,24 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 		matched = greaterThanOrEqualToMin && lessThanOrEqualToMax
 		return matched, nil
 	},
-	inTypeList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	inTypeList: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		matched := false
 		if spec == nil {
-			return strings.ToLower(string(localv1alpha1.RawDisk)) == strings.ToLower(dev.Type), nil
+			return strings.ToLower(string(localv1.RawDisk)) == strings.ToLower(dev.GetType()), nil
 		}
 		if len(spec.DeviceTypes) < 1 {
-			return strings.ToLower(string(localv1alpha1.RawDisk)) == strings.ToLower(dev.Type), nil
+			return strings.ToLower(string(localv1.RawDisk)) == strings.ToLower(dev.GetType()), nil
 		}
 
 		for _, deviceType := range spec.DeviceTypes {
-			if strings.ToLower(string(deviceType)) == strings.ToLower(dev.Type) {
+			if strings.ToLower(string(deviceType)) == strings.ToLower(dev.GetType()) {
 				matched = true
 				break
 			}
 		}
 		return matched, nil
 	},
-	inMechanicalPropertyList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	inMechanicalPropertyList: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		if spec == nil {
 			return true, nil
 		}
@@ -154,8 +156,8 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 			return false, err
 		}
 		for _, prop := range spec.DeviceMechanicalProperties {
-			matchedRotational := prop == localv1alpha1.Rotational && rotational
-			matchedNonRotational := prop == localv1alpha1.NonRotational && !rotational
+			matchedRotational := prop == localv1.Rotational && rotational
+			matchedNonRotational := prop == localv1.NonRotational && !rotational
 			if matchedRotational || matchedNonRotational {
 				matched = true
 				break
@@ -163,7 +165,7 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 		}
 		return matched, nil
 	},
-	inVendorList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	inVendorList: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		if spec == nil {
 			return true, nil
 		}
@@ -180,7 +182,7 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 		return matched, nil
 	},
 
-	inModelList: func(dev internal.BlockDevice, spec *localv1alpha1.DeviceInclusionSpec) (bool, error) {
+	inModelList: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
 		if spec == nil {
 			return true, nil
 		}
@@ -196,4 +198,35 @@ var matcherMap = map[string]func(internal.BlockDevice, *localv1alpha1.DeviceIncl
 		}
 		return matched, nil
 	},
+
+	inDevicePathList: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil {
+			return true, nil
+		}
+		if len(spec.DevicePaths) == 0 {
+			return true, nil
+		}
+		return pathsEvalToDevice(spec.DevicePaths, dev)
+	},
+
+	notInExcludedPathList: func(dev internal.BlockDevice, spec *localv1.DeviceInclusionSpec) (bool, error) {
+		if spec == nil {
+			return true, nil
+		}
+		excluded, err := pathsEvalToDevice(spec.ExcludedDevicePaths, dev)
+		return !excluded, err
+	},
+}
+
+// pathsEvalToDevice returns true if one of the paths points to the device
+func pathsEvalToDevice(paths []string, dev internal.BlockDevice) (bool, error) {
+	for _, path := range paths {
+		matched, err := internal.PathEvalsToDiskLabel(path, dev.KName)
+		if err != nil {
+			return false, err
+		} else if matched {
+			return true, nil
+		}
+	}
+	return false, nil
 }
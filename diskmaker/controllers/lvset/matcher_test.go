@@ -2,13 +2,14 @@ package lvset
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/openshift/local-storage-operator/internal"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/resource"
 
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 )
 
 const (
@@ -236,7 +237,7 @@ func TestInSizeRange(t *testing.T) {
 	tenGi := resource.MustParse("10Gi")
 	fiftyGi := resource.MustParse("50Gi")
 
-	matcherMap := matcherMap
+	matcherMap := MatcherMap
 	matcher := inSizeRange
 	results := []knownMatcherResult{
 		// both specified
@@ -244,41 +245,41 @@ func TestInSizeRange(t *testing.T) {
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 10*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
 			expectMatch: true, expectErr: false,
 		},
 		// in size range upper limit
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 50*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
 			expectMatch: true, expectErr: false,
 		},
 		// in size range, max not specified
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 40*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi},
 			expectMatch: true, expectErr: false,
 		},
 		// in size range, min not specified
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 40*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MaxSize: &fiftyGi},
 			expectMatch: true, expectErr: false,
 		},
 		// nothing specified
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 40*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			spec:        &localv1.DeviceInclusionSpec{},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 1000*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			spec:        &localv1.DeviceInclusionSpec{},
 			expectMatch: true, expectErr: false,
 		},
 		// violate min
@@ -286,13 +287,13 @@ func TestInSizeRange(t *testing.T) {
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 9.99*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
 			expectMatch: false, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 1*Ki)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
 			expectMatch: false, expectErr: false,
 		},
 		// violate max
@@ -300,39 +301,39 @@ func TestInSizeRange(t *testing.T) {
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 50.1*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
 			expectMatch: false, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 1000*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
 			expectMatch: false, expectErr: false,
 		},
 		// bad raw size
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: "foo"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MinSize: &tenGi, MaxSize: &fiftyGi},
 			expectMatch: false, expectErr: true,
 		},
 		// default minSize
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", .9*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			spec:        &localv1.DeviceInclusionSpec{},
 			expectMatch: false, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", 1.1*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			spec:        &localv1.DeviceInclusionSpec{},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Size: fmt.Sprintf("%v", .9*Gi)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{MaxSize: &fiftyGi},
+			spec:        &localv1.DeviceInclusionSpec{MaxSize: &fiftyGi},
 			expectMatch: false, expectErr: false,
 		},
 	}
@@ -343,87 +344,94 @@ func TestInSizeRange(t *testing.T) {
 // 	match, err := in size range()
 // }
 func TestInTypeList(t *testing.T) {
-	matcherMap := matcherMap
+	matcherMap := MatcherMap
 	matcher := inTypeList
 	results := []knownMatcherResult{
 		// exact match
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.RawDisk)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk}},
+			dev:         internal.BlockDevice{Type: string(localv1.RawDisk)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.RawDisk}},
 			expectMatch: true, expectErr: false,
 		},
 		// subset
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.RawDisk)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk, localv1alpha1.Partition}},
+			dev:         internal.BlockDevice{Type: string(localv1.RawDisk)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.RawDisk, localv1.Partition}},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.Partition)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk, localv1alpha1.Partition}},
+			dev:         internal.BlockDevice{Type: string(localv1.Partition)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.RawDisk, localv1.Partition}},
+			expectMatch: true, expectErr: false,
+		},
+		// md arrays of any level
+		{
+			matcherMap: matcherMap, matcher: matcher,
+			dev:         internal.BlockDevice{Type: "raid10"},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.RAID}},
 			expectMatch: true, expectErr: false,
 		},
 		// exact mismatch, fails
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.Loop)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk}},
+			dev:         internal.BlockDevice{Type: string(localv1.Loop)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.RawDisk}},
 			expectMatch: false, expectErr: false,
 		},
 		// subset mismatch, fails
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.Loop)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk, localv1alpha1.Partition}},
+			dev:         internal.BlockDevice{Type: string(localv1.Loop)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.RawDisk, localv1.Partition}},
 			expectMatch: false, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Type: "crypt"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk, localv1alpha1.Partition}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{localv1.RawDisk, localv1.Partition}},
 			expectMatch: false, expectErr: false,
 		},
 		// case mismatch, works anyway
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.RawDisk)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{"DISK"}},
+			dev:         internal.BlockDevice{Type: string(localv1.RawDisk)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{"DISK"}},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.Partition)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{"DISK", "PART"}},
+			dev:         internal.BlockDevice{Type: string(localv1.Partition)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{"DISK", "PART"}},
 			expectMatch: true, expectErr: false,
 		},
 		// mispelling, fails
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.RawDisk)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{"diskfoo"}},
+			dev:         internal.BlockDevice{Type: string(localv1.RawDisk)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{"diskfoo"}},
 			expectMatch: false, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.Partition)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceTypes: []localv1alpha1.DeviceType{"foo", "BAR"}},
+			dev:         internal.BlockDevice{Type: string(localv1.Partition)},
+			spec:        &localv1.DeviceInclusionSpec{DeviceTypes: []localv1.DeviceType{"foo", "BAR"}},
 			expectMatch: false, expectErr: false,
 		},
 		// deviceType spec omitted, pass
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.RawDisk)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			dev:         internal.BlockDevice{Type: string(localv1.RawDisk)},
+			spec:        &localv1.DeviceInclusionSpec{},
 			expectMatch: true, expectErr: false,
 		},
 		// deviceType spec omitted, fail
 		{
 			matcherMap: matcherMap, matcher: matcher,
-			dev:         internal.BlockDevice{Type: string(localv1alpha1.Partition)},
-			spec:        &localv1alpha1.DeviceInclusionSpec{},
+			dev:         internal.BlockDevice{Type: string(localv1.Partition)},
+			spec:        &localv1.DeviceInclusionSpec{},
 			expectMatch: false, expectErr: false,
 		},
 	}
@@ -431,59 +439,59 @@ func TestInTypeList(t *testing.T) {
 }
 
 func TestInMechanicalPropertyList(t *testing.T) {
-	matcherMap := matcherMap
+	matcherMap := MatcherMap
 	matcher := inMechanicalPropertyList
 	results := []knownMatcherResult{
 		// exact match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "0"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.NonRotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.NonRotational}},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "1"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.Rotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational}},
 			expectMatch: true, expectErr: false,
 		},
 		// subset
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "0"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.Rotational, localv1alpha1.NonRotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational, localv1.NonRotational}},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "1"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.Rotational, localv1alpha1.NonRotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational, localv1.NonRotational}},
 			expectMatch: true, expectErr: false,
 		},
 		// exact mismatch, fails
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "0"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.Rotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational}},
 			expectMatch: false, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "1"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.NonRotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.NonRotational}},
 			expectMatch: false, expectErr: false,
 		},
 		// bad parse
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "foo"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.Rotational, localv1alpha1.NonRotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational, localv1.NonRotational}},
 			expectMatch: false, expectErr: true,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Rotational: "-1"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1alpha1.DeviceMechanicalProperty{localv1alpha1.Rotational, localv1alpha1.NonRotational}},
+			spec:        &localv1.DeviceInclusionSpec{DeviceMechanicalProperties: []localv1.DeviceMechanicalProperty{localv1.Rotational, localv1.NonRotational}},
 			expectMatch: false, expectErr: true,
 		},
 	}
@@ -495,55 +503,55 @@ func TestInMechanicalPropertyList(t *testing.T) {
 // }
 
 func TestInVendorList(t *testing.T) {
-	matcherMap := matcherMap
+	matcherMap := MatcherMap
 	matcher := inVendorList
 	results := []knownMatcherResult{
 		// exact match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Vendor: "ATA"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Vendors: []string{"ATA"}},
+			spec:        &localv1.DeviceInclusionSpec{Vendors: []string{"ATA"}},
 			expectMatch: true, expectErr: false,
 		},
 		// substring match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Vendor: "ATA"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Vendors: []string{"AT"}},
+			spec:        &localv1.DeviceInclusionSpec{Vendors: []string{"AT"}},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Vendor: "ATA"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Vendors: []string{"TA"}},
+			spec:        &localv1.DeviceInclusionSpec{Vendors: []string{"TA"}},
 			expectMatch: true, expectErr: false,
 		},
 		// different case match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Vendor: "ATA"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Vendors: []string{"ata"}},
+			spec:        &localv1.DeviceInclusionSpec{Vendors: []string{"ata"}},
 			expectMatch: true, expectErr: false,
 		},
 		// subset match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Vendor: "ATA"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Vendors: []string{"asus", "ata"}},
+			spec:        &localv1.DeviceInclusionSpec{Vendors: []string{"asus", "ata"}},
 			expectMatch: true, expectErr: false,
 		},
 		// mismatch
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Vendor: "ATA"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Vendors: []string{"asus"}},
+			spec:        &localv1.DeviceInclusionSpec{Vendors: []string{"asus"}},
 			expectMatch: false, expectErr: false,
 		},
 		// subset mismatch
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Vendor: "ATA"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Vendors: []string{"asus", "foo"}},
+			spec:        &localv1.DeviceInclusionSpec{Vendors: []string{"asus", "foo"}},
 			expectMatch: false, expectErr: false,
 		},
 	}
@@ -551,57 +559,111 @@ func TestInVendorList(t *testing.T) {
 }
 
 func TestInModelList(t *testing.T) {
-	matcherMap := matcherMap
+	matcherMap := MatcherMap
 	matcher := inModelList
 	results := []knownMatcherResult{
 		// exact match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Model: "SAMSUNG158"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Models: []string{"SAMSUNG158"}},
+			spec:        &localv1.DeviceInclusionSpec{Models: []string{"SAMSUNG158"}},
 			expectMatch: true, expectErr: false,
 		},
 		// substring match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Model: "SAMSUNG158"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Models: []string{"SAMSUNG"}},
+			spec:        &localv1.DeviceInclusionSpec{Models: []string{"SAMSUNG"}},
 			expectMatch: true, expectErr: false,
 		},
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Model: "SAMSUNG158"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Models: []string{"158"}},
+			spec:        &localv1.DeviceInclusionSpec{Models: []string{"158"}},
 			expectMatch: true, expectErr: false,
 		},
 		// different case match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Model: "SAMSUNG158"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Models: []string{"samsung"}},
+			spec:        &localv1.DeviceInclusionSpec{Models: []string{"samsung"}},
 			expectMatch: true, expectErr: false,
 		},
 		// subset match
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Model: "ASUS258"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Models: []string{"samsung", "asus"}},
+			spec:        &localv1.DeviceInclusionSpec{Models: []string{"samsung", "asus"}},
 			expectMatch: true, expectErr: false,
 		},
 		// mismatch
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Model: "VIRTUAL"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Models: []string{"asus"}},
+			spec:        &localv1.DeviceInclusionSpec{Models: []string{"asus"}},
 			expectMatch: false, expectErr: false,
 		},
 		// subset mismatch
 		{
 			matcherMap: matcherMap, matcher: matcher,
 			dev:         internal.BlockDevice{Model: "SAMSUNG158"},
-			spec:        &localv1alpha1.DeviceInclusionSpec{Models: []string{"bar", "foo"}},
+			spec:        &localv1.DeviceInclusionSpec{Models: []string{"bar", "foo"}},
+			expectMatch: false, expectErr: false,
+		},
+	}
+	assertAll(t, results)
+}
+
+func TestInDevicePathList(t *testing.T) {
+	internal.FilePathEvalSymLinks = func(path string) (string, error) {
+		switch path {
+		case "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3":
+			return "/dev/sdb", nil
+		case "/dev/disk/by-id/wwn-0x5000c500a0b1c2d4":
+			return "/dev/sdc", nil
+		}
+		return path, nil
+	}
+	defer func() { internal.FilePathEvalSymLinks = filepath.EvalSymlinks }()
+	results := []knownMatcherResult{
+		// no paths
+		{
+			matcherMap: MatcherMap, matcher: inDevicePathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1.DeviceInclusionSpec{},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: MatcherMap, matcher: inDevicePathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1.DeviceInclusionSpec{DevicePaths: []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d4", "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: MatcherMap, matcher: inDevicePathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1.DeviceInclusionSpec{DevicePaths: []string{"/dev/sdb"}},
+			expectMatch: true, expectErr: false,
+		},
+		{
+			matcherMap: MatcherMap, matcher: inDevicePathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1.DeviceInclusionSpec{DevicePaths: []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d4"}},
+			expectMatch: false, expectErr: false,
+		},
+		// excluded
+		{
+			matcherMap: MatcherMap, matcher: notInExcludedPathList,
+			dev:         internal.BlockDevice{KName: "sdb"},
+			spec:        &localv1.DeviceInclusionSpec{ExcludedDevicePaths: []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"}},
 			expectMatch: false, expectErr: false,
 		},
+		{
+			matcherMap: MatcherMap, matcher: notInExcludedPathList,
+			dev:         internal.BlockDevice{KName: "sdc"},
+			spec:        &localv1.DeviceInclusionSpec{ExcludedDevicePaths: []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"}},
+			expectMatch: true, expectErr: false,
+		},
 	}
 	assertAll(t, results)
 }
@@ -609,10 +671,10 @@ func TestInModelList(t *testing.T) {
 // a known result for a particular filter that can be asserted
 type knownMatcherResult struct {
 	// should pass one of filterMap or matcherMap
-	matcherMap  map[string]func(internal.BlockDevice, *localv1alpha1.DeviceInclusionSpec) (bool, error)
+	matcherMap  map[string]func(internal.BlockDevice, *localv1.DeviceInclusionSpec) (bool, error)
 	matcher     string
 	dev         internal.BlockDevice
-	spec        *localv1alpha1.DeviceInclusionSpec
+	spec        *localv1.DeviceInclusionSpec
 	expectMatch bool
 	expectErr   bool
 }
@@ -0,0 +1,97 @@
+package lvset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/internal"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	staticProvisioner "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
+)
+
+// precedingSet is a LocalVolumeSet of this node that claims the devices it shares with the reconciled one
+type precedingSet struct {
+	lvset      localv1.LocalVolumeSet
+	symLinkDir string
+	// rejectedDevices are the devices rejected in its LocalVolumeSetApproval of this node, with the Manual claimPolicy
+	rejectedDevices sets.String
+}
+
+// getPrecedingSets returns the LocalVolumeSets of the namespace that select this node and have precedence over lvset,
+// from the one with the highest precedence
+func (r *LocalVolumeSetReconciler) getPrecedingSets(
+	ctx context.Context,
+	lvset *localv1.LocalVolumeSet,
+	storageClassConfig map[string]staticProvisioner.MountConfig,
+) ([]precedingSet, error) {
+	lvsets := &localv1.LocalVolumeSetList{}
+	err := r.Client.List(ctx, lvsets, client.InNamespace(lvset.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("could not list the LocalVolumeSets: %w", err)
+	}
+	preceding := make([]precedingSet, 0)
+	for _, other := range lvsets.Items {
+		if other.Name == lvset.Name || !other.DeletionTimestamp.IsZero() || !common.HasPrecedence(&other, lvset) {
+			continue
+		}
+		// a Removed LocalVolumeSet doesn't claim devices anymore
+		if other.Spec.ManagementState == operatorv1.Removed {
+			continue
+		}
+		matches, err := common.NodeSelectorMatchesNodeLabels(r.runtimeConfig.Node, other.Spec.NodeSelector)
+		if err != nil || !matches {
+			continue
+		}
+		set := precedingSet{
+			lvset:           other,
+			symLinkDir:      storageClassConfig[other.Spec.StorageClassName].HostDir,
+			rejectedDevices: sets.NewString(),
+		}
+		if other.Spec.ClaimPolicy == localv1.ClaimPolicyManual {
+			approval := &localv1alpha1.LocalVolumeSetApproval{}
+			err = r.Client.Get(ctx, types.NamespacedName{Name: getApprovalName(other.Name, r.nodeName), Namespace: other.Namespace}, approval)
+			if err != nil && !kerrors.IsNotFound(err) {
+				return nil, fmt.Errorf("could not get the LocalVolumeSetApproval of LocalVolumeSet %q: %w", other.Name, err)
+			}
+			set.rejectedDevices.Insert(approval.Spec.RejectedDevices...)
+		}
+		preceding = append(preceding, set)
+	}
+	sort.Slice(preceding, func(i, j int) bool {
+		return common.HasPrecedence(&preceding[i].lvset, &preceding[j].lvset)
+	})
+	return preceding, nil
+}
+
+// getPrecedingClaimant returns the name of the first preceding LocalVolumeSet that matches the device and can still claim it,
+// or an empty string if the device can be claimed
+func getPrecedingClaimant(preceding []precedingSet, blockDevice internal.BlockDevice, deviceID string, blockDevices []internal.BlockDevice) string {
+SetLoop:
+	for _, set := range preceding {
+		if set.rejectedDevices.Has(deviceID) {
+			continue
+		}
+		for _, matcher := range MatcherMap {
+			valid, err := matcher(blockDevice, set.lvset.Spec.DeviceInclusionSpec)
+			if err != nil || !valid {
+				continue SetLoop
+			}
+		}
+		if set.lvset.Spec.MaxDeviceCount != nil && set.symLinkDir != "" {
+			count, _, _, err := getAlreadySymlinked(set.symLinkDir, blockDevice, blockDevices)
+			if err == nil && int32(count) >= *set.lvset.Spec.MaxDeviceCount {
+				continue
+			}
+		}
+		return set.lvset.Name
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package lvset
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	v1alphav1api "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	provCommon "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
+)
+
+func TestGetPrecedingClaimant(t *testing.T) {
+	created := metav1.NewTime(time.Now().Truncate(time.Second))
+	fiftyGi := resource.MustParse("50Gi")
+	one := int32(1)
+	newLVSet := func(name string, priority int32, storageClassName string) *localv1.LocalVolumeSet {
+		return &localv1.LocalVolumeSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, CreationTimestamp: created},
+			Spec:       localv1.LocalVolumeSetSpec{StorageClassName: storageClassName, Priority: priority},
+		}
+	}
+	lvset := newLVSet("lvset-a", 0, "a")
+	// an older LocalVolumeSet of the same priority has precedence
+	older := newLVSet("older", 0, "older")
+	older.CreationTimestamp = metav1.NewTime(created.Add(-time.Hour))
+	older.Spec.DeviceInclusionSpec = &localv1.DeviceInclusionSpec{MaxSize: &fiftyGi}
+	lower := newLVSet("lower", -1, "lower")
+	otherNode := newLVSet("other-node", 20, "other-node")
+	otherNode.Spec.NodeSelector = &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+		MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node2"}}},
+	}}}
+	manual := newLVSet("manual", 10, "manual")
+	manual.Spec.ClaimPolicy = localv1.ClaimPolicyManual
+	approval := &v1alphav1api.LocalVolumeSetApproval{
+		ObjectMeta: metav1.ObjectMeta{Name: "manual-node1", Namespace: testNamespace},
+		Spec:       v1alphav1api.LocalVolumeSetApprovalSpec{RejectedDevices: []string{"/dev/disk/by-id/wwn-0x1"}},
+	}
+	full := newLVSet("full", 5, "full")
+	full.Spec.MaxDeviceCount = &one
+	high := newLVSet("high", 1, "high")
+
+	r, _ := newFakeLocalVolumeSetReconciler(t, lvset, older, lower, otherNode, manual, approval, full, high)
+	r.nodeName = "node1"
+	r.runtimeConfig.Node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{corev1.LabelHostname: "node1"}}}
+
+	// the full LocalVolumeSet has provisioned its only device
+	fullDir := t.TempDir()
+	assert.NoError(t, os.Symlink(internal.FencedDir+"fenced", filepath.Join(fullDir, "fenced")))
+	storageClassConfig := map[string]provCommon.MountConfig{"full": {HostDir: fullDir}}
+
+	preceding, err := r.getPrecedingSets(context.TODO(), lvset, storageClassConfig)
+	assert.NoError(t, err)
+	names := []string{}
+	for _, set := range preceding {
+		names = append(names, set.lvset.Name)
+	}
+	assert.Equal(t, []string{"manual", "full", "high", "older"}, names)
+
+	device := internal.BlockDevice{Name: "sdb", KName: "sdb", Type: "disk", Size: fmt.Sprintf("%v", 100*Gi)}
+	// the manual LocalVolumeSet rejected the device, and the full one can't claim more
+	claimant := getPrecedingClaimant(preceding, device, "/dev/disk/by-id/wwn-0x1", []internal.BlockDevice{device})
+	assert.Equal(t, "high", claimant)
+
+	small := internal.BlockDevice{Name: "sdc", KName: "sdc", Type: "disk", Size: fmt.Sprintf("%v", 20*Gi)}
+	claimant = getPrecedingClaimant(preceding, small, "/dev/disk/by-id/wwn-0x2", []internal.BlockDevice{small})
+	assert.Equal(t, "manual", claimant)
+
+	// the LocalVolumeSet with the highest priority claims all its devices
+	preceding, err = r.getPrecedingSets(context.TODO(), otherNode, storageClassConfig)
+	assert.NoError(t, err)
+	assert.Empty(t, preceding)
+}
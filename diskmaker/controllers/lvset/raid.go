@@ -0,0 +1,256 @@
+package lvset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	"github.com/openshift/local-storage-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+const (
+	// DegradedRAIDArray is an event reason string
+	DegradedRAIDArray = "DegradedRAIDArray"
+	// RebuildingRAIDArray is an event reason string
+	RebuildingRAIDArray = "RebuildingRAIDArray"
+)
+
+// raidCandidate is a matching device that can become a member of an md array
+type raidCandidate struct {
+	blockDevice internal.BlockDevice
+	deviceID    string
+}
+
+// getRAIDArrays returns the symlinks to md arrays in symLinkDir
+func getRAIDArrays(symLinkDir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(symLinkDir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	links := make([]string, 0)
+	for _, path := range paths {
+		if target, err := os.Readlink(path); err == nil && internal.IsMDArrayPath(target) {
+			links = append(links, path)
+		}
+	}
+	return links, nil
+}
+
+// newRAIDArrayStatus returns the status of the md array named name, as listed in mdstat
+func newRAIDArrayStatus(name, kname string, mdstat []internal.MDArray) localv1.RAIDArrayStatus {
+	for _, array := range mdstat {
+		if array.Name != kname {
+			continue
+		}
+		state := array.State
+		if array.Sync != "" {
+			state = fmt.Sprintf("%s, %s", state, array.Sync)
+		}
+		return localv1.RAIDArrayStatus{
+			Name:          name,
+			State:         state,
+			Degraded:      array.Degraded(),
+			RAIDDevices:   int32(array.RAIDDevices),
+			ActiveDevices: int32(array.ActiveDevices),
+			Members:       append(append([]string{}, array.Members...), array.SpareMembers...),
+		}
+	}
+	return localv1.RAIDArrayStatus{Name: name, State: "missing", Degraded: true}
+}
+
+// needsRebuild returns true if the md array is degraded, and not already recovering onto a spare.
+// The levels without redundancy are never rebuilt, their data is lost with any member.
+func needsRebuild(array localv1.RAIDArrayStatus, mdstat []internal.MDArray, kname string) bool {
+	if !array.Degraded {
+		return false
+	}
+	for _, md := range mdstat {
+		if md.Name == kname {
+			return md.Level != string(localv1.RAID0) && md.Sync == "" && len(md.SpareMembers) == 0
+		}
+	}
+	return false
+}
+
+// isUnclaimed returns true if the device is not symlinked by any storage class and not in use
+func isUnclaimed(devLogger logr.Logger, dev internal.BlockDevice) (bool, error) {
+	devPath, err := dev.GetDevPath()
+	if err != nil {
+		return false, err
+	}
+	lock, locked, existingSymlinks, err := internal.GetPVCreationLock(devPath, common.GetLocalDiskLocationPath())
+	// mdadm opens the members exclusively, the lock is only used for the check
+	defer func() {
+		err := lock.Unlock()
+		if err != nil {
+			devLogger.Error(err, "failed to unlock device")
+		}
+	}()
+	if err != nil {
+		return false, err
+	}
+	return locked && len(existingSymlinks) == 0, nil
+}
+
+// provisionRAIDArrays ensures the PVs of the md arrays already symlinked in symLinkDir exist, and rebuilds the degraded ones.
+// It then groups the remaining candidates into new md arrays of lvset.Spec.RAID.Width devices, up to maxDeviceCount arrays.
+// It returns the health of the arrays sorted by name, to report in the status of the LocalVolumeSet.
+func (r *LocalVolumeSetReconciler) provisionRAIDArrays(
+	lvset *localv1.LocalVolumeSet,
+	reqLogger logr.Logger,
+	storageClass storagev1.StorageClass,
+	symLinkDir string,
+	candidates []raidCandidate,
+) ([]localv1.RAIDArrayStatus, error) {
+	unclaimed := make([]raidCandidate, 0)
+	for _, candidate := range candidates {
+		ok, err := isUnclaimed(reqLogger, candidate.blockDevice)
+		if err != nil {
+			reqLogger.Error(err, "could not check if the device is claimed", "Device.Name", candidate.blockDevice.Name)
+			continue
+		}
+		if ok {
+			unclaimed = append(unclaimed, candidate)
+		}
+	}
+	candidates = unclaimed
+
+	links, err := getRAIDArrays(symLinkDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the md arrays: %w", err)
+	}
+	mdstat, err := internal.ReadMDStat()
+	if err != nil {
+		return nil, err
+	}
+	mountPointMap, err := common.GenerateMountMap(r.runtimeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]localv1.RAIDArrayStatus, 0)
+	for _, link := range links {
+		arrayPath, _ := os.Readlink(link)
+		name := filepath.Base(arrayPath)
+		arrayLogger := reqLogger.WithValues("Array.Name", name)
+		if _, err := os.Stat(arrayPath); os.IsNotExist(err) {
+			arrayLogger.Info("assembling md array")
+			err = internal.MDAssemble(name)
+			if err != nil {
+				arrayLogger.Error(err, "could not assemble md array")
+			}
+			mdstat, err = internal.ReadMDStat()
+			if err != nil {
+				return nil, err
+			}
+		}
+		var kname string
+		if devPath, err := filepath.EvalSymlinks(arrayPath); err == nil {
+			kname = filepath.Base(devPath)
+		}
+
+		status := newRAIDArrayStatus(name, kname, mdstat)
+		if status.Degraded {
+			r.eventReporter.Report(lvset, newDiskEvent(DegradedRAIDArray, fmt.Sprintf("md array %q is degraded: %s", name, status.State), kname, corev1.EventTypeWarning))
+		}
+		if needsRebuild(status, mdstat, kname) && len(candidates) > 0 {
+			replacement := candidates[0]
+			candidates = candidates[1:]
+			arrayLogger.Info("rebuilding md array", "Device.ID", replacement.deviceID)
+			r.eventReporter.Report(lvset, newDiskEvent(RebuildingRAIDArray, fmt.Sprintf("rebuilding md array %q with matching disk %q", name, replacement.deviceID), replacement.blockDevice.KName, corev1.EventTypeNormal))
+			err = internal.MDReplace(arrayPath, replacement.deviceID)
+			if err != nil {
+				r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, "could not rebuild md array", replacement.blockDevice.KName, corev1.EventTypeWarning))
+				arrayLogger.Error(err, "could not rebuild md array")
+			} else {
+				r.recordRAIDClaim(lvset, arrayLogger, replacement, name, storageClass.Name)
+			}
+		}
+		statuses = append(statuses, status)
+
+		if kname == "" {
+			continue
+		}
+		err = common.CreateLocalPV(lvset, r.runtimeConfig, r.cleanupTracker, arrayLogger, storageClass, mountPointMap, r.Client, link, kname, true, map[string]string{})
+		if err != nil {
+			return nil, fmt.Errorf("could not provision md array: %w", err)
+		}
+	}
+
+	width := int(lvset.Spec.RAID.Width)
+	for len(candidates) >= width {
+		if lvset.Spec.MaxDeviceCount != nil && len(statuses) >= int(*lvset.Spec.MaxDeviceCount) {
+			break
+		}
+		members := candidates[:width]
+		candidates = candidates[width:]
+		name := internal.MDArrayName(members[0].deviceID)
+		arrayLogger := reqLogger.WithValues("Array.Name", name)
+		devicePaths := make([]string, 0, width)
+		for _, member := range members {
+			devicePaths = append(devicePaths, member.deviceID)
+		}
+
+		arrayLogger.Info("creating md array", "level", lvset.Spec.RAID.Level, "devices", devicePaths)
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.FoundMatchingDisk, fmt.Sprintf("creating %s md array %q of matching disks", lvset.Spec.RAID.Level, name), "", corev1.EventTypeNormal))
+		arrayPath, err := internal.MDCreate(name, string(lvset.Spec.RAID.Level), devicePaths)
+		if err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, "could not create md array", "", corev1.EventTypeWarning))
+			return nil, fmt.Errorf("could not create md array: %w", err)
+		}
+		for _, member := range members {
+			r.recordRAIDClaim(lvset, arrayLogger, member, name, storageClass.Name)
+		}
+
+		err = os.MkdirAll(symLinkDir, 0755)
+		if err != nil {
+			return nil, fmt.Errorf("could not create symlinkdir: %w", err)
+		}
+		link := filepath.Join(symLinkDir, name)
+		arrayLogger.Info("symlinking", "sourcePath", arrayPath, "targetPath", link)
+		err = os.Symlink(arrayPath, link)
+		if err != nil && !os.IsExist(err) {
+			return nil, err
+		}
+		devPath, err := filepath.EvalSymlinks(arrayPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve md array: %w", err)
+		}
+		mdstat, err = internal.ReadMDStat()
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, newRAIDArrayStatus(name, filepath.Base(devPath), mdstat))
+		err = common.CreateLocalPV(lvset, r.runtimeConfig, r.cleanupTracker, arrayLogger, storageClass, mountPointMap, r.Client, link, filepath.Base(devPath), true, map[string]string{})
+		if err != nil {
+			return nil, fmt.Errorf("could not provision md array: %w", err)
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses, nil
+}
+
+// recordRAIDClaim persists the claim of a member of an md array
+func (r *LocalVolumeSetReconciler) recordRAIDClaim(lvset *localv1.LocalVolumeSet, arrayLogger logr.Logger, member raidCandidate, arrayName, storageClassName string) {
+	err := r.StateStore.RecordClaim(member.deviceID, state.Claim{
+		DeviceName: member.blockDevice.KName,
+		PVName:     common.GeneratePVName(arrayName, r.nodeName, storageClassName),
+		Owner:      fmt.Sprintf("LocalVolumeSet/%s/%s", lvset.Namespace, lvset.Name),
+		ClaimedAt:  time.Now(),
+	})
+	if err != nil {
+		arrayLogger.Error(err, "could not persist the claim of the device", "Device.ID", member.deviceID)
+	}
+}
@@ -0,0 +1,61 @@
+package lvset
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRAIDArrays(t *testing.T) {
+	symLinkDir, err := ioutil.TempDir("", "raid")
+	assert.NoError(t, err)
+	defer os.RemoveAll(symLinkDir)
+
+	arrayLink := filepath.Join(symLinkDir, "lso-0123456789abcdef")
+	err = os.Symlink(filepath.Join(internal.MDDir, "lso-0123456789abcdef"), arrayLink)
+	assert.NoError(t, err)
+	err = os.Symlink("/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", filepath.Join(symLinkDir, "wwn-0x5000c500a0b1c2d3"))
+	assert.NoError(t, err)
+
+	arrays, err := getRAIDArrays(symLinkDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{arrayLink}, arrays)
+}
+
+func TestNewRAIDArrayStatus(t *testing.T) {
+	mdstat := []internal.MDArray{
+		{
+			Name:          "md127",
+			State:         "active",
+			Level:         "raid1",
+			Members:       []string{"sda", "sdc"},
+			FailedMembers: []string{"sdb"},
+			RAIDDevices:   2,
+			ActiveDevices: 1,
+			Sync:          "recovery = 12.6%",
+		},
+	}
+	status := newRAIDArrayStatus("lso-0123456789abcdef", "md127", mdstat)
+	assert.Equal(t, "active, recovery = 12.6%", status.State)
+	assert.True(t, status.Degraded)
+	assert.Equal(t, []string{"sda", "sdc"}, status.Members)
+	assert.False(t, needsRebuild(status, mdstat, "md127"), "the array is already recovering")
+
+	mdstat[0].Sync = ""
+	mdstat[0].Members = []string{"sda"}
+	status = newRAIDArrayStatus("lso-0123456789abcdef", "md127", mdstat)
+	assert.True(t, needsRebuild(status, mdstat, "md127"))
+
+	mdstat[0].Level = "raid0"
+	assert.False(t, needsRebuild(status, mdstat, "md127"), "raid0 arrays have no redundancy to rebuild from")
+	mdstat[0].Level = "raid1"
+
+	status = newRAIDArrayStatus("lso-fedcba9876543210", "md126", mdstat)
+	assert.Equal(t, "missing", status.State)
+	assert.True(t, status.Degraded)
+	assert.False(t, needsRebuild(status, mdstat, "md126"), "missing arrays are assembled, not rebuilt")
+}
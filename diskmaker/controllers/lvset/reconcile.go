@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/go-logr/logr"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/common"
 	"github.com/openshift/local-storage-operator/diskmaker"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
 	"github.com/openshift/local-storage-operator/internal"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
@@ -50,13 +53,13 @@ var log = logf.Log.WithName(ComponentName)
 // Note:
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
-func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.Request) (result ctrl.Result, err error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling LocalVolumeSet")
 
 	// Fetch the LocalVolumeSet instance
-	lvset := &localv1alpha1.LocalVolumeSet{}
-	err := r.Client.Get(ctx, request.NamespacedName, lvset)
+	lvset := &localv1.LocalVolumeSet{}
+	err = r.Client.Get(ctx, request.NamespacedName, lvset)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -91,6 +94,45 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 		return ctrl.Result{}, nil
 	}
 
+	// the outcome of the reconcile is reported in the status of the LocalVolumeSet
+	report := &nodeReport{}
+	defer func() {
+		statusErr := r.updateNodeStatus(ctx, lvset, report, err)
+		if statusErr != nil {
+			reqLogger.Error(statusErr, "could not update the status of the node")
+		}
+	}()
+
+	// no device is provisioned on a node in maintenance, the reconcile resumes once the label is removed
+	if common.IsNodeInMaintenance(r.runtimeConfig.Node) {
+		reqLogger.Info("node is in maintenance, skipping provisioning", "label", common.NodeMaintenanceLabel)
+		report.paused = true
+		return ctrl.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+	}
+
+	switch lvset.Spec.ManagementState {
+	case operatorv1.Unmanaged:
+		// nothing is provisioned for an Unmanaged LocalVolumeSet, its PVs and symlinks are left as they are
+		reqLogger.Info("LocalVolumeSet is unmanaged, skipping provisioning")
+		return ctrl.Result{}, nil
+	case operatorv1.Removed:
+		var remaining int
+		remaining, report.retainedPVs, err = r.tearDown(ctx, lvset, reqLogger)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if remaining > 0 {
+			reqLogger.Info("waiting for the bound PVs of the removed LocalVolumeSet to be released", "remaining", remaining)
+			return ctrl.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+		}
+		// the PVs the admin deletes trigger a new teardown, which removes their symlinks
+		if len(report.retainedPVs) > 0 {
+			reqLogger.Info("the released PVs of the removed LocalVolumeSet are retained, they must be deleted by hand", "pvs", report.retainedPVs)
+		}
+		report.tornDown = true
+		return ctrl.Result{}, nil
+	}
+
 	storageClassName := lvset.Spec.StorageClassName
 
 	// get associated storageclass
@@ -147,15 +189,123 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 		reqLogger.Error(fmt.Errorf("bad rows"), "could not parse all the lsblk rows", "lsblk.BadRows", badRows)
 	}
 
+	// only the multipath device of a LUN is a candidate, never its paths
+	blockDevices, _, err = internal.GroupMultipaths(blockDevices)
+	if err != nil {
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorRunningBlockList, "failed to group the paths of the multipath devices", "", corev1.EventTypeWarning))
+		return ctrl.Result{}, err
+	}
+
+	// symlinks created on the kernel name of devices without a persistent link are pointed to the link they have now
+	migrations := common.MigrateKNameSymlinks(ctx, r.Client, r.StateStore, symLinkDir, r.nodeName, storageClassName, blockDevices, lvset.Spec.IdentityPolicy)
+	for _, migration := range migrations {
+		if migration.Err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorMigratingSymlink, fmt.Sprintf("could not migrate symlink %q: %v", migration.SymlinkPath, migration.Err), migration.Device.KName, corev1.EventTypeWarning))
+			reqLogger.Error(migration.Err, "could not migrate symlink", "symlink", migration.SymlinkPath)
+			continue
+		}
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.MigratedSymlink, fmt.Sprintf("migrated symlink %q from %q to %q", migration.SymlinkPath, migration.OldSource, migration.Source), migration.Device.KName, corev1.EventTypeNormal))
+		reqLogger.Info("migrated symlink", "symlink", migration.SymlinkPath, "oldSource", migration.OldSource, "source", migration.Source)
+	}
+
+	// symlinks that point to another device than the one their PV was created on are fenced
+	for _, fencedPV := range common.FenceMismatchedPVs(ctx, r.Client, symLinkDir, r.nodeName, storageClassName) {
+		if fencedPV.Unfenced {
+			if fencedPV.Err != nil {
+				r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorVerifyingDevice, fmt.Sprintf("could not unfence PV %q: %v", fencedPV.PVName, fencedPV.Err), "", corev1.EventTypeWarning))
+				reqLogger.Error(fencedPV.Err, "could not unfence PV", "pv.Name", fencedPV.PVName)
+				continue
+			}
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.UnfencedPV, fmt.Sprintf("unfenced PV %q, its symlink points to %q again", fencedPV.PVName, fencedPV.Source), "", corev1.EventTypeNormal))
+			reqLogger.Info("unfenced PV", "pv.Name", fencedPV.PVName, "source", fencedPV.Source)
+			continue
+		}
+		if fencedPV.Mismatch == "" {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorVerifyingDevice, fmt.Sprintf("could not verify the device of symlink %q: %v", fencedPV.SymlinkPath, fencedPV.Err), "", corev1.EventTypeWarning))
+			reqLogger.Error(fencedPV.Err, "could not verify the device of symlink", "symlink", fencedPV.SymlinkPath)
+			continue
+		}
+		msg := fmt.Sprintf("fenced PV %q, its symlink pointed to %q whose %s", fencedPV.PVName, fencedPV.Source, fencedPV.Mismatch)
+		if fencedPV.Err != nil {
+			msg = fmt.Sprintf("could not fence PV %q, its symlink points to %q whose %s: %v", fencedPV.PVName, fencedPV.Source, fencedPV.Mismatch, fencedPV.Err)
+		}
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.FencedPV, msg, "", corev1.EventTypeWarning))
+		reqLogger.Info(msg, "pv.Name", fencedPV.PVName)
+	}
+
+	// forget the age of removed devices, a device inserted in their place has to wait for deviceMinAge
+	presentDevices := sets.NewString()
+	for _, blockDevice := range blockDevices {
+		presentDevices.Insert(blockDevice.KName)
+	}
+	r.deviceAgeMap.forgetMissingDevices(presentDevices)
+
 	// find disks that match lvset filters and matchers
 	validDevices, delayedDevices := r.getValidDevices(reqLogger, lvset, blockDevices)
+	for _, blockDevice := range delayedDevices {
+		report.delayedDevices = append(report.delayedDevices, blockDevice.KName)
+	}
+	sort.Strings(report.delayedDevices)
+
+	// with the Manual claimPolicy, only the devices approved in the LocalVolumeSetApproval of this node are provisioned
+	manualClaim := lvset.Spec.ClaimPolicy == localv1.ClaimPolicyManual
+	var approval *localv1alpha1.LocalVolumeSetApproval
+	approvedDevices, rejectedDevices, alreadyPending := sets.NewString(), sets.NewString(), sets.NewString()
+	pendingDevices := make([]localv1alpha1.PendingDevice, 0)
+	if manualClaim {
+		approval, err = r.getOrCreateApproval(ctx, lvset)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		approvedDevices.Insert(approval.Spec.ApprovedDevices...)
+		rejectedDevices.Insert(approval.Spec.RejectedDevices...)
+		for _, device := range approval.Status.PendingDevices {
+			alreadyPending.Insert(device.DeviceID)
+		}
+	}
+
+	// new invalid specs are rejected by the validating webhook, the ones admitted before a rule was added, or without
+	// the webhook, are reported and provisioned as far as possible, their PVs are kept
+	if errs := common.ValidateLocalVolumeSet(lvset); len(errs) > 0 {
+		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, fmt.Sprintf("invalid LocalVolumeSet: %v", errs.ToAggregate()), "", corev1.EventTypeWarning))
+		reqLogger.Error(errs.ToAggregate(), "invalid LocalVolumeSet")
+	}
+
+	// formatted shared filesystem devices are not matched by the filters anymore, their PVs are ensured separately.
+	// No other device is claimed once the shared filesystem of the node is provisioned.
+	if lvset.Spec.SharedFilesystem != nil {
+		sharedDevices, err := r.ensureSharedFilesystems(lvset, reqLogger, *storageClass, symLinkDir)
+		if err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, "provisioning of the shared filesystem failed", "", corev1.EventTypeWarning))
+			return ctrl.Result{}, err
+		}
+		if sharedDevices > 0 {
+			validDevices = nil
+		}
+	}
+
+	// opened encrypted devices are not matched by the filters anymore, their PVs are ensured separately
+	if lvset.Spec.Encryption != nil {
+		err = r.ensureEncryptedPVs(ctx, lvset, reqLogger, *storageClass, symLinkDir)
+		if err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, "provisioning of encrypted disks failed", "", corev1.EventTypeWarning))
+			return ctrl.Result{}, err
+		}
+	}
+
+	// the devices this lvset shares with LocalVolumeSets of the node that have precedence are left to them
+	precedingSets, err := r.getPrecedingSets(ctx, lvset, provisionerConfig.StorageClassConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// process valid devices
 	var noMatch []string
+	raidCandidates := make([]raidCandidate, 0)
 	for _, blockDevice := range validDevices {
 		devLogger := reqLogger.WithValues("Device.Name", blockDevice.Name)
 
-		symlinkSourcePath, symlinkPath, idExists, err := common.GetSymLinkSourceAndTarget(blockDevice, symLinkDir)
+		symlinkSourcePath, symlinkPath, idExists, err := common.GetSymLinkSourceAndTarget(blockDevice, symLinkDir, lvset.Spec.IdentityPolicy)
 		if err != nil {
 			devLogger.Error(err, "error while discovering symlink source and target")
 			continue
@@ -167,13 +317,48 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 
 		// validate MaxDeviceCount
 		var alreadyProvisionedCount int
-		var currentDeviceSymlinked bool
-		alreadyProvisionedCount, currentDeviceSymlinked, noMatch, err = getAlreadySymlinked(symLinkDir, blockDevice, blockDevices)
-		_ = currentDeviceSymlinked
+		var currentDeviceSymlink string
+		alreadyProvisionedCount, currentDeviceSymlink, noMatch, err = getAlreadySymlinked(symLinkDir, blockDevice, blockDevices)
 		if err != nil && lvset.Spec.MaxDeviceCount != nil {
 			r.eventReporter.Report(lvset, newDiskEvent(ErrorListingExistingSymlinks, "error determining already provisioned disks", "", corev1.EventTypeWarning))
 			return ctrl.Result{}, fmt.Errorf("could not determine how many devices are already provisioned: %w", err)
 		}
+		currentDeviceSymlinked := currentDeviceSymlink != ""
+		// devices symlinked by another name, such as their kernel name before they had a persistent link, keep their symlink and PV
+		if currentDeviceSymlinked {
+			symlinkPath = currentDeviceSymlink
+		}
+		if !currentDeviceSymlinked {
+			if claimant := getPrecedingClaimant(precedingSets, blockDevice, symlinkSourcePath, blockDevices); claimant != "" {
+				devLogger.Info("leaving device to LocalVolumeSet with precedence", "LocalVolumeSet", claimant)
+				r.eventReporter.Report(lvset, newDiskEvent(DeviceLeftToPrecedingSet, fmt.Sprintf("matching disk is left to LocalVolumeSet %q, that has precedence", claimant), blockDevice.KName, corev1.EventTypeNormal))
+				continue
+			}
+		}
+		// devices that are already provisioned don't need an approval
+		if manualClaim && !currentDeviceSymlinked {
+			if rejectedDevices.Has(symlinkSourcePath) {
+				devLogger.Info("skipping rejected device", "Device.ID", symlinkSourcePath)
+				continue
+			}
+			if !approvedDevices.Has(symlinkSourcePath) {
+				devLogger.Info("device is waiting for approval", "Device.ID", symlinkSourcePath)
+				// the event is only reported when the device becomes pending, not on every reconcile
+				if !alreadyPending.Has(symlinkSourcePath) {
+					r.eventReporter.Report(lvset, newDiskEvent(DeviceWaitingForApproval, fmt.Sprintf("matching disk %q is waiting for approval in LocalVolumeSetApproval %q", symlinkSourcePath, approval.Name), blockDevice.KName, corev1.EventTypeNormal))
+				}
+				pendingDevices = append(pendingDevices, newPendingDevice(symlinkSourcePath, blockDevice))
+				continue
+			}
+		}
+		// the members of md arrays are provisioned after all the devices are matched
+		if lvset.Spec.RAID != nil {
+			if blockDevice.IsRAID() {
+				continue
+			}
+			raidCandidates = append(raidCandidates, raidCandidate{blockDevice: blockDevice, deviceID: symlinkSourcePath})
+			continue
+		}
 		withinMax := true
 		if lvset.Spec.MaxDeviceCount != nil {
 			withinMax = int32(alreadyProvisionedCount) < *lvset.Spec.MaxDeviceCount
@@ -183,29 +368,107 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 			break
 		}
 
+		// the first device is formatted and mounted as the shared filesystem of the node
+		if lvset.Spec.SharedFilesystem != nil {
+			// the device is formatted when its filesystem is not found, through a kernel name
+			// it could be another disk after a reboot
+			if !idExists {
+				devLogger.Info("not provisioning shared filesystem on a device without persistent link")
+				r.eventReporter.Report(lvset, newDiskEvent(SharedDeviceWithoutPersistentLink, "matching disk has no persistent link, it can't be provisioned as a shared filesystem", blockDevice.KName, corev1.EventTypeWarning))
+				continue
+			}
+			devLogger.Info("provisioning shared filesystem")
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.FoundMatchingDisk, "provisioning matching disk as a shared filesystem", blockDevice.KName, corev1.EventTypeNormal))
+			linkPath, err := r.claimSharedDevice(devLogger, blockDevice, symlinkSourcePath, filepath.Join(symLinkDir, sharedDirName))
+			if err == nil && linkPath != "" {
+				// the fingerprint of the device is recorded before it is formatted, it is verified first
+				size, _ := blockDevice.GetSize()
+				err = r.StateStore.RecordClaim(symlinkSourcePath, state.Claim{
+					DeviceName: blockDevice.KName,
+					Owner:      fmt.Sprintf("LocalVolumeSet/%s/%s", lvset.Namespace, lvset.Name),
+					ClaimedAt:  time.Now(),
+					Serial:     blockDevice.Serial,
+					WWN:        blockDevice.WWN,
+					Size:       size,
+				})
+				if err != nil {
+					devLogger.Error(err, "could not persist the claim of the device")
+				}
+				err = r.ensureSharedFilesystem(lvset, devLogger, *storageClass, symLinkDir, linkPath)
+			}
+			if err != nil {
+				r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, "provisioning failed", blockDevice.KName, corev1.EventTypeWarning))
+				return ctrl.Result{}, fmt.Errorf("could not provision shared filesystem: %w", err)
+			}
+			if linkPath == "" {
+				continue
+			}
+			devLogger.Info("provisioning succeeded")
+			break
+		}
+
 		mountPointMap, err := common.GenerateMountMap(r.runtimeConfig)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 
+		// encrypted devices are symlinked through their device-mapper node
+		linkSourcePath := symlinkSourcePath
+		if lvset.Spec.Encryption != nil {
+			devLogger.Info("encrypting device", "Device.ID", symlinkSourcePath)
+			linkSourcePath, err = r.openEncryptedDevice(ctx, lvset, symlinkSourcePath)
+			if err != nil {
+				r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorEncryptingDisk, "could not encrypt disk", blockDevice.KName, corev1.EventTypeWarning))
+				return ctrl.Result{}, fmt.Errorf("could not encrypt disk: %w", err)
+			}
+		}
+
 		devLogger.Info("provisioning PV")
 		r.eventReporter.Report(lvset, newDiskEvent(diskmaker.FoundMatchingDisk, "provisioning matching disk", blockDevice.KName, corev1.EventTypeNormal))
-		err = r.provisionPV(lvset, devLogger, blockDevice, *storageClass, mountPointMap, symlinkSourcePath, symlinkPath, idExists)
+		err = r.provisionPV(lvset, devLogger, blockDevice, *storageClass, mountPointMap, linkSourcePath, symlinkPath, idExists)
 		if err != nil {
 			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, "provisioning failed", blockDevice.KName, corev1.EventTypeWarning))
 			return ctrl.Result{}, fmt.Errorf("could not provision disk: %w", err)
 		}
 		devLogger.Info("provisioning succeeded")
+		size, _ := blockDevice.GetSize()
+		err = r.StateStore.RecordClaim(symlinkSourcePath, state.Claim{
+			DeviceName: blockDevice.KName,
+			PVName:     common.GeneratePVName(filepath.Base(symlinkPath), r.runtimeConfig.Node.Name, storageClass.Name),
+			Owner:      fmt.Sprintf("LocalVolumeSet/%s/%s", lvset.Namespace, lvset.Name),
+			ClaimedAt:  time.Now(),
+			Serial:     blockDevice.Serial,
+			WWN:        blockDevice.WWN,
+			Size:       size,
+		})
+		if err != nil {
+			devLogger.Error(err, "could not persist the claim of the device")
+		}
 
 	}
+	if lvset.Spec.RAID != nil {
+		report.raidArrays, err = r.provisionRAIDArrays(lvset, reqLogger, *storageClass, symLinkDir, raidCandidates)
+		if err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorProvisioningDisk, "provisioning of md arrays failed", "", corev1.EventTypeWarning))
+			return ctrl.Result{}, err
+		}
+	}
+	if manualClaim {
+		err = r.updatePendingDevices(ctx, approval, pendingDevices)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 	if len(noMatch) > 0 {
 		reqLogger.Info("found stale symLink Entries", "storageClass.Name", storageClassName, "paths.List", noMatch, "directory", symLinkDir)
 	}
 
-	// shorten the requeueTime if there are delayed devices
+	// shorten the requeueTime if there are delayed devices, so that they are claimed as soon as they are old enough
 	requeueTime := time.Minute
-	if len(delayedDevices) > 1 {
-		requeueTime = deviceMinAge / 2
+	for _, blockDevice := range delayedDevices {
+		if remaining := r.deviceAgeMap.timeUntilOld(blockDevice.KName) + time.Second; remaining < requeueTime {
+			requeueTime = remaining
+		}
 	}
 
 	return ctrl.Result{Requeue: true, RequeueAfter: requeueTime}, nil
@@ -217,7 +480,7 @@ func (r *LocalVolumeSetReconciler) Reconcile(ctx context.Context, request ctrl.R
 // if the waitingDevices list is nonempty, the operator should requeueue
 func (r *LocalVolumeSetReconciler) getValidDevices(
 	reqLogger logr.Logger,
-	lvset *localv1alpha1.LocalVolumeSet,
+	lvset *localv1.LocalVolumeSet,
 	blockDevices []internal.BlockDevice,
 ) ([]internal.BlockDevice, []internal.BlockDevice) {
 	validDevices := make([]internal.BlockDevice, 0)
@@ -265,7 +528,7 @@ DeviceLoop:
 			continue DeviceLoop
 		}
 
-		for name, matcher := range matcherMap {
+		for name, matcher := range MatcherMap {
 			matcherLogger := devLogger.WithValues("matcher.Name", name)
 			valid, err := matcher(blockDevice, lvset.Spec.DeviceInclusionSpec)
 			if err != nil {
@@ -287,40 +550,46 @@ DeviceLoop:
 
 // returns:
 // count of already symlinked from validDevices
-// if the currentDevice is alreadysymlinks
+// the symlink of the currentDevice if it is already symlinked
 // list of symlinks that don't match validDevices
 // err
-func getAlreadySymlinked(symLinkDir string, currentDevice internal.BlockDevice, validDevices []internal.BlockDevice) (int, bool, []string, error) {
+func getAlreadySymlinked(symLinkDir string, currentDevice internal.BlockDevice, validDevices []internal.BlockDevice) (int, string, []string, error) {
 	count := 0
 	noMatch := make([]string, 0)
-	currentDeviceSymlinked := false
+	currentDeviceSymlink := ""
 	paths, err := filepath.Glob(filepath.Join(symLinkDir, "/*"))
 	if err != nil {
-		return 0, currentDeviceSymlinked, []string{}, err
+		return 0, currentDeviceSymlink, []string{}, err
 	}
 
 PathLoop:
 	for _, path := range paths {
+		// encrypted devices and md arrays are symlinked through their device-mapper or md node, which is never a valid device,
+		// and the symlinks of fenced PVs to no device
+		if target, err := os.Readlink(path); err == nil && (internal.IsLUKSMapperPath(target) || internal.IsMDArrayPath(target) || internal.IsFencedPath(target)) {
+			count++
+			continue
+		}
 		for _, device := range validDevices {
 			isMatch, err := internal.PathEvalsToDiskLabel(path, device.KName)
 			if err != nil {
-				return 0, currentDeviceSymlinked, []string{}, err
+				return 0, currentDeviceSymlink, []string{}, err
 			}
 			if isMatch {
 				count++
 				if currentDevice.KName == device.KName {
-					currentDeviceSymlinked = true
+					currentDeviceSymlink = path
 				}
 				continue PathLoop
 			}
 		}
 		noMatch = append(noMatch, path)
 	}
-	return count, currentDeviceSymlinked, noMatch, nil
+	return count, currentDeviceSymlink, noMatch, nil
 }
 
 func (r *LocalVolumeSetReconciler) provisionPV(
-	obj *localv1alpha1.LocalVolumeSet,
+	obj *localv1.LocalVolumeSet,
 	devLogger logr.Logger,
 	dev internal.BlockDevice,
 	storageClass storagev1.StorageClass,
@@ -335,6 +604,13 @@ func (r *LocalVolumeSetReconciler) provisionPV(
 	if err != nil {
 		return err
 	}
+	// the symlinks of encrypted devices evaluate to their device-mapper node
+	if internal.IsLUKSMapperPath(symlinkSourcePath) {
+		devLabelPath, err = filepath.EvalSymlinks(symlinkSourcePath)
+		if err != nil {
+			return err
+		}
+	}
 
 	symLinkDir := filepath.Dir(symlinkPath)
 
@@ -432,8 +708,13 @@ func (r *LocalVolumeSetReconciler) provisionPV(
 type LocalVolumeSetReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	Client        client.Client
-	Scheme        *runtime.Scheme
+	Client client.Client
+	Scheme *runtime.Scheme
+	// DeviceEvents are the add and remove events of the block devices of the node.
+	// All the LocalVolumeSets are reconciled on each event, when set.
+	DeviceEvents <-chan event.GenericEvent
+	// StateStore persists the device ages and claims across restarts, when set
+	StateStore    *state.Store
 	nodeName      string
 	eventReporter *eventReporter
 	// map from KNAME of device to time when the device was first observed since the process started
@@ -474,14 +755,14 @@ func (r *LocalVolumeSetReconciler) SetupWithManager(mgr ctrl.Manager, cleanupTra
 
 	r.nodeName = nodeName
 	r.eventReporter = newEventReporter(mgr.GetEventRecorderFor(ComponentName))
-	r.deviceAgeMap = newAgeMap(clock)
+	r.deviceAgeMap = newAgeMap(clock, r.StateStore)
 	r.cleanupTracker = cleanupTracker
 	r.runtimeConfig = runtimeConfig
 	r.deleter = provDeleter.NewDeleter(runtimeConfig, cleanupTracker)
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		// set to 1 explicitly, despite it being the default, as the reconciler is not thread-safe.
 		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
-		For(&localv1alpha1.LocalVolumeSet{}).
+		For(&localv1.LocalVolumeSet{}).
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
 			OwnerType: &localv1.LocalVolume{},
 		}).
@@ -513,7 +794,28 @@ func (r *LocalVolumeSetReconciler) SetupWithManager(mgr ctrl.Manager, cleanupTra
 				}
 			},
 		}).
-		Complete(r)
+		// reconcile right away when a device of this node is approved or rejected
+		Watches(&source.Kind{Type: &localv1alpha1.LocalVolumeSetApproval{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForApproval))
+	// reconcile right away when a device is added or removed, instead of waiting for the next requeue
+	if r.DeviceEvents != nil {
+		builder = builder.Watches(&source.Channel{Source: r.DeviceEvents}, handler.EnqueueRequestsFromMapFunc(r.requestsForDeviceEvent))
+	}
+	return builder.Complete(r)
+}
+
+// requestsForDeviceEvent returns a request for each LocalVolumeSet in the watched namespace
+func (r *LocalVolumeSetReconciler) requestsForDeviceEvent(obj client.Object) []reconcile.Request {
+	lvSetList := &localv1.LocalVolumeSetList{}
+	err := r.Client.List(context.TODO(), lvSetList, client.InNamespace(watchNamespace))
+	if err != nil {
+		log.Error(err, "could not list LocalVolumeSets for device event", "Device.Name", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(lvSetList.Items))
+	for _, lvset := range lvSetList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: lvset.Name, Namespace: lvset.Namespace}})
+	}
+	return requests
 }
 
 func handlePVChange(runtimeConfig *provCommon.RuntimeConfig, pv *corev1.PersistentVolume, q workqueue.RateLimitingInterface, isDelete bool) {
@@ -14,6 +14,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -21,6 +22,7 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	crFake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	provCache "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/cache"
 	provCommon "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
 	"sigs.k8s.io/sig-storage-local-static-provisioner/pkg/deleter"
@@ -99,7 +101,7 @@ func newFakeLocalVolumeSetReconciler(t *testing.T, objs ...runtime.Object) (*Loc
 		Client:         fakeClient,
 		Scheme:         scheme,
 		eventReporter:  newEventReporter(fakeRecorder),
-		deviceAgeMap:   newAgeMap(fakeClock),
+		deviceAgeMap:   newAgeMap(fakeClock, nil),
 		cleanupTracker: &provDeleter.CleanupStatusTracker{ProcTable: deleter.NewProcTable()},
 		runtimeConfig:  runtimeConfig,
 		deleter:        provDeleter.NewDeleter(runtimeConfig, cleanupTracker),
@@ -145,3 +147,17 @@ func (a apiUtil) DeleteJob(jobName string, namespace string) error {
 	err = a.client.Delete(context.TODO(), job)
 	return err
 }
+
+func TestRequestsForDeviceEvent(t *testing.T) {
+	lvsets := []runtime.Object{
+		&v1api.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "lvset-a", Namespace: watchNamespace}},
+		&v1api.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "lvset-b", Namespace: watchNamespace}},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t, lvsets...)
+
+	requests := r.requestsForDeviceEvent(&metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "sdb"}})
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "lvset-a", Namespace: watchNamespace}},
+		{NamespacedName: types.NamespacedName{Name: "lvset-b", Namespace: watchNamespace}},
+	}, requests)
+}
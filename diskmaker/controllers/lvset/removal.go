@@ -0,0 +1,107 @@
+package lvset
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	provCommon "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
+)
+
+// tearDown deletes the PVs of a Removed LocalVolumeSet on this node that are not bound to a claim, with their symlinks.
+// Bound PVs are kept until they are released, the deleter wipes and deletes them then, and their symlinks are removed
+// by the next teardown. It returns the number of PVs of the LocalVolumeSet left for the deleter on the node, and the
+// sorted names of the Released PVs whose reclaim policy is Retain: nothing deletes them, they are left to the admin.
+func (r *LocalVolumeSetReconciler) tearDown(ctx context.Context, lvset *localv1.LocalVolumeSet, reqLogger logr.Logger) (int, []string, error) {
+	pvs := &corev1.PersistentVolumeList{}
+	err := r.Client.List(ctx, pvs, client.MatchingLabels{
+		common.PVOwnerKindLabel:      localv1.LocalVolumeSetKind,
+		common.PVOwnerNamespaceLabel: lvset.Namespace,
+		common.PVOwnerNameLabel:      lvset.Name,
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not list the PVs of the LocalVolumeSet: %w", err)
+	}
+
+	provisionedBy := common.GetProvisionedByValue(*r.runtimeConfig.Node)
+	remaining := 0
+	retained := sets.NewString()
+	kept := sets.NewString()
+	removed := sets.NewString()
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Annotations[provCommon.AnnProvisionedBy] != provisionedBy {
+			continue
+		}
+		symlinkPath := ""
+		if pv.Spec.Local != nil {
+			symlinkPath = pv.Spec.Local.Path
+		}
+		if pv.Status.Phase == corev1.VolumeReleased && pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain {
+			kept.Insert(symlinkPath)
+			retained.Insert(pv.Name)
+			continue
+		}
+		if pv.Status.Phase == corev1.VolumeBound || pv.Status.Phase == corev1.VolumeReleased {
+			kept.Insert(symlinkPath)
+			remaining++
+			continue
+		}
+		// the PV is not deleted if it was bound since it was listed
+		err = r.Client.Delete(ctx, pv, client.Preconditions{ResourceVersion: &pv.ResourceVersion})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return remaining, retained.List(), fmt.Errorf("could not delete PV %q: %w", pv.Name, err)
+		}
+		reqLogger.Info("deleted the PV of the removed LocalVolumeSet", "pv.Name", pv.Name)
+		removed.Insert(symlinkPath)
+	}
+
+	// the symlinks left by the PVs deleted by the deleter are only known when no other LocalVolumeSet
+	// creates symlinks for the storageclass
+	shared, err := r.isStorageClassShared(ctx, lvset)
+	if err != nil {
+		return remaining, retained.List(), err
+	}
+	symLinkDir := filepath.Join(common.GetLocalDiskLocationPath(), lvset.Spec.StorageClassName)
+	entries, err := os.ReadDir(symLinkDir)
+	if err != nil && !os.IsNotExist(err) {
+		return remaining, retained.List(), fmt.Errorf("could not read symlink dir %q: %w", symLinkDir, err)
+	}
+	for _, entry := range entries {
+		symlinkPath := filepath.Join(symLinkDir, entry.Name())
+		if entry.Type()&os.ModeSymlink == 0 || kept.Has(symlinkPath) || (shared && !removed.Has(symlinkPath)) {
+			continue
+		}
+		err = os.Remove(symlinkPath)
+		if err != nil && !os.IsNotExist(err) {
+			return remaining, retained.List(), fmt.Errorf("could not remove symlink %q: %w", symlinkPath, err)
+		}
+		reqLogger.Info("removed the symlink of the removed LocalVolumeSet", "symlink", symlinkPath)
+	}
+	return remaining, retained.List(), nil
+}
+
+// isStorageClassShared returns true when another LocalVolumeSet of the namespace that is not Removed has the same storageclass
+func (r *LocalVolumeSetReconciler) isStorageClassShared(ctx context.Context, lvset *localv1.LocalVolumeSet) (bool, error) {
+	lvsets := &localv1.LocalVolumeSetList{}
+	err := r.Client.List(ctx, lvsets, client.InNamespace(lvset.Namespace))
+	if err != nil {
+		return false, fmt.Errorf("could not list LocalVolumeSets: %w", err)
+	}
+	for _, other := range lvsets.Items {
+		if other.Name != lvset.Name && other.Spec.StorageClassName == lvset.Spec.StorageClassName &&
+			other.Spec.ManagementState != operatorv1.Removed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,98 @@
+package lvset
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestTearDown(t *testing.T) {
+	testcases := []struct {
+		label            string
+		otherState       operatorv1.ManagementState
+		expectedSymlinks []string
+	}{
+		{
+			label:            "case 1: the storageclass is shared, only the symlinks of the deleted PVs are removed",
+			otherState:       operatorv1.Managed,
+			expectedSymlinks: []string{"bound", "orphan", "released", "retained"},
+		},
+		{
+			label:            "case 2: the storageclass isn't shared anymore, all the symlinks without PV are removed",
+			otherState:       operatorv1.Removed,
+			expectedSymlinks: []string{"bound", "released", "retained"},
+		},
+	}
+
+	for _, tc := range testcases {
+		localDiskLocation := t.TempDir()
+		os.Setenv(common.LocalDiskLocationEnv, localDiskLocation)
+		defer os.Unsetenv(common.LocalDiskLocationEnv)
+		symLinkDir := filepath.Join(localDiskLocation, "fast")
+		assert.NoError(t, os.MkdirAll(filepath.Join(symLinkDir, "directory"), 0755))
+		for _, name := range []string{"available", "bound", "released", "retained", "orphan"} {
+			assert.NoError(t, os.Symlink("/dev/"+name, filepath.Join(symLinkDir, name)))
+		}
+
+		node1 := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "uid1"}}
+		node2 := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2", UID: "uid2"}}
+		newPV := func(name string, node corev1.Node, phase corev1.PersistentVolumePhase) *corev1.PersistentVolume {
+			pv := newOwnedPV(name, "fast", node, "10Gi", phase)
+			pv.Spec.Local = &corev1.LocalVolumeSource{Path: filepath.Join(symLinkDir, name)}
+			return pv
+		}
+		lvset := &localv1.LocalVolumeSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "fast", Namespace: "default"},
+			Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "fast", ManagementState: operatorv1.Removed},
+		}
+		other := &localv1.LocalVolumeSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+			Spec:       localv1.LocalVolumeSetSpec{StorageClassName: "fast", ManagementState: tc.otherState},
+		}
+		retainedPV := newPV("retained", node1, corev1.VolumeReleased)
+		retainedPV.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+		r, _ := newFakeLocalVolumeSetReconciler(t,
+			lvset,
+			other,
+			newPV("available", node1, corev1.VolumeAvailable),
+			newPV("bound", node1, corev1.VolumeBound),
+			newPV("released", node1, corev1.VolumeReleased),
+			retainedPV,
+			newPV("other-node", node2, corev1.VolumeAvailable),
+		)
+		r.nodeName = node1.Name
+		r.runtimeConfig.Node = &node1
+
+		remaining, retained, err := r.tearDown(context.TODO(), lvset, logf.Log.WithName("test"))
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+		assert.Equalf(t, 2, remaining, "[%s] the bound and released PVs are kept", tc.label)
+		assert.Equalf(t, []string{"retained"}, retained, "[%s] the retained PVs are left to the admin", tc.label)
+
+		pvs := &corev1.PersistentVolumeList{}
+		assert.NoError(t, r.Client.List(context.TODO(), pvs))
+		pvNames := []string{}
+		for _, pv := range pvs.Items {
+			pvNames = append(pvNames, pv.Name)
+		}
+		assert.ElementsMatchf(t, []string{"bound", "released", "retained", "other-node"}, pvNames, "[%s]", tc.label)
+
+		entries, err := os.ReadDir(symLinkDir)
+		assert.NoError(t, err)
+		symlinks := []string{}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink != 0 {
+				symlinks = append(symlinks, entry.Name())
+			}
+		}
+		assert.Equalf(t, tc.expectedSymlinks, symlinks, "[%s]", tc.label)
+	}
+}
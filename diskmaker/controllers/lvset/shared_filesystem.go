@@ -0,0 +1,292 @@
+package lvset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker"
+	"github.com/openshift/local-storage-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// sharedDirName is the directory of the symlink dir that holds the symlinks of the shared filesystem devices and their mountpoints
+	sharedDirName = ".shared"
+	// sharedMountSuffix suffixes the symlink of a shared filesystem device to name its mountpoint
+	sharedMountSuffix = ".mnt"
+	// sharedFilesystemType is the only filesystem with project quotas
+	sharedFilesystemType = "xfs"
+	// sharedProjectIDBits are the low bits of the XFS project IDs that number the directories of a device,
+	// the high bits are the offset of the device. It bounds the volumeCount of the SharedFilesystemSpec.
+	sharedProjectIDBits = 16
+)
+
+// getSharedDevices returns the symlinks of the shared filesystem devices in sharedDir
+func getSharedDevices(sharedDir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(sharedDir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	links := make([]string, 0)
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			links = append(links, path)
+		}
+	}
+	return links, nil
+}
+
+// getSharedVolumeName returns the name of the nth directory PV of the shared filesystem device linked at linkPath.
+// It is both the name of the directory on the shared filesystem and the name of its bind mount in the symlink dir.
+func getSharedVolumeName(linkPath string, n int) string {
+	return fmt.Sprintf("%s-vol-%d", filepath.Base(linkPath), n)
+}
+
+// getSharedProjectID returns the XFS project ID of the nth directory PV of the shared filesystem device linked at linkPath.
+// The IDs of each device start at an offset derived from its link, so that the directories of the shared filesystems
+// don't reuse the project IDs of one another, nor the low IDs that the host may use for its own projects.
+func getSharedProjectID(linkPath string, n int) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(filepath.Base(linkPath)))
+	offset := hash.Sum32()%(1<<(32-sharedProjectIDBits)-1) + 1
+	return offset<<sharedProjectIDBits | uint32(n)
+}
+
+// getSharedVolumeSize returns the project quota of each directory PV, given the capacity of the shared filesystem
+func getSharedVolumeSize(spec *localv1.SharedFilesystemSpec, capacityBytes int64) int64 {
+	if spec.VolumeSize != nil {
+		return spec.VolumeSize.Value()
+	}
+	return capacityBytes / int64(spec.VolumeCount)
+}
+
+// claimSharedDevice symlinks the device in the shared dir, unless it is already claimed.
+func (r *LocalVolumeSetReconciler) claimSharedDevice(devLogger logr.Logger, dev internal.BlockDevice, symlinkSourcePath, sharedDir string) (string, error) {
+	devLabelPath, err := dev.GetDevPath()
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(sharedDir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("could not create the shared dir: %w", err)
+	}
+
+	// get PV creation lock which checks for existing symlinks to this device
+	pvLock, pvLocked, existingSymlinks, err := internal.GetSharedPVCreationLock(devLabelPath, common.GetLocalDiskLocationPath())
+	defer func() {
+		err := pvLock.Unlock()
+		if err != nil {
+			devLogger.Error(err, "failed to unlock device")
+		}
+	}()
+	if len(existingSymlinks) > 0 { // already claimed
+		return "", nil
+	} else if err != nil || !pvLocked {
+		devLogger.Error(err, "not provisioning, could not get lock")
+		return "", err
+	}
+
+	linkPath := filepath.Join(sharedDir, filepath.Base(symlinkSourcePath))
+	devLogger.Info("symlinking", "sourcePath", symlinkSourcePath, "targetPath", linkPath)
+	err = os.Symlink(symlinkSourcePath, linkPath)
+	if err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	return linkPath, nil
+}
+
+// ensureSharedFilesystems ensures that the shared filesystem devices already symlinked in the symlink dir are mounted,
+// and that their directory PVs exist. It returns the number of shared filesystem devices.
+func (r *LocalVolumeSetReconciler) ensureSharedFilesystems(
+	lvset *localv1.LocalVolumeSet,
+	reqLogger logr.Logger,
+	storageClass storagev1.StorageClass,
+	symLinkDir string,
+) (int, error) {
+	devices, err := getSharedDevices(filepath.Join(symLinkDir, sharedDirName))
+	if err != nil {
+		return 0, fmt.Errorf("could not list the shared filesystem devices: %w", err)
+	}
+	for _, linkPath := range devices {
+		err = r.ensureSharedFilesystem(lvset, reqLogger.WithValues("Device.ID", filepath.Base(linkPath)), storageClass, symLinkDir, linkPath)
+		if err != nil {
+			return len(devices), err
+		}
+	}
+	return len(devices), nil
+}
+
+// ensureSharedFilesystem formats the device linked at linkPath with XFS if needed and mounts it with project quotas.
+// It then bind mounts a directory limited by its own project quota in the symlink dir for each PV, and ensures the PVs exist.
+// The quotas of the directories are set again each time their PVs are created, which resets them after a cleanup.
+func (r *LocalVolumeSetReconciler) ensureSharedFilesystem(
+	lvset *localv1.LocalVolumeSet,
+	devLogger logr.Logger,
+	storageClass storagev1.StorageClass,
+	symLinkDir string,
+	linkPath string,
+) error {
+	devicePath, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		return fmt.Errorf("could not resolve the shared filesystem device: %w", err)
+	}
+	kname := filepath.Base(devicePath)
+	mountPointMap, err := common.GenerateMountMap(r.runtimeConfig)
+	if err != nil {
+		return err
+	}
+
+	mountPath := linkPath + sharedMountSuffix
+	if !mountPointMap.Has(mountPath) {
+		err = r.mountSharedFilesystem(lvset, devLogger, linkPath, devicePath, mountPath)
+		if err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorMountingFilesystem, "could not mount the shared filesystem", kname, corev1.EventTypeWarning))
+			return err
+		}
+		mountPointMap.Insert(mountPath)
+	}
+	capacityBytes, err := r.runtimeConfig.VolUtil.GetFsCapacityByte(mountPath)
+	if err != nil {
+		return fmt.Errorf("could not read the capacity of the shared filesystem: %w", err)
+	}
+	volumeSize := getSharedVolumeSize(lvset.Spec.SharedFilesystem, capacityBytes)
+
+	for n := 1; n <= int(lvset.Spec.SharedFilesystem.VolumeCount); n++ {
+		volumeName := getSharedVolumeName(linkPath, n)
+		volumeDir := filepath.Join(mountPath, volumeName)
+		pvPath := filepath.Join(symLinkDir, volumeName)
+		pvName := common.GeneratePVName(volumeName, r.runtimeConfig.Node.Name, storageClass.Name)
+		volLogger := devLogger.WithValues("pv.Name", pvName)
+
+		err = r.ensureSharedVolumeMount(volLogger, mountPointMap, volumeDir, pvPath)
+		if err != nil {
+			r.eventReporter.Report(lvset, newDiskEvent(diskmaker.ErrorMountingFilesystem, "could not mount a directory of the shared filesystem", kname, corev1.EventTypeWarning))
+			return err
+		}
+
+		// the project of the directory is only (re)set when its PV is created, as it walks the contents of the directory
+		_, pvExists := r.runtimeConfig.Cache.GetPV(pvName)
+		if !pvExists && !r.cleanupTracker.InProgress(pvName, false) {
+			projectID := getSharedProjectID(linkPath, n)
+			err = internal.XFSSetProject(mountPath, volumeDir, projectID)
+			if err == nil {
+				err = internal.XFSLimitProject(mountPath, projectID, volumeSize)
+			}
+			if err != nil {
+				return fmt.Errorf("could not set the project quota of %q: %w", volumeDir, err)
+			}
+		}
+
+		err = common.CreateLocalPV(
+			lvset,
+			r.runtimeConfig,
+			r.cleanupTracker,
+			volLogger,
+			storageClass,
+			mountPointMap,
+			r.Client,
+			pvPath,
+			kname,
+			false,
+			map[string]string{},
+		)
+		if err != nil {
+			return fmt.Errorf("could not provision a directory of the shared filesystem: %w", err)
+		}
+	}
+	return nil
+}
+
+// mountSharedFilesystem formats the device linked at linkPath with XFS if it has no filesystem,
+// and mounts it at mountPath with project quotas. It is only formatted if it is the device that was claimed.
+func (r *LocalVolumeSetReconciler) mountSharedFilesystem(lvset *localv1.LocalVolumeSet, devLogger logr.Logger, linkPath, devicePath, mountPath string) error {
+	fsType, err := internal.GetFilesystemType(devicePath)
+	if err != nil {
+		return err
+	}
+	switch fsType {
+	case "":
+		err = r.verifySharedDevice(linkPath, devicePath)
+		if err != nil {
+			return err
+		}
+		var label string
+		var options []string
+		if lvset.Spec.Filesystem != nil {
+			label, options = lvset.Spec.Filesystem.Label, lvset.Spec.Filesystem.MkfsOptions
+		}
+		devLogger.Info("formatting shared filesystem", "options", options)
+		err = internal.Mkfs(devicePath, sharedFilesystemType, label, options)
+		if err != nil {
+			return err
+		}
+	case sharedFilesystemType:
+	default:
+		return fmt.Errorf("shared filesystem device %q has a %s filesystem instead of %s", devicePath, fsType, sharedFilesystemType)
+	}
+
+	err = os.MkdirAll(mountPath, 0755)
+	if err != nil {
+		return err
+	}
+	devLogger.Info("mounting shared filesystem", "mountPath", mountPath)
+	return r.runtimeConfig.Mounter.Mount(devicePath, mountPath, sharedFilesystemType, []string{"prjquota"})
+}
+
+// verifySharedDevice returns an error unless the device linked at linkPath has the fingerprint recorded when it was claimed.
+// Its serial or WWN must match when one was recorded.
+func (r *LocalVolumeSetReconciler) verifySharedDevice(linkPath, devicePath string) error {
+	deviceID, err := os.Readlink(linkPath)
+	if err != nil {
+		return err
+	}
+	claim, found := r.StateStore.GetClaim(deviceID)
+	if !found || !claim.HasFingerprint() {
+		return fmt.Errorf("not formatting shared filesystem device %q, no fingerprint was recorded when it was claimed", deviceID)
+	}
+	current, err := internal.GetFingerprint(devicePath)
+	if err != nil {
+		return err
+	}
+	recorded := internal.Fingerprint{Serial: claim.Serial, WWN: claim.WWN, Size: claim.Size}
+	if mismatch := recorded.Mismatch(current); mismatch != "" {
+		return fmt.Errorf("not formatting shared filesystem device %q, it is not the device that was claimed, %s", deviceID, mismatch)
+	}
+	if (recorded.Serial != "" || recorded.WWN != "") && !recorded.Identifies(current) {
+		return fmt.Errorf("not formatting shared filesystem device %q, it has neither the serial nor the WWN of the device that was claimed", deviceID)
+	}
+	return nil
+}
+
+// ensureSharedVolumeMount bind mounts the directory of a PV on the shared filesystem at the path of the PV
+func (r *LocalVolumeSetReconciler) ensureSharedVolumeMount(volLogger logr.Logger, mountPointMap sets.String, volumeDir, pvPath string) error {
+	if mountPointMap.Has(pvPath) {
+		return nil
+	}
+	err := os.MkdirAll(volumeDir, 0755)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(pvPath, 0755)
+	if err != nil {
+		return err
+	}
+	volLogger.Info("bind mounting shared filesystem directory", "sourcePath", volumeDir, "targetPath", pvPath)
+	err = r.runtimeConfig.Mounter.Mount(volumeDir, pvPath, "", []string{"bind"})
+	if err != nil {
+		return err
+	}
+	mountPointMap.Insert(pvPath)
+	return nil
+}
@@ -0,0 +1,148 @@
+package lvset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetSharedDevices(t *testing.T) {
+	sharedDir, err := ioutil.TempDir("", "shared")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sharedDir)
+
+	devices, err := getSharedDevices(filepath.Join(sharedDir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, devices)
+
+	linkPath := filepath.Join(sharedDir, "wwn-0x5000c500a0b1c2d3")
+	err = os.Symlink("/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", linkPath)
+	assert.NoError(t, err)
+	err = os.Mkdir(linkPath+sharedMountSuffix, 0755)
+	assert.NoError(t, err)
+
+	devices, err = getSharedDevices(sharedDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{linkPath}, devices, "the mountpoints are not devices")
+	assert.Equal(t, "wwn-0x5000c500a0b1c2d3-vol-3", getSharedVolumeName(linkPath, 3))
+}
+
+func TestGetSharedVolumeSize(t *testing.T) {
+	spec := &localv1.SharedFilesystemSpec{VolumeCount: 4}
+	assert.Equal(t, int64(25*common.GiB), getSharedVolumeSize(spec, 100*common.GiB))
+
+	volumeSize := resource.MustParse("10Gi")
+	spec.VolumeSize = &volumeSize
+	assert.Equal(t, int64(10*common.GiB), getSharedVolumeSize(spec, 100*common.GiB))
+}
+
+func TestGetSharedProjectID(t *testing.T) {
+	first := getSharedProjectID("/mnt/local-storage/shared/.shared/wwn-0x5000c500a0b1c2d3", 1)
+	assert.Equal(t, first+1, getSharedProjectID("/mnt/local-storage/shared/.shared/wwn-0x5000c500a0b1c2d3", 2))
+	assert.Equal(t, first, getSharedProjectID("/mnt/other/.shared/wwn-0x5000c500a0b1c2d3", 1), "the offset is derived from the device")
+	assert.Greater(t, first, uint32(1<<sharedProjectIDBits), "the low project IDs are left to the host")
+
+	other := getSharedProjectID("/mnt/local-storage/shared/.shared/wwn-0x5000c500a0b1c2d4", 1)
+	assert.NotEqual(t, first, other, "the devices have their own project IDs")
+}
+
+// sharedFsHelperCommand returns a fake exec.Cmd that reports a device without filesystem to blkid,
+// prints the fingerprint for lsblk, and records the mkfs invocations in mkfsCalls.
+func sharedFsHelperCommand(fingerprint string, mkfsCalls *[]string) func(string, ...string) *exec.Cmd {
+	return func(command string, args ...string) *exec.Cmd {
+		stdout, exitCode := "", 0
+		switch command {
+		case "blkid":
+			exitCode = 2
+		case "lsblk":
+			stdout = fingerprint
+		default:
+			*mkfsCalls = append(*mkfsCalls, fmt.Sprint(append([]string{command}, args...)))
+		}
+		cmd := exec.Command(os.Args[0], "-test.run=TestSharedFsHelperProcess", "--", command)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", fmt.Sprintf("EXIT_CODE=%d", exitCode), "STDOUT=" + stdout}
+		return cmd
+	}
+}
+
+func TestSharedFsHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	var exitCode int
+	fmt.Sscanf(os.Getenv("EXIT_CODE"), "%d", &exitCode)
+	fmt.Fprintln(os.Stdout, os.Getenv("STDOUT"))
+	os.Exit(exitCode)
+}
+
+func TestMountSharedFilesystemVerifiesDevice(t *testing.T) {
+	defer func() { internal.ExecCommand = exec.Command }()
+	lvset := &localv1.LocalVolumeSet{Spec: localv1.LocalVolumeSetSpec{SharedFilesystem: &localv1.SharedFilesystemSpec{VolumeCount: 2}}}
+	deviceID := "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"
+	sharedDir := t.TempDir()
+	linkPath := filepath.Join(sharedDir, filepath.Base(deviceID))
+	assert.NoError(t, os.Symlink(deviceID, linkPath))
+	mountPath := linkPath + sharedMountSuffix
+
+	testTable := []struct {
+		desc        string
+		claim       *state.Claim
+		fingerprint string
+		expectMkfs  bool
+	}{
+		{
+			desc:        "device claimed without fingerprint",
+			claim:       &state.Claim{DeviceName: "sdb"},
+			fingerprint: `SERIAL="serial-a" WWN="0x5000c500a0b1c2d3" SIZE="107374182400" PARTUUID=""`,
+		},
+		{
+			desc:        "another device behind the link",
+			claim:       &state.Claim{DeviceName: "sdb", Serial: "serial-a", WWN: "0x5000c500a0b1c2d3", Size: 100 * common.GiB},
+			fingerprint: `SERIAL="serial-b" WWN="0x5000c500a0b1c2d4" SIZE="107374182400" PARTUUID=""`,
+		},
+		{
+			desc:        "claimed device",
+			claim:       &state.Claim{DeviceName: "sdb", Serial: "serial-a", WWN: "0x5000c500a0b1c2d3", Size: 100 * common.GiB},
+			fingerprint: `SERIAL="serial-a" WWN="0x5000c500a0b1c2d3" SIZE="107374182400" PARTUUID=""`,
+			expectMkfs:  true,
+		},
+		{
+			desc:        "device not claimed",
+			fingerprint: `SERIAL="serial-a" WWN="0x5000c500a0b1c2d3" SIZE="107374182400" PARTUUID=""`,
+		},
+	}
+	for _, tc := range testTable {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, testConfig := newFakeLocalVolumeSetReconciler(t, lvset)
+			store, err := state.Open(filepath.Join(t.TempDir(), state.FileName))
+			assert.NoError(t, err)
+			r.StateStore = store
+			if tc.claim != nil {
+				assert.NoError(t, store.RecordClaim(deviceID, *tc.claim))
+			}
+			var mkfsCalls []string
+			internal.ExecCommand = sharedFsHelperCommand(tc.fingerprint, &mkfsCalls)
+
+			err = r.mountSharedFilesystem(lvset, log.WithName("testLogger"), linkPath, "/dev/sdb", mountPath)
+			if !tc.expectMkfs {
+				assert.Error(t, err)
+				assert.Empty(t, mkfsCalls, "the device must not be formatted")
+				assert.Empty(t, testConfig.fakeMounter.MountPoints)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, mkfsCalls, 1)
+			assert.Len(t, testConfig.fakeMounter.MountPoints, 1)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package lvset
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	provCommon "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
+)
+
+// nodeStatusResyncPeriod is how often the lastReconcileTime of the node is refreshed when nothing else changed,
+// so that the diskmakers of all the nodes don't update the shared status on each reconcile
+const nodeStatusResyncPeriod = 5 * time.Minute
+
+// nodeReport is what a reconcile observed on this node
+type nodeReport struct {
+	// delayedDevices are the kernel names of the matching devices that are not old enough yet
+	delayedDevices []string
+	// raidArrays are nil when the md arrays were not checked, their last reported state is kept then
+	raidArrays []localv1.RAIDArrayStatus
+	// paused is set when the node is in maintenance
+	paused bool
+	// tornDown is set once the teardown of a Removed LocalVolumeSet is done on the node
+	tornDown bool
+	// retainedPVs are the Released PVs of a Removed LocalVolumeSet left to the admin
+	retainedPVs []string
+}
+
+// updateNodeStatus replaces the entry of this node in the status of the LocalVolumeSet
+// with the PVs provisioned on the node, the report of the reconcile and its error.
+// The status is shared by the diskmakers of all the nodes, conflicting updates are retried.
+func (r *LocalVolumeSetReconciler) updateNodeStatus(ctx context.Context, lvset *localv1.LocalVolumeSet, report *nodeReport, reconcileErr error) error {
+	now := metav1.Now()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := r.Client.Get(ctx, types.NamespacedName{Name: lvset.Name, Namespace: lvset.Namespace}, lvset)
+		if err != nil {
+			return err
+		}
+		nodeStatus, err := r.getNodeStatus(ctx, lvset, report, reconcileErr)
+		if err != nil {
+			return err
+		}
+		nodeStatus.LastReconcileTime = &now
+		for _, previous := range lvset.Status.Nodes {
+			if previous.NodeName != nodeStatus.NodeName {
+				continue
+			}
+			if report.raidArrays == nil {
+				nodeStatus.RAIDArrays = previous.RAIDArrays
+			}
+			// only refresh the time of a recently reported node when something else changed
+			if previous.LastReconcileTime != nil && now.Sub(previous.LastReconcileTime.Time) < nodeStatusResyncPeriod {
+				nodeStatus.LastReconcileTime = previous.LastReconcileTime
+				if equality.Semantic.DeepEqual(previous, nodeStatus) {
+					return nil
+				}
+				nodeStatus.LastReconcileTime = &now
+			}
+		}
+		lvset.Status.Nodes = setNodeStatus(lvset.Status.Nodes, nodeStatus)
+		return r.Client.Status().Update(ctx, lvset)
+	})
+}
+
+// getNodeStatus returns the PVs provisioned by the LocalVolumeSet on this node, with the report of the reconcile and its error
+func (r *LocalVolumeSetReconciler) getNodeStatus(ctx context.Context, lvset *localv1.LocalVolumeSet, report *nodeReport, reconcileErr error) (localv1.LocalVolumeSetNodeStatus, error) {
+	nodeStatus := localv1.LocalVolumeSetNodeStatus{
+		NodeName:       r.nodeName,
+		DelayedDevices: report.delayedDevices,
+		RAIDArrays:     report.raidArrays,
+		Paused:         report.paused,
+		TornDown:       report.tornDown,
+		RetainedPVs:    report.retainedPVs,
+	}
+	if reconcileErr != nil {
+		nodeStatus.LastError = reconcileErr.Error()
+	}
+
+	pvs := &corev1.PersistentVolumeList{}
+	err := r.Client.List(ctx, pvs, client.MatchingLabels{
+		common.PVOwnerKindLabel:      localv1.LocalVolumeSetKind,
+		common.PVOwnerNamespaceLabel: lvset.Namespace,
+		common.PVOwnerNameLabel:      lvset.Name,
+	})
+	if err != nil {
+		return nodeStatus, err
+	}
+	provisionedBy := common.GetProvisionedByValue(*r.runtimeConfig.Node)
+	totalCapacity := resource.NewQuantity(0, resource.BinarySI)
+	for _, pv := range pvs.Items {
+		if pv.Annotations[provCommon.AnnProvisionedBy] != provisionedBy {
+			continue
+		}
+		nodeStatus.ProvisionedDeviceCount++
+		totalCapacity.Add(pv.Spec.Capacity[corev1.ResourceStorage])
+		switch pv.Status.Phase {
+		case corev1.VolumeBound:
+			nodeStatus.BoundDeviceCount++
+		case corev1.VolumeAvailable:
+			nodeStatus.AvailableDeviceCount++
+		}
+	}
+	nodeStatus.TotalCapacity = totalCapacity
+	return nodeStatus, nil
+}
+
+// setNodeStatus returns nodes with the entry of the node of nodeStatus replaced by it, sorted by node name
+func setNodeStatus(nodes []localv1.LocalVolumeSetNodeStatus, nodeStatus localv1.LocalVolumeSetNodeStatus) []localv1.LocalVolumeSetNodeStatus {
+	result := make([]localv1.LocalVolumeSetNodeStatus, 0, len(nodes)+1)
+	for _, node := range nodes {
+		if node.NodeName != nodeStatus.NodeName {
+			result = append(result, node)
+		}
+	}
+	result = append(result, nodeStatus)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NodeName < result[j].NodeName
+	})
+	return result
+}
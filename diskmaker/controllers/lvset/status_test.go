@@ -0,0 +1,109 @@
+package lvset
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	provCommon "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/common"
+)
+
+func newOwnedPV(name, owner string, node corev1.Node, size string, phase corev1.PersistentVolumePhase) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				common.PVOwnerKindLabel:      localv1.LocalVolumeSetKind,
+				common.PVOwnerNamespaceLabel: "default",
+				common.PVOwnerNameLabel:      owner,
+			},
+			Annotations: map[string]string{provCommon.AnnProvisionedBy: common.GetProvisionedByValue(node)},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: phase},
+	}
+}
+
+func TestUpdateNodeStatus(t *testing.T) {
+	node1 := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "uid1"}}
+	node2 := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2", UID: "uid2"}}
+	lvset := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "fast", Namespace: "default"},
+		Status: localv1.LocalVolumeSetStatus{Nodes: []localv1.LocalVolumeSetNodeStatus{
+			{NodeName: "node1", RAIDArrays: []localv1.RAIDArrayStatus{{Name: "lso-a"}}},
+			{NodeName: "node2", ProvisionedDeviceCount: 3},
+		}},
+	}
+	r, _ := newFakeLocalVolumeSetReconciler(t,
+		lvset,
+		newOwnedPV("pv-a", "fast", node1, "10Gi", corev1.VolumeBound),
+		newOwnedPV("pv-b", "fast", node1, "5Gi", corev1.VolumeAvailable),
+		newOwnedPV("pv-c", "fast", node1, "1Gi", corev1.VolumeReleased),
+		newOwnedPV("pv-d", "fast", node2, "10Gi", corev1.VolumeAvailable),
+		newOwnedPV("pv-e", "slow", node1, "10Gi", corev1.VolumeAvailable),
+	)
+	r.nodeName = node1.Name
+	r.runtimeConfig.Node = &node1
+	key := types.NamespacedName{Name: lvset.Name, Namespace: lvset.Namespace}
+
+	report := &nodeReport{delayedDevices: []string{"sdc"}}
+	err := r.updateNodeStatus(context.TODO(), lvset, report, fmt.Errorf("could not list block devices"))
+	assert.NoError(t, err)
+	updated := &localv1.LocalVolumeSet{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, updated))
+	if !assert.Len(t, updated.Status.Nodes, 2) {
+		return
+	}
+	nodeStatus := updated.Status.Nodes[0]
+	assert.Equal(t, "node1", nodeStatus.NodeName)
+	assert.Equal(t, int32(3), nodeStatus.ProvisionedDeviceCount)
+	assert.Equal(t, int32(1), nodeStatus.BoundDeviceCount)
+	assert.Equal(t, int32(1), nodeStatus.AvailableDeviceCount)
+	assert.Equal(t, "16Gi", nodeStatus.TotalCapacity.String())
+	assert.Equal(t, []string{"sdc"}, nodeStatus.DelayedDevices)
+	assert.Equal(t, "could not list block devices", nodeStatus.LastError)
+	assert.NotNil(t, nodeStatus.LastReconcileTime)
+	assert.Equal(t, []localv1.RAIDArrayStatus{{Name: "lso-a"}}, nodeStatus.RAIDArrays, "the md arrays are kept when they were not checked")
+	assert.Equal(t, int32(3), updated.Status.Nodes[1].ProvisionedDeviceCount, "the status of the other nodes is kept")
+
+	// the status is not updated when only the time of the reconcile changed
+	err = r.updateNodeStatus(context.TODO(), lvset, report, fmt.Errorf("could not list block devices"))
+	assert.NoError(t, err)
+	unchanged := &localv1.LocalVolumeSet{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, unchanged))
+	assert.Equal(t, updated.ResourceVersion, unchanged.ResourceVersion)
+
+	report = &nodeReport{raidArrays: []localv1.RAIDArrayStatus{}, paused: true}
+	err = r.updateNodeStatus(context.TODO(), lvset, report, nil)
+	assert.NoError(t, err)
+	updated = &localv1.LocalVolumeSet{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, updated))
+	assert.Empty(t, updated.Status.Nodes[0].LastError)
+	assert.Empty(t, updated.Status.Nodes[0].DelayedDevices)
+	assert.Empty(t, updated.Status.Nodes[0].RAIDArrays)
+	assert.True(t, updated.Status.Nodes[0].Paused)
+}
+
+func TestSetNodeStatus(t *testing.T) {
+	nodes := []localv1.LocalVolumeSetNodeStatus{
+		{NodeName: "node1", RAIDArrays: []localv1.RAIDArrayStatus{{Name: "lso-a"}}},
+		{NodeName: "node2", RAIDArrays: []localv1.RAIDArrayStatus{{Name: "lso-b"}}},
+	}
+	result := setNodeStatus(nodes, localv1.LocalVolumeSetNodeStatus{NodeName: "node1", ProvisionedDeviceCount: 2})
+	assert.Equal(t, []localv1.LocalVolumeSetNodeStatus{
+		{NodeName: "node1", ProvisionedDeviceCount: 2},
+		{NodeName: "node2", RAIDArrays: []localv1.RAIDArrayStatus{{Name: "lso-b"}}},
+	}, result)
+
+	result = setNodeStatus(nodes, localv1.LocalVolumeSetNodeStatus{NodeName: "node0"})
+	assert.Equal(t, []string{"node0", "node1", "node2"}, []string{result[0].NodeName, result[1].NodeName, result[2].NodeName})
+}
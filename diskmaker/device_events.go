@@ -0,0 +1,29 @@
+package diskmaker
+
+import (
+	"github.com/openshift/local-storage-operator/internal"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// BlockDeviceEvents converts the add and remove uevents of block devices into GenericEvents,
+// to be consumed by a controller-runtime source.Channel. The object of each event is only
+// named after the kernel name of the device, the handler is expected to map it to the owners to reconcile.
+// The returned channel is closed when uevents is closed.
+func BlockDeviceEvents(uevents <-chan internal.UEvent) <-chan event.GenericEvent {
+	events := make(chan event.GenericEvent)
+	go func() {
+		defer close(events)
+		for uevent := range uevents {
+			if uevent.Action != internal.UEventAdd && uevent.Action != internal.UEventRemove {
+				continue
+			}
+			events <- event.GenericEvent{
+				Object: &metav1.PartialObjectMetadata{
+					ObjectMeta: metav1.ObjectMeta{Name: uevent.DevName},
+				},
+			}
+		}
+	}()
+	return events
+}
@@ -6,11 +6,14 @@ import (
 	"os/signal"
 	"reflect"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
 	v1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/common"
 	"github.com/openshift/local-storage-operator/diskmaker"
 	"github.com/openshift/local-storage-operator/diskmaker/controllers/lvset"
 	"github.com/openshift/local-storage-operator/internal"
@@ -22,23 +25,61 @@ import (
 
 const (
 	localVolumeDiscoveryComponent = "auto-discover-devices"
-	udevEventPeriod               = 5 * time.Second
-	probeInterval                 = 5 * time.Minute
 	resultCRName                  = "discovery-result-%s"
+
+	// DefaultUdevEventPeriod is the default period over which udev events are collapsed
+	DefaultUdevEventPeriod = 5 * time.Second
+	// DefaultProbeInterval is the default interval between two periodic scans
+	DefaultProbeInterval = 5 * time.Minute
+)
+
+var (
+	// DefaultSupportedDeviceTypes are the device types discovered by default
+	DefaultSupportedDeviceTypes = []string{"disk", "part", "lvm", internal.RAIDType, internal.MultipathType}
+	// DefaultUdevExclusionFilter are the devices whose udev events are ignored by default
+	DefaultUdevExclusionFilter = []string{"(?i)dm-[0-9]+", "(?i)rbd[0-9]", "(?i)nbd[0-9]+"}
 )
 
-var supportedDeviceTypes = sets.NewString("disk", "part", "lvm")
+// Options configures the discovery cadence and filters
+type Options struct {
+	// ProbeInterval is the interval between two periodic scans
+	ProbeInterval time.Duration
+	// UdevEventPeriod is the period over which udev events are collapsed into a single scan
+	UdevEventPeriod time.Duration
+	// UdevExclusionFilter is the list of regular expressions of devices whose udev events are ignored
+	UdevExclusionFilter []string
+	// SupportedDeviceTypes is the list of device types that are discovered
+	SupportedDeviceTypes []string
+}
+
+// DefaultOptions returns the Options used when the LocalVolumeDiscovery doesn't override them
+func DefaultOptions() Options {
+	return Options{
+		ProbeInterval:        DefaultProbeInterval,
+		UdevEventPeriod:      DefaultUdevEventPeriod,
+		UdevExclusionFilter:  DefaultUdevExclusionFilter,
+		SupportedDeviceTypes: DefaultSupportedDeviceTypes,
+	}
+}
 
 // DeviceDiscovery instance
 type DeviceDiscovery struct {
 	apiClient            diskmaker.ApiUpdater
 	eventSync            *diskmaker.EventReporter
-	disks                []v1alpha1.DiscoveredDevice
-	localVolumeDiscovery *v1alpha1.LocalVolumeDiscovery
+	disks                []v1.DiscoveredDevice
+	localVolumeDiscovery *v1.LocalVolumeDiscovery
+	// lastUpdated is the last time the LocalVolumeDiscoveryResult status was updated
+	lastUpdated          time.Time
+	probeInterval        time.Duration
+	udevEventPeriod      time.Duration
+	udevExclusionFilter  []string
+	supportedDeviceTypes sets.String
+	// paused is set while the node is in maintenance
+	paused bool
 }
 
 // NewDeviceDiscovery returns a new DeviceDiscovery instance
-func NewDeviceDiscovery() (*DeviceDiscovery, error) {
+func NewDeviceDiscovery(opts Options) (*DeviceDiscovery, error) {
 	scheme := scheme.Scheme
 	v1.AddToScheme(scheme)
 	v1alpha1.AddToScheme(scheme)
@@ -49,7 +90,12 @@ func NewDeviceDiscovery() (*DeviceDiscovery, error) {
 		return &DeviceDiscovery{}, err
 	}
 
-	dd := &DeviceDiscovery{}
+	dd := &DeviceDiscovery{
+		probeInterval:        opts.ProbeInterval,
+		udevEventPeriod:      opts.UdevEventPeriod,
+		udevExclusionFilter:  opts.UdevExclusionFilter,
+		supportedDeviceTypes: sets.NewString(opts.SupportedDeviceTypes...),
+	}
 	dd.apiClient = apiUpdater
 	dd.eventSync = diskmaker.NewEventReporter(dd.apiClient)
 	lvd, err := dd.apiClient.GetLocalVolumeDiscovery(localVolumeDiscoveryComponent, os.Getenv("WATCH_NAMESPACE"))
@@ -81,20 +127,43 @@ func (discovery *DeviceDiscovery) Start() error {
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc, syscall.SIGTERM)
 
+	klog.Infof("probe interval: %v, udev event period: %v, supported device types: %v",
+		discovery.probeInterval, discovery.udevEventPeriod, discovery.supportedDeviceTypes.List())
+
+	stop := make(chan struct{})
+	defer close(stop)
+	ueventListener := internal.NewUEventListener(internal.KernelUEvents)
+	uevents := ueventListener.Subscribe()
+	go ueventListener.Start(stop)
+
 	udevEvents := make(chan string)
-	go udevBlockMonitor(udevEvents, udevEventPeriod)
+	go udevBlockMonitor(udevEvents, uevents, discovery.udevEventPeriod, discovery.udevExclusionFilter)
+
+	// Watch for rescan requests on the LocalVolumeDiscoveryResult of this node
+	rescanRequests := make(chan string)
+	go discovery.watchRescanRequests(rescanRequests)
 	for {
 		select {
 		case <-sigc:
 			klog.Info("shutdown signal received, exiting...")
 			return nil
-		case <-time.After(probeInterval):
+		case request := <-rescanRequests:
+			klog.Infof("trigger probe from rescan request %q", request)
+			// always refresh the LocalVolumeDiscoveryResult on a rescan request
+			discovery.lastUpdated = time.Time{}
+			if err := discovery.discoverDevices(); err != nil {
+				klog.Errorf("failed to discover devices triggered from rescan request. %v", err)
+			}
+			if err := discovery.completeRescanRequest(request); err != nil {
+				klog.Errorf("failed to complete rescan request. %v", err)
+			}
+		case <-time.After(discovery.probeInterval):
 			if err := discovery.discoverDevices(); err != nil {
 				klog.Errorf("failed to discover devices during probe interval. %v", err)
 			}
-		case _, ok := <-udevEvents:
+		case devName, ok := <-udevEvents:
 			if ok {
-				klog.Info("trigger probe from udev event")
+				klog.Infof("trigger probe from udev event on %q", devName)
 				if err := discovery.discoverDevices(); err != nil {
 					klog.Errorf("failed to discover devices triggered from udev event. %v", err)
 				}
@@ -108,8 +177,14 @@ func (discovery *DeviceDiscovery) Start() error {
 
 // discoverDevices identifies the list of usable disks on the current node
 func (discovery *DeviceDiscovery) discoverDevices() error {
+	// the LocalVolumeDiscoveryResult of an Unmanaged LocalVolumeDiscovery is left as it is
+	if discovery.isUnmanaged() {
+		klog.Info("LocalVolumeDiscovery is unmanaged, skipping discovery")
+		return nil
+	}
+
 	// List all the valid block devices on the node
-	validDevices, err := getValidBlockDevices()
+	validDevices, multipaths, err := getValidBlockDevices(discovery.supportedDeviceTypes)
 	if err != nil {
 		message := "failed to discover devices"
 		e := diskmaker.NewEvent(diskmaker.ErrorListingBlockDevices, fmt.Sprintf("%s. Error: %+v", message, err), "")
@@ -119,13 +194,17 @@ func (discovery *DeviceDiscovery) discoverDevices() error {
 
 	klog.Infof("valid block devices: %+v", validDevices)
 
-	discoveredDisks := getDiscoverdDevices(validDevices)
+	discoveredDisks := getDiscoverdDevices(validDevices, multipaths)
 	klog.Infof("discovered devices: %+v", discoveredDisks)
 
-	// Update discovered devices in the  LocalVolumeDiscoveryResult resource
-	if !reflect.DeepEqual(discovery.disks, discoveredDisks) {
-		klog.Info("device list updated. Updating LocalVolumeDiscoveryResult status...")
+	// Update discovered devices and the maintenance of the node in the  LocalVolumeDiscoveryResult resource
+	paused := discovery.isNodeInMaintenance()
+	changed := !reflect.DeepEqual(discovery.disks, discoveredDisks) || paused != discovery.paused
+	// Refresh the discovery time on every probe, so that the operator can tell stale results apart
+	if changed || time.Since(discovery.lastUpdated) >= discovery.probeInterval {
+		klog.Infof("updating LocalVolumeDiscoveryResult status. device list changed: %t", changed)
 		discovery.disks = discoveredDisks
+		discovery.paused = paused
 		err = discovery.updateStatus()
 		if err != nil {
 			message := "failed to update LocalVolumeDiscoveryResult status"
@@ -133,39 +212,71 @@ func (discovery *DeviceDiscovery) discoverDevices() error {
 			discovery.eventSync.Report(e, discovery.localVolumeDiscovery)
 			return errors.Wrapf(err, message)
 		}
-		message := "successfully updated discovered device details in the LocalVolumeDiscoveryResult resource"
-		e := diskmaker.NewSuccessEvent(diskmaker.UpdatedDiscoveredDeviceList, message, "")
-		discovery.eventSync.Report(e, discovery.localVolumeDiscovery)
+		discovery.lastUpdated = time.Now()
+		if changed {
+			message := "successfully updated discovered device details in the LocalVolumeDiscoveryResult resource"
+			e := diskmaker.NewSuccessEvent(diskmaker.UpdatedDiscoveredDeviceList, message, "")
+			discovery.eventSync.Report(e, discovery.localVolumeDiscovery)
+		}
 	}
 
 	return nil
 }
 
-// getValidBlockDevices fetchs all the block devices sutitable for discovery
-func getValidBlockDevices() ([]internal.BlockDevice, error) {
+// isNodeInMaintenance returns true when the node has the maintenance label,
+// the last known state is kept when the node can't be read
+func (discovery *DeviceDiscovery) isNodeInMaintenance() bool {
+	node, err := discovery.apiClient.GetNode(os.Getenv("MY_NODE_NAME"))
+	if err != nil {
+		klog.Errorf("failed to get the node to check its maintenance. %v", err)
+		return discovery.paused
+	}
+	return common.IsNodeInMaintenance(node)
+}
+
+// isUnmanaged returns true when the LocalVolumeDiscovery is Unmanaged,
+// the last known managementState is kept when the LocalVolumeDiscovery can't be read
+func (discovery *DeviceDiscovery) isUnmanaged() bool {
+	lvd, err := discovery.apiClient.GetLocalVolumeDiscovery(localVolumeDiscoveryComponent, os.Getenv("WATCH_NAMESPACE"))
+	if err != nil {
+		klog.Errorf("failed to get the LocalVolumeDiscovery to check its managementState. %v", err)
+	} else {
+		discovery.localVolumeDiscovery.Spec.ManagementState = lvd.Spec.ManagementState
+	}
+	return discovery.localVolumeDiscovery.Spec.ManagementState == operatorv1.Unmanaged
+}
+
+// getValidBlockDevices fetchs all the block devices sutitable for discovery.
+// The paths of a LUN are reported once, with the paths of the devices that have several.
+func getValidBlockDevices(supportedDeviceTypes sets.String) ([]internal.BlockDevice, map[string]internal.Multipath, error) {
 	blockDevices, badRows, err := internal.ListBlockDevices()
 	if err != nil {
 
-		return blockDevices, errors.Wrapf(err, "failed to list all the block devices in the node.")
+		return blockDevices, nil, errors.Wrapf(err, "failed to list all the block devices in the node.")
 	} else if len(badRows) > 0 {
 		klog.Warningf("failed to parse all the lsblk rows. Bad rows: %+v", badRows)
 	}
 
+	blockDevices, multipaths, err := internal.GroupMultipaths(blockDevices)
+	if err != nil {
+		return blockDevices, nil, errors.Wrapf(err, "failed to group the paths of the multipath devices")
+	}
+
 	// Get valid list of devices
 	validDevices := make([]internal.BlockDevice, 0)
 	for _, blockDevice := range blockDevices {
-		if ignoreDevices(blockDevice) {
+		if ignoreDevices(blockDevice, supportedDeviceTypes) {
 			continue
 		}
 		validDevices = append(validDevices, blockDevice)
 	}
 
-	return validDevices, nil
+	return validDevices, multipaths, nil
 }
 
-// getDiscoverdDevices creates v1alpha1.DiscoveredDevice from internal.BlockDevices
-func getDiscoverdDevices(blockDevices []internal.BlockDevice) []v1alpha1.DiscoveredDevice {
-	discoveredDevices := make([]v1alpha1.DiscoveredDevice, 0)
+// getDiscoverdDevices creates v1.DiscoveredDevice from internal.BlockDevices
+func getDiscoverdDevices(blockDevices []internal.BlockDevice, multipaths map[string]internal.Multipath) []v1.DiscoveredDevice {
+	discoveredDevices := make([]v1.DiscoveredDevice, 0)
 	for _, blockDevice := range blockDevices {
 		deviceID, err := blockDevice.GetPathByID()
 		if err != nil {
@@ -178,7 +289,7 @@ func getDiscoverdDevices(blockDevices []internal.BlockDevice) []v1alpha1.Discove
 			klog.Warningf("failed to parse size for the device %q. Error %v", blockDevice.Name, err)
 		}
 
-		discoveredDevice := v1alpha1.DiscoveredDevice{
+		discoveredDevice := v1.DiscoveredDevice{
 			Path:     fmt.Sprintf("/dev/%s", blockDevice.Name),
 			Model:    blockDevice.Model,
 			Vendor:   blockDevice.Vendor,
@@ -189,6 +300,11 @@ func getDiscoverdDevices(blockDevices []internal.BlockDevice) []v1alpha1.Discove
 			Size:     size,
 			Property: parseDeviceProperty(blockDevice.Rotational),
 			Status:   getDeviceStatus(blockDevice),
+			WWN:      blockDevice.WWN,
+		}
+		if multipath, found := multipaths[blockDevice.KName]; found {
+			discoveredDevice.Paths = int32(len(multipath.Paths))
+			discoveredDevice.PathState = multipath.State()
 		}
 		discoveredDevices = append(discoveredDevices, discoveredDevice)
 	}
@@ -197,7 +313,7 @@ func getDiscoverdDevices(blockDevices []internal.BlockDevice) []v1alpha1.Discove
 }
 
 // ignoreDevices checks if a device should be ignored during discovery
-func ignoreDevices(dev internal.BlockDevice) bool {
+func ignoreDevices(dev internal.BlockDevice, supportedDeviceTypes sets.String) bool {
 	if readOnly, err := dev.GetReadOnly(); err != nil || readOnly {
 		klog.Infof("ignoring read only device %q", dev.Name)
 		return true
@@ -213,7 +329,7 @@ func ignoreDevices(dev internal.BlockDevice) bool {
 		return true
 	}
 
-	if !supportedDeviceTypes.Has(dev.Type) {
+	if !supportedDeviceTypes.Has(dev.GetType()) {
 		klog.Infof("ignoring device %q with invalid type %q", dev.Name, dev.Type)
 		return true
 	}
@@ -222,72 +338,76 @@ func ignoreDevices(dev internal.BlockDevice) bool {
 }
 
 // getDeviceStatus returns device status as "Available", "NotAvailable" or "Unkown"
-func getDeviceStatus(dev internal.BlockDevice) v1alpha1.DeviceStatus {
-	status := v1alpha1.DeviceStatus{}
+func getDeviceStatus(dev internal.BlockDevice) v1.DeviceStatus {
+	status := v1.DeviceStatus{}
 	if dev.FSType != "" {
 		klog.Infof("device %q with filesystem %q is not available", dev.Name, dev.FSType)
-		status.State = v1alpha1.NotAvailable
+		status.State = v1.NotAvailable
 		return status
 	}
 
 	noBiosBootInPartLabel, err := lvset.FilterMap["noBiosBootInPartLabel"](dev, nil)
 	if err != nil {
-		status.State = v1alpha1.Unknown
+		status.State = v1.Unknown
 		return status
 	}
 	if !noBiosBootInPartLabel {
 		klog.Infof("device %q with part label %q is not available", dev.Name, dev.PartLabel)
-		status.State = v1alpha1.NotAvailable
+		status.State = v1.NotAvailable
 		return status
 	}
 
 	canOpen, err := lvset.FilterMap["canOpenExclusively"](dev, nil)
 	if err != nil {
-		status.State = v1alpha1.Unknown
+		status.State = v1.Unknown
 		return status
 	}
 	if !canOpen {
 		klog.Infof("device %q is not available as it can't be opened exclusively", dev.Name)
-		status.State = v1alpha1.NotAvailable
+		status.State = v1.NotAvailable
 		return status
 	}
 
 	hasBindMounts, mountPoint, err := dev.HasBindMounts()
 	if err != nil {
-		status.State = v1alpha1.Unknown
+		status.State = v1.Unknown
 		return status
 	}
 
 	if hasBindMounts {
 		klog.Infof("device %q with mount point %q is not available", dev.Name, mountPoint)
-		status.State = v1alpha1.NotAvailable
+		status.State = v1.NotAvailable
 		return status
 	}
 
 	klog.Infof("device %q is available", dev.Name)
-	status.State = v1alpha1.Available
+	status.State = v1.Available
 	return status
 }
 
-func parseDeviceProperty(property string) v1alpha1.DeviceMechanicalProperty {
+func parseDeviceProperty(property string) v1.DeviceMechanicalProperty {
 	switch {
 	case property == "1":
-		return v1alpha1.Rotational
+		return v1.Rotational
 	case property == "0":
-		return v1alpha1.NonRotational
+		return v1.NonRotational
 	}
 
 	return ""
 }
 
-func parseDeviceType(deviceType string) v1alpha1.DiscoveredDeviceType {
+func parseDeviceType(deviceType string) v1.DiscoveredDeviceType {
 	switch {
 	case deviceType == "disk":
-		return v1alpha1.DiskType
+		return v1.DiskType
 	case deviceType == "part":
-		return v1alpha1.PartType
+		return v1.PartType
 	case deviceType == "lvm":
-		return v1alpha1.LVMType
+		return v1.LVMType
+	case strings.HasPrefix(deviceType, internal.RAIDType):
+		return v1.RAIDType
+	case deviceType == internal.MultipathType:
+		return v1.MultipathType
 	}
 
 	return ""
@@ -7,10 +7,14 @@ import (
 	"path/filepath"
 	"testing"
 
-	"github.com/openshift/local-storage-operator/api/v1alpha1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
 	"github.com/openshift/local-storage-operator/diskmaker"
 	"github.com/openshift/local-storage-operator/internal"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 var lsblkOut string
@@ -74,6 +78,82 @@ func TestDiscoverDevices(t *testing.T) {
 	}
 
 }
+func TestDiscoverDevicesPaused(t *testing.T) {
+	lsblkOut = `NAME="sda" KNAME="sda" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="0" STATE="running" SERIAL=""`
+	blkidOut = ""
+	internal.ExecCommand = helperCommand
+	internal.FilePathGlob = func(name string) ([]string, error) {
+		return []string{"removable", "subsytem", "sda"}, nil
+	}
+	defer func() {
+		internal.FilePathGlob = filepath.Glob
+		internal.ExecCommand = exec.Command
+	}()
+
+	node := &corev1.Node{}
+	var status localv1.LocalVolumeDiscoveryResultStatus
+	deviceDiscovery := getFakeDeviceDiscovery()
+	deviceDiscovery.apiClient = &diskmaker.MockAPIUpdater{
+		MockGetNode: func(name string) (*corev1.Node, error) {
+			return node, nil
+		},
+		MockUpdateDiscoveryResultStatus: func(lvdr *localv1.LocalVolumeDiscoveryResult) error {
+			status = lvdr.Status
+			return nil
+		},
+	}
+	assert.NoError(t, deviceDiscovery.discoverDevices())
+	assert.False(t, status.Paused)
+
+	// the result is updated as soon as the node is in maintenance, even though the devices didn't change
+	node.Labels = map[string]string{common.NodeMaintenanceLabel: ""}
+	status = localv1.LocalVolumeDiscoveryResultStatus{}
+	assert.NoError(t, deviceDiscovery.discoverDevices())
+	assert.True(t, status.Paused)
+}
+
+func TestDiscoverDevicesUnmanaged(t *testing.T) {
+	lsblkOut = `NAME="sda" KNAME="sda" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="0" STATE="running" SERIAL=""`
+	blkidOut = ""
+	internal.ExecCommand = helperCommand
+	internal.FilePathGlob = func(name string) ([]string, error) {
+		return []string{"removable", "subsytem", "sda"}, nil
+	}
+	defer func() {
+		internal.FilePathGlob = filepath.Glob
+		internal.ExecCommand = exec.Command
+	}()
+
+	lvd := &localv1.LocalVolumeDiscovery{Spec: localv1.LocalVolumeDiscoverySpec{ManagementState: operatorv1.Unmanaged}}
+	updated := false
+	deviceDiscovery := getFakeDeviceDiscovery()
+	deviceDiscovery.apiClient = &diskmaker.MockAPIUpdater{
+		MockGetLocalVolumeDiscovery: func(name, namespace string) (*localv1.LocalVolumeDiscovery, error) {
+			return lvd, nil
+		},
+		MockUpdateDiscoveryResultStatus: func(lvdr *localv1.LocalVolumeDiscoveryResult) error {
+			updated = true
+			return nil
+		},
+	}
+	assert.NoError(t, deviceDiscovery.discoverDevices())
+	assert.False(t, updated, "the result of an Unmanaged LocalVolumeDiscovery is not updated")
+
+	// the last known managementState is kept when the LocalVolumeDiscovery can't be read
+	deviceDiscovery.apiClient.(*diskmaker.MockAPIUpdater).MockGetLocalVolumeDiscovery = func(name, namespace string) (*localv1.LocalVolumeDiscovery, error) {
+		return nil, fmt.Errorf("could not get LocalVolumeDiscovery")
+	}
+	assert.NoError(t, deviceDiscovery.discoverDevices())
+	assert.False(t, updated)
+
+	lvd.Spec.ManagementState = operatorv1.Managed
+	deviceDiscovery.apiClient.(*diskmaker.MockAPIUpdater).MockGetLocalVolumeDiscovery = func(name, namespace string) (*localv1.LocalVolumeDiscovery, error) {
+		return lvd, nil
+	}
+	assert.NoError(t, deviceDiscovery.discoverDevices())
+	assert.True(t, updated)
+}
+
 func TestDiscoverDevicesFail(t *testing.T) {
 	testcases := []struct {
 		deviceDiscovery    *DeviceDiscovery
@@ -85,7 +165,7 @@ func TestDiscoverDevicesFail(t *testing.T) {
 		{
 			deviceDiscovery: getFakeDeviceDiscovery(),
 			mockClient: &diskmaker.MockAPIUpdater{
-				MockUpdateDiscoveryResultStatus: func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+				MockUpdateDiscoveryResultStatus: func(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 					return fmt.Errorf("failed to update status")
 				},
 			},
@@ -203,7 +283,7 @@ func TestIgnoreDevices(t *testing.T) {
 			internal.FilePathGlob = filepath.Glob
 		}()
 
-		actual := ignoreDevices(tc.blockDevice)
+		actual := ignoreDevices(tc.blockDevice, sets.NewString(DefaultSupportedDeviceTypes...))
 		assert.Equalf(t, tc.expected, actual, "[%s]: %s", tc.label, tc.errMessage)
 	}
 }
@@ -273,7 +353,7 @@ func TestValidBlockDevices(t *testing.T) {
 			internal.FilePathGlob = filepath.Glob
 			internal.ExecCommand = exec.Command
 		}()
-		actual, err := getValidBlockDevices()
+		actual, _, err := getValidBlockDevices(sets.NewString(DefaultSupportedDeviceTypes...))
 		assert.NoError(t, err)
 		assert.Equalf(t, tc.expectedDiscoveredDeviceSize, len(actual), "[%s]: %s", tc.label, tc.errMessage)
 	}
@@ -283,7 +363,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 	testcases := []struct {
 		label               string
 		blockDevices        []internal.BlockDevice
-		expected            []v1alpha1.DiscoveredDevice
+		expected            []localv1.DiscoveredDevice
 		fakeGlobfunc        func(string) ([]string, error)
 		fakeEvalSymlinkfunc func(string) (string, error)
 	}{
@@ -305,7 +385,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					State:      "running",
 				},
 			},
-			expected: []v1alpha1.DiscoveredDevice{
+			expected: []localv1.DiscoveredDevice{
 				{
 					DeviceID: "/dev/disk/by-id/sdb",
 					Path:     "/dev/sdb",
@@ -316,7 +396,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					Size:     int64(62914560000),
 					Property: "Rotational",
 					FSType:   "ext4",
-					Status:   v1alpha1.DeviceStatus{State: "NotAvailable"},
+					Status:   localv1.DeviceStatus{State: "NotAvailable"},
 				},
 			},
 			fakeGlobfunc: func(name string) ([]string, error) {
@@ -345,7 +425,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					State:      "running",
 				},
 			},
-			expected: []v1alpha1.DiscoveredDevice{
+			expected: []localv1.DiscoveredDevice{
 				{
 					DeviceID: "/dev/disk/by-id/sda1",
 					Path:     "/dev/sda1",
@@ -356,7 +436,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					Size:     int64(62913494528),
 					Property: "NonRotational",
 					FSType:   "ext4",
-					Status:   v1alpha1.DeviceStatus{State: "NotAvailable"},
+					Status:   localv1.DeviceStatus{State: "NotAvailable"},
 				},
 			},
 			fakeGlobfunc: func(name string) ([]string, error) {
@@ -385,7 +465,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					PartLabel:  "BIOS-BOOT",
 				},
 			},
-			expected: []v1alpha1.DiscoveredDevice{
+			expected: []localv1.DiscoveredDevice{
 				{
 					DeviceID: "/dev/disk/by-id/sda1",
 					Path:     "/dev/sda1",
@@ -396,7 +476,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					Size:     int64(62913494528),
 					Property: "NonRotational",
 					FSType:   "",
-					Status:   v1alpha1.DeviceStatus{State: "NotAvailable"},
+					Status:   localv1.DeviceStatus{State: "NotAvailable"},
 				},
 			},
 			fakeGlobfunc: func(name string) ([]string, error) {
@@ -425,7 +505,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					PartLabel:  "EFI-SYSTEM",
 				},
 			},
-			expected: []v1alpha1.DiscoveredDevice{
+			expected: []localv1.DiscoveredDevice{
 				{
 					DeviceID: "/dev/disk/by-id/sda1",
 					Path:     "/dev/sda1",
@@ -436,7 +516,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 					Size:     int64(62913494528),
 					Property: "NonRotational",
 					FSType:   "vfat",
-					Status:   v1alpha1.DeviceStatus{State: "NotAvailable"},
+					Status:   localv1.DeviceStatus{State: "NotAvailable"},
 				},
 			},
 			fakeGlobfunc: func(name string) ([]string, error) {
@@ -456,7 +536,7 @@ func TestGetDiscoveredDevices(t *testing.T) {
 			internal.FilePathEvalSymLinks = filepath.EvalSymlinks
 		}()
 
-		actual := getDiscoverdDevices(tc.blockDevices)
+		actual := getDiscoverdDevices(tc.blockDevices, nil)
 		for i := 0; i < len(tc.expected); i++ {
 			assert.Equalf(t, tc.expected[i].DeviceID, actual[i].DeviceID, "[%s: Discovered Device: %d]: invalid device ID", tc.label, i+1)
 			assert.Equalf(t, tc.expected[i].Path, actual[i].Path, "[%s: Discovered Device: %d]: invalid device path", tc.label, i+1)
@@ -476,28 +556,33 @@ func TestParseDeviceType(t *testing.T) {
 	testcases := []struct {
 		label    string
 		input    string
-		expected v1alpha1.DiscoveredDeviceType
+		expected localv1.DiscoveredDeviceType
 	}{
 		{
 			label:    "Case 1: disk type",
 			input:    "disk",
-			expected: v1alpha1.DiskType,
+			expected: localv1.DiskType,
 		},
 		{
 			label:    "Case 2: part type",
 			input:    "part",
-			expected: v1alpha1.PartType,
+			expected: localv1.PartType,
 		},
 		{
 			label:    "Case 3: lvm type",
 			input:    "lvm",
-			expected: v1alpha1.LVMType,
+			expected: localv1.LVMType,
 		},
 		{
 			label:    "Case 4: loop device type",
 			input:    "loop",
 			expected: "",
 		},
+		{
+			label:    "Case 5: md array type",
+			input:    "raid10",
+			expected: localv1.RAIDType,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -510,17 +595,17 @@ func TestParseDeviceProperty(t *testing.T) {
 	testcases := []struct {
 		label    string
 		input    string
-		expected v1alpha1.DeviceMechanicalProperty
+		expected localv1.DeviceMechanicalProperty
 	}{
 		{
 			label:    "Case 1: rotational device",
 			input:    "1",
-			expected: v1alpha1.Rotational,
+			expected: localv1.Rotational,
 		},
 		{
 			label:    "Case 2: non-rotational device",
 			input:    "0",
-			expected: v1alpha1.NonRotational,
+			expected: localv1.NonRotational,
 		},
 		{
 			label:    "Case 3: invalid rotational property",
@@ -536,11 +621,16 @@ func TestParseDeviceProperty(t *testing.T) {
 }
 
 func getFakeDeviceDiscovery() *DeviceDiscovery {
-	dd := &DeviceDiscovery{}
+	dd := &DeviceDiscovery{
+		probeInterval:        DefaultProbeInterval,
+		udevEventPeriod:      DefaultUdevEventPeriod,
+		udevExclusionFilter:  DefaultUdevExclusionFilter,
+		supportedDeviceTypes: sets.NewString(DefaultSupportedDeviceTypes...),
+	}
 	dd.apiClient = &diskmaker.MockAPIUpdater{}
 	dd.eventSync = diskmaker.NewEventReporter(dd.apiClient)
-	dd.disks = []v1alpha1.DiscoveredDevice{}
-	dd.localVolumeDiscovery = &v1alpha1.LocalVolumeDiscovery{}
+	dd.disks = []localv1.DiscoveredDevice{}
+	dd.localVolumeDiscovery = &localv1.LocalVolumeDiscovery{}
 
 	return dd
 }
@@ -1,33 +1,40 @@
 package discovery
 
 import (
-	"bufio"
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
+	"github.com/openshift/local-storage-operator/internal"
 	"k8s.io/klog"
 )
 
 var (
-	udevExclusionFilter = []string{"(?i)dm-[0-9]+", "(?i)rbd[0-9]", "(?i)nbd[0-9]+"}
-	udevEventMatch      = []string{"(?i)add", "(?i)remove"}
+	udevEventActions = []internal.UEventAction{internal.UEventAdd, internal.UEventRemove}
+	// multipathEventActions also include change, sent when a path of a multipath device fails or is reinstated
+	multipathEventActions = []internal.UEventAction{internal.UEventAdd, internal.UEventRemove, internal.UEventChange}
+)
+
+const (
+	// multipathUUIDPrefix prefixes the device-mapper UUID of the dm-multipath devices
+	multipathUUIDPrefix = "mpath-"
 )
 
 // Monitors udev for block device changes, and collapses these events such that
 // only one event is emitted per period in order to deal with flapping.
-func udevBlockMonitor(c chan string, period time.Duration) {
+func udevBlockMonitor(c chan string, uevents <-chan internal.UEvent, period time.Duration, udevExclusionFilter []string) {
 	defer close(c)
 
 	// return any add or remove events, but none that match device mapper
 	// events. string matching is case-insensitive
 	events := make(chan string)
 
-	klog.Infof("regex for matching udev events - %q", udevEventMatch)
+	klog.Infof("udev event actions to be matched - %q", udevEventActions)
 	klog.Infof("regex for list of devices to be ignored for udev events - %q", udevExclusionFilter)
 
-	go rawUdevBlockMonitor(events, udevEventMatch, udevExclusionFilter)
+	go filterUdevBlockEvents(events, uevents, udevExclusionFilter)
 
 	for {
 		event, ok := <-events
@@ -51,70 +58,66 @@ func udevBlockMonitor(c chan string, period time.Duration) {
 	}
 }
 
-// Scans `udevadm monitor` output for block sub-system events. Each line of
-// output matching a set of substrings is sent to the provided channel. An event
-// is returned if it passes any matches tests, and passes all exclusion tests.
-func rawUdevBlockMonitor(c chan string, matches, exclusions []string) {
+// Filters the block device uevents. The name of each device whose event matches is sent
+// to the provided channel. An event is returned if its action is matched, and passes all exclusion tests.
+func filterUdevBlockEvents(c chan string, uevents <-chan internal.UEvent, exclusions []string) {
 	defer close(c)
 
-	// stdbuf -oL performs line bufferred output
-	cmd := exec.Command("stdbuf", "-oL", "udevadm", "monitor", "-u", "-k", "-s", "block")
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		klog.Warningf("Cannot open udevadm stdout: %v", err)
-		return
-	}
-
-	err = cmd.Start()
-	if err != nil {
-		klog.Warningf("Cannot start udevadm monitoring: %v", err)
-		return
-	}
-
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		text := scanner.Text()
-		klog.Infof("udevadm monitor: %s", text)
-		match, err := matchUdevEvent(text, matches, exclusions)
+	for event := range uevents {
+		klog.V(4).Infof("uevent: %s %s", event.Action, event.DevPath)
+		match, err := matchUdevEvent(event, udevEventActions, exclusions)
 		if err != nil {
-			klog.Warningf("udevadm filtering failed: %v", err)
+			klog.Warningf("udev event filtering failed: %v", err)
 			return
 		}
 		if match {
-			c <- text
+			c <- getUEventDevName(event)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		klog.Warningf("udevadm monitor scanner error: %v", err)
-	}
-
-	klog.Info("udevadm monitor finished")
+	klog.Info("udev monitor finished")
 }
 
-func matchUdevEvent(text string, matches, exclusions []string) (bool, error) {
-	for _, match := range matches {
-		matched, err := regexp.MatchString(match, text)
-		if err != nil {
-			return false, fmt.Errorf("failed to search string: %v", err)
+func matchUdevEvent(event internal.UEvent, actions []internal.UEventAction, exclusions []string) (bool, error) {
+	devName := getUEventDevName(event)
+	// the exclusions target the other device-mapper devices, multipath devices are discovered
+	if isMultipathUEvent(event) {
+		for _, action := range multipathEventActions {
+			if event.Action == action {
+				klog.Infof("udev monitor: matched %s event on multipath device %q", event.Action, devName)
+				return true, nil
+			}
 		}
-		if matched {
-			hasExclusion := false
-			for _, exclusion := range exclusions {
-				matched, err = regexp.MatchString(exclusion, text)
-				if err != nil {
-					return false, fmt.Errorf("failed to search string: %v", err)
-				}
-				if matched {
-					hasExclusion = true
-					break
-				}
+		return false, nil
+	}
+	for _, action := range actions {
+		if event.Action != action {
+			continue
+		}
+		for _, exclusion := range exclusions {
+			matched, err := regexp.MatchString(exclusion, devName)
+			if err != nil {
+				return false, fmt.Errorf("failed to search string: %v", err)
 			}
-			if !hasExclusion {
-				klog.Infof("udevadm monitor: matched event: %s", text)
-				return true, nil
+			if matched {
+				return false, nil
 			}
 		}
+		klog.Infof("udev monitor: matched %s event on %q", event.Action, devName)
+		return true, nil
 	}
 	return false, nil
 }
+
+// isMultipathUEvent returns true if the event is on a dm-multipath device
+func isMultipathUEvent(event internal.UEvent) bool {
+	return strings.HasPrefix(event.Properties["DM_UUID"], multipathUUIDPrefix)
+}
+
+// getUEventDevName returns the kernel name of the device of the event
+func getUEventDevName(event internal.UEvent) string {
+	if event.DevName != "" {
+		return event.DevName
+	}
+	return filepath.Base(event.DevPath)
+}
@@ -3,49 +3,51 @@ package discovery
 import (
 	"testing"
 
+	"github.com/openshift/local-storage-operator/internal"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMatchUdevEvent(t *testing.T) {
 	testcases := []struct {
 		label     string
-		text      string
-		matches   []string
+		event     internal.UEvent
 		exclusion []string
 		expected  bool
 	}{
 		{
 			label:     "Case 1: match add udev event",
-			text:      "KERNEL[1008.734088] add      /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)",
-			matches:   []string{"(?i)add", "(?i)remove"},
+			event:     internal.UEvent{Action: internal.UEventAdd, DevPath: "/devices/pci0000:00/0000:00:07.0/virtio5/block/vdc", DevName: "vdc"},
 			exclusion: []string{"(?i)dm-[0-9]+"},
 			expected:  true,
 		},
 		{
 			label:     "Case 2: match remove udev event",
-			text:      "KERNEL[1008.734088] remove     /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)",
-			matches:   []string{"(?i)add", "(?i)remove"},
+			event:     internal.UEvent{Action: internal.UEventRemove, DevPath: "/devices/pci0000:00/0000:00:07.0/virtio5/block/vdc", DevName: "vdc"},
 			exclusion: []string{"(?i)dm-[0-9]+"},
 			expected:  true,
 		},
 		{
 			label:     "Case 3: validate exclusion of change udev event",
-			text:      "KERNEL[1008.734088] change      /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)",
-			matches:   []string{"(?i)add", "(?i)remove"},
+			event:     internal.UEvent{Action: internal.UEventChange, DevPath: "/devices/pci0000:00/0000:00:07.0/virtio5/block/vdc", DevName: "vdc"},
 			exclusion: []string{"(?i)dm-[0-9]+"},
 			expected:  false,
 		},
 		{
 			label:     "Case 4: validate exlusion of event on dm device",
-			text:      "KERNEL[1042.464238] add      /devices/virtual/block/dm-1 (block)",
-			matches:   []string{"(?i)add", "(?i)remove"},
+			event:     internal.UEvent{Action: internal.UEventAdd, DevPath: "/devices/virtual/block/dm-1"},
 			exclusion: []string{"(?i)dm-[0-9]+"},
 			expected:  false,
 		},
+		{
+			label:     "Case 5: match change udev event on multipath device",
+			event:     internal.UEvent{Action: internal.UEventChange, DevPath: "/devices/virtual/block/dm-2", Properties: map[string]string{"DM_UUID": "mpath-3600a0b80001"}},
+			exclusion: []string{"(?i)dm-[0-9]+"},
+			expected:  true,
+		},
 	}
 
 	for _, tc := range testcases {
-		actual, err := matchUdevEvent(tc.text, tc.matches, tc.exclusion)
+		actual, err := matchUdevEvent(tc.event, udevEventActions, tc.exclusion)
 		assert.NoError(t, err)
 		assert.Equalf(t, tc.expected, actual, "[%q] udev event matcher failed", tc.label)
 	}
@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"os"
+	"time"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog"
+)
+
+// rewatchDelay is the delay before watching the LocalVolumeDiscoveryResult again after the watch failed or closed
+var rewatchDelay = 10 * time.Second
+
+// watchRescanRequests watches the LocalVolumeDiscoveryResult of this node and sends the value of
+// the rescan-requested annotation to c whenever it is set to a new value.
+func (discovery *DeviceDiscovery) watchRescanRequests(c chan<- string) {
+	name := truncateNodeName(resultCRName, os.Getenv("MY_NODE_NAME"))
+	namespace := os.Getenv("WATCH_NAMESPACE")
+	lastRequest := ""
+	for {
+		w, err := discovery.apiClient.WatchDiscoveryResult(name, namespace)
+		if err != nil {
+			klog.Warningf("failed to watch LocalVolumeDiscoveryResult %q for rescan requests: %v", name, err)
+			time.Sleep(rewatchDelay)
+			continue
+		}
+		for event := range w.ResultChan() {
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			result, ok := event.Object.(*localv1.LocalVolumeDiscoveryResult)
+			if !ok {
+				continue
+			}
+			request, found := result.Annotations[common.DiscoveryRescanRequestedAnnotation]
+			if !found {
+				lastRequest = ""
+				continue
+			}
+			// the result is also modified by the scan itself, only send each request once
+			if request != lastRequest {
+				lastRequest = request
+				c <- request
+			}
+		}
+		w.Stop()
+		time.Sleep(rewatchDelay)
+	}
+}
+
+// completeRescanRequest removes the rescan-requested annotation from the LocalVolumeDiscoveryResult,
+// unless it was changed by a newer request in the meantime.
+func (discovery *DeviceDiscovery) completeRescanRequest(request string) error {
+	name := truncateNodeName(resultCRName, os.Getenv("MY_NODE_NAME"))
+	resultCR, err := discovery.apiClient.GetDiscoveryResult(name, os.Getenv("WATCH_NAMESPACE"))
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve LocalVolumeDiscoveryResult resource")
+	}
+
+	current, found := resultCR.Annotations[common.DiscoveryRescanRequestedAnnotation]
+	if !found || current != request {
+		return nil
+	}
+	delete(resultCR.Annotations, common.DiscoveryRescanRequestedAnnotation)
+	err = discovery.apiClient.UpdateDiscoveryResult(resultCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove the rescan request from the LocalVolumeDiscoveryResult resource")
+	}
+
+	return nil
+}
@@ -7,7 +7,7 @@ import (
 	"os"
 	"time"
 
-	"github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/common"
 	"github.com/openshift/local-storage-operator/diskmaker"
 	"github.com/pkg/errors"
@@ -19,25 +19,25 @@ import (
 )
 
 // newDiscoveryResultInstance creates spec for the LocalVolumeDiscoveryResult
-func newDiscoveryResultInstance(nodeName, namespace, parentObjName, parentObjUID string) *v1alpha1.LocalVolumeDiscoveryResult {
+func newDiscoveryResultInstance(nodeName, namespace, parentObjName, parentObjUID string) *localv1.LocalVolumeDiscoveryResult {
 	truncatedNodeName := truncateNodeName(resultCRName, nodeName)
 	labels := map[string]string{}
 	labels[common.DiscoveryNodeLabel] = nodeName
-	cr := &v1alpha1.LocalVolumeDiscoveryResult{
+	cr := &localv1.LocalVolumeDiscoveryResult{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      truncatedNodeName,
 			Namespace: namespace,
 			Labels:    labels,
 			OwnerReferences: []metav1.OwnerReference{
 				{
-					APIVersion: v1alpha1.GroupVersion.String(),
+					APIVersion: localv1.GroupVersion.String(),
 					Kind:       "LocalVolumeDiscovery",
 					Name:       parentObjName,
 					UID:        apiTypes.UID(parentObjUID),
 				},
 			},
 		},
-		Spec: v1alpha1.LocalVolumeDiscoveryResultSpec{
+		Spec: localv1.LocalVolumeDiscoveryResultSpec{
 			NodeName: nodeName,
 		},
 	}
@@ -87,6 +87,7 @@ func (discovery *DeviceDiscovery) updateStatus() error {
 	// Update discovered devce list and discovery time
 	resultCR.Status.DiscoveredDevices = discovery.disks
 	resultCR.Status.DiscoveredTimeStamp = time.Now().UTC().Format(time.RFC3339)
+	resultCR.Status.Paused = discovery.paused
 
 	err = discovery.apiClient.UpdateDiscoveryResultStatus(resultCR)
 	if err != nil {
@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
 	"github.com/openshift/local-storage-operator/diskmaker"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,7 +29,7 @@ func TestEnsureDiscoveryResultNoEnv(t *testing.T) {
 func TestEnsureDiscoveryResultFail(t *testing.T) {
 	// failed to get existing discovery result object
 	mockClient := &diskmaker.MockAPIUpdater{
-		MockGetDiscoveryResult: func(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error) {
+		MockGetDiscoveryResult: func(name, namespace string) (*localv1.LocalVolumeDiscoveryResult, error) {
 			return nil, fmt.Errorf("failed to get result object")
 		},
 	}
@@ -53,7 +54,7 @@ func TestUpdateStatus(t *testing.T) {
 func TestUpdateStatusFail(t *testing.T) {
 	// failed to get discovery result
 	mockClient := &diskmaker.MockAPIUpdater{
-		MockGetDiscoveryResult: func(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error) {
+		MockGetDiscoveryResult: func(name, namespace string) (*localv1.LocalVolumeDiscoveryResult, error) {
 			return nil, fmt.Errorf("failed to get result object")
 		},
 	}
@@ -66,7 +67,7 @@ func TestUpdateStatusFail(t *testing.T) {
 
 	// failed to update discovery result status
 	mockClient = &diskmaker.MockAPIUpdater{
-		MockUpdateDiscoveryResultStatus: func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+		MockUpdateDiscoveryResultStatus: func(lvdr *localv1.LocalVolumeDiscoveryResult) error {
 			return fmt.Errorf("failed to update status")
 		},
 	}
@@ -85,7 +86,7 @@ func TestNewDiscoveryResultInstance(t *testing.T) {
 		namespace        string
 		parentObjectName string
 		parentObjectUID  string
-		expected         v1alpha1.LocalVolumeDiscoveryResult
+		expected         localv1.LocalVolumeDiscoveryResult
 	}{
 		{
 			label:            "Case 1: node name less than 63 characters",
@@ -93,7 +94,7 @@ func TestNewDiscoveryResultInstance(t *testing.T) {
 			namespace:        "local-storage",
 			parentObjectName: "diskmaker-discvoery-123",
 			parentObjectUID:  "f288b336-434e-4939-b742-9d8fd232a56c",
-			expected: v1alpha1.LocalVolumeDiscoveryResult{
+			expected: localv1.LocalVolumeDiscoveryResult{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "discovery-result-node1",
 					Namespace: "local-storage",
@@ -105,7 +106,7 @@ func TestNewDiscoveryResultInstance(t *testing.T) {
 						},
 					},
 				},
-				Spec: v1alpha1.LocalVolumeDiscoveryResultSpec{
+				Spec: localv1.LocalVolumeDiscoveryResultSpec{
 					NodeName: "node1",
 				},
 			},
@@ -116,7 +117,7 @@ func TestNewDiscoveryResultInstance(t *testing.T) {
 			namespace:        "default",
 			parentObjectName: "diskmaker-discvoery-456",
 			parentObjectUID:  "f288b336-434e-4939-b742-9d8fd232a56c",
-			expected: v1alpha1.LocalVolumeDiscoveryResult{
+			expected: localv1.LocalVolumeDiscoveryResult{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "discovery-result-18ece452ed7782c7cc0eaea565398631",
 					Namespace: "default",
@@ -128,7 +129,7 @@ func TestNewDiscoveryResultInstance(t *testing.T) {
 						},
 					},
 				},
-				Spec: v1alpha1.LocalVolumeDiscoveryResultSpec{
+				Spec: localv1.LocalVolumeDiscoveryResultSpec{
 					NodeName: "192.168.1.27.ec2.internal.node-name-greater-than-63-characters",
 				},
 			},
@@ -174,3 +175,51 @@ func TestTruncateNodeName(t *testing.T) {
 		assert.Equalf(t, tc.expected, actual, "[%s]: failed to truncate node name", tc.label)
 	}
 }
+
+func TestCompleteRescanRequest(t *testing.T) {
+	testcases := []struct {
+		label          string
+		annotation     string
+		request        string
+		expectedUpdate bool
+	}{
+		{
+			label:          "Case 1: remove the completed rescan request",
+			annotation:     "2021-05-01T12:00:00Z",
+			request:        "2021-05-01T12:00:00Z",
+			expectedUpdate: true,
+		},
+		{
+			label:          "Case 2: keep a newer rescan request",
+			annotation:     "2021-05-01T12:05:00Z",
+			request:        "2021-05-01T12:00:00Z",
+			expectedUpdate: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		updated := false
+		mockClient := &diskmaker.MockAPIUpdater{
+			MockGetDiscoveryResult: func(name, namespace string) (*localv1.LocalVolumeDiscoveryResult, error) {
+				return &localv1.LocalVolumeDiscoveryResult{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{common.DiscoveryRescanRequestedAnnotation: tc.annotation},
+					},
+				}, nil
+			},
+			MockUpdateDiscoveryResult: func(lvdr *localv1.LocalVolumeDiscoveryResult) error {
+				updated = true
+				assert.NotContainsf(t, lvdr.Annotations, common.DiscoveryRescanRequestedAnnotation, "[%s]", tc.label)
+				return nil
+			},
+		}
+
+		dd := getFakeDeviceDiscovery()
+		dd.apiClient = mockClient
+		setEnv()
+		err := dd.completeRescanRequest(tc.request)
+		unsetEnv()
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+		assert.Equalf(t, tc.expectedUpdate, updated, "[%s]", tc.label)
+	}
+}
@@ -17,9 +17,18 @@ const (
 	ErrorFindingMatchingDisk = "ErrorFindingMatchingDisk"
 	SymLinkedOnDeviceName    = "SymlinkedOnDeivceName"
 	ErrorProvisioningDisk    = "ErrorProvisioningDisk"
+	ErrorEncryptingDisk      = "ErrorEncryptingDisk"
+	ErrorMountingFilesystem  = "ErrorMountingFilesystem"
+	ErrorMigratingSymlink    = "ErrorMigratingSymlink"
+	FencedPV                 = "FencedPV"
+	UnfencedPV               = "UnfencedPV"
+	ErrorVerifyingDevice     = "ErrorVerifyingDevice"
+	QuarantinedPV            = "QuarantinedPV"
+	ReleasedQuarantinedPV    = "ReleasedQuarantinedPV"
 
 	FoundMatchingDisk   = "FoundMatchingDisk"
 	DeviceSymlinkExists = "DeviceSymlinkExists"
+	MigratedSymlink     = "MigratedSymlink"
 
 	// LocalVolumeDiscovery events
 	ErrorCreatingDiscoveryResultObject = "ErrorCreatingDiscoveryResultObject"
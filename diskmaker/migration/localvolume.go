@@ -0,0 +1,266 @@
+// Package migration moves the devices of LocalVolumes to LocalVolumeSets, without recreating their PVs.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// LocalVolumeMigration is the migration of a LocalVolume to a LocalVolumeSet per storage class.
+// The PVs and storage classes of the LocalVolume are relabelled for the LocalVolumeSets, which find the devices
+// through their existing symlinks, and the LocalVolume is deleted.
+type LocalVolumeMigration struct {
+	LocalVolume     *localv1.LocalVolume
+	LocalVolumeSets []*localv1.LocalVolumeSet
+	Relabels        []Relabel
+}
+
+// Relabel is the change of the owner labels of a PV or a storage class
+type Relabel struct {
+	// Kind is PersistentVolume or StorageClass
+	Kind string
+	Name string
+	// Set are the labels set, Removed the labels removed
+	Set     map[string]string
+	Removed []string
+}
+
+// Plan returns the migration of the LocalVolume. The LocalVolumeSets include only the devices of the LocalVolume,
+// by the links the PVs were created on and the devicePaths, and exclude the devices of the PVs of other owners
+// on the same nodes.
+func Plan(ctx context.Context, c client.Client, namespace, name string) (*LocalVolumeMigration, error) {
+	lv := &localv1.LocalVolume{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, lv)
+	if err != nil {
+		return nil, fmt.Errorf("could not get LocalVolume %s/%s: %w", namespace, name, err)
+	}
+	pvs := &corev1.PersistentVolumeList{}
+	err = c.List(ctx, pvs)
+	if err != nil {
+		return nil, fmt.Errorf("could not list PVs: %w", err)
+	}
+	ownerSelector := common.GetPVOwnerSelector(lv)
+	ownedPVs := make([]corev1.PersistentVolume, 0)
+	nodes := sets.NewString()
+	for _, pv := range pvs.Items {
+		if ownerSelector.Matches(labels.Set(pv.Labels)) {
+			ownedPVs = append(ownedPVs, pv)
+			nodes.Insert(pv.Labels[corev1.LabelHostname])
+		}
+	}
+	excludedPaths := sets.NewString()
+	for _, pv := range pvs.Items {
+		if _, found := pv.Labels[common.PVOwnerKindLabel]; !found || ownerSelector.Matches(labels.Set(pv.Labels)) ||
+			!nodes.Has(pv.Labels[corev1.LabelHostname]) {
+			continue
+		}
+		path, err := getDevicePath(pv)
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			excludedPaths.Insert(path)
+		}
+	}
+
+	migration := &LocalVolumeMigration{LocalVolume: lv}
+	for _, storageClassDevice := range lv.Spec.StorageClassDevices {
+		lvsetName := lv.Name
+		if len(lv.Spec.StorageClassDevices) > 1 {
+			lvsetName = fmt.Sprintf("%s-%s", lv.Name, storageClassDevice.StorageClassName)
+		}
+		devicePaths := sets.NewString(storageClassDevice.DevicePaths...)
+		for _, pv := range ownedPVs {
+			if pv.Spec.StorageClassName != storageClassDevice.StorageClassName {
+				continue
+			}
+			path, err := getDevicePath(pv)
+			if err != nil {
+				return nil, err
+			}
+			if path != "" {
+				devicePaths.Insert(path)
+			}
+			migration.Relabels = append(migration.Relabels, Relabel{
+				Kind: "PersistentVolume",
+				Name: pv.Name,
+				Set: map[string]string{
+					common.PVOwnerKindLabel:      localv1.LocalVolumeSetKind,
+					common.PVOwnerNameLabel:      lvsetName,
+					common.PVOwnerNamespaceLabel: lv.Namespace,
+				},
+				Removed: []string{common.LocalVolumeOwnerNameForPV, common.LocalVolumeOwnerNamespaceForPV},
+			})
+		}
+		migration.Relabels = append(migration.Relabels, Relabel{
+			Kind: "StorageClass",
+			Name: storageClassDevice.StorageClassName,
+			Set: map[string]string{
+				common.OwnerNameLabel:      lvsetName,
+				common.OwnerNamespaceLabel: lv.Namespace,
+			},
+		})
+		migration.LocalVolumeSets = append(migration.LocalVolumeSets, newLocalVolumeSet(lv, lvsetName, storageClassDevice, devicePaths.List(), excludedPaths.List()))
+	}
+	return migration, nil
+}
+
+// newLocalVolumeSet returns the LocalVolumeSet of the devices of the storage class of the LocalVolume
+func newLocalVolumeSet(lv *localv1.LocalVolume, name string, storageClassDevice localv1.StorageClassDevice, devicePaths, excludedPaths []string) *localv1.LocalVolumeSet {
+	// the devices are pinned by their paths, the other matchers must not drop any
+	minSize := resource.MustParse("0")
+	return &localv1.LocalVolumeSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: localv1.GroupVersion.String(),
+			Kind:       localv1.LocalVolumeSetKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: lv.Namespace},
+		Spec: localv1.LocalVolumeSetSpec{
+			NodeSelector:     lv.Spec.NodeSelector,
+			Tolerations:      lv.Spec.Tolerations,
+			StorageClassName: storageClassDevice.StorageClassName,
+			VolumeMode:       storageClassDevice.VolumeMode,
+			FSType:           storageClassDevice.FSType,
+			Filesystem:       storageClassDevice.Filesystem,
+			Encryption:       storageClassDevice.Encryption,
+			IdentityPolicy:   storageClassDevice.IdentityPolicy,
+			DeviceInclusionSpec: &localv1.DeviceInclusionSpec{
+				DeviceTypes:         []localv1.DeviceType{localv1.RawDisk, localv1.Partition, localv1.Multipath},
+				MinSize:             &minSize,
+				DevicePaths:         devicePaths,
+				ExcludedDevicePaths: excludedPaths,
+			},
+		},
+	}
+}
+
+// getDevicePath returns the link the PV was created on, or "" when the device had none
+func getDevicePath(pv corev1.PersistentVolume) (string, error) {
+	id := pv.Annotations[common.PVDeviceIDLabel]
+	if id == "" {
+		return "", nil
+	}
+	identity := pv.Annotations[common.PVDeviceIdentityAnnotation]
+	if identity == "" {
+		// the PVs provisioned before the identity policies were all created on the /dev/disk/by-id link of their device
+		identity = internal.IdentityByID
+	}
+	dir := internal.IdentityDir(identity)
+	if dir == "" {
+		return "", fmt.Errorf("PV %s has the device ID %q, but no link of the device identity %q", pv.Name, id, identity)
+	}
+	return filepath.Join(dir, id), nil
+}
+
+// Diff writes the changes of the migration
+func (m *LocalVolumeMigration) Diff(w io.Writer) error {
+	for _, lvset := range m.LocalVolumeSets {
+		data, err := yaml.Marshal(lvset)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "+ LocalVolumeSet %s/%s\n%s", lvset.Namespace, lvset.Name, data)
+	}
+	for _, relabel := range m.Relabels {
+		fmt.Fprintf(w, "~ %s %s\n", relabel.Kind, relabel.Name)
+		keys := make([]string, 0, len(relabel.Set))
+		for key := range relabel.Set {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, "+   %s: %s\n", key, relabel.Set[key])
+		}
+		for _, key := range relabel.Removed {
+			fmt.Fprintf(w, "-   %s\n", key)
+		}
+	}
+	fmt.Fprintf(w, "- LocalVolume %s/%s\n", m.LocalVolume.Namespace, m.LocalVolume.Name)
+	return nil
+}
+
+// Apply migrates the LocalVolume. The storage classes are relabelled before the LocalVolume is deleted,
+// so that its deletion doesn't remove them, and the PVs after, so that its diskmaker can't label them again.
+// Its deletion is blocked until then by its bound PVs. A failed migration can be applied again.
+func (m *LocalVolumeMigration) Apply(ctx context.Context, c client.Client, w io.Writer) error {
+	for _, lvset := range m.LocalVolumeSets {
+		err := c.Create(ctx, lvset)
+		if kerrors.IsAlreadyExists(err) {
+			fmt.Fprintf(w, "LocalVolumeSet %s/%s already exists\n", lvset.Namespace, lvset.Name)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("could not create LocalVolumeSet %s/%s: %w", lvset.Namespace, lvset.Name, err)
+		}
+		fmt.Fprintf(w, "created LocalVolumeSet %s/%s\n", lvset.Namespace, lvset.Name)
+	}
+	for _, relabel := range m.Relabels {
+		if relabel.Kind != "StorageClass" {
+			continue
+		}
+		err := relabel.apply(ctx, c, &storagev1.StorageClass{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "relabelled StorageClass %s\n", relabel.Name)
+	}
+	err := c.Delete(ctx, m.LocalVolume)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete LocalVolume %s/%s: %w", m.LocalVolume.Namespace, m.LocalVolume.Name, err)
+	}
+	fmt.Fprintf(w, "deleted LocalVolume %s/%s\n", m.LocalVolume.Namespace, m.LocalVolume.Name)
+	for _, relabel := range m.Relabels {
+		if relabel.Kind != "PersistentVolume" {
+			continue
+		}
+		err := relabel.apply(ctx, c, &corev1.PersistentVolume{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "relabelled PersistentVolume %s\n", relabel.Name)
+	}
+	return nil
+}
+
+// apply changes the labels of obj
+func (r Relabel) apply(ctx context.Context, c client.Client, obj client.Object) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := c.Get(ctx, types.NamespacedName{Name: r.Name}, obj)
+		if err != nil {
+			return err
+		}
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = map[string]string{}
+		}
+		for key, value := range r.Set {
+			objLabels[key] = value
+		}
+		for _, key := range r.Removed {
+			delete(objLabels, key)
+		}
+		obj.SetLabels(objLabels)
+		return c.Update(ctx, obj)
+	})
+	if err != nil {
+		return fmt.Errorf("could not relabel %s %s: %w", r.Kind, r.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPV(name, node string, labels map[string]string, deviceID, identity string) *corev1.PersistentVolume {
+	labels[corev1.LabelHostname] = node
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: map[string]string{common.PVDeviceIDLabel: deviceID, common.PVDeviceIdentityAnnotation: identity},
+		},
+		Spec: corev1.PersistentVolumeSpec{StorageClassName: "local-sc"},
+	}
+}
+
+func TestLocalVolumeMigration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, storagev1.AddToScheme(scheme))
+	assert.NoError(t, localv1.AddToScheme(scheme))
+	assert.NoError(t, localv1alpha1.AddToScheme(scheme))
+
+	lv := &localv1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-disks", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSpec{
+			StorageClassDevices: []localv1.StorageClassDevice{{
+				StorageClassName: "local-sc",
+				VolumeMode:       localv1.PersistentVolumeBlock,
+				DevicePaths:      []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", "/dev/sdd"},
+			}},
+		},
+	}
+	lvLabels := func() map[string]string {
+		return map[string]string{
+			common.PVOwnerKindLabel:               localv1.LocalVolumeKind,
+			common.PVOwnerNameLabel:               lv.Name,
+			common.PVOwnerNamespaceLabel:          lv.Namespace,
+			common.LocalVolumeOwnerNameForPV:      lv.Name,
+			common.LocalVolumeOwnerNamespaceForPV: lv.Namespace,
+		}
+	}
+	otherLabels := func() map[string]string {
+		return map[string]string{common.PVOwnerKindLabel: localv1.LocalVolumeSetKind, common.PVOwnerNameLabel: "other"}
+	}
+	storageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+		Name:   "local-sc",
+		Labels: map[string]string{common.OwnerNameLabel: lv.Name, common.OwnerNamespaceLabel: lv.Namespace},
+	}}
+	client := fake.NewFakeClientWithScheme(scheme, lv, storageClass,
+		newPV("local-pv-1", "node1", lvLabels(), "wwn-0x5000c500a0b1c2d3", "wwn"),
+		newPV("local-pv-2", "node1", lvLabels(), "pci-0000:00:1f.2-ata-2", "by-path"),
+		newPV("local-pv-5", "node1", lvLabels(), "SERIAL5", "by-lso"),
+		newPV("local-pv-6", "node1", lvLabels(), "ata-SAMSUNG_6", ""),
+		newPV("local-pv-3", "node1", otherLabels(), "wwn-0x5000c500a0b1c2d9", "wwn"),
+		newPV("local-pv-4", "node2", otherLabels(), "wwn-0x5000c500a0b1c2e0", "wwn"),
+	)
+
+	lvMigration, err := Plan(context.TODO(), client, lv.Namespace, lv.Name)
+	assert.NoError(t, err)
+	assert.Len(t, lvMigration.LocalVolumeSets, 1)
+	lvset := lvMigration.LocalVolumeSets[0]
+	assert.Equal(t, "local-disks", lvset.Name)
+	assert.Equal(t, "local-sc", lvset.Spec.StorageClassName)
+	assert.Equal(t, localv1.PersistentVolumeBlock, lvset.Spec.VolumeMode)
+	assert.Equal(t, []string{
+		"/dev/disk/by-id/ata-SAMSUNG_6",
+		"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3",
+		"/dev/disk/by-lso/SERIAL5",
+		"/dev/disk/by-path/pci-0000:00:1f.2-ata-2",
+		"/dev/sdd",
+	}, lvset.Spec.DeviceInclusionSpec.DevicePaths, "the devices are pinned by the link of their identity, by-id for the PVs without one")
+	assert.Equal(t, []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d9"}, lvset.Spec.DeviceInclusionSpec.ExcludedDevicePaths, "only the devices of the other owners on the same nodes are excluded")
+	assert.Len(t, lvMigration.Relabels, 5)
+
+	diff := &bytes.Buffer{}
+	assert.NoError(t, lvMigration.Diff(diff))
+	assert.Contains(t, diff.String(), "+ LocalVolumeSet openshift-local-storage/local-disks\n")
+	assert.Contains(t, diff.String(), "~ PersistentVolume local-pv-1\n+   storage.openshift.com/owner-kind: LocalVolumeSet\n")
+	assert.Contains(t, diff.String(), "-   storage.openshift.com/local-volume-owner-name\n")
+	assert.Contains(t, diff.String(), "- LocalVolume openshift-local-storage/local-disks\n")
+
+	// the dry-run changes nothing
+	pv := &corev1.PersistentVolume{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "local-pv-1"}, pv))
+	assert.Equal(t, localv1.LocalVolumeKind, pv.Labels[common.PVOwnerKindLabel])
+
+	assert.NoError(t, lvMigration.Apply(context.TODO(), client, &bytes.Buffer{}))
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Namespace: lv.Namespace, Name: "local-disks"}, &localv1.LocalVolumeSet{}))
+	err = client.Get(context.TODO(), types.NamespacedName{Namespace: lv.Namespace, Name: lv.Name}, &localv1.LocalVolume{})
+	assert.True(t, kerrors.IsNotFound(err))
+	for _, name := range []string{"local-pv-1", "local-pv-2", "local-pv-5", "local-pv-6"} {
+		pv := &corev1.PersistentVolume{}
+		assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: name}, pv))
+		assert.Equal(t, localv1.LocalVolumeSetKind, pv.Labels[common.PVOwnerKindLabel])
+		assert.Equal(t, "local-disks", pv.Labels[common.PVOwnerNameLabel])
+		assert.NotContains(t, pv.Labels, common.LocalVolumeOwnerNameForPV)
+	}
+	pv = &corev1.PersistentVolume{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "local-pv-3"}, pv))
+	assert.Equal(t, "other", pv.Labels[common.PVOwnerNameLabel])
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: "local-sc"}, storageClass))
+	assert.Equal(t, "local-disks", storageClass.Labels[common.OwnerNameLabel])
+}
+
+func TestGetDevicePath(t *testing.T) {
+	path, err := getDevicePath(*newPV("local-pv-1", "node1", map[string]string{}, "sdb", "kname"))
+	assert.Error(t, err, "a kernel name is not a link of the device")
+	assert.Equal(t, "", path)
+
+	path, err = getDevicePath(*newPV("local-pv-2", "node1", map[string]string{}, "", "kname"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", path, "the devices without link are not pinned")
+
+	path, err = getDevicePath(*newPV("local-pv-3", "node1", map[string]string{}, "2c1a4f3e-01", "partuuid"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/disk/by-partuuid/2c1a4f3e-01", path)
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	provDeleter "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/deleter"
+)
+
+// procTable is a provDeleter.ProcTable that records the cleanups in the Store,
+// so that they survive a restart of the diskmaker
+type procTable struct {
+	store     *Store
+	succeeded int
+	failed    int
+}
+
+var _ provDeleter.ProcTable = &procTable{}
+
+// ProcTable returns a provDeleter.ProcTable backed by the store.
+// Quarantined PVs are reported as running, so that they are neither cleaned up again nor recreated.
+func (s *Store) ProcTable() provDeleter.ProcTable {
+	return &procTable{store: s}
+}
+
+// IsRunning returns true if the cleanup of the PV is running or quarantined
+func (p *procTable) IsRunning(pvName string) bool {
+	cleanup, found := p.store.GetCleanup(pvName)
+	return found && (cleanup.Status == CleanupRunning || cleanup.Status == CleanupQuarantined)
+}
+
+// IsEmpty returns true if no cleanup is recorded
+func (p *procTable) IsEmpty() bool {
+	p.store.mux.Lock()
+	defer p.store.mux.Unlock()
+	return len(p.store.state.Cleanups) == 0
+}
+
+// MarkRunning records the start of the cleanup of the PV.
+// A PV whose previous cleanup failed was removed from the table by RemoveEntry, its attempts and interruptions are kept.
+func (p *procTable) MarkRunning(pvName string) error {
+	p.store.mux.Lock()
+	defer p.store.mux.Unlock()
+	cleanup, found := p.store.state.Cleanups[pvName]
+	if found && cleanup.Status != CleanupFailed {
+		return fmt.Errorf("cannot start the cleanup of PV %q, its cleanup is %s", pvName, cleanup.Status)
+	}
+	p.store.state.Cleanups[pvName] = Cleanup{
+		Status:        CleanupRunning,
+		StartTime:     time.Now(),
+		Attempts:      cleanup.Attempts + 1,
+		Interruptions: cleanup.Interruptions,
+	}
+	return p.store.save()
+}
+
+// MarkFailed records the failure of the cleanup of the PV
+func (p *procTable) MarkFailed(pvName string) error {
+	p.failed++
+	return p.markStatus(pvName, CleanupFailed)
+}
+
+// MarkSucceeded records the completion of the cleanup of the PV
+func (p *procTable) MarkSucceeded(pvName string) error {
+	p.succeeded++
+	return p.markStatus(pvName, CleanupSucceeded)
+}
+
+func (p *procTable) markStatus(pvName string, status CleanupStatus) error {
+	p.store.mux.Lock()
+	defer p.store.mux.Unlock()
+	cleanup, found := p.store.state.Cleanups[pvName]
+	if !found {
+		return fmt.Errorf("failed to mark status %s for PV %q as it is not present in the state", status, pvName)
+	}
+	cleanup.Status = status
+	p.store.state.Cleanups[pvName] = cleanup
+	return p.store.save()
+}
+
+// RemoveEntry returns the final state and start time of a completed cleanup.
+// A succeeded cleanup is removed along with the claim of its device. A failed cleanup is kept with its
+// attempts, and reported as failed so that the deleter restarts it.
+func (p *procTable) RemoveEntry(pvName string) (provDeleter.CleanupState, *time.Time, error) {
+	p.store.mux.Lock()
+	defer p.store.mux.Unlock()
+	cleanup, found := p.store.state.Cleanups[pvName]
+	if !found {
+		return provDeleter.CSNotFound, nil, nil
+	}
+	startTime := cleanup.StartTime
+	switch cleanup.Status {
+	case CleanupSucceeded:
+		delete(p.store.state.Cleanups, pvName)
+		for deviceID, claim := range p.store.state.Claims {
+			if claim.PVName == pvName {
+				delete(p.store.state.Claims, deviceID)
+			}
+		}
+		return provDeleter.CSSucceeded, &startTime, p.store.save()
+	case CleanupFailed:
+		return provDeleter.CSFailed, &startTime, nil
+	default:
+		return provDeleter.CSUnknown, nil, fmt.Errorf("cannot remove the cleanup of PV %q, its cleanup is %s", pvName, cleanup.Status)
+	}
+}
+
+// Stats returns the stats of the cleanups since the diskmaker started
+func (p *procTable) Stats() provDeleter.ProcTableStats {
+	p.store.mux.Lock()
+	defer p.store.mux.Unlock()
+	running := 0
+	for _, cleanup := range p.store.state.Cleanups {
+		if cleanup.Status == CleanupRunning {
+			running++
+		}
+	}
+	return provDeleter.ProcTableStats{
+		Running:   running,
+		Succeeded: p.succeeded,
+		Failed:    p.failed,
+	}
+}
@@ -0,0 +1,385 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// FileName is the name of the state file of the diskmaker, kept in the symlink directory of the host
+	FileName = ".diskmaker-state.json"
+
+	// MaxCleanupInterruptions is the number of interrupted cleanups after which the device of a PV is quarantined
+	MaxCleanupInterruptions = 3
+)
+
+// bootIDPath is the path of the boot ID of the host. It is replaced in unit tests
+var bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// CleanupStatus is the status of the cleanup of a released PV
+type CleanupStatus string
+
+const (
+	// CleanupRunning is the status of a cleanup in progress, or interrupted by a restart of the diskmaker
+	CleanupRunning CleanupStatus = "Running"
+	// CleanupSucceeded is the status of a cleanup that completed. The PV can be deleted
+	CleanupSucceeded CleanupStatus = "Succeeded"
+	// CleanupFailed is the status of a cleanup that failed or was interrupted. It is restarted by the deleter
+	CleanupFailed CleanupStatus = "Failed"
+	// CleanupQuarantined is the status of a cleanup that was interrupted MaxCleanupInterruptions times.
+	// The PV is neither cleaned up again nor deleted, so that its device is not offered again.
+	// The deleter labels the PV, removing the label releases it with ReleaseQuarantine.
+	CleanupQuarantined CleanupStatus = "Quarantined"
+)
+
+// Cleanup records the progress of the cleanup of a released PV
+type Cleanup struct {
+	Status    CleanupStatus `json:"status"`
+	StartTime time.Time     `json:"startTime"`
+	// Attempts is the number of cleanups started for the PV
+	Attempts int `json:"attempts"`
+	// Interruptions is the number of cleanups interrupted by a restart of the diskmaker
+	Interruptions int `json:"interruptions"`
+	// Reported is set once the quarantine of the PV was reported on the PV
+	Reported bool `json:"reported,omitempty"`
+}
+
+// Claim records the PV provisioned on a device
+type Claim struct {
+	// DeviceName is the kernel name of the device when it was claimed
+	DeviceName string `json:"deviceName"`
+	PVName     string `json:"pvName"`
+	// Owner is the kind/namespace/name of the object that claimed the device
+	Owner     string    `json:"owner"`
+	ClaimedAt time.Time `json:"claimedAt"`
+	// Serial, WWN and Size fingerprint the device when it was first claimed,
+	// so that it can be told apart from a device that took its kernel name
+	Serial string `json:"serial,omitempty"`
+	WWN    string `json:"wwn,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+}
+
+// HasFingerprint returns true if the device was fingerprinted when it was claimed
+func (c Claim) HasFingerprint() bool {
+	return c.Size != 0
+}
+
+// nodeState is the content of the state file
+type nodeState struct {
+	// BootID of the host when the state was saved. The device ages are dropped after a reboot,
+	// as the kernel names of the devices can change.
+	BootID string `json:"bootID"`
+	// DeviceFirstSeen is the time each device was first observed, by kernel name
+	DeviceFirstSeen map[string]time.Time `json:"deviceFirstSeen,omitempty"`
+	// Claims are the claimed devices, by device ID
+	Claims map[string]Claim `json:"claims,omitempty"`
+	// Cleanups are the cleanups of the released PVs, by PV name
+	Cleanups map[string]Cleanup `json:"cleanups,omitempty"`
+	// ProvisionerName is the provisioner of the PVs of the node, found by the first run of the deleter
+	ProvisionerName string `json:"provisionerName,omitempty"`
+}
+
+// Store is a crash-safe store of the diskmaker state on the host.
+// Every change is written to a temporary file that atomically replaces the state file.
+// A nil Store keeps nothing.
+type Store struct {
+	path  string
+	mux   sync.Mutex
+	state nodeState
+}
+
+// Open loads the state file at path, or starts an empty state if it doesn't exist.
+// Cleanups that were running when the diskmaker stopped are marked as failed, so that they are restarted,
+// or quarantined once they were interrupted MaxCleanupInterruptions times.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+		state: nodeState{
+			DeviceFirstSeen: map[string]time.Time{},
+			Claims:          map[string]Claim{},
+			Cleanups:        map[string]Cleanup{},
+		},
+	}
+	bootID, err := getBootID()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not read state file %q: %w", path, err)
+	} else if err == nil {
+		loaded := nodeState{}
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			// a corrupted state is not fatal, the diskmaker behaves as after a first start
+			klog.Warningf("ignoring corrupted state file %q: %v", path, err)
+		} else {
+			if loaded.BootID == bootID && loaded.DeviceFirstSeen != nil {
+				s.state.DeviceFirstSeen = loaded.DeviceFirstSeen
+			}
+			if loaded.Claims != nil {
+				s.state.Claims = loaded.Claims
+			}
+			if loaded.Cleanups != nil {
+				s.state.Cleanups = loaded.Cleanups
+			}
+			s.state.ProvisionerName = loaded.ProvisionerName
+		}
+	}
+	s.state.BootID = bootID
+
+	for pvName, cleanup := range s.state.Cleanups {
+		if cleanup.Status != CleanupRunning {
+			continue
+		}
+		cleanup.Interruptions++
+		if cleanup.Interruptions >= MaxCleanupInterruptions {
+			klog.Warningf("quarantining PV %q, its cleanup was interrupted %d times", pvName, cleanup.Interruptions)
+			cleanup.Status = CleanupQuarantined
+		} else {
+			klog.Infof("cleanup of PV %q was interrupted, it will be restarted", pvName)
+			cleanup.Status = CleanupFailed
+		}
+		s.state.Cleanups[pvName] = cleanup
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DeviceAges returns the time each device was first observed, by kernel name
+func (s *Store) DeviceAges() map[string]time.Time {
+	ages := map[string]time.Time{}
+	if s == nil {
+		return ages
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for key, firstSeen := range s.state.DeviceFirstSeen {
+		ages[key] = firstSeen
+	}
+	return ages
+}
+
+// SetDeviceFirstSeen records the time a device was first observed
+func (s *Store) SetDeviceFirstSeen(key string, firstSeen time.Time) error {
+	if s == nil {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.state.DeviceFirstSeen[key] = firstSeen
+	return s.save()
+}
+
+// ForgetDevices removes the age of the devices that are not present
+func (s *Store) ForgetDevices(present sets.String) error {
+	if s == nil {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	changed := false
+	for key := range s.state.DeviceFirstSeen {
+		if !present.Has(key) {
+			delete(s.state.DeviceFirstSeen, key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// RecordClaim records the PV provisioned on the device identified by deviceID
+func (s *Store) RecordClaim(deviceID string, claim Claim) error {
+	if s == nil {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	existing, found := s.state.Claims[deviceID]
+	// the fingerprint is the one of the device first claimed, a device that takes its kernel name doesn't replace it
+	if found && existing.HasFingerprint() {
+		claim.Serial, claim.WWN, claim.Size = existing.Serial, existing.WWN, existing.Size
+	}
+	if found && existing.PVName == claim.PVName && existing.Owner == claim.Owner &&
+		(existing.HasFingerprint() || !claim.HasFingerprint()) {
+		return nil
+	}
+	s.state.Claims[deviceID] = claim
+	return s.save()
+}
+
+// MoveClaim records the claim of the device identified by deviceID under newDeviceID, when the device is symlinked by a new ID
+func (s *Store) MoveClaim(deviceID, newDeviceID string) error {
+	if s == nil {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	claim, found := s.state.Claims[deviceID]
+	if !found {
+		return nil
+	}
+	delete(s.state.Claims, deviceID)
+	s.state.Claims[newDeviceID] = claim
+	return s.save()
+}
+
+// GetClaim returns the claim of the device identified by deviceID
+func (s *Store) GetClaim(deviceID string) (Claim, bool) {
+	if s == nil {
+		return Claim{}, false
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	claim, found := s.state.Claims[deviceID]
+	return claim, found
+}
+
+// GetCleanup returns the cleanup of a PV
+func (s *Store) GetCleanup(pvName string) (Cleanup, bool) {
+	if s == nil {
+		return Cleanup{}, false
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	cleanup, found := s.state.Cleanups[pvName]
+	return cleanup, found
+}
+
+// QuarantinedPVs returns the names of the PVs whose cleanup is quarantined, sorted
+func (s *Store) QuarantinedPVs() []string {
+	if s == nil {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	pvNames := []string{}
+	for pvName, cleanup := range s.state.Cleanups {
+		if cleanup.Status == CleanupQuarantined {
+			pvNames = append(pvNames, pvName)
+		}
+	}
+	sort.Strings(pvNames)
+	return pvNames
+}
+
+// MarkQuarantineReported records that the quarantine of the PV was reported on the PV
+func (s *Store) MarkQuarantineReported(pvName string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	cleanup, found := s.state.Cleanups[pvName]
+	if !found || cleanup.Status != CleanupQuarantined {
+		return fmt.Errorf("the cleanup of PV %q is not quarantined", pvName)
+	}
+	cleanup.Reported = true
+	s.state.Cleanups[pvName] = cleanup
+	return s.save()
+}
+
+// ReleaseQuarantine marks the quarantined cleanup of the PV as failed, so that the deleter restarts it.
+// Its interruptions are forgotten, its attempts are kept.
+func (s *Store) ReleaseQuarantine(pvName string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	cleanup, found := s.state.Cleanups[pvName]
+	if !found || cleanup.Status != CleanupQuarantined {
+		return fmt.Errorf("the cleanup of PV %q is not quarantined", pvName)
+	}
+	s.state.Cleanups[pvName] = Cleanup{
+		Status:    CleanupFailed,
+		StartTime: cleanup.StartTime,
+		Attempts:  cleanup.Attempts,
+	}
+	return s.save()
+}
+
+// ProvisionerName returns the provisioner of the PVs of the node recorded by SetProvisionerName
+func (s *Store) ProvisionerName() string {
+	if s == nil {
+		return ""
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.state.ProvisionerName
+}
+
+// SetProvisionerName records the provisioner of the PVs of the node
+func (s *Store) SetProvisionerName(name string) error {
+	if s == nil {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.state.ProvisionerName == name {
+		return nil
+	}
+	s.state.ProvisionerName = name
+	return s.save()
+}
+
+// save writes the state to a temporary file that replaces the state file. s.mux must be held.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode the diskmaker state: %w", err)
+	}
+	dir := filepath.Dir(s.path)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("could not create state directory %q: %w", dir, err)
+	}
+
+	tmp, err := ioutil.TempFile(dir, FileName+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not create temporary state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write temporary state file %q: %w", tmp.Name(), err)
+	}
+	err = os.Rename(tmp.Name(), s.path)
+	if err != nil {
+		return fmt.Errorf("could not replace state file %q: %w", s.path, err)
+	}
+
+	// persist the rename
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("could not open state directory %q: %w", dir, err)
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func getBootID() (string, error) {
+	data, err := ioutil.ReadFile(bootIDPath)
+	if err != nil {
+		return "", fmt.Errorf("could not read the boot ID: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,191 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	provDeleter "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/deleter"
+)
+
+// setBootID replaces the boot ID of the host and returns a func to restore it
+func setBootID(t *testing.T, dir, bootID string) func() {
+	path := filepath.Join(dir, "boot_id")
+	err := ioutil.WriteFile(path, []byte(bootID+"\n"), 0644)
+	assert.NoError(t, err)
+	old := bootIDPath
+	bootIDPath = path
+	return func() { bootIDPath = old }
+}
+
+func TestDeviceAges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diskmaker-state")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer setBootID(t, dir, "boot-1")()
+	path := filepath.Join(dir, FileName)
+
+	store, err := Open(path)
+	assert.NoError(t, err)
+	assert.Empty(t, store.DeviceAges())
+
+	firstSeen := time.Unix(1000, 0).UTC()
+	assert.NoError(t, store.SetDeviceFirstSeen("sdb", firstSeen))
+	assert.NoError(t, store.SetDeviceFirstSeen("sdc", firstSeen))
+	assert.NoError(t, store.ForgetDevices(sets.NewString("sdb")))
+
+	// the ages are kept across restarts
+	store, err = Open(path)
+	assert.NoError(t, err)
+	ages := store.DeviceAges()
+	assert.Len(t, ages, 1)
+	assert.True(t, firstSeen.Equal(ages["sdb"]))
+
+	// but not across reboots
+	setBootID(t, dir, "boot-2")
+	store, err = Open(path)
+	assert.NoError(t, err)
+	assert.Empty(t, store.DeviceAges())
+}
+
+func TestCleanups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diskmaker-state")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer setBootID(t, dir, "boot-1")()
+	path := filepath.Join(dir, FileName)
+
+	store, err := Open(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.RecordClaim("/dev/disk/by-id/wwn-0x1", Claim{DeviceName: "sdb", PVName: "local-pv-1", Owner: "LocalVolumeSet/ns/lvset"}))
+	procTable := store.ProcTable()
+	assert.True(t, procTable.IsEmpty())
+
+	// a completed cleanup releases the claim of the device
+	assert.NoError(t, procTable.MarkRunning("local-pv-1"))
+	assert.True(t, procTable.IsRunning("local-pv-1"))
+	assert.NoError(t, procTable.MarkSucceeded("local-pv-1"))
+	state, startTime, err := procTable.RemoveEntry("local-pv-1")
+	assert.NoError(t, err)
+	assert.Equal(t, provDeleter.CSSucceeded, state)
+	assert.NotNil(t, startTime)
+	_, found := store.GetClaim("/dev/disk/by-id/wwn-0x1")
+	assert.False(t, found)
+	assert.True(t, procTable.IsEmpty())
+
+	// an interrupted cleanup is restarted after a restart
+	assert.NoError(t, procTable.MarkRunning("local-pv-2"))
+	for i := 1; i < MaxCleanupInterruptions; i++ {
+		store, err = Open(path)
+		assert.NoError(t, err)
+		procTable = store.ProcTable()
+		assert.False(t, procTable.IsRunning("local-pv-2"))
+		state, _, err = procTable.RemoveEntry("local-pv-2")
+		assert.NoError(t, err)
+		assert.Equal(t, provDeleter.CSFailed, state)
+		assert.NoError(t, procTable.MarkRunning("local-pv-2"))
+	}
+
+	// and quarantined once it was interrupted too many times
+	store, err = Open(path)
+	assert.NoError(t, err)
+	procTable = store.ProcTable()
+	cleanup, found := store.GetCleanup("local-pv-2")
+	assert.True(t, found)
+	assert.Equal(t, CleanupQuarantined, cleanup.Status)
+	assert.Equal(t, MaxCleanupInterruptions, cleanup.Attempts)
+	assert.True(t, procTable.IsRunning("local-pv-2"), "quarantined PVs should not be cleaned up again")
+	assert.Error(t, procTable.MarkRunning("local-pv-2"))
+	assert.Equal(t, []string{"local-pv-2"}, store.QuarantinedPVs())
+
+	// the quarantine is reported once, across restarts
+	assert.NoError(t, store.MarkQuarantineReported("local-pv-2"))
+	store, err = Open(path)
+	assert.NoError(t, err)
+	cleanup, _ = store.GetCleanup("local-pv-2")
+	assert.True(t, cleanup.Reported)
+
+	// a released PV is cleaned up again, and can be interrupted MaxCleanupInterruptions times again
+	assert.NoError(t, store.ReleaseQuarantine("local-pv-2"))
+	assert.Empty(t, store.QuarantinedPVs())
+	assert.Error(t, store.ReleaseQuarantine("local-pv-2"))
+	assert.Error(t, store.MarkQuarantineReported("local-pv-2"))
+	procTable = store.ProcTable()
+	assert.False(t, procTable.IsRunning("local-pv-2"))
+	state, _, err = procTable.RemoveEntry("local-pv-2")
+	assert.NoError(t, err)
+	assert.Equal(t, provDeleter.CSFailed, state)
+	assert.NoError(t, procTable.MarkRunning("local-pv-2"))
+	cleanup, _ = store.GetCleanup("local-pv-2")
+	assert.Equal(t, MaxCleanupInterruptions+1, cleanup.Attempts)
+	assert.Equal(t, 0, cleanup.Interruptions)
+	assert.False(t, cleanup.Reported)
+}
+
+func TestClaims(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diskmaker-state")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer setBootID(t, dir, "boot-1")()
+	path := filepath.Join(dir, FileName)
+
+	store, err := Open(path)
+	assert.NoError(t, err)
+	claim := Claim{DeviceName: "sdb", PVName: "local-pv-1", Owner: "LocalVolumeSet/ns/lvset", Serial: "S3EWNX0K", Size: 1024}
+	assert.NoError(t, store.RecordClaim("/dev/sdb", claim))
+
+	// a device that takes the kernel name of the claimed device doesn't replace its fingerprint
+	assert.NoError(t, store.RecordClaim("/dev/sdb", Claim{DeviceName: "sdb", PVName: "local-pv-1", Owner: "LocalVolumeSet/ns/lvset", Serial: "Z1Z2Z3", Size: 2048}))
+	assert.NoError(t, store.MoveClaim("/dev/sdb", "/dev/disk/by-path/pci-0000:00:1f.2-ata-1"))
+
+	store, err = Open(path)
+	assert.NoError(t, err)
+	_, found := store.GetClaim("/dev/sdb")
+	assert.False(t, found)
+	moved, found := store.GetClaim("/dev/disk/by-path/pci-0000:00:1f.2-ata-1")
+	assert.True(t, found)
+	assert.Equal(t, "S3EWNX0K", moved.Serial)
+	assert.Equal(t, int64(1024), moved.Size)
+
+	// claims recorded without a fingerprint get one
+	assert.NoError(t, store.RecordClaim("/dev/sdc", Claim{DeviceName: "sdc", PVName: "local-pv-2"}))
+	assert.NoError(t, store.RecordClaim("/dev/sdc", Claim{DeviceName: "sdc", PVName: "local-pv-2", WWN: "0x5000c500a0b1c2d3", Size: 1024}))
+	fingerprinted, _ := store.GetClaim("/dev/sdc")
+	assert.True(t, fingerprinted.HasFingerprint())
+}
+
+func TestNilStore(t *testing.T) {
+	var store *Store
+	assert.NoError(t, store.SetDeviceFirstSeen("sdb", time.Now()))
+	assert.NoError(t, store.ForgetDevices(sets.NewString()))
+	assert.NoError(t, store.RecordClaim("/dev/sdb", Claim{}))
+	assert.NoError(t, store.MoveClaim("/dev/sdb", "/dev/disk/by-lso/S3EWNX0K"))
+	assert.Empty(t, store.DeviceAges())
+}
+
+func TestProvisionerName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diskmaker-state")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer setBootID(t, dir, "boot-1")()
+	path := filepath.Join(dir, FileName)
+
+	store, err := Open(path)
+	assert.NoError(t, err)
+	assert.Empty(t, store.ProvisionerName())
+	assert.NoError(t, store.SetProvisionerName("local-volume-provisioner-node1-uid"))
+
+	// the provisioner name is kept across restarts and reboots
+	setBootID(t, dir, "boot-2")
+	store, err = Open(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "local-volume-provisioner-node1-uid", store.ProvisionerName())
+
+	var nilStore *Store
+	assert.Empty(t, nilStore.ProvisionerName())
+	assert.NoError(t, nilStore.SetProvisionerName("ignored"))
+}
@@ -6,9 +6,19 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var discoveryOptions = discovery.DefaultOptions()
+
+func init() {
+	flags := discoveryDaemonCmd.Flags()
+	flags.DurationVar(&discoveryOptions.ProbeInterval, "probe-interval", discovery.DefaultProbeInterval, "interval between two periodic scans of the devices")
+	flags.DurationVar(&discoveryOptions.UdevEventPeriod, "udev-event-period", discovery.DefaultUdevEventPeriod, "period over which udev events are collapsed into a single scan")
+	flags.StringArrayVar(&discoveryOptions.UdevExclusionFilter, "udev-exclusion-filter", discovery.DefaultUdevExclusionFilter, "regular expression of devices whose udev events are ignored, can be repeated")
+	flags.StringSliceVar(&discoveryOptions.SupportedDeviceTypes, "supported-device-types", discovery.DefaultSupportedDeviceTypes, "device types that are discovered")
+}
+
 func startDeviceDiscovery(cmd *cobra.Command, args []string) error {
 	printVersion()
-	discoveryObj, err := discovery.NewDeviceDiscovery()
+	discoveryObj, err := discovery.NewDeviceDiscovery(discoveryOptions)
 	if err != nil {
 		return errors.Wrapf(err, "failed to discover devices")
 	}
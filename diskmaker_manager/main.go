@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"k8s.io/klog"
 )
 
 var rootCmd = &cobra.Command{
@@ -26,11 +28,25 @@ var discoveryDaemonCmd = &cobra.Command{
 	Short: "Used to start device discovery for the LocalVolumeDiscovery CR",
 	RunE:  startDeviceDiscovery,
 }
+var migrateLocalVolumeCmd = &cobra.Command{
+	Use:   "migrate-localvolume NAME",
+	Short: "Used to migrate the devices and PVs of a LocalVolume to LocalVolumeSets, without recreating the PVs",
+	Args:  cobra.ExactArgs(1),
+	RunE:  migrateLocalVolume,
+}
+
+func init() {
+	// the verbosity of the daemons is set by the operator with --v
+	klogFlags := flag.NewFlagSet("local-storage-diskmaker", flag.ExitOnError)
+	klog.InitFlags(klogFlags)
+	rootCmd.PersistentFlags().AddGoFlag(klogFlags.Lookup("v"))
+}
 
 func main() {
 	rootCmd.AddCommand(lvDaemonCmd)
 	rootCmd.AddCommand(managerCmd)
 	rootCmd.AddCommand(discoveryDaemonCmd)
+	rootCmd.AddCommand(migrateLocalVolumeCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -1,22 +1,28 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"path/filepath"
 
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker"
 	diskmakerControllerDeleter "github.com/openshift/local-storage-operator/diskmaker/controllers/deleter"
+	diskmakerControllerKeyRotation "github.com/openshift/local-storage-operator/diskmaker/controllers/keyrotation"
 	diskmakerControllerLv "github.com/openshift/local-storage-operator/diskmaker/controllers/lv"
 	diskmakerControllerLvSet "github.com/openshift/local-storage-operator/diskmaker/controllers/lvset"
+	"github.com/openshift/local-storage-operator/diskmaker/state"
+	"github.com/openshift/local-storage-operator/internal"
 	"github.com/prometheus/common/log"
 	"github.com/spf13/cobra"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/klog"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	provCache "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/cache"
 	provDeleter "sigs.k8s.io/sig-storage-local-static-provisioner/pkg/deleter"
@@ -35,8 +41,6 @@ func init() {
 }
 
 func startManager(cmd *cobra.Command, args []string) error {
-	klogFlags := flag.NewFlagSet("local-storage-diskmaker", flag.ExitOnError)
-	klog.InitFlags(klogFlags)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -73,26 +77,62 @@ func startManager(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// the state of the diskmaker is kept on the host, so that device ages, claims and cleanups survive restarts
+	stateStore, err := state.Open(filepath.Join(common.GetLocalDiskLocationPath(), state.FileName))
+	if err != nil {
+		setupLog.Error(err, "unable to open diskmaker state")
+		return err
+	}
+
+	// the cleanups are shared by the deleter and the provisioning controllers, so that a PV is not recreated
+	// on a device that is being wiped or quarantined, even after a restart.
+	// Only the deleter removes their entries, the provisioning controllers read them.
+	cleanupTracker := &provDeleter.CleanupStatusTracker{ProcTable: stateStore.ProcTable()}
+
+	// block device events are shared by the provisioning controllers, so they react to hot-plugged disks
+	ueventListener := internal.NewUEventListener(internal.KernelUEvents)
+	if err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		ueventListener.Start(ctx.Done())
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to add uevent listener")
+		return err
+	}
+
 	if err = (&diskmakerControllerLv.LocalVolumeReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr, &provDeleter.CleanupStatusTracker{ProcTable: provDeleter.NewProcTable()}, provCache.NewVolumeCache()); err != nil {
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		DeviceEvents: diskmaker.BlockDeviceEvents(ueventListener.Subscribe()),
+		StateStore:   stateStore,
+	}).SetupWithManager(mgr, cleanupTracker, provCache.NewVolumeCache()); err != nil {
 		setupLog.Error(err, "unable to create diskmaker controller", "controller", "LocalVolume")
 		return err
 	}
 
 	if err = (&diskmakerControllerLvSet.LocalVolumeSetReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr, &provDeleter.CleanupStatusTracker{ProcTable: provDeleter.NewProcTable()}, provCache.NewVolumeCache()); err != nil {
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		DeviceEvents: diskmaker.BlockDeviceEvents(ueventListener.Subscribe()),
+		StateStore:   stateStore,
+	}).SetupWithManager(mgr, cleanupTracker, provCache.NewVolumeCache()); err != nil {
 		setupLog.Error(err, "unable to create diskmaker controller", "controller", "LocalVolumeSet")
 		return err
 	}
 
+	if err = (&diskmakerControllerKeyRotation.KeyRotationReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		StateStore: stateStore,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create diskmaker controller", "controller", "EncryptionKeyRotation")
+		return err
+	}
+
 	if err = (&diskmakerControllerDeleter.DeleteReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr, &provDeleter.CleanupStatusTracker{ProcTable: provDeleter.NewProcTable()}, provCache.NewVolumeCache()); err != nil {
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		StateStore: stateStore,
+	}).SetupWithManager(mgr, cleanupTracker, provCache.NewVolumeCache()); err != nil {
 		setupLog.Error(err, "unable to create diskmaker controller", "controller", "Deleter")
 		return err
 	}
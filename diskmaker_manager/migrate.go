@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/openshift/local-storage-operator/diskmaker/migration"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var migrateOptions = struct {
+	namespace string
+	dryRun    bool
+}{}
+
+func init() {
+	flags := migrateLocalVolumeCmd.Flags()
+	flags.StringVarP(&migrateOptions.namespace, "namespace", "n", "openshift-local-storage", "namespace of the LocalVolume")
+	flags.BoolVar(&migrateOptions.dryRun, "dry-run", false, "only print the LocalVolumeSets and the label changes of the migration")
+}
+
+func migrateLocalVolume(cmd *cobra.Command, args []string) error {
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create client")
+	}
+	ctx := context.TODO()
+	lvMigration, err := migration.Plan(ctx, c, migrateOptions.namespace, args[0])
+	if err != nil {
+		return err
+	}
+	if migrateOptions.dryRun {
+		return lvMigration.Diff(os.Stdout)
+	}
+	return lvMigration.Apply(ctx, c, os.Stdout)
+}
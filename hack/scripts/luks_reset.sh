@@ -0,0 +1,60 @@
+#!/bin/bash -e
+
+# Usage:
+# $ luks_reset.sh
+
+# Import common functions.
+. $(dirname "$0")/common.sh
+
+if [ "$1" == "-h" ]; then
+  echo "Usage: $(basename $0) "
+  echo "Closes the LUKS device of a local PV, erases its keyslots and calls wipefs to remove any signatures."
+  echo "The symlink to the opened LUKS device must be specified by the environment variable LOCAL_PV_BLKDEVICE"
+  exit 0
+fi
+
+if [ -z ${LOCAL_PV_BLKDEVICE+x} ]
+then
+    errorExit "Environment variable LOCAL_PV_BLKDEVICE has not been set"
+fi
+
+# The symlink points to /dev/mapper/lso-<hash> and is named after the link the encrypted device was opened by,
+# whatever its identity. The hash is the one of the path of that link, so the device is found
+# even if the device-mapper node was already closed by an interrupted cleanup.
+MAPPER_PATH=$(readlink $LOCAL_PV_BLKDEVICE) || errorExit "$LOCAL_PV_BLKDEVICE is not a symlink."
+MAPPER_NAME=$(basename $MAPPER_PATH)
+DEVICE_NAME=$(basename $LOCAL_PV_BLKDEVICE)
+DEVICE_PATH=""
+for CANDIDATE in /dev/disk/by-id/$DEVICE_NAME /dev/disk/by-path/$DEVICE_NAME /dev/disk/by-partuuid/$DEVICE_NAME /dev/disk/by-lso/$DEVICE_NAME /dev/$DEVICE_NAME
+do
+    if [ "lso-$(echo -n $CANDIDATE | sha256sum | cut -c1-16)" == "$MAPPER_NAME" ]
+    then
+        DEVICE_PATH=$CANDIDATE
+        break
+    fi
+done
+if [ -z "$DEVICE_PATH" ]
+then
+    errorExit "$MAPPER_NAME was not opened for a device named $DEVICE_NAME."
+fi
+if [ ! -b "$DEVICE_PATH" ]
+then
+    errorExit "$DEVICE_PATH is not a block device."
+fi
+
+if [ -b "$MAPPER_PATH" ]
+then
+    echo "Closing $MAPPER_PATH"
+    cryptsetup close $(basename $MAPPER_PATH)
+fi
+
+if cryptsetup isLuks $DEVICE_PATH
+then
+    echo "Erasing the LUKS keyslots of $DEVICE_PATH"
+    cryptsetup erase --batch-mode $DEVICE_PATH
+fi
+
+echo "Calling wipefs"
+ionice -c 3 wipefs -a $DEVICE_PATH
+
+echo "LUKS reset completed"
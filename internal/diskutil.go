@@ -12,6 +12,7 @@ import (
 
 	"github.com/pkg/errors"
 	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 var (
@@ -24,6 +25,8 @@ var (
 const (
 	// StateSuspended is a possible value of BlockDevice.State
 	StateSuspended = "suspended"
+	// RAIDType is the type of the md arrays of all the RAID levels, that lsblk reports as raid0, raid1, raid10...
+	RAIDType = "raid"
 	// DiskByIDDir is the path for symlinks to the device by id.
 	DiskByIDDir = "/dev/disk/by-id/"
 )
@@ -55,10 +58,24 @@ type BlockDevice struct {
 	PathByID   string `json:"pathByID,omitempty"`
 	Serial     string `json:"serial,omitempty"`
 	PartLabel  string `json:"partLabel,omitempty"`
+	WWN        string `json:"wwn,omitempty"`
 }
 
 // IDPathNotFoundError indicates that a symlink to the device was not found in /dev/disk/by-id/
 
+// GetType returns the lsblk type of the device, with the RAID levels of the md arrays folded into RAIDType
+func (b BlockDevice) GetType() string {
+	if b.IsRAID() {
+		return RAIDType
+	}
+	return b.Type
+}
+
+// IsRAID returns true if the device is an md array
+func (b BlockDevice) IsRAID() bool {
+	return strings.HasPrefix(b.Type, RAIDType)
+}
+
 // GetRotational as bool
 func (b BlockDevice) GetRotational() (bool, error) {
 	v, err := parseBitBool(b.Rotational)
@@ -211,7 +228,7 @@ func ListBlockDevices() ([]BlockDevice, []string, error) {
 		return []BlockDevice{}, []string{}, errors.Wrap(err, "failed to list block devices")
 	}
 
-	columns := "NAME,ROTA,TYPE,SIZE,MODEL,VENDOR,RO,RM,STATE,KNAME,SERIAL,PARTLABEL"
+	columns := "NAME,ROTA,TYPE,SIZE,MODEL,VENDOR,RO,RM,STATE,KNAME,SERIAL,PARTLABEL,WWN"
 	args := []string{"--pairs", "-b", "-o", columns}
 	cmd := ExecCommand("lsblk", args...)
 	output, err := executeCmdWithCombinedOutput(cmd)
@@ -221,6 +238,7 @@ func ListBlockDevices() ([]BlockDevice, []string, error) {
 	badRows := make([]string, 0)
 	// convert to json and then Marshal.
 	outputMapList := make([]map[string]interface{}, 0)
+	knames := sets.NewString()
 	rowList := strings.Split(output, "\n")
 	for _, row := range rowList {
 		if len(strings.Trim(row, " ")) == 0 {
@@ -251,6 +269,14 @@ func ListBlockDevices() ([]BlockDevice, []string, error) {
 			break
 		}
 
+		// md arrays and multipath devices are listed once per member
+		if kname, ok := outputMap["kname"].(string); ok && kname != "" {
+			if knames.Has(kname) {
+				continue
+			}
+			knames.Insert(kname)
+		}
+
 		// Update device filesystem using `blkid`
 		if fs, ok := deviceFSMap[fmt.Sprintf("/dev/%s", name)]; ok {
 			outputMap["fsType"] = fs
@@ -322,13 +348,23 @@ func GetDeviceFSMap() (map[string]string, error) {
 // existingLinkPaths is a list of existing symlinks. It is not exhaustive
 // error
 func GetPVCreationLock(device string, symlinkDirs ...string) (ExclusiveFileLock, bool, []string, error) {
+	return getPVCreationLock(device, false, symlinkDirs...)
+}
+
+// GetSharedPVCreationLock is GetPVCreationLock for the shared filesystem devices,
+// the symlinks are searched without descending into the filesystems mounted in symlinkDirs.
+func GetSharedPVCreationLock(device string, symlinkDirs ...string) (ExclusiveFileLock, bool, []string, error) {
+	return getPVCreationLock(device, true, symlinkDirs...)
+}
+
+func getPVCreationLock(device string, sameFilesystem bool, symlinkDirs ...string) (ExclusiveFileLock, bool, []string, error) {
 	lock := ExclusiveFileLock{Path: device}
 	locked, err := lock.Lock()
 	// If the device is busy, then we should continue and check for symlinks
 	if err != nil && err != unix.EBUSY {
 		return lock, locked, []string{}, err
 	}
-	existingLinkPaths, symErr := GetMatchingSymlinksInDirs(device, symlinkDirs...)
+	existingLinkPaths, symErr := getMatchingSymlinksInDirs(device, sameFilesystem, symlinkDirs...)
 	// If symErr is not nil, there was an error fetching the symlinks
 	if symErr != nil {
 		return lock, locked, existingLinkPaths, symErr
@@ -344,7 +380,17 @@ func GetPVCreationLock(device string, symlinkDirs ...string) (ExclusiveFileLock,
 // GetMatchingSymlinksInDirs returns all the files in dir that are the same file as path after evaluating symlinks
 // it works using `find -L dir1 dir2 dirn -samefile path`
 func GetMatchingSymlinksInDirs(path string, dirs ...string) ([]string, error) {
-	cmd := exec.Command("find", "-L", strings.Join(dirs, " "), "-samefile", path)
+	return getMatchingSymlinksInDirs(path, false, dirs...)
+}
+
+// getMatchingSymlinksInDirs is GetMatchingSymlinksInDirs, sameFilesystem adding -xdev to keep find out of
+// the filesystems mounted in the dirs, such as the directory PVs of shared filesystems
+func getMatchingSymlinksInDirs(path string, sameFilesystem bool, dirs ...string) ([]string, error) {
+	args := []string{"-L", strings.Join(dirs, " ")}
+	if sameFilesystem {
+		args = append(args, "-xdev")
+	}
+	cmd := exec.Command("find", append(args, "-samefile", path)...)
 	output, err := executeCmdWithCombinedOutput(cmd)
 	if err != nil {
 		return []string{}, fmt.Errorf("failed to get symlinks in directories: %q for device path %q. %v", dirs, path, err)
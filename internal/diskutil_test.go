@@ -20,6 +20,11 @@ NAME="sda1" KNAME="sda1" ROTA="1" TYPE="part" SIZE="62913494528" MODEL="" VENDOR
 `
 	lsblkOutput2 = `NAME="sdc" KNAME="sdc" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="1" STATE="running" SERIAL=""
 NAME="sdc3" KNAME="sdc3" ROTA="1" TYPE="part" SIZE="62913494528" MODEL="" VENDOR="" RO="0" RM="1" STATE="" SERIAL=""
+`
+	lsblkOutput3 = `NAME="sdd" KNAME="sdd" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="0" STATE="running" SERIAL=""
+NAME="md127" KNAME="md127" ROTA="1" TYPE="raid1" SIZE="62912397312" MODEL="" VENDOR="" RO="0" RM="0" STATE="" SERIAL=""
+NAME="sde" KNAME="sde" ROTA="1" TYPE="disk" SIZE="62914560000" MODEL="VBOX HARDDISK" VENDOR="ATA" RO="0" RM="0" STATE="running" SERIAL=""
+NAME="md127" KNAME="md127" ROTA="1" TYPE="raid1" SIZE="62912397312" MODEL="" VENDOR="" RO="0" RM="0" STATE="" SERIAL=""
 `
 	blkIDOutput1 = `/dev/sdc: TYPE="ext4"
 /dev/sdc3: TYPE="ext2"
@@ -136,14 +141,25 @@ func TestListBlockDevices(t *testing.T) {
 			},
 		},
 		{
-			label:             "Case 3: empty lsblk output",
+			label:             "Case 3: md array listed once per member",
+			lsblkOutput:       lsblkOutput3,
+			totalBlockDevices: 3,
+			totalBadRows:      0,
+			expected: []BlockDevice{
+				{Name: "sdd", Type: "disk", Size: "62914560000", Model: "VBOX HARDDISK", Vendor: "ATA", Rotational: "1", ReadOnly: "0"},
+				{Name: "md127", Type: "raid1", Size: "62912397312", Rotational: "1", ReadOnly: "0"},
+				{Name: "sde", Type: "disk", Size: "62914560000", Model: "VBOX HARDDISK", Vendor: "ATA", Rotational: "1", ReadOnly: "0"},
+			},
+		},
+		{
+			label:             "Case 4: empty lsblk output",
 			lsblkOutput:       "",
 			totalBlockDevices: 0,
 			totalBadRows:      0,
 			expected:          []BlockDevice{},
 		},
 		{
-			label:             "Case 4: lsblk output with white space",
+			label:             "Case 5: lsblk output with white space",
 			lsblkOutput:       `NAME="sda" MODEL="VBOX HARDDISK   " VENDOR="ATA   "`,
 			totalBlockDevices: 1,
 			totalBadRows:      0,
@@ -626,3 +642,10 @@ func TestGetRotationalFail(t *testing.T) {
 	}
 
 }
+
+func TestGetType(t *testing.T) {
+	assert.Equal(t, "disk", BlockDevice{Type: "disk"}.GetType())
+	assert.Equal(t, RAIDType, BlockDevice{Type: "raid10"}.GetType())
+	assert.True(t, BlockDevice{Type: "raid1"}.IsRAID())
+	assert.False(t, BlockDevice{Type: "part"}.IsRAID())
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GetFilesystemType returns the type of the filesystem on the device, or an empty string if it has none.
+// Like the kubelet, it probes the device itself instead of reading the blkid cache, which can be stale after a cleanup,
+// and fails on devices with a partition table so that they are never formatted.
+func GetFilesystemType(devicePath string) (string, error) {
+	cmd := ExecCommand("blkid", "-p", "-s", "TYPE", "-s", "PTTYPE", "-o", "export", devicePath)
+	output, err := cmd.Output()
+	if err != nil {
+		// blkid exits with 2 when it finds no signature on the device
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 2 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to probe the filesystem of %q: %w", devicePath, err)
+	}
+	var fsType string
+	for _, line := range strings.Split(string(output), "\n") {
+		values := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(values) != 2 {
+			continue
+		}
+		switch values[0] {
+		case "TYPE":
+			fsType = values[1]
+		case "PTTYPE":
+			return "", fmt.Errorf("%q has a %s partition table", devicePath, values[1])
+		}
+	}
+	return fsType, nil
+}
+
+// Mkfs formats the device with mkfs.<fsType>, passing it the options and the label.
+func Mkfs(devicePath, fsType, label string, options []string) error {
+	cmd := ExecCommand("mkfs."+fsType, mkfsArgs(devicePath, label, options)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mkfs.%s failed: %w: %s", fsType, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func mkfsArgs(devicePath, label string, options []string) []string {
+	args := append([]string{}, options...)
+	if label != "" {
+		args = append(args, "-L", label)
+	}
+	return append(args, devicePath)
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fsHelperCommand returns a fake blkid or mkfs exec.Cmd that prints stdout and exits with exitCode
+func fsHelperCommand(stdout string, exitCode int) func(string, ...string) *exec.Cmd {
+	return func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestFilesystemHelperProcess", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", fmt.Sprintf("EXIT_CODE=%d", exitCode), "STDOUT=" + stdout}
+		return cmd
+	}
+}
+
+func TestFilesystemHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	var exitCode int
+	fmt.Sscanf(os.Getenv("EXIT_CODE"), "%d", &exitCode)
+	fmt.Fprintln(os.Stdout, os.Getenv("STDOUT"))
+	os.Exit(exitCode)
+}
+
+func TestGetFilesystemType(t *testing.T) {
+	defer func() { ExecCommand = exec.Command }()
+	testcases := []struct {
+		label    string
+		stdout   string
+		exitCode int
+		fsType   string
+		hasError bool
+	}{
+		{label: "xfs", stdout: "DEVNAME=/dev/sdb\nTYPE=xfs", exitCode: 0, fsType: "xfs"},
+		{label: "partition table", stdout: "DEVNAME=/dev/sdb\nPTTYPE=gpt", exitCode: 0, hasError: true},
+		{label: "no signature", stdout: "", exitCode: 2, fsType: ""},
+		{label: "blkid failure", stdout: "", exitCode: 4, hasError: true},
+	}
+	for _, tc := range testcases {
+		ExecCommand = fsHelperCommand(tc.stdout, tc.exitCode)
+		fsType, err := GetFilesystemType("/dev/sdb")
+		if tc.hasError {
+			assert.Error(t, err, tc.label)
+			continue
+		}
+		assert.NoError(t, err, tc.label)
+		assert.Equal(t, tc.fsType, fsType, tc.label)
+	}
+}
+
+func TestMkfs(t *testing.T) {
+	defer func() { ExecCommand = exec.Command }()
+	assert.Equal(t, []string{"-m", "0", "-L", "data", "/dev/sdb"}, mkfsArgs("/dev/sdb", "data", []string{"-m", "0"}))
+	assert.Equal(t, []string{"/dev/sdb"}, mkfsArgs("/dev/sdb", "", nil))
+
+	ExecCommand = fsHelperCommand("", 0)
+	assert.NoError(t, Mkfs("/dev/sdb", "ext4", "", []string{"-m", "0"}))
+	ExecCommand = fsHelperCommand("", 1)
+	assert.Error(t, Mkfs("/dev/sdb", "ext4", "", nil))
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FencedDir is the directory the symlinks of fenced PVs point to. It doesn't exist, so that nothing can use or wipe
+// the device of a fenced PV through its symlink.
+const FencedDir = "/dev/lso-fenced/"
+
+var lsblkPair = regexp.MustCompile(`([A-Z-]+)="([^"]*)"`)
+
+// Fingerprint identifies a device independently of its kernel name
+type Fingerprint struct {
+	Serial   string
+	WWN      string
+	Size     int64
+	PartUUID string
+}
+
+// GetFingerprint returns the fingerprint of the device at devPath
+func GetFingerprint(devPath string) (Fingerprint, error) {
+	cmd := ExecCommand("lsblk", "--nodeps", "--bytes", "--noheadings", "--pairs", "--output", "SERIAL,WWN,SIZE,PARTUUID", devPath)
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	fingerprint := Fingerprint{}
+	for _, pair := range lsblkPair.FindAllStringSubmatch(output, -1) {
+		value := strings.TrimSpace(pair[2])
+		switch pair[1] {
+		case "SERIAL":
+			fingerprint.Serial = value
+		case "WWN":
+			fingerprint.WWN = value
+		case "PARTUUID":
+			fingerprint.PartUUID = value
+		case "SIZE":
+			fingerprint.Size, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return Fingerprint{}, fmt.Errorf("failed to parse size %q of %q: %w", value, devPath, err)
+			}
+		}
+	}
+	return fingerprint, nil
+}
+
+// Mismatch returns a description of the first property of the fingerprint that differs on the device of the current
+// fingerprint, or "" when they match. The properties unknown in either of them, such as the serial of a device
+// udev has not probed yet, are not compared.
+func (f Fingerprint) Mismatch(current Fingerprint) string {
+	properties := []struct{ name, recorded, current string }{
+		{"serial", f.Serial, current.Serial},
+		{"WWN", f.WWN, current.WWN},
+		{"partuuid", f.PartUUID, current.PartUUID},
+	}
+	for _, property := range properties {
+		if property.recorded != "" && property.current != "" && property.recorded != property.current {
+			return fmt.Sprintf("%s %q is %q", property.name, property.recorded, property.current)
+		}
+	}
+	if f.Size != 0 && current.Size != 0 && f.Size != current.Size {
+		return fmt.Sprintf("size %d is %d", f.Size, current.Size)
+	}
+	return ""
+}
+
+// Identifies returns true if current has the serial or WWN of the recorded fingerprint and nothing else differs
+func (f Fingerprint) Identifies(current Fingerprint) bool {
+	if f.Mismatch(current) != "" {
+		return false
+	}
+	return (f.Serial != "" && f.Serial == current.Serial) || (f.WWN != "" && f.WWN == current.WWN)
+}
+
+// GetFencedPath returns the path the symlink of a fenced PV points to
+func GetFencedPath(symlinkPath string) string {
+	return filepath.Join(FencedDir, filepath.Base(symlinkPath))
+}
+
+// IsFencedPath returns true if path is the target of the symlink of a fenced PV
+func IsFencedPath(path string) bool {
+	return strings.HasPrefix(path, FencedDir)
+}
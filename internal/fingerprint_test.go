@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFingerprint(t *testing.T) {
+	defer func() { ExecCommand = exec.Command }()
+	ExecCommand = fsHelperCommand(`SERIAL="S3Z8NB0K" WWN="0x5002538e40a1b2c3" SIZE="500107862016" PARTUUID=""`, 0)
+	fingerprint, err := GetFingerprint("/dev/sdb")
+	assert.NoError(t, err)
+	assert.Equal(t, Fingerprint{Serial: "S3Z8NB0K", WWN: "0x5002538e40a1b2c3", Size: 500107862016}, fingerprint)
+
+	ExecCommand = fsHelperCommand(`SERIAL="" WWN="" SIZE="big" PARTUUID=""`, 0)
+	_, err = GetFingerprint("/dev/sdb")
+	assert.Error(t, err)
+
+	ExecCommand = fsHelperCommand("lsblk: /dev/sdb: not a block device", 32)
+	_, err = GetFingerprint("/dev/sdb")
+	assert.Error(t, err)
+}
+
+func TestFingerprintMismatch(t *testing.T) {
+	recorded := Fingerprint{Serial: "S3Z8NB0K", WWN: "0x5002538e40a1b2c3", Size: 500107862016}
+	testcases := []struct {
+		label    string
+		current  Fingerprint
+		mismatch bool
+	}{
+		{label: "same device", current: recorded},
+		{label: "serial not probed yet", current: Fingerprint{WWN: "0x5002538e40a1b2c3", Size: 500107862016}},
+		{label: "other serial", current: Fingerprint{Serial: "S3Z8NB0X", WWN: "0x5002538e40a1b2c3", Size: 500107862016}, mismatch: true},
+		{label: "other size", current: Fingerprint{Serial: "S3Z8NB0K", Size: 1000204886016}, mismatch: true},
+		{label: "partition", current: Fingerprint{Serial: "S3Z8NB0K", WWN: "0x5002538e40a1b2c3", Size: 500107862016, PartUUID: "7c9e5a1f-01"}},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.mismatch, recorded.Mismatch(tc.current) != "", tc.label)
+	}
+	assert.Equal(t, `serial "S3Z8NB0K" is "S3Z8NB0X"`, recorded.Mismatch(Fingerprint{Serial: "S3Z8NB0X"}))
+}
+
+func TestIsFencedPath(t *testing.T) {
+	assert.Equal(t, "/dev/lso-fenced/sdb", GetFencedPath("/mnt/local-storage/local-sc/sdb"))
+	assert.True(t, IsFencedPath(GetFencedPath("/mnt/local-storage/local-sc/sdb")))
+	assert.False(t, IsFencedPath("/dev/sdb"))
+	assert.Equal(t, "", GetIdentity(GetFencedPath("/mnt/local-storage/local-sc/sdb")), "fenced symlinks are never migrated")
+}
@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"k8s.io/klog"
+)
+
+// The sources of persistent device names, as used in the identity policies
+const (
+	// IdentityByID is any link in /dev/disk/by-id
+	IdentityByID = "by-id"
+	// IdentityWWN is the /dev/disk/by-id/wwn-* link
+	IdentityWWN = "wwn"
+	// IdentityByPath is the /dev/disk/by-path link, bound to the port or slot of the device
+	IdentityByPath = "by-path"
+	// IdentityPartUUID is the /dev/disk/by-partuuid link of a partition
+	IdentityPartUUID = "partuuid"
+	// IdentityByLSO is the /dev/disk/by-lso/<serial> link, created by the diskmaker with a udev rule
+	IdentityByLSO = "by-lso"
+	// IdentityKName is the kernel name of the device, which is not persistent
+	IdentityKName = "kname"
+
+	// DiskByPathDir is the path for symlinks to the device by path.
+	DiskByPathDir = "/dev/disk/by-path/"
+	// DiskByPartUUIDDir is the path for symlinks to the partitions by partition UUID.
+	DiskByPartUUIDDir = "/dev/disk/by-partuuid/"
+	// DiskByLSODir is the path for the symlinks created by the udev rules of the diskmaker.
+	DiskByLSODir = "/dev/disk/by-lso/"
+
+	wwnLinkPrefix = "wwn-"
+	// udevRulePrefix prefixes the udev rules written by the diskmaker
+	udevRulePrefix = "99-lso-"
+	// udevPatternChars are the characters udev reads as a pattern, or as the end of the value, in a match
+	udevPatternChars = `*?[]|"\`
+)
+
+var (
+	// DefaultIdentityPolicy is the order the persistent names of the devices are tried in by default
+	DefaultIdentityPolicy = []string{IdentityByID, IdentityWWN, IdentityByPath, IdentityPartUUID, IdentityByLSO}
+	// UDevRulesDir is the directory the udev rules of the by-lso links are written to
+	UDevRulesDir = "/etc/udev/rules.d"
+
+	unsafeSerialChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+	// byLSOLinks caches the by-lso links of the devices, by kernel name and serial
+	byLSOLinks    = map[string]string{}
+	byLSOLinksMux sync.Mutex
+)
+
+// GetPathByIdentity returns the link to the device of the given identity, or "" when the device has none
+func (b BlockDevice) GetPathByIdentity(identity string) (string, error) {
+	switch identity {
+	case IdentityByID:
+		path, err := b.GetPathByID()
+		if errors.As(err, &IDPathNotFoundError{}) {
+			return "", nil
+		}
+		return path, err
+	case IdentityWWN:
+		return findDeviceLink(filepath.Join(DiskByIDDir, wwnLinkPrefix+"*"), b.KName)
+	case IdentityByPath:
+		return findDeviceLink(filepath.Join(DiskByPathDir, "*"), b.KName)
+	case IdentityPartUUID:
+		return findDeviceLink(filepath.Join(DiskByPartUUIDDir, "*"), b.KName)
+	case IdentityByLSO:
+		return b.EnsureByLSOLink()
+	}
+	return "", fmt.Errorf("unknown device identity %q", identity)
+}
+
+// GetIdentity returns the identity of a persistent link to a device, IdentityKName for /dev/KNAME paths
+// and "" for the other paths, such as the nodes of the encrypted devices.
+func GetIdentity(path string) string {
+	dir := filepath.Dir(path) + "/"
+	switch {
+	case dir == DiskByIDDir && strings.HasPrefix(filepath.Base(path), wwnLinkPrefix):
+		return IdentityWWN
+	case dir == DiskByIDDir:
+		return IdentityByID
+	case dir == DiskByPathDir:
+		return IdentityByPath
+	case dir == DiskByPartUUIDDir:
+		return IdentityPartUUID
+	case dir == DiskByLSODir:
+		return IdentityByLSO
+	case dir == "/dev/":
+		return IdentityKName
+	}
+	return ""
+}
+
+// IdentityDir returns the directory of the persistent links of an identity,
+// or "" for IdentityKName and the unknown identities
+func IdentityDir(identity string) string {
+	switch identity {
+	case IdentityByID, IdentityWWN:
+		return DiskByIDDir
+	case IdentityByPath:
+		return DiskByPathDir
+	case IdentityPartUUID:
+		return DiskByPartUUIDDir
+	case IdentityByLSO:
+		return DiskByLSODir
+	}
+	return ""
+}
+
+// findDeviceLink returns the first link matching pattern that evaluates to the device kname, or ""
+func findDeviceLink(pattern, kname string) (string, error) {
+	paths, err := FilePathGlob(pattern)
+	if err != nil {
+		return "", fmt.Errorf("could not list files matching %q: %w", pattern, err)
+	}
+	for _, path := range paths {
+		isMatch, err := PathEvalsToDiskLabel(path, kname)
+		if err != nil {
+			return "", err
+		}
+		if isMatch {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// byLSOName returns the name of the by-lso link of a device serial, or "" if the serial has no usable character
+func byLSOName(serial string) string {
+	return strings.Trim(unsafeSerialChars.ReplaceAllString(serial, "_"), "_")
+}
+
+// byLSORule returns the udev rule that links the disk with the given serial, and its partitions, in DiskByLSODir
+func byLSORule(serial, name string) string {
+	link := filepath.Join(strings.TrimPrefix(DiskByLSODir, "/dev/"), name)
+	return fmt.Sprintf(`# persistent names of the devices without a by-id link, written by the local-storage diskmaker
+SUBSYSTEM=="block", ENV{DEVTYPE}=="disk", ENV{ID_SERIAL_SHORT}=="%[1]s", SYMLINK+="%[2]s"
+SUBSYSTEM=="block", ENV{DEVTYPE}=="disk", ATTR{device/serial}=="%[1]s", SYMLINK+="%[2]s"
+SUBSYSTEM=="block", ENV{DEVTYPE}=="partition", ENV{ID_SERIAL_SHORT}=="%[1]s", SYMLINK+="%[2]s-part%%n"
+`, serial, link)
+}
+
+// EnsureByLSOLink writes a udev rule creating a /dev/disk/by-lso link for the serial of the device,
+// and waits for udev to create it. It returns the link, or "" when the device has no serial or udev
+// doesn't know it. No link is created for the serials udev would read as a pattern, and for the ones
+// another disk shares, even once sanitized by byLSOName, since the link could point to either disk.
+// The result is cached, so that the rule is only written once per device.
+func (b BlockDevice) EnsureByLSOLink() (string, error) {
+	name := byLSOName(b.Serial)
+	if name == "" {
+		return "", nil
+	}
+	cacheKey := b.KName + "/" + b.Serial
+	byLSOLinksMux.Lock()
+	defer byLSOLinksMux.Unlock()
+	if link, found := byLSOLinks[cacheKey]; found {
+		return link, nil
+	}
+
+	link, err := b.ensureByLSOLink(name)
+	if err != nil {
+		return "", err
+	}
+	byLSOLinks[cacheKey] = link
+	return link, nil
+}
+
+func (b BlockDevice) ensureByLSOLink(name string) (string, error) {
+	link, err := findDeviceLink(filepath.Join(DiskByLSODir, name+"*"), b.KName)
+	if link != "" || err != nil {
+		return link, err
+	}
+	if strings.ContainsAny(b.Serial, udevPatternChars) {
+		klog.Warningf("not linking %q in %s, its serial %q has characters udev reads as a pattern", b.KName, DiskByLSODir, b.Serial)
+		return "", nil
+	}
+	knames, err := getDisksByLSOName(name)
+	if err != nil {
+		return "", err
+	}
+	if len(knames) > 1 {
+		klog.Warningf("not linking %q in %s, the disks %v share its serial %q", b.KName, DiskByLSODir, knames, b.Serial)
+		return "", nil
+	}
+
+	rule := byLSORule(b.Serial, name)
+	rulePath := filepath.Join(UDevRulesDir, udevRulePrefix+name+".rules")
+	// the rule of a disk whose serial is sanitized to the same name
+	existingRule, err := ioutil.ReadFile(rulePath)
+	if err == nil && string(existingRule) != rule {
+		klog.Warningf("not linking %q in %s, the rule %q links another serial to %q", b.KName, DiskByLSODir, rulePath, name)
+		return "", nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	err = ioutil.WriteFile(rulePath, []byte(rule), 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write udev rule %q: %w", rulePath, err)
+	}
+	for _, args := range [][]string{
+		{"control", "--reload"},
+		{"trigger", "--action=change", "--sysname-match=" + b.KName},
+		{"settle", "--timeout=10"},
+	} {
+		cmd := ExecCommand("udevadm", args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("udevadm %s failed: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	link, err = findDeviceLink(filepath.Join(DiskByLSODir, name+"*"), b.KName)
+	if err != nil {
+		return "", err
+	}
+	// udev doesn't know the serial of the device, the rule would never match it
+	if link == "" {
+		err = os.Remove(rulePath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return link, nil
+}
+
+// getDisksByLSOName returns the kernel names of the disks whose serial has the given by-lso name
+func getDisksByLSOName(name string) ([]string, error) {
+	cmd := ExecCommand("lsblk", "--pairs", "--nodeps", "--noheadings", "-o", "KNAME,SERIAL")
+	output, err := executeCmdWithCombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the serials of the disks: %w", err)
+	}
+	knames := make([]string, 0)
+	for _, row := range strings.Split(output, "\n") {
+		values := map[string]string{}
+		for _, pair := range lsblkPair.FindAllStringSubmatch(row, -1) {
+			values[pair[1]] = pair[2]
+		}
+		if values["KNAME"] != "" && byLSOName(values["SERIAL"]) == name {
+			knames = append(knames, values["KNAME"])
+		}
+	}
+	return knames, nil
+}
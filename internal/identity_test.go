@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIdentity(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/dev/disk/by-id/ata-VBOX_HARDDISK_VB1":                    IdentityByID,
+		"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3":                   IdentityWWN,
+		"/dev/disk/by-path/pci-0000:00:1f.2-ata-1":                 IdentityByPath,
+		"/dev/disk/by-partuuid/8d3a1a2e-01":                        IdentityPartUUID,
+		"/dev/disk/by-lso/S3EWNX0K":                                IdentityByLSO,
+		"/dev/sdb":                                                 IdentityKName,
+		"/dev/mapper/lso-0123456789abcdef":                         "",
+		"/mnt/local-storage/local-sc/wwn-0x5000c500a0b1c2d3-vol-0": "",
+	} {
+		assert.Equalf(t, expected, GetIdentity(path), "identity of %q", path)
+	}
+}
+
+func TestGetPathByIdentity(t *testing.T) {
+	FilePathGlob = func(pattern string) ([]string, error) {
+		switch pattern {
+		case "/dev/disk/by-id/*":
+			return []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"}, nil
+		case "/dev/disk/by-id/wwn-*":
+			return []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"}, nil
+		case "/dev/disk/by-path/*":
+			return []string{"/dev/disk/by-path/pci-0000:00:1f.2-ata-1", "/dev/disk/by-path/pci-0000:00:1f.2-ata-2"}, nil
+		}
+		return []string{}, nil
+	}
+	FilePathEvalSymLinks = func(path string) (string, error) {
+		switch path {
+		case "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", "/dev/disk/by-path/pci-0000:00:1f.2-ata-1":
+			return "/dev/sdb", nil
+		}
+		return "/dev/sdc", nil
+	}
+	defer func() {
+		FilePathGlob = filepath.Glob
+		FilePathEvalSymLinks = filepath.EvalSymlinks
+	}()
+
+	sdb := BlockDevice{KName: "sdb"}
+	path, err := sdb.GetPathByIdentity(IdentityWWN)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", path)
+
+	sdc := BlockDevice{KName: "sdc"}
+	path, err = sdc.GetPathByIdentity(IdentityByID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", path, "sdc has no by-id link")
+	path, err = sdc.GetPathByIdentity(IdentityByPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/disk/by-path/pci-0000:00:1f.2-ata-2", path)
+	path, err = sdc.GetPathByIdentity(IdentityPartUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", path)
+	path, err = sdc.GetPathByIdentity(IdentityByLSO)
+	assert.NoError(t, err)
+	assert.Equal(t, "", path, "sdc has no serial")
+
+	_, err = sdc.GetPathByIdentity("by-label")
+	assert.Error(t, err)
+}
+
+func TestEnsureByLSOLink(t *testing.T) {
+	rulesDir, err := ioutil.TempDir("", "rules")
+	assert.NoError(t, err)
+	defer os.RemoveAll(rulesDir)
+	UDevRulesDir = rulesDir
+	linked := false
+	FilePathGlob = func(pattern string) ([]string, error) {
+		if linked && pattern == "/dev/disk/by-lso/S3EW_NX0K*" {
+			return []string{"/dev/disk/by-lso/S3EW_NX0K"}, nil
+		}
+		return []string{}, nil
+	}
+	FilePathEvalSymLinks = func(path string) (string, error) {
+		return "/dev/sdb", nil
+	}
+	udevadm := fsHelperCommand("", 0)
+	ExecCommand = func(command string, args ...string) *exec.Cmd {
+		linked = true
+		return udevadm(command, args...)
+	}
+	byLSOLinks = map[string]string{}
+	defer func() {
+		UDevRulesDir = "/etc/udev/rules.d"
+		FilePathGlob = filepath.Glob
+		FilePathEvalSymLinks = filepath.EvalSymlinks
+		ExecCommand = exec.Command
+		byLSOLinks = map[string]string{}
+	}()
+
+	path, err := BlockDevice{KName: "sdb", Serial: "S3EW NX0K"}.EnsureByLSOLink()
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/disk/by-lso/S3EW_NX0K", path)
+	rule, err := ioutil.ReadFile(filepath.Join(rulesDir, "99-lso-S3EW_NX0K.rules"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(rule), `ENV{ID_SERIAL_SHORT}=="S3EW NX0K", SYMLINK+="disk/by-lso/S3EW_NX0K"`)
+	assert.Contains(t, string(rule), `SYMLINK+="disk/by-lso/S3EW_NX0K-part%n"`)
+
+	// the rule is removed when udev doesn't create the link
+	path, err = BlockDevice{KName: "sdc", Serial: "unknown"}.EnsureByLSOLink()
+	assert.NoError(t, err)
+	assert.Equal(t, "", path)
+	_, err = os.Stat(filepath.Join(rulesDir, "99-lso-unknown.rules"))
+	assert.True(t, os.IsNotExist(err))
+
+	// and udev is not asked again
+	executed := false
+	ExecCommand = func(command string, args ...string) *exec.Cmd {
+		executed = true
+		return udevadm(command, args...)
+	}
+	path, err = BlockDevice{KName: "sdc", Serial: "unknown"}.EnsureByLSOLink()
+	assert.NoError(t, err)
+	assert.Equal(t, "", path)
+	assert.False(t, executed)
+}
+
+func TestEnsureByLSOLinkRefused(t *testing.T) {
+	rulesDir, err := ioutil.TempDir("", "rules")
+	assert.NoError(t, err)
+	defer os.RemoveAll(rulesDir)
+	UDevRulesDir = rulesDir
+	FilePathGlob = func(pattern string) ([]string, error) {
+		return []string{}, nil
+	}
+	byLSOLinks = map[string]string{}
+	defer func() {
+		UDevRulesDir = "/etc/udev/rules.d"
+		FilePathGlob = filepath.Glob
+		ExecCommand = exec.Command
+		byLSOLinks = map[string]string{}
+	}()
+	ExecCommand = fsHelperCommand(`KNAME="sdb" SERIAL="S3EW NX0K"
+KNAME="sdc" SERIAL="S3EW_NX0K"
+KNAME="sdd" SERIAL="S3EW*"
+KNAME="sde" SERIAL="S4EW"`, 0)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(rulesDir, "99-lso-S4EW.rules"), []byte(byLSORule("S4EW ", "S4EW")), 0644))
+
+	for _, device := range []BlockDevice{
+		{KName: "sdb", Serial: "S3EW NX0K"},
+		{KName: "sdd", Serial: "S3EW*"},
+		{KName: "sde", Serial: "S4EW"},
+	} {
+		path, err := device.EnsureByLSOLink()
+		assert.NoErrorf(t, err, "%s", device.KName)
+		assert.Equalf(t, "", path, "%s", device.KName)
+	}
+	rules, err := filepath.Glob(filepath.Join(rulesDir, "*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(rulesDir, "99-lso-S4EW.rules")}, rules)
+}
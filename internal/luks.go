@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// MapperDir is the directory of the device-mapper nodes of the opened LUKS devices.
+	MapperDir = "/dev/mapper"
+	// luksMapperPrefix prefixes the names of the device-mapper nodes opened by the diskmaker.
+	luksMapperPrefix = "lso-"
+)
+
+// LUKSMapperName returns the device-mapper name the LUKS device identified by deviceID is opened as.
+// It is derived from the stable device ID, so that the device is opened with the same name after a reboot.
+func LUKSMapperName(deviceID string) string {
+	sum := sha256.Sum256([]byte(deviceID))
+	return luksMapperPrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+// IsLUKSMapperPath returns true if path is a device-mapper node opened by the diskmaker.
+func IsLUKSMapperPath(path string) bool {
+	return filepath.Dir(path) == MapperDir && strings.HasPrefix(filepath.Base(path), luksMapperPrefix)
+}
+
+// IsLUKS returns true if the device has a LUKS header.
+func IsLUKS(devicePath string) (bool, error) {
+	cmd := ExecCommand("cryptsetup", "isLuks", devicePath)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check for a LUKS header on %q: %w", devicePath, err)
+}
+
+// LUKSFormat writes a new LUKS header to the device, destroying its contents.
+// cipher is optional, the cryptsetup default is used when it is empty.
+func LUKSFormat(devicePath string, key []byte, cipher string) error {
+	args := []string{"luksFormat", "--batch-mode", "--key-file", "-"}
+	if cipher != "" {
+		args = append(args, "--cipher", cipher)
+	}
+	args = append(args, devicePath)
+	return runCryptsetup(key, args...)
+}
+
+// LUKSOpen opens the LUKS device as /dev/mapper/<name> and returns that path.
+// It does nothing if the device-mapper node already exists.
+func LUKSOpen(devicePath string, key []byte, name string) (string, error) {
+	mapperPath := filepath.Join(MapperDir, name)
+	if _, err := os.Stat(mapperPath); err == nil {
+		return mapperPath, nil
+	}
+	err := runCryptsetup(key, "luksOpen", "--key-file", "-", devicePath, name)
+	if err != nil {
+		return "", err
+	}
+	return mapperPath, nil
+}
+
+// LUKSClose closes the device-mapper node with the given name.
+func LUKSClose(name string) error {
+	return runCryptsetup(nil, "luksClose", name)
+}
+
+// LUKSTestKey returns true if the key opens a keyslot of the LUKS device.
+func LUKSTestKey(devicePath string, key []byte) (bool, error) {
+	cmd := ExecCommand("cryptsetup", "open", "--test-passphrase", "--key-file", "-", devicePath)
+	cmd.Stdin = bytes.NewReader(key)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	// cryptsetup exits with 2 when no keyslot matches the key
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 2 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to test the key of %q: %w", devicePath, err)
+}
+
+// LUKSAddKey adds a keyslot with newKey to the LUKS device, unlocked with key.
+// The new key is passed through a pipe, so that it is never written to disk.
+func LUKSAddKey(devicePath string, key, newKey []byte) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	go func() {
+		w.Write(newKey)
+		w.Close()
+	}()
+	cmd := ExecCommand("cryptsetup", "luksAddKey", "--batch-mode", "--key-file", "-", devicePath, "/dev/fd/3")
+	cmd.Stdin = bytes.NewReader(key)
+	cmd.ExtraFiles = []*os.File{r}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cryptsetup luksAddKey failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// LUKSRemoveKey removes the keyslot of key from the LUKS device.
+func LUKSRemoveKey(devicePath string, key []byte) error {
+	return runCryptsetup(key, "luksRemoveKey", "--batch-mode", "--key-file", "-", devicePath)
+}
+
+func runCryptsetup(stdin []byte, args ...string) error {
+	cmd := ExecCommand("cryptsetup", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cryptsetup %s failed: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// luksHelperCommand returns a fake cryptsetup exec.Cmd that exits with exitCode
+func luksHelperCommand(exitCode int) func(string, ...string) *exec.Cmd {
+	return func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestLUKSHelperProcess", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", fmt.Sprintf("EXIT_CODE=%d", exitCode)}
+		return cmd
+	}
+}
+
+func TestLUKSHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	var exitCode int
+	fmt.Sscanf(os.Getenv("EXIT_CODE"), "%d", &exitCode)
+	os.Exit(exitCode)
+}
+
+func TestIsLUKS(t *testing.T) {
+	defer func() { ExecCommand = exec.Command }()
+	testcases := []struct {
+		label    string
+		exitCode int
+		isLUKS   bool
+		hasError bool
+	}{
+		{label: "LUKS header", exitCode: 0, isLUKS: true},
+		{label: "no LUKS header", exitCode: 1, isLUKS: false},
+		{label: "cryptsetup failure", exitCode: 4, hasError: true},
+	}
+	for _, tc := range testcases {
+		ExecCommand = luksHelperCommand(tc.exitCode)
+		isLUKS, err := IsLUKS("/dev/sdb")
+		if tc.hasError {
+			assert.Error(t, err, tc.label)
+			continue
+		}
+		assert.NoError(t, err, tc.label)
+		assert.Equal(t, tc.isLUKS, isLUKS, tc.label)
+	}
+}
+
+func TestLUKSMapperName(t *testing.T) {
+	name := LUKSMapperName("/dev/disk/by-id/wwn-0x5000c500a0b1c2d3")
+	assert.Equal(t, name, LUKSMapperName("/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"), "the name is stable")
+	assert.NotEqual(t, name, LUKSMapperName("/dev/disk/by-id/wwn-0x5000c500a0b1c2d4"))
+	assert.Len(t, name, len(luksMapperPrefix)+16)
+	assert.True(t, IsLUKSMapperPath(MapperDir+"/"+name))
+	assert.False(t, IsLUKSMapperPath(MapperDir+"/rhel-root"))
+	assert.False(t, IsLUKSMapperPath("/dev/"+name))
+}
+
+func TestLUKSFormat(t *testing.T) {
+	defer func() { ExecCommand = exec.Command }()
+	ExecCommand = luksHelperCommand(0)
+	assert.NoError(t, LUKSFormat("/dev/sdb", []byte("key"), "aes-xts-plain64"))
+	ExecCommand = luksHelperCommand(1)
+	assert.Error(t, LUKSFormat("/dev/sdb", []byte("key"), ""))
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MDDir is the directory of the named md arrays
+	MDDir = "/dev/md"
+	// mdArrayPrefix prefixes the names of the md arrays created by the diskmaker
+	mdArrayPrefix = "lso-"
+)
+
+var mdstatFile = "/proc/mdstat"
+
+// MDArrayName returns the name of the md array whose first member is identified by deviceID.
+// The array is assembled as /dev/md/<name>.
+func MDArrayName(deviceID string) string {
+	sum := sha256.Sum256([]byte(deviceID))
+	return mdArrayPrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+// IsMDArrayPath returns true if path is a named md array created by the diskmaker.
+func IsMDArrayPath(path string) bool {
+	return filepath.Dir(path) == MDDir && strings.HasPrefix(filepath.Base(path), mdArrayPrefix)
+}
+
+// MDCreate creates the md array /dev/md/<name> of the RAID level out of the devices, and returns its path.
+func MDCreate(name, level string, devicePaths []string) (string, error) {
+	arrayPath := filepath.Join(MDDir, name)
+	args := []string{"--create", arrayPath, "--run", "--metadata=1.2", "--name=" + name, "--level=" + level, fmt.Sprintf("--raid-devices=%d", len(devicePaths))}
+	err := runMDAdm(append(args, devicePaths...)...)
+	if err != nil {
+		return "", err
+	}
+	return arrayPath, nil
+}
+
+// MDAssemble assembles the md array with the given name from its members.
+func MDAssemble(name string) error {
+	return runMDAdm("--assemble", "--scan", "--name="+name)
+}
+
+// MDReplace removes the failed and detached members of the md array, and adds the device to rebuild it.
+func MDReplace(arrayPath, devicePath string) error {
+	err := runMDAdm("--manage", arrayPath, "--remove", "failed", "--remove", "detached")
+	if err != nil {
+		return err
+	}
+	return runMDAdm("--manage", arrayPath, "--add", devicePath)
+}
+
+func runMDAdm(args ...string) error {
+	cmd := ExecCommand("mdadm", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mdadm %s failed: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MDArray is an md array as listed in /proc/mdstat
+type MDArray struct {
+	// Name is the kernel name of the array, such as md127
+	Name string
+	// State is active or inactive
+	State string
+	// Level is the RAID level, such as raid10
+	Level string
+	// Members are the kernel names of the members, including the ones being rebuilt
+	Members []string
+	// FailedMembers are the kernel names of the faulty members
+	FailedMembers []string
+	// SpareMembers are the kernel names of the spares
+	SpareMembers []string
+	// RAIDDevices is the number of members of the healthy array
+	RAIDDevices int
+	// ActiveDevices is the number of working members
+	ActiveDevices int
+	// Sync is the progress of a recovery or resync of the array, such as "recovery = 12.6%"
+	Sync string
+}
+
+// Degraded returns true if members of the array are missing
+func (a MDArray) Degraded() bool {
+	return a.ActiveDevices < a.RAIDDevices
+}
+
+var (
+	mdArrayLine  = regexp.MustCompile(`^(md\S+)\s*:\s*(\S+)\s+(.*)$`)
+	mdMember     = regexp.MustCompile(`^(\S+)\[\d+\](\([A-Z]\))*$`)
+	mdCounts     = regexp.MustCompile(`\[(\d+)/(\d+)\]`)
+	mdSyncStatus = regexp.MustCompile(`(recovery|resync|reshape|check)\s*=\s*(\S+)`)
+)
+
+// ReadMDStat returns the md arrays of the node
+func ReadMDStat() ([]MDArray, error) {
+	content, err := ioutil.ReadFile(mdstatFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", mdstatFile, err)
+	}
+	return parseMDStat(string(content)), nil
+}
+
+// parseMDStat parses the content of /proc/mdstat. Sample:
+//
+//	md127 : active raid10 sdd[3] sdc[2](F) sdb[1] sda[0]
+//	      2095104 blocks super 1.2 512K chunks 2 near-copies [4/3] [UU_U]
+//	      [==>..................]  recovery = 12.6% (132096/1047552) finish=0.3min speed=44032K/sec
+func parseMDStat(content string) []MDArray {
+	arrays := make([]MDArray, 0)
+	var current *MDArray
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := mdArrayLine.FindStringSubmatch(line); match != nil {
+			arrays = append(arrays, MDArray{Name: match[1], State: match[2]})
+			current = &arrays[len(arrays)-1]
+			for _, field := range strings.Fields(match[3]) {
+				member := mdMember.FindStringSubmatch(field)
+				switch {
+				case member == nil:
+					// the level is the only field that isn't a member, besides the (auto-read-only) state
+					if !strings.HasPrefix(field, "(") {
+						current.Level = field
+					}
+				case strings.Contains(field, "(F)"):
+					current.FailedMembers = append(current.FailedMembers, member[1])
+				case strings.Contains(field, "(S)"):
+					current.SpareMembers = append(current.SpareMembers, member[1])
+				default:
+					current.Members = append(current.Members, member[1])
+				}
+			}
+			continue
+		}
+		if current == nil || !strings.HasPrefix(line, " ") {
+			current = nil
+			continue
+		}
+		if counts := mdCounts.FindStringSubmatch(line); counts != nil {
+			current.RAIDDevices, _ = strconv.Atoi(counts[1])
+			current.ActiveDevices, _ = strconv.Atoi(counts[2])
+		}
+		if sync := mdSyncStatus.FindStringSubmatch(line); sync != nil {
+			current.Sync = sync[1] + " = " + sync[2]
+		}
+	}
+	// arrays without redundancy don't report their counts
+	for i := range arrays {
+		if arrays[i].RAIDDevices == 0 {
+			arrays[i].RAIDDevices = len(arrays[i].Members) + len(arrays[i].FailedMembers)
+			arrays[i].ActiveDevices = len(arrays[i].Members)
+		}
+	}
+	return arrays
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMDStat(t *testing.T) {
+	content := `Personalities : [raid10] [raid1] [raid0]
+md127 : active raid10 sdd[3] sdc[2](F) sdb[1] sda[0]
+      2095104 blocks super 1.2 512K chunks 2 near-copies [4/3] [UU_U]
+
+md126 : active (auto-read-only) raid1 sdf[2] sde[0] sdg[3](S)
+      1047552 blocks super 1.2 [2/1] [U_]
+      [==>..................]  recovery = 12.6% (132096/1047552) finish=0.3min speed=44032K/sec
+
+md125 : active raid0 sdi[1] sdh[0]
+      2095104 blocks super 1.2 512k chunks
+
+unused devices: <none>
+`
+	arrays := parseMDStat(content)
+	assert.Len(t, arrays, 3)
+
+	assert.Equal(t, MDArray{
+		Name:          "md127",
+		State:         "active",
+		Level:         "raid10",
+		Members:       []string{"sdd", "sdb", "sda"},
+		FailedMembers: []string{"sdc"},
+		RAIDDevices:   4,
+		ActiveDevices: 3,
+	}, arrays[0])
+	assert.True(t, arrays[0].Degraded())
+
+	assert.Equal(t, "raid1", arrays[1].Level)
+	assert.Equal(t, []string{"sdf", "sde"}, arrays[1].Members)
+	assert.Equal(t, []string{"sdg"}, arrays[1].SpareMembers)
+	assert.Equal(t, "recovery = 12.6%", arrays[1].Sync)
+	assert.True(t, arrays[1].Degraded())
+
+	assert.Equal(t, "raid0", arrays[2].Level)
+	assert.Equal(t, 2, arrays[2].RAIDDevices)
+	assert.False(t, arrays[2].Degraded())
+}
+
+func TestMDArrayName(t *testing.T) {
+	name := MDArrayName("/dev/disk/by-id/wwn-0x5000c500a0b1c2d3")
+	assert.Equal(t, name, MDArrayName("/dev/disk/by-id/wwn-0x5000c500a0b1c2d3"), "the name is stable")
+	assert.Len(t, name, len(mdArrayPrefix)+16)
+	assert.True(t, IsMDArrayPath(MDDir+"/"+name))
+	assert.False(t, IsMDArrayPath(MDDir+"/home"))
+}
+
+func TestMDCreate(t *testing.T) {
+	defer func() { ExecCommand = exec.Command }()
+	ExecCommand = fsHelperCommand("", 0)
+	arrayPath, err := MDCreate("lso-0123456789abcdef", "raid10", []string{"/dev/sda", "/dev/sdb", "/dev/sdc", "/dev/sdd"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/md/lso-0123456789abcdef", arrayPath)
+	ExecCommand = fsHelperCommand("", 1)
+	_, err = MDCreate("lso-0123456789abcdef", "raid10", []string{"/dev/sda", "/dev/sdb"})
+	assert.Error(t, err)
+	assert.Error(t, MDReplace("/dev/md/lso-0123456789abcdef", "/dev/sde"))
+}
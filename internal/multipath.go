@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// MultipathType is the lsblk type of the dm-multipath devices
+	MultipathType = "mpath"
+	// StateRunning is the BlockDevice.State of the paths that are up
+	StateRunning = "running"
+
+	// PathStateRunning is the state of a LUN whose paths are all running
+	PathStateRunning = "running"
+	// PathStateDegraded is the state of a LUN with some paths down
+	PathStateDegraded = "degraded"
+	// PathStateFailed is the state of a LUN with all its paths down
+	PathStateFailed = "failed"
+
+	// dmUUIDMultipathPrefix is the prefix of the device-mapper UUID of the multipath devices
+	dmUUIDMultipathPrefix = "mpath-"
+)
+
+var sysBlockDir = "/sys/block"
+
+// IsMultipath returns true if the device is a dm-multipath device
+func (b BlockDevice) IsMultipath() bool {
+	return b.Type == MultipathType
+}
+
+// Multipath is the set of paths to a LUN
+type Multipath struct {
+	// WWN of the LUN
+	WWN string
+	// Paths are the devices of the paths, sorted by kernel name
+	Paths []BlockDevice
+}
+
+// ActivePaths returns the number of paths that are running
+func (m Multipath) ActivePaths() int {
+	active := 0
+	for _, path := range m.Paths {
+		// only scsi devices report a state
+		if path.State == StateRunning || path.State == "" {
+			active++
+		}
+	}
+	return active
+}
+
+// State returns PathStateRunning, PathStateDegraded or PathStateFailed depending on the paths that are running
+func (m Multipath) State() string {
+	switch active := m.ActivePaths(); {
+	case active == len(m.Paths):
+		return PathStateRunning
+	case active == 0:
+		return PathStateFailed
+	default:
+		return PathStateDegraded
+	}
+}
+
+// GetMultipathSlaves returns the kernel names of the paths of the dm-multipath device, as listed in sysfs
+func GetMultipathSlaves(kname string) ([]string, error) {
+	paths, err := FilePathGlob(filepath.Join(sysBlockDir, kname, "slaves", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the paths of %q: %w", kname, err)
+	}
+	slaves := make([]string, 0, len(paths))
+	for _, path := range paths {
+		slaves = append(slaves, filepath.Base(path))
+	}
+	return slaves, nil
+}
+
+// isAssembledMultipath returns true if device-mapper assembled the device as a multipath device, with a DM_UUID
+// prefixed by mpath-. The type reported by lsblk is not enough, the slaves of other dm targets are not paths of a LUN.
+func isAssembledMultipath(kname string) (bool, error) {
+	uuid, err := ioutil.ReadFile(filepath.Join(sysBlockDir, kname, "dm", "uuid"))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read the device-mapper uuid of %q: %w", kname, err)
+	}
+	return strings.HasPrefix(strings.TrimSpace(string(uuid)), dmUUIDMultipathPrefix), nil
+}
+
+// getPartitions returns the kernel names of the partitions of the device, as listed in sysfs
+func getPartitions(kname string) ([]string, error) {
+	paths, err := FilePathGlob(filepath.Join(sysBlockDir, kname, kname+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the partitions of %q: %w", kname, err)
+	}
+	partitions := make([]string, 0, len(paths))
+	for _, path := range paths {
+		partitions = append(partitions, filepath.Base(path))
+	}
+	return partitions, nil
+}
+
+// GroupMultipaths groups the paths of the LUNs, so that each LUN is offered once.
+// Only the dm-multipath devices that device-mapper assembled are grouped: their paths are read from sysfs,
+// they are left out with their partitions and the multipath device is kept.
+// It returns the remaining devices, and the paths of the multipath devices by kernel name.
+func GroupMultipaths(devices []BlockDevice) ([]BlockDevice, map[string]Multipath, error) {
+	byKName := make(map[string]BlockDevice, len(devices))
+	for _, device := range devices {
+		byKName[device.KName] = device
+	}
+
+	multipaths := make(map[string]Multipath)
+	isPath := make(map[string]bool)
+	for i, device := range devices {
+		if !device.IsMultipath() {
+			continue
+		}
+		assembled, err := isAssembledMultipath(device.KName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !assembled {
+			continue
+		}
+		slaves, err := GetMultipathSlaves(device.KName)
+		if err != nil {
+			return nil, nil, err
+		}
+		multipath := Multipath{}
+		for _, slave := range slaves {
+			isPath[slave] = true
+			// the partitions of a path are the partitions of the LUN, they are only offered through the multipath device
+			partitions, err := getPartitions(slave)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, partition := range partitions {
+				isPath[partition] = true
+			}
+			if path, found := byKName[slave]; found {
+				multipath.Paths = append(multipath.Paths, path)
+				if multipath.WWN == "" {
+					multipath.WWN = path.WWN
+				}
+			}
+		}
+		sortPaths(multipath.Paths)
+		// lsblk doesn't report the WWN of dm devices
+		if device.WWN == "" {
+			devices[i].WWN = multipath.WWN
+		}
+		multipaths[device.KName] = multipath
+	}
+
+	grouped := make([]BlockDevice, 0, len(devices))
+	for _, device := range devices {
+		if !isPath[device.KName] {
+			grouped = append(grouped, device)
+		}
+	}
+	return grouped, multipaths, nil
+}
+
+func sortPaths(paths []BlockDevice) {
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i].KName < paths[j].KName
+	})
+}
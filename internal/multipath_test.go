@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMultipaths(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "sys-block")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	defer func(dir string) { sysBlockDir = dir }(sysBlockDir)
+	sysBlockDir = tmpDir
+	for _, path := range []string{"dm-0/dm", "dm-0/slaves/sdb", "dm-0/slaves/sdc", "sdb/sdb1", "sdd/sdd1", "dm-1/dm", "dm-1/slaves/sdf"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(tmpDir, path), 0755))
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "dm-0", "dm", "uuid"), []byte("mpath-3600a0b80001\n"), 0644))
+	// not assembled by multipathd
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "dm-1", "dm", "uuid"), []byte("CRYPT-LUKS2-0123-lso\n"), 0644))
+
+	devices := []BlockDevice{
+		{KName: "sda", Type: "disk"},
+		{KName: "sdb", Type: "disk", WWN: "0x600a0b80001", State: StateRunning},
+		{KName: "sdb1", Type: "part", WWN: "0x600a0b80001"},
+		{KName: "dm-0", Type: MultipathType},
+		{KName: "sdc", Type: "disk", WWN: "0x600a0b80001", State: "offline"},
+		{KName: "sde", Type: "disk", WWN: "0x600a0b80002", State: StateRunning},
+		{KName: "sdd", Type: "disk", WWN: "0x600a0b80002", State: StateRunning},
+		{KName: "sdd1", Type: "part", WWN: "0x600a0b80002"},
+		{KName: "dm-1", Type: MultipathType},
+		{KName: "sdf", Type: "disk", WWN: "0x5000c500a0b1c2d3"},
+	}
+	grouped, multipaths, err := GroupMultipaths(devices)
+	assert.NoError(t, err)
+
+	knames := make([]string, 0)
+	for _, device := range grouped {
+		knames = append(knames, device.KName)
+	}
+	assert.Equal(t, []string{"sda", "dm-0", "sde", "sdd", "sdd1", "dm-1", "sdf"}, knames,
+		"only the paths of the assembled multipath devices and their partitions are left out")
+	assert.Equal(t, "0x600a0b80001", grouped[1].WWN, "the multipath device has the WWN of its paths")
+
+	assert.Len(t, multipaths, 1)
+	assert.Equal(t, "0x600a0b80001", multipaths["dm-0"].WWN)
+	assert.Len(t, multipaths["dm-0"].Paths, 2)
+	assert.Equal(t, "sdb", multipaths["dm-0"].Paths[0].KName)
+	assert.Equal(t, 1, multipaths["dm-0"].ActivePaths())
+	assert.Equal(t, PathStateDegraded, multipaths["dm-0"].State())
+}
+
+func TestMultipathState(t *testing.T) {
+	multipath := Multipath{Paths: []BlockDevice{{KName: "sdb", State: "offline"}, {KName: "sdc", State: "blocked"}}}
+	assert.Equal(t, PathStateFailed, multipath.State())
+	multipath.Paths[1].State = StateRunning
+	assert.Equal(t, PathStateDegraded, multipath.State())
+	multipath.Paths[0].State = ""
+	assert.Equal(t, PathStateRunning, multipath.State())
+}
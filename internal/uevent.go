@@ -0,0 +1,343 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog"
+)
+
+// UEventAction is the action of a kernel uevent
+type UEventAction string
+
+const (
+	// UEventAdd is sent when a device is added
+	UEventAdd UEventAction = "add"
+	// UEventRemove is sent when a device is removed
+	UEventRemove UEventAction = "remove"
+	// UEventChange is sent when a device is changed. For eg, a partition table is written
+	UEventChange UEventAction = "change"
+)
+
+// UEventGroup is the netlink multicast group the uevents are received from
+type UEventGroup uint32
+
+const (
+	// KernelUEvents are the raw uevents sent by the kernel. They are multicast in every network namespace,
+	// the listener waits for udev to process them before broadcasting them
+	KernelUEvents UEventGroup = 1
+	// UdevUEvents are the uevents sent by udev once it has processed the rules of the device.
+	// They are sent after the /dev/disk/by-* symlinks are created, in the host network namespace only
+	UdevUEvents UEventGroup = 2
+
+	// BlockSubsystem is the subsystem of the block device uevents
+	BlockSubsystem = "block"
+
+	// udevMonitorMagic is the magic number of the header of udev uevents, in network byte order
+	udevMonitorMagic = 0xfeedcafe
+	udevEventPrefix  = "libudev\x00"
+
+	ueventBufferSize     = 64 * 1024
+	ueventSocketRcvBuf   = 4 * 1024 * 1024
+	ueventReadTimeout    = time.Second
+	subscriberBufferSize = 100
+)
+
+var (
+	// UEventReconnectDelay is the delay before opening the netlink socket again after a failure
+	UEventReconnectDelay = 5 * time.Second
+	// dialUEventSocket opens the netlink socket. It is replaced in unit tests
+	dialUEventSocket = newNetlinkUEventConn
+	// settleUdev waits for udev to process the queued uevents. It is replaced in unit tests
+	settleUdev = udevSettle
+	// nativeEndian is the byte order of the udev header fields, other than the magic number
+	nativeEndian = getNativeEndian()
+)
+
+// UEvent is a structured uevent of the block subsystem
+type UEvent struct {
+	Action    UEventAction
+	DevPath   string
+	Subsystem string
+	// DevName is the kernel name of the device. For eg, sdb
+	DevName string
+	// DevType is the type of the device. For eg, disk or partition
+	DevType string
+	// Properties are all the KEY=VALUE pairs of the uevent
+	Properties map[string]string
+}
+
+// ueventConn is a connection to a uevent netlink socket
+type ueventConn interface {
+	// Read returns the next message, or nil when no message was received before the read timeout
+	Read() ([]byte, error)
+	Close() error
+}
+
+// UEventListener listens to block device uevents on a NETLINK_KOBJECT_UEVENT socket and
+// broadcasts them to its subscribers. It reopens the socket when it fails.
+type UEventListener struct {
+	group       UEventGroup
+	lock        sync.Mutex
+	subscribers []chan UEvent
+}
+
+// NewUEventListener returns a UEventListener for the uevents of group
+func NewUEventListener(group UEventGroup) *UEventListener {
+	return &UEventListener{group: group}
+}
+
+// Subscribe returns a channel that receives the block device uevents.
+// Events are dropped when the subscriber doesn't keep up.
+func (l *UEventListener) Subscribe() <-chan UEvent {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	c := make(chan UEvent, subscriberBufferSize)
+	l.subscribers = append(l.subscribers, c)
+	return c
+}
+
+// Start listens to uevents until stop is closed, then closes all the subscriber channels
+func (l *UEventListener) Start(stop <-chan struct{}) {
+	defer l.closeSubscribers()
+	for {
+		err := l.listen(stop)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		klog.Warningf("uevent listener failed, reconnecting in %v: %v", UEventReconnectDelay, err)
+		select {
+		case <-stop:
+			return
+		case <-time.After(UEventReconnectDelay):
+		}
+	}
+}
+
+// listen reads uevents from a new connection until stop is closed or the connection fails
+func (l *UEventListener) listen(stop <-chan struct{}) error {
+	conn, err := dialUEventSocket(l.group)
+	if err != nil {
+		return fmt.Errorf("failed to open uevent socket: %w", err)
+	}
+	defer conn.Close()
+	klog.Infof("listening to uevents of netlink group %d", l.group)
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		msg, err := conn.Read()
+		if err != nil {
+			return fmt.Errorf("failed to read uevent: %w", err)
+		}
+		if msg == nil {
+			continue
+		}
+		event, err := ParseUEvent(msg)
+		if err != nil {
+			klog.Warningf("ignoring invalid uevent: %v", err)
+			continue
+		}
+		if event.Subsystem != BlockSubsystem {
+			continue
+		}
+		// the /dev/disk/by-* symlinks of the device are only created once udev has processed the uevent
+		if l.group == KernelUEvents {
+			if err := settleUdev(); err != nil {
+				klog.Warningf("broadcasting %s uevent for %q before udev processed it: %v", event.Action, event.DevName, err)
+			}
+		}
+		l.broadcast(event)
+	}
+}
+
+func (l *UEventListener) broadcast(event UEvent) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, c := range l.subscribers {
+		select {
+		case c <- event:
+		default:
+			klog.Warningf("dropping %s uevent for %q, subscriber is not keeping up", event.Action, event.DevName)
+		}
+	}
+}
+
+// udevSettle runs udevadm settle, that returns once the udev event queue is empty
+func udevSettle() error {
+	cmd := ExecCommand("udevadm", "settle", "--timeout=10")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("udevadm settle failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (l *UEventListener) closeSubscribers() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, c := range l.subscribers {
+		close(c)
+	}
+	l.subscribers = nil
+}
+
+// ParseUEvent parses a netlink uevent message, sent either by the kernel or by udev
+func ParseUEvent(msg []byte) (UEvent, error) {
+	var fields [][]byte
+	if bytes.HasPrefix(msg, []byte(udevEventPrefix)) {
+		// udev header: prefix[8], magic, header_size, properties_off, properties_len, ...
+		if len(msg) < 24 {
+			return UEvent{}, fmt.Errorf("udev uevent too short: %d bytes", len(msg))
+		}
+		if magic := binary.BigEndian.Uint32(msg[8:12]); magic != udevMonitorMagic {
+			return UEvent{}, fmt.Errorf("invalid udev uevent magic %#x", magic)
+		}
+		offset := nativeEndian.Uint32(msg[16:20])
+		length := nativeEndian.Uint32(msg[20:24])
+		if uint64(offset)+uint64(length) > uint64(len(msg)) {
+			return UEvent{}, fmt.Errorf("invalid udev uevent properties offset %d and length %d", offset, length)
+		}
+		fields = bytes.Split(msg[offset:offset+length], []byte{0})
+	} else {
+		// kernel uevent: action@devpath followed by the properties
+		fields = bytes.Split(msg, []byte{0})
+		if len(fields) == 0 || !bytes.Contains(fields[0], []byte("@")) {
+			return UEvent{}, fmt.Errorf("invalid kernel uevent header")
+		}
+		fields = fields[1:]
+	}
+
+	event := UEvent{Properties: map[string]string{}}
+	for _, field := range fields {
+		parts := strings.SplitN(string(field), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		event.Properties[parts[0]] = parts[1]
+	}
+	event.Action = UEventAction(event.Properties["ACTION"])
+	event.DevPath = event.Properties["DEVPATH"]
+	event.Subsystem = event.Properties["SUBSYSTEM"]
+	event.DevType = event.Properties["DEVTYPE"]
+	event.DevName = strings.TrimPrefix(event.Properties["DEVNAME"], "/dev/")
+	if event.Action == "" || event.DevPath == "" {
+		return UEvent{}, fmt.Errorf("uevent without ACTION or DEVPATH")
+	}
+	return event, nil
+}
+
+func getNativeEndian() binary.ByteOrder {
+	i := uint16(1)
+	if *(*byte)(unsafe.Pointer(&i)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// checkUEventSender returns an error unless the message received from the netlink address from, with the
+// ancillary data oob, was multicast to group by the kernel or by udev.
+// Any process of the network namespace can send to the uevent groups, only the kernel sends from port 0
+// and udev runs as root.
+func checkUEventSender(group UEventGroup, from unix.Sockaddr, oob []byte) error {
+	sender, ok := from.(*unix.SockaddrNetlink)
+	if !ok {
+		return fmt.Errorf("uevent from a non-netlink address %T", from)
+	}
+	if sender.Groups&uint32(group) == 0 {
+		return fmt.Errorf("uevent not multicast to group %d, but to %d", group, sender.Groups)
+	}
+	if group == KernelUEvents && sender.Pid != 0 {
+		return fmt.Errorf("kernel uevent sent by netlink port %d instead of the kernel", sender.Pid)
+	}
+	messages, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return fmt.Errorf("failed to parse the credentials of the uevent: %w", err)
+	}
+	for i := range messages {
+		cred, err := unix.ParseUnixCredentials(&messages[i])
+		if err != nil {
+			continue
+		}
+		if cred.Uid != 0 {
+			return fmt.Errorf("uevent sent by uid %d instead of root", cred.Uid)
+		}
+		return nil
+	}
+	return fmt.Errorf("uevent without the credentials of its sender")
+}
+
+// netlinkUEventConn is a NETLINK_KOBJECT_UEVENT socket
+type netlinkUEventConn struct {
+	fd    int
+	group UEventGroup
+	buf   []byte
+	oob   []byte
+}
+
+func newNetlinkUEventConn(group UEventGroup) (ueventConn, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, err
+	}
+	// a larger receive buffer avoids losing events when many devices are added at once
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_RCVBUFFORCE, ueventSocketRcvBuf); err != nil {
+		_ = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_RCVBUF, ueventSocketRcvBuf)
+	}
+	// the credentials of the senders are checked before their uevents are trusted
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_PASSCRED, 1); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	timeout := unix.NsecToTimeval(ueventReadTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: uint32(group)}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &netlinkUEventConn{
+		fd:    fd,
+		group: group,
+		buf:   make([]byte, ueventBufferSize),
+		oob:   make([]byte, unix.CmsgSpace(unix.SizeofUcred)),
+	}, nil
+}
+
+func (c *netlinkUEventConn) Read() ([]byte, error) {
+	n, oobn, _, from, err := unix.Recvmsg(c.fd, c.buf, c.oob, 0)
+	if err != nil {
+		if err == unix.EAGAIN || err == unix.EWOULDBLOCK || err == unix.EINTR {
+			return nil, nil
+		}
+		if err == unix.ENOBUFS {
+			klog.Warning("uevent socket buffer overrun, some uevents were lost")
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := checkUEventSender(c.group, from, c.oob[:oobn]); err != nil {
+		klog.Warningf("ignoring uevent: %v", err)
+		return nil, nil
+	}
+	msg := make([]byte, n)
+	copy(msg, c.buf[:n])
+	return msg, nil
+}
+
+func (c *netlinkUEventConn) Close() error {
+	return unix.Close(c.fd)
+}
@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// fakeUEventConn returns the queued messages, then fails
+type fakeUEventConn struct {
+	messages [][]byte
+	closed   bool
+}
+
+func (c *fakeUEventConn) Read() ([]byte, error) {
+	if len(c.messages) == 0 {
+		return nil, fmt.Errorf("connection reset")
+	}
+	msg := c.messages[0]
+	c.messages = c.messages[1:]
+	return msg, nil
+}
+
+func (c *fakeUEventConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func kernelUEvent(action, devPath, subsystem, devName string) []byte {
+	fields := []string{
+		fmt.Sprintf("%s@%s", action, devPath),
+		"ACTION=" + action,
+		"DEVPATH=" + devPath,
+		"SUBSYSTEM=" + subsystem,
+		"DEVNAME=" + devName,
+		"DEVTYPE=disk",
+	}
+	return []byte(strings.Join(fields, "\x00") + "\x00")
+}
+
+func udevUEvent(action, devPath, devName string) []byte {
+	properties := []byte(strings.Join([]string{
+		"ACTION=" + action,
+		"DEVPATH=" + devPath,
+		"SUBSYSTEM=block",
+		"DEVNAME=/dev/" + devName,
+		"DEVTYPE=partition",
+		"ID_SERIAL=abc",
+	}, "\x00") + "\x00")
+	header := make([]byte, 40)
+	copy(header, udevEventPrefix)
+	binary.BigEndian.PutUint32(header[8:12], udevMonitorMagic)
+	nativeEndian.PutUint32(header[12:16], uint32(len(header)))
+	nativeEndian.PutUint32(header[16:20], uint32(len(header)))
+	nativeEndian.PutUint32(header[20:24], uint32(len(properties)))
+	return append(header, properties...)
+}
+
+func TestParseUEvent(t *testing.T) {
+	testcases := []struct {
+		label       string
+		msg         []byte
+		expectErr   bool
+		expected    UEvent
+		expectedKey string
+	}{
+		{
+			label: "Case 1: kernel uevent",
+			msg:   kernelUEvent("add", "/devices/virtual/block/loop0", "block", "loop0"),
+			expected: UEvent{
+				Action:    UEventAdd,
+				DevPath:   "/devices/virtual/block/loop0",
+				Subsystem: "block",
+				DevName:   "loop0",
+				DevType:   "disk",
+			},
+		},
+		{
+			label: "Case 2: udev uevent",
+			msg:   udevUEvent("remove", "/devices/pci0000:00/block/sdb/sdb1", "sdb1"),
+			expected: UEvent{
+				Action:    UEventRemove,
+				DevPath:   "/devices/pci0000:00/block/sdb/sdb1",
+				Subsystem: "block",
+				DevName:   "sdb1",
+				DevType:   "partition",
+			},
+			expectedKey: "ID_SERIAL",
+		},
+		{
+			label:     "Case 3: kernel uevent without header",
+			msg:       []byte("ACTION=add\x00DEVPATH=/devices/virtual/block/loop0\x00"),
+			expectErr: true,
+		},
+		{
+			label:     "Case 4: truncated udev uevent",
+			msg:       udevUEvent("add", "/devices/virtual/block/loop0", "loop0")[:20],
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		event, err := ParseUEvent(tc.msg)
+		if tc.expectErr {
+			assert.Errorf(t, err, "[%s] expected error", tc.label)
+			continue
+		}
+		assert.NoErrorf(t, err, "[%s]", tc.label)
+		assert.Equalf(t, tc.expected.Action, event.Action, "[%s] action", tc.label)
+		assert.Equalf(t, tc.expected.DevPath, event.DevPath, "[%s] devpath", tc.label)
+		assert.Equalf(t, tc.expected.Subsystem, event.Subsystem, "[%s] subsystem", tc.label)
+		assert.Equalf(t, tc.expected.DevName, event.DevName, "[%s] devname", tc.label)
+		assert.Equalf(t, tc.expected.DevType, event.DevType, "[%s] devtype", tc.label)
+		if tc.expectedKey != "" {
+			assert.Containsf(t, event.Properties, tc.expectedKey, "[%s] properties", tc.label)
+		}
+	}
+}
+
+func TestUEventListener(t *testing.T) {
+	defer func(dial func(UEventGroup) (ueventConn, error), settle func() error, delay time.Duration) {
+		dialUEventSocket = dial
+		settleUdev = settle
+		UEventReconnectDelay = delay
+	}(dialUEventSocket, settleUdev, UEventReconnectDelay)
+	UEventReconnectDelay = time.Millisecond
+	// the kernel uevents are broadcast once udev settled, even when it fails to
+	var settled int32
+	settleUdev = func() error {
+		atomic.AddInt32(&settled, 1)
+		return fmt.Errorf("udev is not running")
+	}
+
+	// the first connection fails after one event, the second one after the next two
+	conns := make(chan *fakeUEventConn, 2)
+	conns <- &fakeUEventConn{messages: [][]byte{
+		kernelUEvent("add", "/devices/virtual/block/loop0", "block", "loop0"),
+	}}
+	conns <- &fakeUEventConn{messages: [][]byte{
+		kernelUEvent("add", "/devices/virtual/net/veth0", "net", "veth0"),
+		[]byte("garbage"),
+		kernelUEvent("remove", "/devices/virtual/block/loop0", "block", "loop0"),
+	}}
+	dialUEventSocket = func(group UEventGroup) (ueventConn, error) {
+		select {
+		case conn := <-conns:
+			return conn, nil
+		default:
+			return nil, fmt.Errorf("no more connections")
+		}
+	}
+
+	listener := NewUEventListener(KernelUEvents)
+	first := listener.Subscribe()
+	second := listener.Subscribe()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		listener.Start(stop)
+		close(done)
+	}()
+
+	for _, c := range []<-chan UEvent{first, second} {
+		for _, expected := range []UEventAction{UEventAdd, UEventRemove} {
+			select {
+			case event := <-c:
+				assert.Equal(t, expected, event.Action)
+				assert.Equal(t, "loop0", event.DevName)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for %s uevent", expected)
+			}
+		}
+	}
+
+	close(stop)
+	<-done
+	_, ok := <-first
+	assert.False(t, ok, "subscriber channel should be closed")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&settled), "udev should settle before each block uevent")
+}
+
+func TestCheckUEventSender(t *testing.T) {
+	root := unix.UnixCredentials(&unix.Ucred{Pid: 412, Uid: 0, Gid: 0})
+	user := unix.UnixCredentials(&unix.Ucred{Pid: 4242, Uid: 1000, Gid: 1000})
+	testCases := []struct {
+		label   string
+		group   UEventGroup
+		from    unix.Sockaddr
+		oob     []byte
+		trusted bool
+	}{
+		{
+			label:   "udev uevent",
+			group:   UdevUEvents,
+			from:    &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: uint32(UdevUEvents), Pid: 412},
+			oob:     root,
+			trusted: true,
+		},
+		{
+			label:   "kernel uevent",
+			group:   KernelUEvents,
+			from:    &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: uint32(KernelUEvents)},
+			oob:     unix.UnixCredentials(&unix.Ucred{}),
+			trusted: true,
+		},
+		{
+			label: "uevent of an unprivileged process",
+			group: UdevUEvents,
+			from:  &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: uint32(UdevUEvents), Pid: 4242},
+			oob:   user,
+		},
+		{
+			label: "kernel uevent sent by a process",
+			group: KernelUEvents,
+			from:  &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: uint32(KernelUEvents), Pid: 412},
+			oob:   root,
+		},
+		{
+			label: "unicast uevent",
+			group: UdevUEvents,
+			from:  &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Pid: 412},
+			oob:   root,
+		},
+		{
+			label: "uevent without credentials",
+			group: UdevUEvents,
+			from:  &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: uint32(UdevUEvents), Pid: 412},
+		},
+	}
+	for _, tc := range testCases {
+		err := checkUEventSender(tc.group, tc.from, tc.oob)
+		if tc.trusted {
+			assert.NoErrorf(t, err, "[%s]", tc.label)
+		} else {
+			assert.Errorf(t, err, "[%s]", tc.label)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// XFSSetProject makes dir the root of the XFS project projectID on the filesystem mounted at mountPath.
+// The files created in dir inherit the project, so that they are accounted to its quota.
+func XFSSetProject(mountPath, dir string, projectID uint32) error {
+	return runXFSQuota(mountPath, fmt.Sprintf("project -s -p %s %d", dir, projectID))
+}
+
+// XFSLimitProject sets the hard block limit of the XFS project projectID on the filesystem mounted at mountPath.
+func XFSLimitProject(mountPath string, projectID uint32, bytes int64) error {
+	return runXFSQuota(mountPath, fmt.Sprintf("limit -p bhard=%d %d", bytes, projectID))
+}
+
+func runXFSQuota(mountPath, command string) error {
+	cmd := ExecCommand("xfs_quota", "-x", "-c", command, mountPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("xfs_quota %q failed: %w: %s", command, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
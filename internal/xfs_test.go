@@ -0,0 +1,18 @@
+package internal
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXFSProject(t *testing.T) {
+	defer func() { ExecCommand = exec.Command }()
+	ExecCommand = fsHelperCommand("", 0)
+	assert.NoError(t, XFSSetProject("/mnt/xfs", "/mnt/xfs/vol-1", 1))
+	assert.NoError(t, XFSLimitProject("/mnt/xfs", 1, 10*1024*1024))
+	ExecCommand = fsHelperCommand("", 1)
+	assert.Error(t, XFSSetProject("/mnt/xfs", "/mnt/xfs/vol-1", 1))
+	assert.Error(t, XFSLimitProject("/mnt/xfs", 1, 10*1024*1024))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var devicesNode string
+
+func runDevices(cmd *cobra.Command, args []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	w := newTabWriter()
+	defer w.Flush()
+	return printDevices(context.TODO(), c, w, namespace, devicesNode)
+}
+
+// printDevices writes a row per device discovered on the nodes, or on the node if it's not empty
+func printDevices(ctx context.Context, c client.Client, w io.Writer, namespace, node string) error {
+	results := &localv1.LocalVolumeDiscoveryResultList{}
+	err := c.List(ctx, results, client.InNamespace(namespace))
+	if err != nil {
+		return fmt.Errorf("could not list the LocalVolumeDiscoveryResults: %w", err)
+	}
+	sort.Slice(results.Items, func(i, j int) bool {
+		return results.Items[i].Spec.NodeName < results.Items[j].Spec.NodeName
+	})
+	fmt.Fprintln(w, "NODE\tPATH\tDEVICE ID\tTYPE\tSIZE\tMODEL\tVENDOR\tFSTYPE\tSTATE")
+	for _, result := range results.Items {
+		if node != "" && result.Spec.NodeName != node {
+			continue
+		}
+		for _, device := range result.Status.DiscoveredDevices {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				result.Spec.NodeName, device.Path, valueOrNone(device.DeviceID), device.Type,
+				resource.NewQuantity(device.Size, resource.BinarySI), valueOrNone(device.Model), valueOrNone(device.Vendor),
+				valueOrNone(device.FSType), device.Status.State)
+		}
+	}
+	return nil
+}
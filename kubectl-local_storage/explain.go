@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/openshift/local-storage-operator/diskmaker/controllers/lvset"
+	"github.com/openshift/local-storage-operator/internal"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// nodeFilters read the node, or properties of the devices that are not discovered.
+	// The state of the discovered devices sums them up.
+	nodeFilters = sets.NewString("notRemovable", "noBiosBootInPartLabel", "noBindMounts", "canOpenExclusively")
+	// discoveryFilters are always passed by the discovered devices, the devices that don't pass them are not discovered
+	discoveryFilters = sets.NewString("notReadOnly", "noChildren", "notSuspended")
+)
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	w := newTabWriter()
+	defer w.Flush()
+	return explain(context.TODO(), c, w, namespace, args[0], args[1])
+}
+
+// explain writes a row per device discovered on the node, with the filters and matchers of the LocalVolumeSet
+// it doesn't pass
+func explain(ctx context.Context, c client.Client, w io.Writer, namespace, lvsetName, nodeName string) error {
+	lvSet := &localv1.LocalVolumeSet{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: lvsetName}, lvSet)
+	if err != nil {
+		return fmt.Errorf("could not get LocalVolumeSet %s/%s: %w", namespace, lvsetName, err)
+	}
+	node := &corev1.Node{}
+	err = c.Get(ctx, types.NamespacedName{Name: nodeName}, node)
+	if err != nil {
+		return fmt.Errorf("could not get node %q: %w", nodeName, err)
+	}
+	matches, err := common.NodeSelectorMatchesNodeLabels(node, lvSet.Spec.NodeSelector)
+	if err != nil {
+		return err
+	} else if !matches {
+		fmt.Fprintf(w, "node %q does not match the nodeSelector of LocalVolumeSet %s/%s, none of its devices are provisioned\n", nodeName, namespace, lvsetName)
+		return nil
+	}
+
+	results := &localv1.LocalVolumeDiscoveryResultList{}
+	err = c.List(ctx, results, client.InNamespace(namespace), client.MatchingLabels{common.DiscoveryNodeLabel: nodeName})
+	if err != nil {
+		return fmt.Errorf("could not list the LocalVolumeDiscoveryResults: %w", err)
+	} else if len(results.Items) == 0 {
+		return fmt.Errorf("no LocalVolumeDiscoveryResult for node %q, a LocalVolumeDiscovery has to run on it", nodeName)
+	}
+	devices := results.Items[0].Status.DiscoveredDevices
+
+	// the device paths of the LocalVolumeSet are resolved with the links of the discovered devices
+	defer func(evalSymlinks func(string) (string, error)) { internal.FilePathEvalSymLinks = evalSymlinks }(internal.FilePathEvalSymLinks)
+	internal.FilePathEvalSymLinks = discoveredLinkResolver(devices)
+
+	fmt.Fprintln(w, "PATH\tDEVICE ID\tMATCH\tREASON")
+	for _, device := range devices {
+		reasons := explainDevice(device, lvSet.Spec.DeviceInclusionSpec)
+		match := "yes"
+		if len(reasons) > 0 {
+			match = "no"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", device.Path, valueOrNone(device.DeviceID), match, valueOrNone(strings.Join(reasons, ", ")))
+	}
+	return nil
+}
+
+// explainDevice returns the filters and matchers the device doesn't pass
+func explainDevice(device localv1.DiscoveredDevice, spec *localv1.DeviceInclusionSpec) []string {
+	blockDevice := toBlockDevice(device)
+	reasons := make([]string, 0)
+	if device.Status.State != localv1.Available {
+		reasons = append(reasons, fmt.Sprintf("%s on the node (%s)", device.Status.State, strings.Join(nodeFilters.List(), ", ")))
+	}
+	check := func(name string, matcher func(internal.BlockDevice, *localv1.DeviceInclusionSpec) (bool, error)) {
+		matched, err := matcher(blockDevice, spec)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %v", name, err))
+		} else if !matched {
+			reasons = append(reasons, name)
+		}
+	}
+	for _, name := range sortedKeys(lvset.FilterMap) {
+		if nodeFilters.Has(name) || discoveryFilters.Has(name) {
+			continue
+		}
+		check(name, lvset.FilterMap[name])
+	}
+	// the matchers may default the spec
+	spec = spec.DeepCopy()
+	for _, name := range sortedKeys(lvset.MatcherMap) {
+		check(name, lvset.MatcherMap[name])
+	}
+	return reasons
+}
+
+// toBlockDevice returns the block device the filters and matchers of the diskmaker see for the discovered device
+func toBlockDevice(device localv1.DiscoveredDevice) internal.BlockDevice {
+	rotational := "0"
+	if device.Property == localv1.Rotational {
+		rotational = "1"
+	}
+	kname := filepath.Base(device.Path)
+	return internal.BlockDevice{
+		Name:       kname,
+		KName:      kname,
+		Type:       string(device.Type),
+		Model:      device.Model,
+		Vendor:     device.Vendor,
+		FSType:     device.FSType,
+		Size:       strconv.FormatInt(device.Size, 10),
+		Rotational: rotational,
+		ReadOnly:   "0",
+		Removable:  "0",
+		PathByID:   device.DeviceID,
+		Serial:     device.Serial,
+		WWN:        device.WWN,
+	}
+}
+
+// discoveredLinkResolver evaluates the device IDs of the discovered devices to their path,
+// and their paths to themselves
+func discoveredLinkResolver(devices []localv1.DiscoveredDevice) func(string) (string, error) {
+	links := map[string]string{}
+	for _, device := range devices {
+		links[device.Path] = device.Path
+		if device.DeviceID != "" {
+			links[device.DeviceID] = device.Path
+		}
+	}
+	return func(path string) (string, error) {
+		if devPath, found := links[path]; found {
+			return devPath, nil
+		}
+		return "", &os.PathError{Op: "lstat", Path: path, Err: os.ErrNotExist}
+	}
+}
+
+func sortedKeys(m map[string]func(internal.BlockDevice, *localv1.DeviceInclusionSpec) (bool, error)) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+// kubectl-local_storage is a kubectl and oc plugin to inspect the devices and PVs of the local-storage-operator.
+// Installed in the PATH, it runs as "kubectl local-storage".
+package main
+
+import (
+	"flag"
+	"os"
+	"text/tabwriter"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	"github.com/spf13/cobra"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	scheme    = apiruntime.NewScheme()
+	namespace string
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	utilruntime.Must(localv1.AddToScheme(scheme))
+	utilruntime.Must(localv1alpha1.AddToScheme(scheme))
+}
+
+var rootCmd = &cobra.Command{
+	Use:          "kubectl local-storage",
+	Short:        "Used to inspect the devices and PVs of the local-storage-operator",
+	SilenceUsage: true,
+}
+var devicesCmd = &cobra.Command{
+	Use:   "devices",
+	Short: "Used to list the devices discovered on each node by the LocalVolumeDiscovery",
+	Args:  cobra.NoArgs,
+	RunE:  runDevices,
+}
+var pvsCmd = &cobra.Command{
+	Use:   "pvs",
+	Short: "Used to list the local PVs with their owner and device",
+	Args:  cobra.NoArgs,
+	RunE:  runPVs,
+}
+var explainCmd = &cobra.Command{
+	Use:   "explain LOCALVOLUMESET NODE",
+	Short: "Used to show why each device discovered on the node matches the LocalVolumeSet or not",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runExplain,
+}
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Used to list the local PVs whose LocalVolume or LocalVolumeSet no longer exists",
+	Args:  cobra.NoArgs,
+	RunE:  runOrphans,
+}
+
+func main() {
+	// the kubeconfig flag of controller-runtime
+	rootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "openshift-local-storage", "namespace of the local-storage-operator")
+	devicesCmd.Flags().StringVar(&devicesNode, "node", "", "only list the devices of this node")
+
+	rootCmd.AddCommand(devicesCmd)
+	rootCmd.AddCommand(pvsCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(orphansCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newClient() (client.Client, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+func newTabWriter() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+}
+
+// valueOrNone returns "<none>" for empty values, like kubectl get
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func runOrphans(cmd *cobra.Command, args []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	w := newTabWriter()
+	defer w.Flush()
+	return printOrphans(context.TODO(), c, w)
+}
+
+// printOrphans writes a row per local PV whose owner no longer exists
+func printOrphans(ctx context.Context, c client.Client, w io.Writer) error {
+	pvs, err := listLocalPVs(ctx, c)
+	if err != nil {
+		return err
+	}
+	ownerExists := map[string]bool{}
+	fmt.Fprintln(w, "NAME\tNODE\tSTATUS\tOWNER\tCLAIM")
+	for _, pv := range pvs {
+		owner := getOwner(pv)
+		exists, found := ownerExists[owner]
+		if !found {
+			exists, err = getOwnerExists(ctx, c, pv.Labels[common.PVOwnerKindLabel], pv.Labels[common.PVOwnerNamespaceLabel], pv.Labels[common.PVOwnerNameLabel])
+			if err != nil {
+				return err
+			}
+			ownerExists[owner] = exists
+		}
+		if exists {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pv.Name, pv.Labels[corev1.LabelHostname], getStatus(pv), owner, valueOrNone(getClaim(pv)))
+	}
+	return nil
+}
+
+// getOwnerExists returns true if the LocalVolume or LocalVolumeSet exists. The owners of other kinds are never orphaned.
+func getOwnerExists(ctx context.Context, c client.Client, kind, namespace, name string) (bool, error) {
+	var owner client.Object
+	switch kind {
+	case localv1.LocalVolumeKind:
+		owner = &localv1.LocalVolume{}
+	case localv1.LocalVolumeSetKind:
+		owner = &localv1.LocalVolumeSet{}
+	default:
+		return true, nil
+	}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, owner)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("could not get %s %s/%s: %w", kind, namespace, name, err)
+	}
+	return true, nil
+}
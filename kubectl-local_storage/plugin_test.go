@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newLocalPV(name, ownerKind, ownerName string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				corev1.LabelHostname:         "node1",
+				common.PVOwnerKindLabel:      ownerKind,
+				common.PVOwnerNameLabel:      ownerName,
+				common.PVOwnerNamespaceLabel: "openshift-local-storage",
+			},
+			Annotations: map[string]string{common.PVDeviceNameLabel: "sdb", common.PVDeviceIDLabel: "wwn-0x5000c500a0b1c2d3"},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			StorageClassName: "local-sc",
+			Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+}
+
+func TestPrintPVsAndOrphans(t *testing.T) {
+	lvset := &localv1.LocalVolumeSet{ObjectMeta: metav1.ObjectMeta{Name: "local-disks", Namespace: "openshift-local-storage"}}
+	c := fake.NewFakeClientWithScheme(scheme, lvset,
+		newLocalPV("local-pv-1", localv1.LocalVolumeSetKind, "local-disks"),
+		newLocalPV("local-pv-2", localv1.LocalVolumeKind, "deleted"),
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "other-pv"}},
+	)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, printPVs(context.TODO(), c, out))
+	assert.Equal(t, "NAME\tNODE\tSTORAGECLASS\tCAPACITY\tSTATUS\tOWNER\tDEVICE NAME\tDEVICE ID\tCLAIM\n"+
+		"local-pv-1\tnode1\tlocal-sc\t10Gi\tAvailable\tLocalVolumeSet/openshift-local-storage/local-disks\tsdb\twwn-0x5000c500a0b1c2d3\t<none>\n"+
+		"local-pv-2\tnode1\tlocal-sc\t10Gi\tAvailable\tLocalVolume/openshift-local-storage/deleted\tsdb\twwn-0x5000c500a0b1c2d3\t<none>\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, printOrphans(context.TODO(), c, out))
+	assert.Equal(t, "NAME\tNODE\tSTATUS\tOWNER\tCLAIM\n"+
+		"local-pv-2\tnode1\tAvailable\tLocalVolume/openshift-local-storage/deleted\t<none>\n", out.String())
+}
+
+func TestExplain(t *testing.T) {
+	minSize := resource.MustParse("20Gi")
+	lvset := &localv1.LocalVolumeSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-disks", Namespace: "openshift-local-storage"},
+		Spec: localv1.LocalVolumeSetSpec{
+			DeviceInclusionSpec: &localv1.DeviceInclusionSpec{
+				DeviceTypes:         []localv1.DeviceType{localv1.RawDisk},
+				MinSize:             &minSize,
+				ExcludedDevicePaths: []string{"/dev/disk/by-id/wwn-0x5000c500a0b1c2d5"},
+			},
+		},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	result := &localv1.LocalVolumeDiscoveryResult{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "discovery-result-node1",
+			Namespace: "openshift-local-storage",
+			Labels:    map[string]string{common.DiscoveryNodeLabel: "node1"},
+		},
+		Spec: localv1.LocalVolumeDiscoveryResultSpec{NodeName: "node1"},
+		Status: localv1.LocalVolumeDiscoveryResultStatus{
+			DiscoveredDevices: []localv1.DiscoveredDevice{
+				{Path: "/dev/sdb", DeviceID: "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", Type: localv1.DiskType, Size: 100 << 30, Status: localv1.DeviceStatus{State: localv1.Available}},
+				{Path: "/dev/sdc", DeviceID: "/dev/disk/by-id/wwn-0x5000c500a0b1c2d4", Type: localv1.DiskType, Size: 10 << 30, Status: localv1.DeviceStatus{State: localv1.Available}},
+				{Path: "/dev/sdd", DeviceID: "/dev/disk/by-id/wwn-0x5000c500a0b1c2d5", Type: localv1.DiskType, Size: 100 << 30, Status: localv1.DeviceStatus{State: localv1.Available}},
+				{Path: "/dev/sde1", Type: localv1.PartType, Size: 100 << 30, FSType: "xfs", Status: localv1.DeviceStatus{State: localv1.NotAvailable}},
+			},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, lvset, node, result)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, explain(context.TODO(), c, out, "openshift-local-storage", "local-disks", "node1"))
+	assert.Equal(t, "PATH\tDEVICE ID\tMATCH\tREASON\n"+
+		"/dev/sdb\t/dev/disk/by-id/wwn-0x5000c500a0b1c2d3\tyes\t<none>\n"+
+		"/dev/sdc\t/dev/disk/by-id/wwn-0x5000c500a0b1c2d4\tno\tinSizeRange\n"+
+		"/dev/sdd\t/dev/disk/by-id/wwn-0x5000c500a0b1c2d5\tno\tnotInExcludedPathList\n"+
+		"/dev/sde1\t<none>\tno\tNotAvailable on the node (canOpenExclusively, noBindMounts, noBiosBootInPartLabel, notRemovable), noFilesystemSignature, inTypeList\n",
+		out.String())
+
+	node.Labels = map[string]string{"storage": "false"}
+	lvset.Spec.NodeSelector = &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+		MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "storage", Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}}},
+	}}}
+	c = fake.NewFakeClientWithScheme(scheme, lvset, node, result)
+	out.Reset()
+	assert.NoError(t, explain(context.TODO(), c, out, "openshift-local-storage", "local-disks", "node1"))
+	assert.Contains(t, out.String(), "does not match the nodeSelector")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/openshift/local-storage-operator/common"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func runPVs(cmd *cobra.Command, args []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	w := newTabWriter()
+	defer w.Flush()
+	return printPVs(context.TODO(), c, w)
+}
+
+// listLocalPVs returns the PVs created by the diskmakers, sorted by name
+func listLocalPVs(ctx context.Context, c client.Client) ([]corev1.PersistentVolume, error) {
+	pvs := &corev1.PersistentVolumeList{}
+	err := c.List(ctx, pvs, client.HasLabels{common.PVOwnerKindLabel})
+	if err != nil {
+		return nil, fmt.Errorf("could not list the PVs: %w", err)
+	}
+	sort.Slice(pvs.Items, func(i, j int) bool {
+		return pvs.Items[i].Name < pvs.Items[j].Name
+	})
+	return pvs.Items, nil
+}
+
+// getOwner returns the kind/namespace/name of the owner of the PV
+func getOwner(pv corev1.PersistentVolume) string {
+	return fmt.Sprintf("%s/%s/%s", pv.Labels[common.PVOwnerKindLabel], pv.Labels[common.PVOwnerNamespaceLabel], pv.Labels[common.PVOwnerNameLabel])
+}
+
+// getStatus returns the phase of the PV, and whether it's fenced or quarantined
+func getStatus(pv corev1.PersistentVolume) string {
+	status := string(pv.Status.Phase)
+	if _, fenced := pv.Labels[common.PVFencedLabel]; fenced {
+		status += ",Fenced"
+	}
+	if _, quarantined := pv.Labels[common.PVQuarantinedLabel]; quarantined {
+		status += ",Quarantined"
+	}
+	return status
+}
+
+// getClaim returns the namespace/name of the PVC bound to the PV
+func getClaim(pv corev1.PersistentVolume) string {
+	if pv.Spec.ClaimRef == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+}
+
+// printPVs writes a row per local PV, with its owner and the device it was created on
+func printPVs(ctx context.Context, c client.Client, w io.Writer) error {
+	pvs, err := listLocalPVs(ctx, c)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "NAME\tNODE\tSTORAGECLASS\tCAPACITY\tSTATUS\tOWNER\tDEVICE NAME\tDEVICE ID\tCLAIM")
+	for _, pv := range pvs {
+		capacity := pv.Spec.Capacity[corev1.ResourceStorage]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			pv.Name, pv.Labels[corev1.LabelHostname], pv.Spec.StorageClassName, capacity.String(), getStatus(pv), getOwner(pv),
+			valueOrNone(pv.Annotations[common.PVDeviceNameLabel]), valueOrNone(pv.Annotations[common.PVDeviceIDLabel]),
+			valueOrNone(getClaim(pv)))
+	}
+	return nil
+}
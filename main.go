@@ -26,6 +26,7 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -41,6 +42,8 @@ import (
 	lvdcontroller "github.com/openshift/local-storage-operator/controllers/localvolumediscovery"
 	lvscontroller "github.com/openshift/local-storage-operator/controllers/localvolumeset"
 	nodedaemoncontroller "github.com/openshift/local-storage-operator/controllers/nodedaemon"
+	"github.com/openshift/local-storage-operator/controllers/storageversion"
+	"github.com/openshift/local-storage-operator/controllers/webhooks"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -54,6 +57,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(localv1.AddToScheme(scheme))
 	utilruntime.Must(localv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -139,6 +143,19 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "LocalVolumeSet")
 		os.Exit(1)
 	}
+	// the serving certificates of the webhooks are injected by OLM, or by the service CA operator with config/default
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		webhooks.SetupWithManager(mgr)
+		// the v1alpha1 objects are converted to v1 by the conversion webhook
+		if err = (&storageversion.Migrator{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Log:       logf.Log.WithName("storageversion"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to add the storage version migration")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
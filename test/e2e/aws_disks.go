@@ -14,7 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/onsi/gomega"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	"github.com/openshift/local-storage-operator/common"
 	framework "github.com/openshift/local-storage-operator/test-framework"
 	corev1 "k8s.io/api/core/v1"
@@ -57,10 +57,10 @@ func populateDeviceInfo(t *testing.T, ctx *framework.TestCtx, nodeEnv []nodeDisk
 	gomega.SetDefaultEventuallyTimeout(time.Minute * 10)
 	gomega.SetDefaultEventuallyPollingInterval(time.Second * 2)
 	// discover device names and ids
-	localVolumeDiscovery := &localv1alpha1.LocalVolumeDiscovery{
+	localVolumeDiscovery := &localv1.LocalVolumeDiscovery{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "LocalVolumeDiscovery",
-			APIVersion: localv1alpha1.GroupVersion.String(),
+			APIVersion: localv1.GroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "auto-discover-devices",
@@ -73,7 +73,7 @@ func populateDeviceInfo(t *testing.T, ctx *framework.TestCtx, nodeEnv []nodeDisk
 	}, time.Minute, time.Second*2).ShouldNot(gomega.HaveOccurred(), "creating localvolumediscovery")
 
 	// get discovery, and match disks on nodes with nodeEnv entries
-	discoveryResults := &localv1alpha1.LocalVolumeDiscoveryResultList{}
+	discoveryResults := &localv1.LocalVolumeDiscoveryResultList{}
 	// returns false if not matched, and retries till timeout
 	matcher.Eventually(func() bool {
 		t.Log("fetching localvolumediscoveryresults")
@@ -95,7 +95,7 @@ func populateDeviceInfo(t *testing.T, ctx *framework.TestCtx, nodeEnv []nodeDisk
 						for _, foundDevice := range result.Status.DiscoveredDevices {
 							matchesSize := foundDevice.Size == int64(diskEntry.size)*common.GiB
 							matchedPreviously := matchedPaths.Has(foundDevice.Path)
-							available := foundDevice.Status.State == localv1alpha1.Available
+							available := foundDevice.Status.State == localv1.Available
 							if !matchedPreviously && matchesSize && available { // matched result with disk
 								deviceFound = true
 								// save as already matched
@@ -7,8 +7,7 @@ import (
 	"time"
 
 	"github.com/onsi/gomega"
-	"github.com/openshift/local-storage-operator/api/v1alpha1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
+	localv1 "github.com/openshift/local-storage-operator/api/v1"
 	framework "github.com/openshift/local-storage-operator/test-framework"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -72,7 +71,7 @@ func LocalVolumeDiscoveryTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn)
 			t.Fatalf("error verifying localvolumediscovery cr: %v", err)
 		}
 
-		localVolumeDiscoveryResult := &localv1alpha1.LocalVolumeDiscoveryResult{
+		localVolumeDiscoveryResult := &localv1.LocalVolumeDiscoveryResult{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      fmt.Sprintf("discovery-result-%s", selectedNode.Name),
 				Namespace: namespace,
@@ -131,17 +130,17 @@ func LocalVolumeDiscoveryTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn)
 	}
 }
 
-func getFakeLocalVolumeDiscovery(selectedNode v1.Node, namespace string) *localv1alpha1.LocalVolumeDiscovery {
-	localVolumeDiscovery := &localv1alpha1.LocalVolumeDiscovery{
+func getFakeLocalVolumeDiscovery(selectedNode v1.Node, namespace string) *localv1.LocalVolumeDiscovery {
+	localVolumeDiscovery := &localv1.LocalVolumeDiscovery{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "LocalVolumeDiscovery",
-			APIVersion: localv1alpha1.GroupVersion.String(),
+			APIVersion: localv1.GroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "auto-discover-devices",
 			Namespace: namespace,
 		},
-		Spec: localv1alpha1.LocalVolumeDiscoverySpec{
+		Spec: localv1.LocalVolumeDiscoverySpec{
 			NodeSelector: &v1.NodeSelector{
 				NodeSelectorTerms: []v1.NodeSelectorTerm{
 					{
@@ -164,16 +163,16 @@ func getFakeLocalVolumeDiscovery(selectedNode v1.Node, namespace string) *localv
 	return localVolumeDiscovery
 }
 
-func checkLocalVolumeDiscoveryStatus(lvd *localv1alpha1.LocalVolumeDiscovery) error {
+func checkLocalVolumeDiscoveryStatus(lvd *localv1.LocalVolumeDiscovery) error {
 	localVolumeDiscoveryPhase := lvd.Status.Phase
-	if localVolumeDiscoveryPhase != localv1alpha1.Discovering {
+	if localVolumeDiscoveryPhase != localv1.Discovering {
 		return fmt.Errorf("expected local volume discovery to be in discovering phase")
 	}
 
 	return nil
 }
 
-func verifyLocalVolumeDiscovery(lvd *localv1alpha1.LocalVolumeDiscovery, client framework.FrameworkClient) error {
+func verifyLocalVolumeDiscovery(lvd *localv1.LocalVolumeDiscovery, client framework.FrameworkClient) error {
 	waitErr := wait.PollImmediate(retryInterval, timeout, func() (bool, error) {
 		objectKey := dynclient.ObjectKey{
 			Namespace: lvd.Namespace,
@@ -189,8 +188,8 @@ func verifyLocalVolumeDiscovery(lvd *localv1alpha1.LocalVolumeDiscovery, client
 	return waitErr
 }
 
-func verifyContinousDiscovery(t *testing.T, client framework.FrameworkClient, lvdr *localv1alpha1.LocalVolumeDiscoveryResult,
-	oldDevices []v1alpha1.DiscoveredDevice, nodeName string) error {
+func verifyContinousDiscovery(t *testing.T, client framework.FrameworkClient, lvdr *localv1.LocalVolumeDiscoveryResult,
+	oldDevices []localv1.DiscoveredDevice, nodeName string) error {
 	waitErr := wait.Poll(retryInterval, timeout, func() (bool, error) {
 		updatedDiscoveredDevices, err := verifyLocalVolumeDiscoveryResult(t, lvdr, nodeName, client)
 		if err != nil {
@@ -207,9 +206,9 @@ func verifyContinousDiscovery(t *testing.T, client framework.FrameworkClient, lv
 	return waitErr
 }
 
-func verifyLocalVolumeDiscoveryResult(t *testing.T, lvdr *localv1alpha1.LocalVolumeDiscoveryResult, nodeName string,
-	client framework.FrameworkClient) ([]v1alpha1.DiscoveredDevice, error) {
-	discoveredDevices := []v1alpha1.DiscoveredDevice{}
+func verifyLocalVolumeDiscoveryResult(t *testing.T, lvdr *localv1.LocalVolumeDiscoveryResult, nodeName string,
+	client framework.FrameworkClient) ([]localv1.DiscoveredDevice, error) {
+	discoveredDevices := []localv1.DiscoveredDevice{}
 	waitErr := wait.PollImmediate(retryInterval, timeout, func() (bool, error) {
 		objectKey := dynclient.ObjectKey{
 			Namespace: lvdr.Namespace,
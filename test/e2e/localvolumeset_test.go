@@ -12,7 +12,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	localv1 "github.com/openshift/local-storage-operator/api/v1"
-	localv1alpha1 "github.com/openshift/local-storage-operator/api/v1alpha1"
 	framework "github.com/openshift/local-storage-operator/test-framework"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
@@ -114,7 +113,7 @@ func LocalVolumeSetTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn) func(
 		two := int32(2)
 		three := int32(3)
 
-		lvSets := []*localv1alpha1.LocalVolumeSet{}
+		lvSets := []*localv1.LocalVolumeSet{}
 
 		// start the lvset with a size range of twenty to fifty on the first node
 		// should match amd claim 2 of node0: 20, 30, 40
@@ -123,12 +122,12 @@ func LocalVolumeSetTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn) func(
 		// node0: 2
 		// node1: 3
 		// node2: 3
-		twentyToFifty := &localv1alpha1.LocalVolumeSet{
+		twentyToFifty := &localv1.LocalVolumeSet{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "twentytofifty-1",
 				Namespace: namespace,
 			},
-			Spec: localv1alpha1.LocalVolumeSetSpec{
+			Spec: localv1.LocalVolumeSetSpec{
 				StorageClassName: "twentytofifty-1",
 				MaxDeviceCount:   &two,
 				VolumeMode:       localv1.PersistentVolumeBlock,
@@ -144,8 +143,8 @@ func LocalVolumeSetTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn) func(
 					},
 				},
 				},
-				DeviceInclusionSpec: &localv1alpha1.DeviceInclusionSpec{
-					DeviceTypes: []localv1alpha1.DeviceType{localv1alpha1.RawDisk},
+				DeviceInclusionSpec: &localv1.DeviceInclusionSpec{
+					DeviceTypes: []localv1.DeviceType{localv1.RawDisk},
 					MinSize:     &twentyGi,
 					MaxSize:     &fiftyGi,
 				},
@@ -160,7 +159,7 @@ func LocalVolumeSetTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn) func(
 		// node0: 1
 		// node1: 4
 		// node2: 4
-		tenToThirty := &localv1alpha1.LocalVolumeSet{}
+		tenToThirty := &localv1.LocalVolumeSet{}
 		twentyToFifty.DeepCopyInto(tenToThirty)
 		tenToThirty.ObjectMeta.Name = fmt.Sprintf("tentothirty-overlapping-%s-2", twentyToFifty.GetName())
 		tenToThirty.Spec.StorageClassName = tenToThirty.GetName()
@@ -170,7 +169,7 @@ func LocalVolumeSetTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn) func(
 		tenToThirty.Spec.DeviceInclusionSpec.MaxSize = &thirtyGi
 
 		// filesystem lvset that only matches the 3rd node
-		twentyToFiftyFilesystem := &localv1alpha1.LocalVolumeSet{}
+		twentyToFiftyFilesystem := &localv1.LocalVolumeSet{}
 		twentyToFifty.DeepCopyInto(twentyToFiftyFilesystem)
 		twentyToFiftyFilesystem.ObjectMeta.Name = "twentytofifty-fs-3"
 		twentyToFiftyFilesystem.Spec.StorageClassName = twentyToFiftyFilesystem.GetName()
@@ -178,7 +177,7 @@ func LocalVolumeSetTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn) func(
 
 		twentyToFiftyFilesystem.Spec.VolumeMode = localv1.PersistentVolumeFilesystem
 
-		lvSets = []*localv1alpha1.LocalVolumeSet{
+		lvSets = []*localv1.LocalVolumeSet{
 			twentyToFifty,
 			tenToThirty,
 			twentyToFiftyFilesystem,
@@ -324,7 +323,7 @@ func LocalVolumeSetTest(ctx *framework.TestCtx, cleanupFuncs *[]cleanupFn) func(
 
 }
 
-func cleanupLVSetResources(t *testing.T, lvsets []*localv1alpha1.LocalVolumeSet) error {
+func cleanupLVSetResources(t *testing.T, lvsets []*localv1.LocalVolumeSet) error {
 	for _, lvset := range lvsets {
 
 		t.Logf("cleaning up pvs and storageclasses: %q", lvset.GetName())
@@ -44,7 +44,7 @@ func TestMain(m *testing.M) {
 func TestLocalStorage(t *testing.T) {
 
 	// register CRD schemes
-	localVolumeDiscoveryList := &localv1alpha1.LocalVolumeDiscoveryList{}
+	localVolumeDiscoveryList := &localv1.LocalVolumeDiscoveryList{}
 	err := framework.AddToFrameworkScheme(api.AddToScheme, localVolumeDiscoveryList)
 	if err != nil {
 		t.Fatalf("error adding local volume discovery list : %v", err)
@@ -67,7 +67,7 @@ func TestLocalStorage(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error adding local volume list : %v", err)
 	}
-	localVolumeSetList := &localv1alpha1.LocalVolumeSetList{}
+	localVolumeSetList := &localv1.LocalVolumeSetList{}
 	err = framework.AddToFrameworkScheme(localv1.AddToScheme, localVolumeSetList)
 	if err != nil {
 		t.Fatalf("error adding local volume set list : %v", err)